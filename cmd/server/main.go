@@ -18,16 +18,19 @@
 package main
 
 import (
-	"context"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 
 	"github.com/princem/peripage-printer/internal/adapters/api"
 	_ "github.com/princem/peripage-printer/internal/adapters/docs"
 	"github.com/princem/peripage-printer/internal/adapters/printer"
+	"github.com/princem/peripage-printer/internal/adapters/printer/tinygoble"
+	"github.com/princem/peripage-printer/internal/adapters/store"
 	"github.com/princem/peripage-printer/internal/config"
 	"github.com/princem/peripage-printer/internal/core"
 )
@@ -43,54 +46,154 @@ func main() {
 	}
 
 	logger.Printf("Starting Peripage Printer Server")
-	logger.Printf("Printer type: %s", cfg.Printer.Type)
-
-	// Initialize the appropriate printer adapter
-	var printerAdapter core.Printer
-	var cleanup func()
-
-	switch cfg.Printer.Type {
-	case "mock":
-		logger.Println("Using mock printer adapter")
-		printerAdapter = printer.NewMockPrinter(logger)
-		cleanup = func() {}
-
-	case "ble":
-		logger.Println("Using BLE printer adapter")
-		blePrinter, err := printer.NewBLEPrinter(printer.BLEPrinterConfig{
-			DeviceName:  cfg.Printer.DeviceName,
-			ScanTimeout: cfg.BLE.ScanTimeout,
-			Logger:      logger,
-		})
-		if err != nil {
-			logger.Fatalf("Failed to initialize BLE printer: %v", err)
+
+	// Initialize the printer adapters. BLE printers share one transport,
+	// which is opened with the first of them.
+	registry := core.NewPrinterRegistry()
+	var mockInspector api.MockPrinter
+	var transport *tinygoble.Transport
+	var cleanups []func()
+
+	for _, printerCfg := range cfg.Printers {
+		logger.Printf("Printer %s: type %s", printerCfg.Name, printerCfg.Type)
+
+		var printerAdapter core.Printer
+		switch printerCfg.Type {
+		case "mock":
+			// Several mock printers each write to their own subdirectory
+			outputDir := cfg.Mock.OutputDir
+			if outputDir != "" && len(cfg.Printers) > 1 {
+				outputDir = filepath.Join(outputDir, printerCfg.Name)
+			}
+
+			mockPrinter, err := printer.NewMockPrinterWithConfig(printer.MockPrinterConfig{
+				OutputDir: outputDir,
+				Settings:  printerCfg.Settings,
+				Faults: printer.MockFaults{
+					FailEvery:        cfg.Mock.FailEvery,
+					FailureRate:      cfg.Mock.FailureRate,
+					Latency:          cfg.Mock.Latency,
+					PaperOut:         cfg.Mock.PaperOut,
+					LowBattery:       cfg.Mock.LowBattery,
+					Overheated:       cfg.Mock.Overheated,
+					DisconnectMidJob: cfg.Mock.DisconnectMidJob,
+				},
+				Logger: logger,
+			})
+			if err != nil {
+				logger.Fatalf("Failed to initialize mock printer %s: %v", printerCfg.Name, err)
+			}
+			if outputDir != "" {
+				logger.Printf("Writing output of mock printer %s to %s", printerCfg.Name, outputDir)
+			}
+
+			// The debug endpoints inspect the default printer, or the first
+			// mock printer when the default is a real one
+			if mockInspector == nil || printerCfg.Name == cfg.DefaultPrinter {
+				mockInspector = mockPrinter
+			}
+			printerAdapter = mockPrinter
+
+		case "ble":
+			if transport == nil {
+				var err error
+				transport, err = tinygoble.NewTransport(watchedServices(cfg.Printers)...)
+				if err != nil {
+					logger.Fatalf("Failed to initialize Bluetooth: %v", err)
+				}
+			}
+
+			blePrinter, err := printer.NewBLEPrinter(printer.BLEPrinterConfig{
+				DeviceName:     printerCfg.DeviceName,
+				Address:        printerCfg.Address,
+				NamePrefix:     printerCfg.NamePrefix,
+				NamePattern:    printerCfg.NamePattern,
+				ServiceUUID:    printerCfg.ServiceUUID,
+				Settings:       printerCfg.Settings,
+				MatchWindow:    cfg.BLE.MatchWindow,
+				ScanTimeout:    cfg.BLE.ScanTimeout,
+				ConnectTimeout: printerCfg.Timeout,
+				ReconnectWait:  printerCfg.Timeout,
+				MinBackoff:     cfg.BLE.ReconnectMinBackoff,
+				MaxBackoff:     cfg.BLE.ReconnectMaxBackoff,
+				Transport:      transport,
+				Logger:         logger,
+			})
+			if err != nil {
+				logger.Fatalf("Failed to initialize BLE printer %s: %v", printerCfg.Name, err)
+			}
+
+			// Connect in the background and keep reconnecting, so the server
+			// starts even while the printer is switched off
+			blePrinter.Start()
+
+			name := printerCfg.Name
+			cleanups = append(cleanups, func() {
+				logger.Printf("Disconnecting from printer %s...", name)
+				if err := blePrinter.Disconnect(); err != nil {
+					logger.Printf("Error disconnecting from printer %s: %v", name, err)
+				}
+			})
+			printerAdapter = blePrinter
+
+		default:
+			logger.Fatalf("Unknown printer type: %s", printerCfg.Type)
 		}
 
-		// Connect to printer
-		ctx, cancel := context.WithTimeout(context.Background(), cfg.Printer.Timeout)
-		defer cancel()
+		if err := registry.Register(printerCfg.Name, printerCfg.Type, printerAdapter); err != nil {
+			logger.Fatalf("Failed to register printer: %v", err)
+		}
+	}
+	for _, poolCfg := range cfg.Pools {
+		if err := registry.RegisterPool(poolCfg.Name, poolCfg.Members...); err != nil {
+			logger.Fatalf("Failed to register pool: %v", err)
+		}
+		logger.Printf("Pool %s: %s", poolCfg.Name, strings.Join(poolCfg.Members, ", "))
+	}
+	if err := registry.SetDefault(cfg.DefaultPrinter); err != nil {
+		logger.Fatalf("Failed to set default printer: %v", err)
+	}
+	logger.Printf("Default printer: %s", cfg.DefaultPrinter)
 
-		if err := blePrinter.Connect(ctx); err != nil {
-			logger.Fatalf("Failed to connect to printer: %v", err)
+	// Open the job store so queued jobs survive restarts
+	queueConfig := core.QueueConfig{
+		Retention: cfg.Jobs.Retention,
+		Logger:    logger,
+	}
+	if cfg.Jobs.StorePath != "" {
+		jobStore, err := store.NewFileJobStore(cfg.Jobs.StorePath)
+		if err != nil {
+			logger.Fatalf("Failed to open job store: %v", err)
 		}
+		defer jobStore.Close()
 
-		printerAdapter = blePrinter
-		cleanup = func() {
-			logger.Println("Disconnecting from printer...")
-			if err := blePrinter.Disconnect(); err != nil {
-				logger.Printf("Error disconnecting from printer: %v", err)
-			}
+		logger.Printf("Persisting jobs to %s", cfg.Jobs.StorePath)
+		queueConfig.Store = jobStore
+	}
+
+	// Open the template store so print templates survive restarts
+	serviceConfig := core.PrintServiceConfig{Queue: queueConfig}
+	if cfg.Templates.StorePath != "" {
+		templateStore, err := store.NewFileTemplateStore(cfg.Templates.StorePath)
+		if err != nil {
+			logger.Fatalf("Failed to open template store: %v", err)
 		}
 
-	default:
-		logger.Fatalf("Unknown printer type: %s", cfg.Printer.Type)
+		logger.Printf("Persisting templates to %s", cfg.Templates.StorePath)
+		serviceConfig.Templates = templateStore
 	}
 
 	// Initialize core service
-	printService := core.NewPrintService(printerAdapter)
+	printService, err := core.NewPrintServiceWithRegistry(registry, serviceConfig)
+	if err != nil {
+		logger.Fatalf("Failed to initialize print service: %v", err)
+	}
 
 	// Initialize API handler
 	handler := api.NewHandler(printService)
+	if mockInspector != nil {
+		handler.WithMockPrinter(mockInspector)
+	}
 
 	// Setup router
 	router := api.SetupRouter(handler)
@@ -112,8 +215,25 @@ func main() {
 
 	logger.Println("Shutting down server...")
 
+	// Let the jobs being printed finish before releasing the printers
+	printService.Close()
+
 	// Cleanup
-	cleanup()
+	for _, cleanup := range cleanups {
+		cleanup()
+	}
 
 	logger.Println("Server stopped")
 }
+
+// watchedServices returns the extra service UUIDs the BLE printers match on,
+// so the transport reports them in advertisements.
+func watchedServices(printers []config.PrinterConfig) []string {
+	var uuids []string
+	for _, printer := range printers {
+		if printer.Type == "ble" && printer.ServiceUUID != "" {
+			uuids = append(uuids, printer.ServiceUUID)
+		}
+	}
+	return uuids
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/princem/peripage-printer/internal/adapters/printer"
+)
+
+// mockFilesPath is where the mock printer's PNG output is served.
+const mockFilesPath = "/debug/mock/files"
+
+// MockPrinter is the inspection interface of the mock printer adapter.
+type MockPrinter interface {
+	Printed() []printer.PrintedJob
+	OutputDir() string
+	Faults() printer.MockFaults
+	SetFaults(faults printer.MockFaults) error
+}
+
+// MockFaultsBody represents the failures simulated by the mock printer.
+type MockFaultsBody struct {
+	FailEvery        int     `json:"fail_every" example:"3"`
+	FailureRate      float64 `json:"failure_rate" example:"0.1"`
+	Latency          string  `json:"latency" example:"500ms"`
+	PaperOut         bool    `json:"paper_out" example:"false"`
+	LowBattery       bool    `json:"low_battery" example:"false"`
+	Overheated       bool    `json:"overheated" example:"false"`
+	DisconnectMidJob bool    `json:"disconnect_mid_job" example:"false"`
+}
+
+// newMockFaultsBody converts mock printer faults to their JSON form.
+func newMockFaultsBody(faults printer.MockFaults) MockFaultsBody {
+	return MockFaultsBody{
+		FailEvery:        faults.FailEvery,
+		FailureRate:      faults.FailureRate,
+		Latency:          faults.Latency.String(),
+		PaperOut:         faults.PaperOut,
+		LowBattery:       faults.LowBattery,
+		Overheated:       faults.Overheated,
+		DisconnectMidJob: faults.DisconnectMidJob,
+	}
+}
+
+// faults converts the JSON form back to mock printer faults.
+func (b MockFaultsBody) faults() (printer.MockFaults, error) {
+	var latency time.Duration
+	if b.Latency != "" {
+		var err error
+		latency, err = time.ParseDuration(b.Latency)
+		if err != nil {
+			return printer.MockFaults{}, err
+		}
+	}
+
+	return printer.MockFaults{
+		FailEvery:        b.FailEvery,
+		FailureRate:      b.FailureRate,
+		Latency:          latency,
+		PaperOut:         b.PaperOut,
+		LowBattery:       b.LowBattery,
+		Overheated:       b.Overheated,
+		DisconnectMidJob: b.DisconnectMidJob,
+	}, nil
+}
+
+// MockPrintsResponse represents the response for the mock print listing endpoint.
+type MockPrintsResponse struct {
+	Prints []printer.PrintedJob `json:"prints"`
+	Count  int                  `json:"count" example:"1"`
+	// Roll is the URL of the continuous paper roll image, when PNG output is enabled.
+	Roll string `json:"roll,omitempty" example:"/debug/mock/files/roll.png"`
+}
+
+// WithMockPrinter enables the /debug/mock endpoints for the given mock printer.
+func (h *Handler) WithMockPrinter(mock MockPrinter) *Handler {
+	h.mock = mock
+	return h
+}
+
+// ListMockPrints handles the GET /debug/mock/prints endpoint.
+// @Summary List mock printer output
+// @Description Lists the jobs printed by the mock printer. When PNG output is enabled,
+// @Description each entry names its image under /debug/mock/files.
+// @Tags debug
+// @Produce json
+// @Success 200 {object} MockPrintsResponse
+// @Router /debug/mock/prints [get]
+func (h *Handler) ListMockPrints(c *gin.Context) {
+	prints := h.mock.Printed()
+
+	response := MockPrintsResponse{
+		Prints: prints,
+		Count:  len(prints),
+	}
+	if h.mock.OutputDir() != "" {
+		response.Roll = mockFilesPath + "/" + printer.RollFile
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetMockFaults handles the GET /debug/mock endpoint.
+// @Summary Get simulated mock printer failures
+// @Description Returns the failures the mock printer currently simulates
+// @Tags debug
+// @Produce json
+// @Success 200 {object} MockFaultsBody
+// @Router /debug/mock [get]
+func (h *Handler) GetMockFaults(c *gin.Context) {
+	c.JSON(http.StatusOK, newMockFaultsBody(h.mock.Faults()))
+}
+
+// SetMockFaults handles the PUT /debug/mock endpoint.
+// @Summary Set simulated mock printer failures
+// @Description Replaces the failures the mock printer simulates. Omitted fields are turned off.
+// @Tags debug
+// @Accept json
+// @Produce json
+// @Param request body MockFaultsBody true "Simulated failures"
+// @Success 200 {object} MockFaultsBody
+// @Failure 400 {object} ErrorResponse
+// @Router /debug/mock [put]
+func (h *Handler) SetMockFaults(c *gin.Context) {
+	var body MockFaultsBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	faults, err := body.faults()
+	if err == nil {
+		err = h.mock.SetFaults(faults)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid faults: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, newMockFaultsBody(h.mock.Faults()))
+}
+
+// setupMockRoutes registers the mock printer inspection endpoints.
+func setupMockRoutes(router *gin.Engine, handler *Handler) {
+	router.GET("/debug/mock", handler.GetMockFaults)
+	router.PUT("/debug/mock", handler.SetMockFaults)
+	router.GET("/debug/mock/prints", handler.ListMockPrints)
+	if dir := handler.mock.OutputDir(); dir != "" {
+		router.Static(mockFilesPath, dir)
+	}
+}
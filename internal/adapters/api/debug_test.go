@@ -0,0 +1,110 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/princem/peripage-printer/internal/adapters/printer"
+	"github.com/princem/peripage-printer/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_ListMockPrints(t *testing.T) {
+	// Arrange: a real mock printer writing PNGs behind the real router
+	mockPrinter, err := printer.NewMockPrinterWithConfig(printer.MockPrinterConfig{
+		OutputDir: t.TempDir(),
+		Logger:    log.New(&bytes.Buffer{}, "", 0),
+	})
+	require.NoError(t, err)
+	service := core.NewPrintService(mockPrinter)
+	defer service.Close()
+	router := SetupRouter(NewHandler(service).WithMockPrinter(mockPrinter))
+
+	require.NoError(t, service.PrintText("Hello"))
+
+	// Act
+	w := doRequest(router, http.MethodGet, "/debug/mock/prints")
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response MockPrintsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Equal(t, 1, response.Count)
+	assert.Equal(t, "Hello", response.Prints[0].Text)
+	assert.Equal(t, "/debug/mock/files/roll.png", response.Roll)
+
+	// Act & Assert: the PNG files are served
+	w = doRequest(router, http.MethodGet, "/debug/mock/files/"+response.Prints[0].File)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+
+	w = doRequest(router, http.MethodGet, response.Roll)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestSetupRouter_MockRoutesOnlyForMockPrinter(t *testing.T) {
+	service := core.NewPrintService(printer.NewMockPrinter(log.New(&bytes.Buffer{}, "", 0)))
+	defer service.Close()
+	router := SetupRouter(NewHandler(service))
+
+	w := doRequest(router, http.MethodGet, "/debug/mock/prints")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandler_MockFaults(t *testing.T) {
+	// Arrange
+	mockPrinter := printer.NewMockPrinter(log.New(&bytes.Buffer{}, "", 0))
+	service := core.NewPrintService(mockPrinter)
+	defer service.Close()
+	router := SetupRouter(NewHandler(service).WithMockPrinter(mockPrinter))
+
+	// Act: run out of paper
+	body := `{"paper_out": true, "latency": "5ms", "fail_every": 4}`
+	req := httptest.NewRequest(http.MethodPut, "/debug/mock", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, printer.MockFaults{PaperOut: true, Latency: 5 * time.Millisecond, FailEvery: 4}, mockPrinter.Faults())
+
+	w = doRequest(router, http.MethodGet, "/debug/mock")
+	require.Equal(t, http.StatusOK, w.Code)
+	var faults MockFaultsBody
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &faults))
+	assert.True(t, faults.PaperOut)
+	assert.Equal(t, "5ms", faults.Latency)
+
+	// Act & Assert: printing now fails with 503
+	req = httptest.NewRequest(http.MethodPost, "/print", bytes.NewBufferString(`{"text": "Hello"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "out of paper")
+}
+
+func TestHandler_SetMockFaults_Invalid(t *testing.T) {
+	mockPrinter := printer.NewMockPrinter(log.New(&bytes.Buffer{}, "", 0))
+	service := core.NewPrintService(mockPrinter)
+	defer service.Close()
+	router := SetupRouter(NewHandler(service).WithMockPrinter(mockPrinter))
+
+	for _, body := range []string{`{"failure_rate": 2}`, `{"latency": "soon"}`, `not json`} {
+		req := httptest.NewRequest(http.MethodPut, "/debug/mock", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+	assert.Equal(t, printer.MockFaults{}, mockPrinter.Faults())
+}
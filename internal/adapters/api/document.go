@@ -0,0 +1,69 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/princem/peripage-printer/internal/core"
+)
+
+// DocumentPrintRequest represents the request body for the document print endpoint.
+type DocumentPrintRequest struct {
+	// Blocks are printed top to bottom as one job.
+	Blocks []core.Block `json:"blocks" binding:"required"`
+	Async  bool         `json:"async,omitempty" example:"false"`
+	// Printer names the printer or pool to print on. Empty selects the default.
+	Printer string `json:"printer,omitempty" example:"front-desk"`
+	// PrintSettings override the printer's density, speed and paper type for this job.
+	core.PrintSettings
+	// JobLayout adds margins and a tear line around the job.
+	core.JobLayout
+}
+
+// PrintDocument handles the POST /print/document endpoint.
+// @Summary Print a document
+// @Description Lays out an ordered list of blocks into one print job, so a receipt can combine
+// @Description a logo, a header, line items and a QR code. Block types are text (with size, bold,
+// @Description italic, mono and align), image (base64, with width, align and dither), qr, barcode,
+// @Description divider, spacer (height in dot lines), table (header and rows, with optional column
+// @Description widths and alignment, cell padding and borders) and columns (lists of blocks side by
+// @Description side, with optional widths).
+// @Tags print
+// @Accept json
+// @Produce json
+// @Param request body DocumentPrintRequest true "Document print request"
+// @Success 200 {object} PrintResponse
+// @Success 202 {object} PrintResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /print/document [post]
+func (h *Handler) PrintDocument(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImageUpload)
+
+	var req DocumentPrintRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{
+				Error: "Document is larger than 10 MB",
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	h.dispatch(c, core.JobRequest{
+		Kind:          core.JobDocument,
+		Document:      &core.Document{Blocks: req.Blocks},
+		Printer:       req.Printer,
+		PrintSettings: req.PrintSettings,
+		JobLayout:     req.JobLayout,
+	}, req.Async)
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/princem/peripage-printer/internal/adapters/printer"
+	"github.com/princem/peripage-printer/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_PrintDocument(t *testing.T) {
+	logo := base64.StdEncoding.EncodeToString(testPNG(t))
+	receipt := `{"blocks": [
+		{"type": "image", "image": "` + logo + `", "width": 96, "align": "center"},
+		{"type": "text", "text": "Corner Café", "style": {"size": 28, "bold": true}, "align": "center"},
+		{"type": "divider", "dashed": true},
+		{"type": "table", "table": {"header": ["Item", "Qty", "Price"], "rows": [["Tea", "2", "5.00"], ["Cake", "1", "3.50"]]}},
+		{"type": "table", "table": {
+			"rows": [["Total", "8.50"]],
+			"columns": [{"ratio": 3}, {"width": 96, "align": "right"}],
+			"padding": 4,
+			"borders": true
+		}},
+		{"type": "spacer", "height": 16},
+		{"type": "columns", "columns": [
+			{"ratio": 2, "blocks": [{"type": "text", "text": "Scan to pay"}]},
+			{"blocks": [{"type": "qr", "qr": {"data": "https://example.com/pay/1042"}}]}
+		]},
+		{"type": "barcode", "barcode": {"data": "ORDER-1042"}}
+	]`
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		errorContains  string
+	}{
+		{name: "receipt", body: receipt + `}`, expectedStatus: http.StatusOK},
+		{name: "queued", body: receipt + `, "async": true, "tear_line": true}`, expectedStatus: http.StatusAccepted},
+		{name: "missing blocks", body: `{}`, expectedStatus: http.StatusBadRequest, errorContains: "Invalid request body"},
+		{name: "no blocks", body: `{"blocks": []}`, expectedStatus: http.StatusBadRequest, errorContains: "document needs at least one block"},
+		{name: "unknown block type", body: `{"blocks": [{"type": "video"}]}`, expectedStatus: http.StatusBadRequest, errorContains: "unknown block type"},
+		{name: "invalid image", body: `{"blocks": [{"type": "image", "image": "bm9wZQ=="}]}`, expectedStatus: http.StatusBadRequest, errorContains: "block 1: unsupported image"},
+		{name: "image not base64", body: `{"blocks": [{"type": "image", "image": "not base64!"}]}`, expectedStatus: http.StatusBadRequest, errorContains: "Invalid request body"},
+		{name: "row wider than the columns", body: `{"blocks": [{"type": "table", "table": {"rows": [["a", "b"]], "columns": [{"width": 96}]}}]}`, expectedStatus: http.StatusBadRequest, errorContains: "block 1: table has rows of 2 cells but only 1 columns"},
+		{name: "wrong check digit when queued", body: `{"blocks": [{"type": "barcode", "barcode": {"type": "ean13", "data": "4006381333932"}}], "async": true}`, expectedStatus: http.StatusBadRequest, errorContains: "check digit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockPrinter := printer.NewMockPrinter(log.New(&bytes.Buffer{}, "", 0))
+			service := core.NewPrintService(mockPrinter)
+			defer service.Close()
+			router := SetupRouter(NewHandler(service))
+
+			req := httptest.NewRequest(http.MethodPost, "/print/document", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.errorContains != "" {
+				assert.Contains(t, w.Body.String(), tt.errorContains)
+				assert.Empty(t, mockPrinter.Printed())
+				return
+			}
+			if tt.expectedStatus == http.StatusOK {
+				printed := mockPrinter.Printed()
+				require.Len(t, printed, 1)
+				assert.Equal(t, "image", printed[0].Kind)
+				assert.Greater(t, printed[0].Height, 300)
+			}
+		})
+	}
+}
+
+func TestHandler_PrintDocument_TooLarge(t *testing.T) {
+	// Arrange
+	router := SetupRouter(&Handler{service: &mockPrintService{}})
+	body := `{"blocks": [{"type": "text", "text": "` + strings.Repeat("x", maxImageUpload) + `"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/print/document", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
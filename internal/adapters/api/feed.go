@@ -0,0 +1,67 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/princem/peripage-printer/internal/core"
+)
+
+// FeedRequest represents the request body for the feed endpoint.
+type FeedRequest struct {
+	// Lines is the number of blank dot lines to feed, 8 per millimetre.
+	Lines int `json:"lines" binding:"required" example:"80"`
+	// Printer names the printer to feed. Empty selects the default.
+	Printer string `json:"printer,omitempty" example:"front-desk"`
+}
+
+// FeedPaper handles the POST /printer/feed endpoint.
+// @Summary Feed paper
+// @Description Advances the paper without printing, e.g. to tear off the last job.
+// @Description The feed waits for the job printing on the printer to finish.
+// @Tags printer
+// @Accept json
+// @Produce json
+// @Param request body FeedRequest true "Feed request"
+// @Success 200 {object} PrintResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /printer/feed [post]
+func (h *Handler) FeedPaper(c *gin.Context) {
+	var req FeedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	err := h.service.Feed(req.Printer, req.Lines)
+	var validationErr *core.ValidationError
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, PrintResponse{
+			Success: true,
+			Message: fmt.Sprintf("Fed %d lines", req.Lines),
+		})
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid feed request: " + err.Error(),
+		})
+	case errors.Is(err, core.ErrPrinterNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error: err.Error(),
+		})
+	case errors.Is(err, core.ErrFeedUnavailable):
+		c.JSON(http.StatusNotImplemented, ErrorResponse{
+			Error: err.Error(),
+		})
+	default:
+		h.printError(c, err)
+	}
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/princem/peripage-printer/internal/adapters/printer"
+	"github.com/princem/peripage-printer/internal/core"
+	"github.com/princem/peripage-printer/internal/core/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_FeedPaper(t *testing.T) {
+	mockPrinter := func(faults printer.MockFaults) core.Printer {
+		p, err := printer.NewMockPrinterWithConfig(printer.MockPrinterConfig{
+			Faults: faults,
+			Logger: log.New(&bytes.Buffer{}, "", 0),
+		})
+		require.NoError(t, err)
+		return p
+	}
+
+	tests := []struct {
+		name           string
+		printer        core.Printer
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "feeds the default printer",
+			printer:        mockPrinter(printer.MockFaults{}),
+			body:           `{"lines": 80}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing lines",
+			printer:        mockPrinter(printer.MockFaults{}),
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "too many lines",
+			printer:        mockPrinter(printer.MockFaults{}),
+			body:           `{"lines": 100000}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown printer",
+			printer:        mockPrinter(printer.MockFaults{}),
+			body:           `{"lines": 80, "printer": "bar"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "printer cannot feed",
+			printer:        new(mocks.MockPrinter),
+			body:           `{"lines": 80}`,
+			expectedStatus: http.StatusNotImplemented,
+		},
+		{
+			name:           "printer out of paper",
+			printer:        mockPrinter(printer.MockFaults{PaperOut: true}),
+			body:           `{"lines": 80}`,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service := core.NewPrintService(tt.printer)
+			defer service.Close()
+			router := SetupRouter(NewHandler(service))
+
+			req := httptest.NewRequest(http.MethodPost, "/printer/feed", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response PrintResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.True(t, response.Success)
+			}
+		})
+	}
+}
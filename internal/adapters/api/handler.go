@@ -1,22 +1,45 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/princem/peripage-printer/internal/core"
+	"github.com/princem/peripage-printer/internal/render"
 )
 
 // PrintService defines the interface for print operations.
 // This allows for easy mocking in tests.
 type PrintService interface {
-	PrintText(text string) error
-	PrintJSON(data interface{}) error
+	Print(ctx context.Context, req core.JobRequest) (core.Job, error)
+	Submit(req core.JobRequest) (core.Job, error)
+	Job(id string) (core.Job, error)
+	Jobs() []core.Job
+	Cancel(id string) (core.Job, error)
+	Reprint(id string) (core.Job, error)
+	Preview(req core.JobRequest) (*render.Bitmap, error)
+	Printers() []core.PrinterInfo
+	Pools() []core.PoolInfo
+	PrinterStatus(printer string) (core.PrinterStatus, error)
+	Readiness() core.Readiness
+	ScanDevices(ctx context.Context, printer string, duration time.Duration) ([]core.DiscoveredDevice, error)
+	Feed(printer string, lines int) error
+	Templates() []core.Template
+	Template(name string) (core.Template, error)
+	CreateTemplate(t core.Template) (core.Template, error)
+	PutTemplate(t core.Template) (core.Template, bool, error)
+	DeleteTemplate(name string) error
+	RenderTemplate(name string, data map[string]interface{}) (core.JobRequest, error)
 }
 
 // Handler manages HTTP requests for the printer API.
 type Handler struct {
 	service PrintService
+	mock    MockPrinter
 }
 
 // NewHandler creates a new API handler.
@@ -30,12 +53,30 @@ func NewHandler(service *core.PrintService) *Handler {
 type PrintRequest struct {
 	Text string                 `json:"text" example:"Hello, World!"`
 	Data map[string]interface{} `json:"data,omitempty"`
+	// Fields selects the parts of data to print, as paths like "customer.name" or "items[*].price".
+	Fields []string `json:"fields,omitempty" example:"customer.name,items[*].name,total"`
+	// Format selects how text is printed: plain text or Markdown.
+	Format string `json:"format,omitempty" example:"text" enums:"text,markdown"`
+	// QR prints a QR code instead of text or data.
+	QR *core.QRCode `json:"qr,omitempty"`
+	// Barcode prints a 1D barcode instead of text or data.
+	Barcode *core.Barcode `json:"barcode,omitempty"`
+	// Async queues the job and returns 202 Accepted without waiting for the printer.
+	Async bool `json:"async,omitempty" example:"false"`
+	// Printer names the printer or pool to print on. Empty selects the default.
+	Printer string `json:"printer,omitempty" example:"front-desk"`
+	// PrintSettings override the printer's density, speed and paper type for this job.
+	core.PrintSettings
+	// JobLayout adds margins and a tear line around the job.
+	core.JobLayout
 }
 
 // PrintResponse represents the response for the print endpoint.
 type PrintResponse struct {
-	Success bool   `json:"success" example:"true"`
-	Message string `json:"message" example:"Print job completed successfully"`
+	Success bool          `json:"success" example:"true"`
+	Message string        `json:"message" example:"Print job completed successfully"`
+	JobID   string        `json:"job_id,omitempty" example:"9f86d081884c7d65"`
+	State   core.JobState `json:"state,omitempty" example:"done"`
 }
 
 // ErrorResponse represents an error response.
@@ -44,15 +85,26 @@ type ErrorResponse struct {
 }
 
 // Print handles the POST /print endpoint.
-// @Summary Print text or JSON data
-// @Description Prints text or formatted JSON data to the Peripage printer
+// @Summary Print text, JSON data, a QR code or a barcode
+// @Description Prints text or JSON data to the Peripage printer. JSON is laid out as a receipt
+// @Description with bold keys; set "fields" to print only some of it.
+// @Description Set "format" to "markdown" to print text with headings, emphasis, lists, checkboxes, rules and code.
+// @Description Set "qr" to print a QR code with an optional caption instead,
+// @Description or "barcode" to print a Code 128, EAN-13, UPC-A or Code 39 barcode.
+// @Description Set "async" to queue the job and get its ID back immediately.
+// @Description Set "printer" to print on a printer other than the default.
+// @Description Set "density", "speed" or "paper" to override the printer's settings for this job.
+// @Description Set "margin_top", "margin_bottom" (dot lines, 8 per mm) or "tear_line" to add blank paper and a dashed tear line.
 // @Tags print
 // @Accept json
 // @Produce json
 // @Param request body PrintRequest true "Print request"
 // @Success 200 {object} PrintResponse
+// @Success 202 {object} PrintResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
 // @Router /print [post]
 func (h *Handler) Print(c *gin.Context) {
 	var req PrintRequest
@@ -63,41 +115,106 @@ func (h *Handler) Print(c *gin.Context) {
 		return
 	}
 
-	// If data is provided, print JSON; otherwise print text
-	var err error
-	if req.Data != nil && len(req.Data) > 0 {
-		err = h.service.PrintJSON(req.Data)
+	// A QR code, barcode or data is printed in preference to text
+	var jobReq core.JobRequest
+	if req.QR != nil {
+		jobReq = core.JobRequest{Kind: core.JobQR, QR: req.QR}
+	} else if req.Barcode != nil {
+		jobReq = core.JobRequest{Kind: core.JobBarcode, Barcode: req.Barcode}
+	} else if req.Data != nil && len(req.Data) > 0 {
+		jobReq = core.JobRequest{Kind: core.JobJSON, Data: req.Data}
 	} else if req.Text != "" {
-		err = h.service.PrintText(req.Text)
+		kind, err := textKind(req.Format)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		jobReq = core.JobRequest{Kind: kind, Text: req.Text}
 	} else {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error: "Either 'text' or 'data' must be provided",
+			Error: "One of 'text', 'data', 'qr' or 'barcode' must be provided",
 		})
 		return
 	}
 
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error: "Print failed: " + err.Error(),
+	jobReq.Fields = req.Fields
+	jobReq.Printer = req.Printer
+	jobReq.PrintSettings = req.PrintSettings
+	jobReq.JobLayout = req.JobLayout
+
+	h.dispatch(c, jobReq, req.Async)
+}
+
+// textKind returns the job kind that prints text in the given format.
+func textKind(format string) (core.JobKind, error) {
+	switch format {
+	case "", "text":
+		return core.JobText, nil
+	case "markdown":
+		return core.JobMarkdown, nil
+	default:
+		return "", fmt.Errorf("unknown format %q: use text or markdown", format)
+	}
+}
+
+// dispatch queues a job and writes the response. Async jobs return 202
+// immediately; otherwise the request waits until the job is printed.
+func (h *Handler) dispatch(c *gin.Context, jobReq core.JobRequest, async bool) {
+	if async {
+		job, err := h.service.Submit(jobReq)
+		if err != nil {
+			h.printError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusAccepted, PrintResponse{
+			Success: true,
+			Message: "Print job queued",
+			JobID:   job.ID,
+			State:   job.State,
 		})
 		return
 	}
 
+	job, err := h.service.Print(c.Request.Context(), jobReq)
+	if err != nil {
+		h.printError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusOK, PrintResponse{
 		Success: true,
 		Message: "Print job completed successfully",
+		JobID:   job.ID,
+		State:   job.State,
 	})
 }
 
-// HealthCheck handles the GET /health endpoint.
-// @Summary Health check
-// @Description Returns the health status of the service
-// @Tags health
-// @Produce json
-// @Success 200 {object} map[string]string
-// @Router /health [get]
-func (h *Handler) HealthCheck(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status": "healthy",
-	})
+// printError maps a print service error to an HTTP response.
+func (h *Handler) printError(c *gin.Context, err error) {
+	var validationErr *core.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid print request: " + err.Error(),
+		})
+	case errors.Is(err, core.ErrSettingsUnavailable):
+		c.JSON(http.StatusNotImplemented, ErrorResponse{
+			Error: "Print failed: " + err.Error(),
+		})
+	case errors.Is(err, core.ErrQueueClosed),
+		errors.Is(err, core.ErrPrinterDisconnected),
+		errors.Is(err, core.ErrPaperOut),
+		errors.Is(err, core.ErrLowBattery),
+		errors.Is(err, core.ErrOverheated),
+		errors.Is(err, core.ErrLidOpen):
+		// The printer needs attention; the request itself was fine.
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Error: "Print failed: " + err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "Print failed: " + err.Error(),
+		})
+	}
 }
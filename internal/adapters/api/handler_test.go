@@ -2,15 +2,23 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"log"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/princem/peripage-printer/internal/adapters/printer"
+	"github.com/princem/peripage-printer/internal/core"
 	"github.com/princem/peripage-printer/internal/core/mocks"
+	"github.com/princem/peripage-printer/internal/render"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -68,7 +76,7 @@ func TestHandler_Print_ValidTextRequest(t *testing.T) {
 			},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody: map[string]interface{}{
-				"error": "Either 'text' or 'data' must be provided",
+				"error": "One of 'text', 'data', 'qr' or 'barcode' must be provided",
 			},
 		},
 	}
@@ -127,8 +135,8 @@ func TestHandler_Print_ValidJSONDataRequest(t *testing.T) {
 				},
 			},
 			mockSetup: func(m *mocks.MockPrinter) {
-				// We expect pretty-printed JSON
-				m.On("PrintText", "{\n  \"age\": 30,\n  \"name\": \"John Doe\"\n}").Return(nil).Once()
+				// We expect the data laid out as a receipt
+				m.On("PrintImage", mustRenderJSON(t, map[string]interface{}{"age": 30, "name": "John Doe"})).Return(nil).Once()
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -144,14 +152,10 @@ func TestHandler_Print_ValidJSONDataRequest(t *testing.T) {
 				},
 			},
 			mockSetup: func(m *mocks.MockPrinter) {
-				// Accept any string for nested JSON (order may vary)
-				m.On("PrintText", `{
-  "timestamp": "2025-12-12",
-  "user": {
-    "email": "jane@example.com",
-    "name": "Jane"
-  }
-}`).Return(nil).Once()
+				m.On("PrintImage", mustRenderJSON(t, map[string]interface{}{
+					"timestamp": "2025-12-12",
+					"user":      map[string]interface{}{"email": "jane@example.com", "name": "Jane"},
+				})).Return(nil).Once()
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -164,10 +168,36 @@ func TestHandler_Print_ValidJSONDataRequest(t *testing.T) {
 				},
 			},
 			mockSetup: func(m *mocks.MockPrinter) {
-				m.On("PrintText", "{\n  \"message\": \"Only this will be printed\"\n}").Return(nil).Once()
+				m.On("PrintImage", mustRenderJSON(t, map[string]interface{}{"message": "Only this will be printed"})).Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "fields select what is printed",
+			requestBody: map[string]interface{}{
+				"data": map[string]interface{}{
+					"customer": map[string]interface{}{"name": "Jane", "email": "jane@example.com"},
+					"total":    12.5,
+				},
+				"fields": []string{"customer.name", "total"},
+			},
+			mockSetup: func(m *mocks.MockPrinter) {
+				m.On("PrintImage", mustRenderJSON(t, map[string]interface{}{
+					"customer": map[string]interface{}{"name": "Jane"},
+					"total":    12.5,
+				})).Return(nil).Once()
 			},
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name: "fields that match nothing",
+			requestBody: map[string]interface{}{
+				"data":   map[string]interface{}{"total": 12.5},
+				"fields": []string{"customer.name"},
+			},
+			mockSetup:      func(m *mocks.MockPrinter) {},
+			expectedStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -217,7 +247,15 @@ func TestHandler_Print_ErrorCases(t *testing.T) {
 			contentType:    "application/json",
 			mockSetup:      func(m *mocks.MockPrinter) {},
 			expectedStatus: http.StatusBadRequest,
-			errorContains:  "Either 'text' or 'data' must be provided",
+			errorContains:  "One of 'text', 'data', 'qr' or 'barcode' must be provided",
+		},
+		{
+			name:           "unknown format",
+			requestBody:    `{"text": "Test", "format": "html"}`,
+			contentType:    "application/json",
+			mockSetup:      func(m *mocks.MockPrinter) {},
+			expectedStatus: http.StatusBadRequest,
+			errorContains:  "unknown format",
 		},
 		{
 			name: "printer error is propagated",
@@ -262,6 +300,331 @@ func TestHandler_Print_ErrorCases(t *testing.T) {
 	}
 }
 
+func TestHandler_Print_Async(t *testing.T) {
+	// Arrange
+	mockPrinter := new(mocks.MockPrinter)
+	printed := make(chan struct{})
+	mockPrinter.On("PrintText", "Queued text").
+		Run(func(args mock.Arguments) { close(printed) }).
+		Return(nil).
+		Once()
+
+	service := core.NewPrintService(mockPrinter)
+	defer service.Close()
+	handler := &Handler{service: service}
+	router := setupTestRouter(handler)
+
+	body := `{"text": "Queued text", "async": true}`
+	req := httptest.NewRequest(http.MethodPost, "/print", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	var response PrintResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Success)
+	assert.NotEmpty(t, response.JobID)
+
+	select {
+	case <-printed:
+	case <-time.After(time.Second):
+		t.Fatal("queued job was never printed")
+	}
+	mockPrinter.AssertExpectations(t)
+}
+
+func TestHandler_Print_SyncResponseIncludesJobID(t *testing.T) {
+	mockPrinter := new(mocks.MockPrinter)
+	mockPrinter.On("PrintText", "Hello").Return(nil).Once()
+	service := core.NewPrintService(mockPrinter)
+	defer service.Close()
+	router := setupTestRouter(&Handler{service: service})
+
+	req := httptest.NewRequest(http.MethodPost, "/print", bytes.NewBufferString(`{"text": "Hello"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response PrintResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.NotEmpty(t, response.JobID)
+	assert.Equal(t, core.JobDone, response.State)
+}
+
+func TestHandler_Print_Settings(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		expectedStatus int
+		expected       core.PrintSettings
+	}{
+		{
+			name:           "printer defaults",
+			requestBody:    `{"text": "Label"}`,
+			expectedStatus: http.StatusOK,
+			expected:       core.DefaultPrintSettings(),
+		},
+		{
+			name:           "job settings",
+			requestBody:    `{"text": "Label", "density": "dark", "paper": "label"}`,
+			expectedStatus: http.StatusOK,
+			expected:       core.PrintSettings{Density: core.DensityDark, Speed: core.SpeedNormal, Paper: core.PaperLabel},
+		},
+		{
+			name:           "unknown speed",
+			requestBody:    `{"text": "Label", "speed": "warp"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockPrinter := printer.NewMockPrinter(log.New(&bytes.Buffer{}, "", 0))
+			service := core.NewPrintService(mockPrinter)
+			defer service.Close()
+			router := setupTestRouter(&Handler{service: service})
+
+			req := httptest.NewRequest(http.MethodPost, "/print", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				assert.Empty(t, mockPrinter.Printed())
+				return
+			}
+			printed := mockPrinter.Printed()
+			require.Len(t, printed, 1)
+			assert.Equal(t, tt.expected, printed[0].PrintSettings)
+		})
+	}
+}
+
+func TestHandler_Print_Markdown(t *testing.T) {
+	// Arrange
+	source := "# Groceries\n- [x] milk\n- [ ] *eggs*"
+	expected, err := render.RenderMarkdown(source, render.DefaultMarkdownOptions())
+	require.NoError(t, err)
+
+	mockPrinter := printer.NewMockPrinter(log.New(&bytes.Buffer{}, "", 0))
+	service := core.NewPrintService(mockPrinter)
+	defer service.Close()
+	router := setupTestRouter(&Handler{service: service})
+
+	body, err := json.Marshal(PrintRequest{Text: source, Format: "markdown"})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/print", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	printed := mockPrinter.Printed()
+	require.Len(t, printed, 1)
+	assert.Equal(t, "image", printed[0].Kind)
+	assert.Equal(t, render.HeadWidth, printed[0].Width)
+	assert.Equal(t, expected.Height, printed[0].Height)
+}
+
+func TestHandler_Print_MarkdownInvalidFences(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          PrintRequest
+		errorContains string
+	}{
+		{name: "barcode data", body: PrintRequest{Text: "```barcode ean13\nabc\n```", Format: "markdown"}, errorContains: "invalid barcode data"},
+		{name: "qr too large", body: PrintRequest{Text: "```qr\n" + strings.Repeat("A", 5000) + "\n```", Format: "markdown"}, errorContains: "too long"},
+		{name: "table columns", body: PrintRequest{Text: "```table 10px 10px\na | b\n```", Format: "markdown"}, errorContains: "width must be at least"},
+		{name: "queued", body: PrintRequest{Text: "```barcode ean13\nabc\n```", Format: "markdown", Async: true}, errorContains: "invalid barcode data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockPrinter := printer.NewMockPrinter(log.New(&bytes.Buffer{}, "", 0))
+			service := core.NewPrintService(mockPrinter)
+			defer service.Close()
+			router := setupTestRouter(&Handler{service: service})
+
+			body, err := json.Marshal(tt.body)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/print", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), tt.errorContains)
+			assert.Empty(t, mockPrinter.Printed())
+		})
+	}
+}
+
+func TestHandler_Print_QR(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedHeight int
+	}{
+		{
+			name:           "prints the code as an image",
+			body:           `{"qr": {"data": "HELLO", "scale": 4}}`,
+			expectedStatus: http.StatusOK,
+			expectedHeight: 29 * 4,
+		},
+		{
+			name:           "qr wins over text",
+			body:           `{"text": "ignored", "qr": {"data": "HELLO"}}`,
+			expectedStatus: http.StatusOK,
+			expectedHeight: 29 * 13,
+		},
+		{
+			name:           "unknown level",
+			body:           `{"qr": {"data": "HELLO", "level": "X"}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "empty data",
+			body:           `{"qr": {"caption": "Menu"}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "too long when queued",
+			body:           `{"qr": {"data": "` + strings.Repeat("a", 3000) + `"}, "async": true}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockPrinter := printer.NewMockPrinter(log.New(&bytes.Buffer{}, "", 0))
+			service := core.NewPrintService(mockPrinter)
+			defer service.Close()
+			router := setupTestRouter(&Handler{service: service})
+
+			req := httptest.NewRequest(http.MethodPost, "/print", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			printed := mockPrinter.Printed()
+			if tt.expectedStatus != http.StatusOK {
+				assert.Empty(t, printed)
+				return
+			}
+			require.Len(t, printed, 1)
+			assert.Equal(t, "image", printed[0].Kind)
+			assert.Equal(t, tt.expectedHeight, printed[0].Height)
+		})
+	}
+}
+
+func TestHandler_Print_Barcode(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedHeight int
+	}{
+		{
+			name:           "prints the bars as an image",
+			body:           `{"barcode": {"type": "code128", "data": "BIN-A-17", "height": 96, "hide_text": true}}`,
+			expectedStatus: http.StatusOK,
+			expectedHeight: 96,
+		},
+		{
+			name:           "barcode wins over data",
+			body:           `{"data": {"ignored": true}, "barcode": {"type": "upca", "data": "03600029145", "hide_text": true}}`,
+			expectedStatus: http.StatusOK,
+			expectedHeight: 80,
+		},
+		{
+			name:           "wrong check digit",
+			body:           `{"barcode": {"type": "ean13", "data": "4006381333932"}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown type",
+			body:           `{"barcode": {"type": "pdf417", "data": "x"}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid characters when queued",
+			body:           `{"barcode": {"type": "code39", "data": "bin-a-17"}, "async": true}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockPrinter := printer.NewMockPrinter(log.New(&bytes.Buffer{}, "", 0))
+			service := core.NewPrintService(mockPrinter)
+			defer service.Close()
+			router := setupTestRouter(&Handler{service: service})
+
+			req := httptest.NewRequest(http.MethodPost, "/print", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			printed := mockPrinter.Printed()
+			if tt.expectedStatus != http.StatusOK {
+				assert.Empty(t, printed)
+				return
+			}
+			require.Len(t, printed, 1)
+			assert.Equal(t, "image", printed[0].Kind)
+			assert.Equal(t, tt.expectedHeight, printed[0].Height)
+		})
+	}
+}
+
+func TestHandler_Print_SettingsUnsupported(t *testing.T) {
+	// Arrange: the mock port does not implement core.SettingsPrinter
+	service := core.NewPrintService(new(mocks.MockPrinter))
+	defer service.Close()
+	router := setupTestRouter(&Handler{service: service})
+
+	req := httptest.NewRequest(http.MethodPost, "/print", bytes.NewBufferString(`{"text": "Label", "density": "dark"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
+
 func TestHandler_HealthCheck(t *testing.T) {
 	// Arrange
 	mockPrinter := new(mocks.MockPrinter)
@@ -290,22 +653,103 @@ type mockPrintService struct {
 	printer *mocks.MockPrinter
 }
 
-func (m *mockPrintService) PrintText(text string) error {
-	if text == "" {
-		return errors.New("text cannot be empty")
+func (m *mockPrintService) Print(ctx context.Context, req core.JobRequest) (core.Job, error) {
+	var err error
+	switch req.Kind {
+	case core.JobText:
+		err = m.PrintText(req.Text)
+	case core.JobJSON:
+		var bitmap *render.Bitmap
+		bitmap, err = core.Rasterize(req)
+		if err == nil {
+			err = m.printer.PrintImage(bitmap)
+		}
 	}
-	return m.printer.PrintText(text)
-}
 
-func (m *mockPrintService) PrintJSON(data interface{}) error {
-	if data == nil {
-		return errors.New("data cannot be nil")
+	if err != nil {
+		return core.Job{ID: "test-job", Request: req, State: core.JobFailed, Error: err.Error()}, err
 	}
+	return core.Job{ID: "test-job", Request: req, State: core.JobDone}, nil
+}
 
-	jsonBytes, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		return err
+func (m *mockPrintService) Submit(req core.JobRequest) (core.Job, error) {
+	if err := req.Validate(); err != nil {
+		return core.Job{}, err
 	}
+	return core.Job{ID: "test-job", Request: req, State: core.JobQueued}, nil
+}
+
+func (m *mockPrintService) Job(id string) (core.Job, error) {
+	return core.Job{}, core.ErrJobNotFound
+}
 
-	return m.printer.PrintText(string(jsonBytes))
+func (m *mockPrintService) Jobs() []core.Job {
+	return nil
+}
+
+func (m *mockPrintService) Cancel(id string) (core.Job, error) {
+	return core.Job{}, core.ErrJobNotFound
+}
+
+func (m *mockPrintService) Reprint(id string) (core.Job, error) {
+	return core.Job{}, core.ErrJobNotFound
+}
+
+func (m *mockPrintService) Preview(req core.JobRequest) (*render.Bitmap, error) {
+	return core.Rasterize(req)
+}
+
+func (m *mockPrintService) Printers() []core.PrinterInfo {
+	return []core.PrinterInfo{{Name: core.DefaultPrinterName, Type: "mock", Default: true, Connection: core.ConnectionConnected}}
+}
+
+func (m *mockPrintService) Pools() []core.PoolInfo {
+	return nil
+}
+
+func (m *mockPrintService) PrinterStatus(printer string) (core.PrinterStatus, error) {
+	return core.PrinterStatus{}, core.ErrStatusUnavailable
+}
+
+func (m *mockPrintService) Readiness() core.Readiness {
+	return core.Readiness{Ready: true, Connection: core.ConnectionConnected}
+}
+
+func (m *mockPrintService) ScanDevices(ctx context.Context, printer string, duration time.Duration) ([]core.DiscoveredDevice, error) {
+	return nil, core.ErrScanUnavailable
+}
+
+func (m *mockPrintService) Feed(printer string, lines int) error {
+	return core.ErrFeedUnavailable
+}
+
+func (m *mockPrintService) Templates() []core.Template {
+	return nil
+}
+
+func (m *mockPrintService) Template(name string) (core.Template, error) {
+	return core.Template{}, core.ErrTemplateNotFound
+}
+
+func (m *mockPrintService) CreateTemplate(t core.Template) (core.Template, error) {
+	return core.Template{}, errors.New("templates not supported")
+}
+
+func (m *mockPrintService) PutTemplate(t core.Template) (core.Template, bool, error) {
+	return core.Template{}, false, errors.New("templates not supported")
+}
+
+func (m *mockPrintService) DeleteTemplate(name string) error {
+	return core.ErrTemplateNotFound
+}
+
+func (m *mockPrintService) RenderTemplate(name string, data map[string]interface{}) (core.JobRequest, error) {
+	return core.JobRequest{}, core.ErrTemplateNotFound
+}
+
+func (m *mockPrintService) PrintText(text string) error {
+	if text == "" {
+		return errors.New("text cannot be empty")
+	}
+	return m.printer.PrintText(text)
 }
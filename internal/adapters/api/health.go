@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/princem/peripage-printer/internal/core"
+)
+
+// HealthResponse is the summary returned by the health endpoint.
+type HealthResponse struct {
+	// Status is "healthy" when the printer can accept work, "degraded" otherwise.
+	Status     string               `json:"status" example:"healthy"`
+	Printer    core.ConnectionState `json:"printer" example:"connected"`
+	QueueDepth int                  `json:"queue_depth" example:"0"`
+}
+
+// Livez handles the GET /livez endpoint.
+// @Summary Liveness probe
+// @Description Returns 200 while the server is running, whatever the state of the printer.
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /livez [get]
+func (h *Handler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "alive",
+	})
+}
+
+// Readyz handles the GET /readyz endpoint.
+// @Summary Readiness probe
+// @Description Reports the printer connection state, queue depth, last successful print and last error.
+// @Description Returns 503 when the printer cannot accept work: it is not connected, or its status reports
+// @Description paper out, an open lid or overheating.
+// @Tags health
+// @Produce json
+// @Success 200 {object} core.Readiness
+// @Failure 503 {object} core.Readiness
+// @Router /readyz [get]
+func (h *Handler) Readyz(c *gin.Context) {
+	readiness := h.service.Readiness()
+	status := http.StatusOK
+	if !readiness.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, readiness)
+}
+
+// HealthCheck handles the GET /health endpoint.
+// @Summary Health check
+// @Description Returns a summary of the service health. It always answers 200 for
+// @Description backward compatibility; use /readyz to check that the printer can accept work.
+// @Tags health
+// @Produce json
+// @Success 200 {object} HealthResponse
+// @Router /health [get]
+func (h *Handler) HealthCheck(c *gin.Context) {
+	readiness := h.service.Readiness()
+	status := "healthy"
+	if !readiness.Ready {
+		status = "degraded"
+	}
+	c.JSON(http.StatusOK, HealthResponse{
+		Status:     status,
+		Printer:    readiness.Connection,
+		QueueDepth: readiness.QueueDepth,
+	})
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/princem/peripage-printer/internal/adapters/printer"
+	"github.com/princem/peripage-printer/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_Health_FollowsPrinterConnection(t *testing.T) {
+	// Arrange: a supervised BLE printer whose simulated Peripage is off
+	sim := printer.NewSimulatedPeripage("PeriPage_A6", "AA:BB:CC:DD:EE:FF")
+	sim.SetPowered(false)
+	blePrinter, err := printer.NewBLEPrinter(printer.BLEPrinterConfig{
+		DeviceName:     "PeriPage_A6",
+		ScanTimeout:    20 * time.Millisecond,
+		ConnectTimeout: 50 * time.Millisecond,
+		MinBackoff:     5 * time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+		Transport:      sim,
+		Logger:         log.New(&bytes.Buffer{}, "", 0),
+	})
+	require.NoError(t, err)
+	blePrinter.Start()
+	defer blePrinter.Disconnect()
+
+	service := core.NewPrintService(blePrinter)
+	defer service.Close()
+	router := SetupRouter(NewHandler(service))
+
+	// Act & Assert: alive but not ready while the printer is off
+	w := doRequest(router, http.MethodGet, "/livez")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = doRequest(router, http.MethodGet, "/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var readiness core.Readiness
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &readiness))
+	assert.False(t, readiness.Ready)
+	assert.NotEqual(t, core.ConnectionConnected, readiness.Connection)
+	assert.NotEmpty(t, readiness.Reason)
+
+	w = doRequest(router, http.MethodGet, "/health")
+	require.Equal(t, http.StatusOK, w.Code, "/health stays 200 for existing checks")
+	var health HealthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &health))
+	assert.Equal(t, "degraded", health.Status)
+
+	// Act & Assert: ready once the printer is switched on
+	sim.SetPowered(true)
+	require.Eventually(t, func() bool {
+		return doRequest(router, http.MethodGet, "/readyz").Code == http.StatusOK
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, service.PrintText("hello"))
+	w = doRequest(router, http.MethodGet, "/readyz")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &readiness))
+	assert.True(t, readiness.Ready)
+	assert.Equal(t, core.ConnectionConnected, readiness.Connection)
+	assert.NotNil(t, readiness.LastSuccess)
+	assert.Empty(t, readiness.ConnectionError)
+
+	w = doRequest(router, http.MethodGet, "/health")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &health))
+	assert.Equal(t, "healthy", health.Status)
+	assert.Equal(t, core.ConnectionConnected, health.Printer)
+}
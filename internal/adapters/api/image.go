@@ -0,0 +1,169 @@
+package api
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/princem/peripage-printer/internal/core"
+	"github.com/princem/peripage-printer/internal/render"
+)
+
+// maxImageUpload is the largest accepted image request body.
+const maxImageUpload = 10 << 20
+
+// ImagePrintRequest represents the JSON request body for the image print endpoint.
+type ImagePrintRequest struct {
+	// Image is the base64-encoded PNG, JPEG or GIF file. A data URL is accepted too.
+	Image  string `json:"image" binding:"required" example:"iVBORw0KGgoAAAANSUhEUgAA..."`
+	Dither string `json:"dither,omitempty" example:"floyd-steinberg" enums:"threshold,floyd-steinberg,atkinson,ordered"`
+	Async  bool   `json:"async,omitempty" example:"false"`
+	// Printer names the printer or pool to print on. Empty selects the default.
+	Printer string `json:"printer,omitempty" example:"front-desk"`
+	// PrintSettings override the printer's density, speed and paper type for this job.
+	core.PrintSettings
+	// JobLayout adds margins and a tear line around the job.
+	core.JobLayout
+}
+
+// PrintImage handles the POST /print/image endpoint.
+// @Summary Print an image
+// @Description Prints a PNG, JPEG or GIF image, scaled to the 384-dot print head and dithered to black and white.
+// @Description Send the file as multipart form data in the "image" field, or as base64 in a JSON body.
+// @Tags print
+// @Accept json,mpfd
+// @Produce json
+// @Param request body ImagePrintRequest false "Base64 image print request"
+// @Param image formData file false "Image file"
+// @Param dither formData string false "Dithering mode" Enums(threshold, floyd-steinberg, atkinson, ordered)
+// @Param async formData bool false "Queue the job and return immediately"
+// @Param printer formData string false "Printer or pool to print on (default if empty)"
+// @Param density formData string false "Print density (printer default if empty)" Enums(light, normal, dark)
+// @Param speed formData string false "Print speed (printer default if empty)" Enums(slow, normal, fast)
+// @Param paper formData string false "Paper type (printer default if empty)" Enums(continuous, label, sticker)
+// @Param margin_top formData int false "Blank dot lines before the image (8 per mm)"
+// @Param margin_bottom formData int false "Blank dot lines after the image (8 per mm)"
+// @Param tear_line formData bool false "Print a dashed tear line after the image"
+// @Success 200 {object} PrintResponse
+// @Success 202 {object} PrintResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /print/image [post]
+func (h *Handler) PrintImage(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImageUpload)
+
+	var (
+		req ImagePrintRequest
+		err error
+		img []byte
+	)
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		img, err = readImageForm(c, &req)
+	} else {
+		img, err = readImageJSON(c, &req)
+	}
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{
+				Error: "Image is larger than 10 MB",
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	h.dispatch(c, core.JobRequest{
+		Kind:          core.JobImage,
+		Image:         img,
+		Dither:        render.Dither(req.Dither),
+		Printer:       req.Printer,
+		PrintSettings: req.PrintSettings,
+		JobLayout:     req.JobLayout,
+	}, req.Async)
+}
+
+// readImageForm reads the image file and options from a multipart form.
+func readImageForm(c *gin.Context, req *ImagePrintRequest) ([]byte, error) {
+	header, err := c.FormFile("image")
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Dither = c.PostForm("dither")
+	req.Printer = c.PostForm("printer")
+	req.Density = core.Density(c.PostForm("density"))
+	req.Speed = core.Speed(c.PostForm("speed"))
+	req.Paper = core.PaperType(c.PostForm("paper"))
+	if async := c.PostForm("async"); async != "" {
+		req.Async, err = strconv.ParseBool(async)
+		if err != nil {
+			return nil, errors.New("async must be a boolean")
+		}
+	}
+	if margin := c.PostForm("margin_top"); margin != "" {
+		req.MarginTop, err = strconv.Atoi(margin)
+		if err != nil {
+			return nil, errors.New("margin_top must be an integer")
+		}
+	}
+	if margin := c.PostForm("margin_bottom"); margin != "" {
+		req.MarginBottom, err = strconv.Atoi(margin)
+		if err != nil {
+			return nil, errors.New("margin_bottom must be an integer")
+		}
+	}
+	if tearLine := c.PostForm("tear_line"); tearLine != "" {
+		req.TearLine, err = strconv.ParseBool(tearLine)
+		if err != nil {
+			return nil, errors.New("tear_line must be a boolean")
+		}
+	}
+	return img, nil
+}
+
+// readImageJSON reads a base64-encoded image from a JSON body.
+func readImageJSON(c *gin.Context, req *ImagePrintRequest) ([]byte, error) {
+	if err := c.ShouldBindJSON(req); err != nil {
+		return nil, err
+	}
+
+	return decodeBase64Image(req.Image)
+}
+
+// decodeBase64Image decodes a base64 image, optionally given as a data URL.
+func decodeBase64Image(encoded string) ([]byte, error) {
+	if strings.HasPrefix(encoded, "data:") {
+		// Strip a data URL prefix such as "data:image/png;base64,".
+		if comma := strings.IndexByte(encoded, ','); comma >= 0 {
+			encoded = encoded[comma+1:]
+		}
+	}
+
+	img, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("image is not valid base64")
+	}
+	return img, nil
+}
@@ -0,0 +1,234 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/png"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/princem/peripage-printer/internal/adapters/printer"
+	"github.com/princem/peripage-printer/internal/core"
+	"github.com/princem/peripage-printer/internal/core/mocks"
+	"github.com/princem/peripage-printer/internal/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupImageRouter(t *testing.T, mockPrinter *mocks.MockPrinter) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	service := core.NewPrintService(mockPrinter)
+	t.Cleanup(service.Close)
+
+	router := gin.New()
+	router.POST("/print/image", (&Handler{service: service}).PrintImage)
+	return router
+}
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 96, 48))))
+	return buf.Bytes()
+}
+
+// tallPNG returns a narrow image that is too tall once scaled to the print head.
+func tallPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 10, 1000))))
+	return buf.Bytes()
+}
+
+// multipartImage builds a multipart body with the image file and extra form fields.
+func multipartImage(t *testing.T, img []byte, fields map[string]string) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if img != nil {
+		part, err := writer.CreateFormFile("image", "logo.png")
+		require.NoError(t, err)
+		_, err = part.Write(img)
+		require.NoError(t, err)
+	}
+	for key, value := range fields {
+		require.NoError(t, writer.WriteField(key, value))
+	}
+	require.NoError(t, writer.Close())
+	return &body, writer.FormDataContentType()
+}
+
+// headWidthBitmap matches the bitmap a 96x48 image becomes on the print head.
+func headWidthBitmap() interface{} {
+	return mock.MatchedBy(func(b *render.Bitmap) bool {
+		return b.Width == render.HeadWidth && b.Height == 192
+	})
+}
+
+func TestHandler_PrintImage_JSON(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString(testPNG(t))
+
+	tests := []struct {
+		name           string
+		image          string
+		dither         string
+		expectPrint    bool
+		expectedStatus int
+		errorContains  string
+	}{
+		{
+			name:           "base64 image",
+			image:          encoded,
+			dither:         "ordered",
+			expectPrint:    true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "data URL",
+			image:          "data:image/png;base64," + encoded,
+			expectPrint:    true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid base64",
+			image:          "not base64!",
+			expectedStatus: http.StatusBadRequest,
+			errorContains:  "not valid base64",
+		},
+		{
+			name:           "not an image",
+			image:          base64.StdEncoding.EncodeToString([]byte("hello")),
+			expectedStatus: http.StatusBadRequest,
+			errorContains:  "unsupported image",
+		},
+		{
+			name:           "unknown dither",
+			image:          encoded,
+			dither:         "halftone",
+			expectedStatus: http.StatusBadRequest,
+			errorContains:  "unknown dither mode",
+		},
+		{
+			name:           "too tall after scaling",
+			image:          base64.StdEncoding.EncodeToString(tallPNG(t)),
+			expectedStatus: http.StatusBadRequest,
+			errorContains:  "image is too tall",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockPrinter := new(mocks.MockPrinter)
+			if tt.expectPrint {
+				mockPrinter.On("PrintImage", headWidthBitmap()).Return(nil).Once()
+			}
+			router := setupImageRouter(t, mockPrinter)
+
+			body, _ := json.Marshal(ImagePrintRequest{Image: tt.image, Dither: tt.dither})
+			req := httptest.NewRequest(http.MethodPost, "/print/image", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.errorContains != "" {
+				assert.Contains(t, w.Body.String(), tt.errorContains)
+			}
+			mockPrinter.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_PrintImage_Multipart(t *testing.T) {
+	// Arrange
+	mockPrinter := new(mocks.MockPrinter)
+	mockPrinter.On("PrintImage", headWidthBitmap()).Return(nil).Once()
+	router := setupImageRouter(t, mockPrinter)
+
+	body, contentType := multipartImage(t, testPNG(t), map[string]string{"dither": "threshold"})
+	req := httptest.NewRequest(http.MethodPost, "/print/image", body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response PrintResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Success)
+	assert.Equal(t, core.JobDone, response.State)
+	mockPrinter.AssertExpectations(t)
+}
+
+func TestHandler_PrintImage_MultipartSettings(t *testing.T) {
+	// Arrange
+	mockPrinter := printer.NewMockPrinter(log.New(&bytes.Buffer{}, "", 0))
+	service := core.NewPrintService(mockPrinter)
+	defer service.Close()
+	router := gin.New()
+	router.POST("/print/image", (&Handler{service: service}).PrintImage)
+
+	body, contentType := multipartImage(t, testPNG(t), map[string]string{"speed": "slow", "paper": "sticker"})
+	req := httptest.NewRequest(http.MethodPost, "/print/image", body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	printed := mockPrinter.Printed()
+	require.Len(t, printed, 1)
+	assert.Equal(t, core.PrintSettings{Density: core.DensityNormal, Speed: core.SpeedSlow, Paper: core.PaperSticker}, printed[0].PrintSettings)
+}
+
+func TestHandler_PrintImage_MultipartErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		image          []byte
+		fields         map[string]string
+		expectedStatus int
+	}{
+		{name: "missing file", fields: map[string]string{"dither": "ordered"}, expectedStatus: http.StatusBadRequest},
+		{name: "invalid async flag", image: []byte("x"), fields: map[string]string{"async": "maybe"}, expectedStatus: http.StatusBadRequest},
+		{name: "unknown paper type", image: []byte("x"), fields: map[string]string{"paper": "vellum"}, expectedStatus: http.StatusBadRequest},
+		{name: "invalid margin", image: []byte("x"), fields: map[string]string{"margin_bottom": "wide"}, expectedStatus: http.StatusBadRequest},
+		{name: "file too large", image: make([]byte, maxImageUpload+1), expectedStatus: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockPrinter := new(mocks.MockPrinter)
+			router := setupImageRouter(t, mockPrinter)
+
+			body, contentType := multipartImage(t, tt.image, tt.fields)
+			req := httptest.NewRequest(http.MethodPost, "/print/image", body)
+			req.Header.Set("Content-Type", contentType)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockPrinter.AssertNotCalled(t, "PrintImage", mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/princem/peripage-printer/internal/core"
+)
+
+// JobListResponse represents the response for the job listing endpoint.
+type JobListResponse struct {
+	Jobs  []core.Job `json:"jobs"`
+	Count int        `json:"count" example:"1"`
+}
+
+// ListJobs handles the GET /jobs endpoint.
+// @Summary List print jobs
+// @Description Lists known print jobs in submission order, optionally filtered by state.
+// @Description The text, data, image and document of each job are left out; get a single job to see them.
+// @Tags jobs
+// @Produce json
+// @Param state query string false "Only return jobs in this state" Enums(queued, printing, done, failed, cancelled)
+// @Success 200 {object} JobListResponse
+// @Router /jobs [get]
+func (h *Handler) ListJobs(c *gin.Context) {
+	state := core.JobState(c.Query("state"))
+
+	jobs := []core.Job{}
+	for _, job := range h.service.Jobs() {
+		if state == "" || job.State == state {
+			jobs = append(jobs, job.WithoutPayload())
+		}
+	}
+
+	c.JSON(http.StatusOK, JobListResponse{
+		Jobs:  jobs,
+		Count: len(jobs),
+	})
+}
+
+// GetJob handles the GET /jobs/{id} endpoint.
+// @Summary Get a print job
+// @Description Returns the state of a single print job
+// @Tags jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} core.Job
+// @Failure 404 {object} ErrorResponse
+// @Router /jobs/{id} [get]
+func (h *Handler) GetJob(c *gin.Context) {
+	job, err := h.service.Job(c.Param("id"))
+	if err != nil {
+		h.jobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// CancelJob handles the DELETE /jobs/{id} endpoint.
+// @Summary Cancel a print job
+// @Description Cancels a job that is still queued. Jobs that already started cannot be cancelled.
+// @Tags jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} core.Job
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /jobs/{id} [delete]
+func (h *Handler) CancelJob(c *gin.Context) {
+	job, err := h.service.Cancel(c.Param("id"))
+	if err != nil {
+		h.jobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// ReprintJob handles the POST /jobs/{id}/reprint endpoint.
+// @Summary Reprint a job
+// @Description Queues a new job with the same content as an existing job
+// @Tags jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 202 {object} PrintResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /jobs/{id}/reprint [post]
+func (h *Handler) ReprintJob(c *gin.Context) {
+	job, err := h.service.Reprint(c.Param("id"))
+	if err != nil {
+		h.jobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, PrintResponse{
+		Success: true,
+		Message: "Print job queued",
+		JobID:   job.ID,
+		State:   job.State,
+	})
+}
+
+// jobError maps a job lookup or control error to an HTTP response.
+func (h *Handler) jobError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, core.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, core.ErrJobNotCancellable):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	default:
+		h.printError(c, err)
+	}
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/princem/peripage-printer/internal/core"
+	"github.com/princem/peripage-printer/internal/core/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupJobsRouter(handler *Handler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/print", handler.Print)
+	router.GET("/jobs", handler.ListJobs)
+	router.GET("/jobs/:id", handler.GetJob)
+	router.DELETE("/jobs/:id", handler.CancelJob)
+	router.POST("/jobs/:id/reprint", handler.ReprintJob)
+	return router
+}
+
+// blockedPrinter returns a mock printer whose first job blocks until release is closed.
+func blockedPrinter() (*mocks.MockPrinter, chan struct{}, chan struct{}) {
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	mockPrinter := new(mocks.MockPrinter)
+	mockPrinter.On("PrintText", mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) {
+			started <- struct{}{}
+			<-release
+		}).
+		Return(nil)
+	return mockPrinter, started, release
+}
+
+func submitAsync(t *testing.T, router *gin.Engine, text string) string {
+	t.Helper()
+
+	body, _ := json.Marshal(map[string]interface{}{"text": text, "async": true})
+	req := httptest.NewRequest(http.MethodPost, "/print", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	var response PrintResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response.JobID
+}
+
+func doRequest(router *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandler_Jobs_Lifecycle(t *testing.T) {
+	// Arrange: the first job blocks the printer so the second stays queued
+	mockPrinter, started, release := blockedPrinter()
+	service := core.NewPrintService(mockPrinter)
+	defer service.Close()
+	router := setupJobsRouter(&Handler{service: service})
+
+	printingID := submitAsync(t, router, "first")
+	<-started
+	queuedID := submitAsync(t, router, "second")
+
+	// Act & Assert: list everything
+	w := doRequest(router, http.MethodGet, "/jobs")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list JobListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, 2, list.Count)
+	for _, listed := range list.Jobs {
+		assert.Equal(t, core.JobText, listed.Request.Kind)
+		assert.Empty(t, listed.Request.Text, "the list leaves out what jobs print")
+	}
+
+	// Act & Assert: filter by state
+	w = doRequest(router, http.MethodGet, "/jobs?state=queued")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Equal(t, 1, list.Count)
+	assert.Equal(t, queuedID, list.Jobs[0].ID)
+
+	// Act & Assert: get a single job
+	w = doRequest(router, http.MethodGet, "/jobs/"+printingID)
+	require.Equal(t, http.StatusOK, w.Code)
+	var job core.Job
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+	assert.Equal(t, core.JobPrinting, job.State)
+	assert.Equal(t, "first", job.Request.Text)
+
+	// Act & Assert: a printing job cannot be cancelled, a queued one can
+	w = doRequest(router, http.MethodDelete, "/jobs/"+printingID)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = doRequest(router, http.MethodDelete, "/jobs/"+queuedID)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+	assert.Equal(t, core.JobCancelled, job.State)
+
+	close(release)
+}
+
+func TestHandler_ReprintJob(t *testing.T) {
+	// Arrange
+	mockPrinter := new(mocks.MockPrinter)
+	printed := make(chan struct{}, 2)
+	mockPrinter.On("PrintText", "Receipt").
+		Run(func(args mock.Arguments) { printed <- struct{}{} }).
+		Return(nil).
+		Twice()
+	service := core.NewPrintService(mockPrinter)
+	defer service.Close()
+	router := setupJobsRouter(&Handler{service: service})
+
+	originalID := submitAsync(t, router, "Receipt")
+
+	// Act
+	w := doRequest(router, http.MethodPost, "/jobs/"+originalID+"/reprint")
+
+	// Assert
+	require.Equal(t, http.StatusAccepted, w.Code)
+	var response PrintResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.NotEmpty(t, response.JobID)
+	assert.NotEqual(t, originalID, response.JobID)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-printed:
+		case <-time.After(time.Second):
+			t.Fatal("reprinted job was never printed")
+		}
+	}
+	mockPrinter.AssertExpectations(t)
+}
+
+func TestHandler_Jobs_NotFound(t *testing.T) {
+	service := core.NewPrintService(new(mocks.MockPrinter))
+	defer service.Close()
+	router := setupJobsRouter(&Handler{service: service})
+
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{method: http.MethodGet, path: "/jobs/missing"},
+		{method: http.MethodDelete, path: "/jobs/missing"},
+		{method: http.MethodPost, path: "/jobs/missing/reprint"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := doRequest(router, tt.method, tt.path)
+
+			assert.Equal(t, http.StatusNotFound, w.Code)
+			assert.Contains(t, w.Body.String(), "job not found")
+		})
+	}
+}
+
+func TestHandler_ListJobs_EmptyIsArray(t *testing.T) {
+	service := core.NewPrintService(new(mocks.MockPrinter))
+	defer service.Close()
+	router := setupJobsRouter(&Handler{service: service})
+
+	w := doRequest(router, http.MethodGet, "/jobs")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"jobs": [], "count": 0}`, w.Body.String())
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/princem/peripage-printer/internal/core"
+	"github.com/princem/peripage-printer/internal/render"
+)
+
+// Preview response headers describing the raster.
+const (
+	headerRasterWidth  = "X-Raster-Width"
+	headerRasterHeight = "X-Raster-Height"
+	headerPaperLength  = "X-Paper-Length-Mm"
+)
+
+// PreviewRequest represents the request body for the preview endpoint.
+// It accepts the fields of /print, the base64 image of /print/image and the
+// blocks of /print/document.
+type PreviewRequest struct {
+	Text string                 `json:"text" example:"Hello, World!"`
+	Data map[string]interface{} `json:"data,omitempty"`
+	// Fields selects the parts of data to print, as paths like "customer.name" or "items[*].price".
+	Fields []string `json:"fields,omitempty" example:"customer.name,items[*].name,total"`
+	// Format selects how text is printed: plain text or Markdown.
+	Format string `json:"format,omitempty" example:"text" enums:"text,markdown"`
+	// QR previews a QR code.
+	QR *core.QRCode `json:"qr,omitempty"`
+	// Barcode previews a 1D barcode.
+	Barcode *core.Barcode `json:"barcode,omitempty"`
+	// Blocks previews a document, as sent to /print/document.
+	Blocks []core.Block `json:"blocks,omitempty"`
+	// Image is a base64-encoded PNG, JPEG or GIF file, optionally as a data URL.
+	Image  string `json:"image,omitempty" example:"iVBORw0KGgoAAAANSUhEUgAA..."`
+	Dither string `json:"dither,omitempty" example:"floyd-steinberg" enums:"threshold,floyd-steinberg,atkinson,ordered"`
+	// JobLayout adds margins and a tear line around the job.
+	core.JobLayout
+}
+
+// Preview handles the POST /preview endpoint.
+// @Summary Preview a print
+// @Description Returns a PNG of the exact 1-bit raster the printer would receive, without printing.
+// @Description The raster size and estimated paper length are returned in the
+// @Description X-Raster-Width, X-Raster-Height and X-Paper-Length-Mm headers.
+// @Tags print
+// @Accept json
+// @Produce png
+// @Param request body PreviewRequest true "Preview request"
+// @Success 200 {file} binary
+// @Header 200 {integer} X-Raster-Width "Raster width in dots"
+// @Header 200 {integer} X-Raster-Height "Raster height in dots"
+// @Header 200 {number} X-Paper-Length-Mm "Estimated paper length in millimetres"
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /preview [post]
+func (h *Handler) Preview(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImageUpload)
+
+	var req PreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	var jobReq core.JobRequest
+	switch {
+	case req.Image != "":
+		img, err := decodeBase64Image(req.Image)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: "Invalid request body: " + err.Error(),
+			})
+			return
+		}
+		jobReq = core.JobRequest{Kind: core.JobImage, Image: img, Dither: render.Dither(req.Dither)}
+	case len(req.Blocks) > 0:
+		jobReq = core.JobRequest{Kind: core.JobDocument, Document: &core.Document{Blocks: req.Blocks}}
+	case req.QR != nil:
+		jobReq = core.JobRequest{Kind: core.JobQR, QR: req.QR}
+	case req.Barcode != nil:
+		jobReq = core.JobRequest{Kind: core.JobBarcode, Barcode: req.Barcode}
+	case len(req.Data) > 0:
+		jobReq = core.JobRequest{Kind: core.JobJSON, Data: req.Data}
+	case req.Text != "":
+		kind, err := textKind(req.Format)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		jobReq = core.JobRequest{Kind: kind, Text: req.Text}
+	default:
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "One of 'text', 'data', 'qr', 'barcode', 'blocks' or 'image' must be provided",
+		})
+		return
+	}
+	jobReq.Fields = req.Fields
+	jobReq.JobLayout = req.JobLayout
+
+	bitmap, err := h.service.Preview(jobReq)
+	if err != nil {
+		h.printError(c, err)
+		return
+	}
+
+	var png bytes.Buffer
+	if err := render.EncodePNG(&png, bitmap); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "Preview failed: " + err.Error(),
+		})
+		return
+	}
+
+	c.Header(headerRasterWidth, strconv.Itoa(bitmap.Width))
+	c.Header(headerRasterHeight, strconv.Itoa(bitmap.Height))
+	c.Header(headerPaperLength, strconv.FormatFloat(render.LengthMM(bitmap.Height), 'f', 1, 64))
+	c.Data(http.StatusOK, "image/png", png.Bytes())
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/princem/peripage-printer/internal/core"
+	"github.com/princem/peripage-printer/internal/core/mocks"
+	"github.com/princem/peripage-printer/internal/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupPreviewRouter() (*gin.Engine, *mocks.MockPrinter) {
+	gin.SetMode(gin.TestMode)
+	mockPrinter := new(mocks.MockPrinter)
+	handler := &Handler{service: &mockPrintService{printer: mockPrinter}}
+
+	router := gin.New()
+	router.POST("/preview", handler.Preview)
+	return router, mockPrinter
+}
+
+func postPreview(router *gin.Engine, body interface{}) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, "/preview", bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandler_Preview(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           PreviewRequest
+		expectedHeight int
+	}{
+		{
+			name:           "single line of text",
+			body:           PreviewRequest{Text: "Hello"},
+			expectedHeight: mustRenderText(t, "Hello").Height,
+		},
+		{
+			name:           "text with margins",
+			body:           PreviewRequest{Text: "Hello", JobLayout: core.JobLayout{MarginTop: 16, MarginBottom: 24}},
+			expectedHeight: mustRenderText(t, "Hello").Height + 40,
+		},
+		{
+			name:           "markdown",
+			body:           PreviewRequest{Text: "# Hello\n---", Format: "markdown"},
+			expectedHeight: mustRenderMarkdown(t, "# Hello\n---").Height,
+		},
+		{
+			name:           "json data",
+			body:           PreviewRequest{Data: map[string]interface{}{"a": 1}},
+			expectedHeight: mustRenderJSON(t, map[string]interface{}{"a": 1}).Height,
+		},
+		{
+			name:           "qr code with a caption",
+			body:           PreviewRequest{QR: &core.QRCode{Data: "HELLO", Scale: 4, Caption: "Hi"}},
+			expectedHeight: 29*4 + mustRenderText(t, "Hi").Height,
+		},
+		{
+			name:           "barcode without text",
+			body:           PreviewRequest{Barcode: &core.Barcode{Type: "ean13", Data: "400638133393", Height: 64, HideText: true}},
+			expectedHeight: 64,
+		},
+		{
+			name:           "document",
+			body:           PreviewRequest{Blocks: []core.Block{{Type: core.BlockSpacer, Height: 40}, {Type: core.BlockDivider}}},
+			expectedHeight: 40 + 2*render.DotsPerMM + 2,
+		},
+		{
+			name:           "base64 image",
+			body:           PreviewRequest{Image: base64.StdEncoding.EncodeToString(testPNG(t)), Dither: "ordered"},
+			expectedHeight: 192,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			router, mockPrinter := setupPreviewRouter()
+
+			// Act
+			w := postPreview(router, tt.body)
+
+			// Assert
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+			assert.Equal(t, "384", w.Header().Get(headerRasterWidth))
+			assert.Equal(t, strconv.Itoa(tt.expectedHeight), w.Header().Get(headerRasterHeight))
+			assert.NotEmpty(t, w.Header().Get(headerPaperLength))
+
+			img, err := png.Decode(w.Body)
+			require.NoError(t, err)
+			assert.Equal(t, render.HeadWidth, img.Bounds().Dx())
+			assert.Equal(t, tt.expectedHeight, img.Bounds().Dy())
+
+			// A preview never reaches the printer
+			mockPrinter.AssertNotCalled(t, "PrintText")
+			mockPrinter.AssertNotCalled(t, "PrintImage")
+		})
+	}
+}
+
+func TestHandler_Preview_PaperLength(t *testing.T) {
+	router, _ := setupPreviewRouter()
+	bitmap := mustRenderText(t, "one\ntwo\nthree\nfour")
+
+	w := postPreview(router, PreviewRequest{Text: "one\ntwo\nthree\nfour"})
+
+	require.Equal(t, http.StatusOK, w.Code)
+	expected := float64(bitmap.Height) / render.DotsPerMM
+	assert.Equal(t, strconv.FormatFloat(expected, 'f', 1, 64), w.Header().Get(headerPaperLength))
+}
+
+func TestHandler_Preview_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          PreviewRequest
+		errorContains string
+	}{
+		{name: "empty request", body: PreviewRequest{}, errorContains: "must be provided"},
+		{name: "unknown format", body: PreviewRequest{Text: "Hello", Format: "html"}, errorContains: "unknown format"},
+		{name: "invalid base64", body: PreviewRequest{Image: "%%%"}, errorContains: "not valid base64"},
+		{name: "invalid markdown fence", body: PreviewRequest{Text: "```barcode ean13\nabc\n```", Format: "markdown"}, errorContains: "invalid barcode data"},
+		{name: "image too tall after scaling", body: PreviewRequest{Image: base64.StdEncoding.EncodeToString(tallPNG(t))}, errorContains: "image is too tall"},
+		{name: "unknown dither", body: PreviewRequest{Image: base64.StdEncoding.EncodeToString(testPNG(t)), Dither: "halftone"}, errorContains: "unknown dither mode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _ := setupPreviewRouter()
+
+			w := postPreview(router, tt.body)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), tt.errorContains)
+		})
+	}
+}
+
+func mustRenderText(t *testing.T, text string) *render.Bitmap {
+	t.Helper()
+	bitmap, err := render.RenderText(text, render.DefaultTextOptions())
+	require.NoError(t, err)
+	return bitmap
+}
+
+func mustRenderMarkdown(t *testing.T, source string) *render.Bitmap {
+	t.Helper()
+	bitmap, err := render.RenderMarkdown(source, render.DefaultMarkdownOptions())
+	require.NoError(t, err)
+	return bitmap
+}
+
+func mustRenderJSON(t *testing.T, data interface{}) *render.Bitmap {
+	t.Helper()
+	bitmap, err := render.RenderJSON(data, render.DefaultJSONOptions())
+	require.NoError(t, err)
+	return bitmap
+}
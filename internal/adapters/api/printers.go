@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/princem/peripage-printer/internal/core"
+)
+
+// PrintersResponse lists the configured printers and pools.
+type PrintersResponse struct {
+	Printers []core.PrinterInfo `json:"printers"`
+	Pools    []core.PoolInfo    `json:"pools,omitempty"`
+	// Default is the printer or pool jobs without a "printer" field go to.
+	Default string `json:"default" example:"front-desk"`
+}
+
+// ListPrinters handles the GET /printers endpoint.
+// @Summary List printers
+// @Description Lists the configured printers with their type, connection state and queue depth,
+// @Description and the printer pools with their members.
+// @Tags printer
+// @Produce json
+// @Success 200 {object} PrintersResponse
+// @Router /printers [get]
+func (h *Handler) ListPrinters(c *gin.Context) {
+	printers := h.service.Printers()
+
+	pools := h.service.Pools()
+
+	resp := PrintersResponse{Printers: printers, Pools: pools}
+	for _, printer := range printers {
+		if printer.Default {
+			resp.Default = printer.Name
+		}
+	}
+	for _, pool := range pools {
+		if pool.Default {
+			resp.Default = pool.Name
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
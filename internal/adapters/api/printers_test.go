@@ -0,0 +1,133 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/princem/peripage-printer/internal/core"
+	"github.com/princem/peripage-printer/internal/core/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPrintersRouter serves a front and a kitchen printer, with the kitchen
+// printer as the default.
+func newPrintersRouter(t *testing.T, front, kitchen core.Printer) *gin.Engine {
+	t.Helper()
+	registry := core.NewPrinterRegistry()
+	require.NoError(t, registry.Register("front", "mock", front))
+	require.NoError(t, registry.Register("kitchen", "mock", kitchen))
+	require.NoError(t, registry.SetDefault("kitchen"))
+
+	service, err := core.NewPrintServiceWithRegistry(registry, core.PrintServiceConfig{})
+	require.NoError(t, err)
+	t.Cleanup(service.Close)
+	return SetupRouter(NewHandler(service))
+}
+
+func TestHandler_ListPrinters(t *testing.T) {
+	// Arrange
+	router := newPrintersRouter(t, new(mocks.MockPrinter), new(mocks.MockPrinter))
+
+	// Act
+	w := doRequest(router, http.MethodGet, "/printers")
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response PrintersResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "kitchen", response.Default)
+	require.Len(t, response.Printers, 2)
+	assert.Equal(t, "front", response.Printers[0].Name)
+	assert.False(t, response.Printers[0].Default)
+	assert.Equal(t, "kitchen", response.Printers[1].Name)
+	assert.True(t, response.Printers[1].Default)
+	assert.Equal(t, core.ConnectionConnected, response.Printers[1].Connection)
+}
+
+func TestHandler_Print_Routing(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    map[string]interface{}
+		expectedStatus int
+		expectFront    bool
+		expectKitchen  bool
+	}{
+		{
+			name:           "named printer",
+			requestBody:    map[string]interface{}{"text": "Order 42", "printer": "front"},
+			expectedStatus: http.StatusOK,
+			expectFront:    true,
+		},
+		{
+			name:           "default printer",
+			requestBody:    map[string]interface{}{"text": "Order 42"},
+			expectedStatus: http.StatusOK,
+			expectKitchen:  true,
+		},
+		{
+			name:           "unknown printer",
+			requestBody:    map[string]interface{}{"text": "Order 42", "printer": "bar"},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			front := new(mocks.MockPrinter)
+			kitchen := new(mocks.MockPrinter)
+			if tt.expectFront {
+				front.On("PrintText", "Order 42").Return(nil).Once()
+			}
+			if tt.expectKitchen {
+				kitchen.On("PrintText", "Order 42").Return(nil).Once()
+			}
+			router := newPrintersRouter(t, front, kitchen)
+
+			body, _ := json.Marshal(tt.requestBody)
+			req := httptest.NewRequest(http.MethodPost, "/print", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			front.AssertExpectations(t)
+			kitchen.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_ListPrinters_Pools(t *testing.T) {
+	// Arrange
+	registry := core.NewPrinterRegistry()
+	require.NoError(t, registry.Register("a6-1", "mock", new(mocks.MockPrinter)))
+	require.NoError(t, registry.Register("a6-2", "mock", new(mocks.MockPrinter)))
+	require.NoError(t, registry.RegisterPool("labels", "a6-1", "a6-2"))
+	require.NoError(t, registry.SetDefault("labels"))
+	service, err := core.NewPrintServiceWithRegistry(registry, core.PrintServiceConfig{})
+	require.NoError(t, err)
+	defer service.Close()
+	router := SetupRouter(NewHandler(service))
+
+	// Act
+	w := doRequest(router, http.MethodGet, "/printers")
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response PrintersResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "labels", response.Default)
+	assert.Len(t, response.Printers, 2)
+	require.Len(t, response.Pools, 1)
+	assert.Equal(t, []string{"a6-1", "a6-2"}, response.Pools[0].Members)
+	assert.Equal(t, 2, response.Pools[0].HealthyMembers)
+	assert.True(t, response.Pools[0].Default)
+}
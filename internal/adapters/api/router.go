@@ -10,11 +10,43 @@ import (
 func SetupRouter(handler *Handler) *gin.Engine {
 	router := gin.Default()
 
-	// Health check endpoint
+	// Health check endpoints
 	router.GET("/health", handler.HealthCheck)
+	router.GET("/livez", handler.Livez)
+	router.GET("/readyz", handler.Readyz)
 
 	// Print endpoint
 	router.POST("/print", handler.Print)
+	router.POST("/print/image", handler.PrintImage)
+	router.POST("/print/document", handler.PrintDocument)
+	router.POST("/print/template/:name", handler.PrintTemplate)
+
+	// Preview endpoint
+	router.POST("/preview", handler.Preview)
+
+	// Printer endpoints
+	router.GET("/printers", handler.ListPrinters)
+	router.GET("/printer/status", handler.PrinterStatus)
+	router.GET("/printer/scan", handler.ScanDevices)
+	router.POST("/printer/feed", handler.FeedPaper)
+
+	// Job endpoints
+	router.GET("/jobs", handler.ListJobs)
+	router.GET("/jobs/:id", handler.GetJob)
+	router.DELETE("/jobs/:id", handler.CancelJob)
+	router.POST("/jobs/:id/reprint", handler.ReprintJob)
+
+	// Template endpoints
+	router.GET("/templates", handler.ListTemplates)
+	router.POST("/templates", handler.CreateTemplate)
+	router.GET("/templates/:name", handler.GetTemplate)
+	router.PUT("/templates/:name", handler.PutTemplate)
+	router.DELETE("/templates/:name", handler.DeleteTemplate)
+
+	// Mock printer inspection
+	if handler.mock != nil {
+		setupMockRoutes(router, handler)
+	}
 
 	// Swagger documentation
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
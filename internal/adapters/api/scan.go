@@ -0,0 +1,68 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/princem/peripage-printer/internal/core"
+)
+
+// Scan durations accepted by the scan endpoint.
+const (
+	defaultScanDuration = 5 * time.Second
+	maxScanDuration     = 30 * time.Second
+)
+
+// ScanResponse lists the devices found by a scan.
+type ScanResponse struct {
+	Devices []core.DiscoveredDevice `json:"devices"`
+	Count   int                     `json:"count" example:"2"`
+}
+
+// ScanDevices handles the GET /printer/scan endpoint.
+// @Summary Scan for BLE devices
+// @Description Runs a timed Bluetooth LE scan and lists every nearby device, strongest signal first.
+// @Description "matches" marks the devices the configured criteria select.
+// @Tags printer
+// @Produce json
+// @Param timeout query string false "Scan duration, e.g. 5s (default 5s, maximum 30s)"
+// @Param printer query string false "Printer whose adapter scans, and whose criteria set \"matches\" (default printer if empty)"
+// @Success 200 {object} ScanResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /printer/scan [get]
+func (h *Handler) ScanDevices(c *gin.Context) {
+	duration := defaultScanDuration
+	if raw := c.Query("timeout"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 || d > maxScanDuration {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: "timeout must be a duration between 0s and 30s, e.g. 5s",
+			})
+			return
+		}
+		duration = d
+	}
+
+	devices, err := h.service.ScanDevices(c.Request.Context(), c.Query("printer"), duration)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, ScanResponse{Devices: devices, Count: len(devices)})
+	case errors.Is(err, core.ErrPrinterNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error: err.Error(),
+		})
+	case errors.Is(err, core.ErrScanUnavailable):
+		c.JSON(http.StatusNotImplemented, ErrorResponse{
+			Error: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "Scan failed: " + err.Error(),
+		})
+	}
+}
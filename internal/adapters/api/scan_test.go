@@ -0,0 +1,69 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"testing"
+
+	"github.com/princem/peripage-printer/internal/adapters/printer"
+	"github.com/princem/peripage-printer/internal/core"
+	"github.com/princem/peripage-printer/internal/core/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_ScanDevices(t *testing.T) {
+	// Arrange
+	sim := printer.NewSimulatedPeripage("PeriPage_A6", "AA:BB:CC:DD:EE:FF")
+	blePrinter, err := printer.NewBLEPrinter(printer.BLEPrinterConfig{
+		NamePrefix: "PeriPage",
+		Transport:  sim,
+		Logger:     log.New(&bytes.Buffer{}, "", 0),
+	})
+	require.NoError(t, err)
+	service := core.NewPrintService(blePrinter)
+	defer service.Close()
+	router := SetupRouter(NewHandler(service))
+
+	// Act
+	w := doRequest(router, http.MethodGet, "/printer/scan?timeout=20ms")
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response ScanResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Equal(t, 1, response.Count)
+	assert.Equal(t, "PeriPage_A6", response.Devices[0].Name)
+	assert.Equal(t, "AA:BB:CC:DD:EE:FF", response.Devices[0].Address)
+	assert.True(t, response.Devices[0].Matches)
+}
+
+func TestHandler_ScanDevices_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{name: "invalid timeout", path: "/printer/scan?timeout=soon", expectedStatus: http.StatusBadRequest},
+		{name: "timeout too long", path: "/printer/scan?timeout=2m", expectedStatus: http.StatusBadRequest},
+		{name: "printer cannot scan", path: "/printer/scan", expectedStatus: http.StatusNotImplemented},
+		{name: "unknown printer", path: "/printer/scan?printer=bar", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service := core.NewPrintService(new(mocks.MockPrinter))
+			defer service.Close()
+			router := SetupRouter(NewHandler(service))
+
+			// Act
+			w := doRequest(router, http.MethodGet, tt.path)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/princem/peripage-printer/internal/core"
+)
+
+// PrinterStatus handles the GET /printer/status endpoint.
+// @Summary Get printer status
+// @Description Queries the printer for its battery level, paper, lid and temperature, firmware version and serial number.
+// @Tags printer
+// @Produce json
+// @Param printer query string false "Printer name (default printer if empty)"
+// @Success 200 {object} core.PrinterStatus
+// @Failure 404 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /printer/status [get]
+func (h *Handler) PrinterStatus(c *gin.Context) {
+	status, err := h.service.PrinterStatus(c.Query("printer"))
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, status)
+	case errors.Is(err, core.ErrPrinterNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error: err.Error(),
+		})
+	case errors.Is(err, core.ErrStatusUnavailable):
+		c.JSON(http.StatusNotImplemented, ErrorResponse{
+			Error: err.Error(),
+		})
+	case errors.Is(err, core.ErrPrinterDisconnected):
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Error: "Status query failed: " + err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "Status query failed: " + err.Error(),
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/princem/peripage-printer/internal/adapters/printer"
+	"github.com/princem/peripage-printer/internal/core"
+	"github.com/princem/peripage-printer/internal/core/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_PrinterStatus(t *testing.T) {
+	// Arrange: a BLE printer talking to a simulated Peripage without paper
+	sim := printer.NewSimulatedPeripage("PeriPage_A6", "AA:BB:CC:DD:EE:FF")
+	sim.SetStatus(printer.SimulatedStatus{Battery: 37, PaperOut: true, Firmware: "V2.11", Serial: "A6-1"})
+	blePrinter, err := printer.NewBLEPrinter(printer.BLEPrinterConfig{
+		DeviceName:  "PeriPage_A6",
+		ScanTimeout: 50 * time.Millisecond,
+		Transport:   sim,
+		Logger:      log.New(&bytes.Buffer{}, "", 0),
+	})
+	require.NoError(t, err)
+	require.NoError(t, blePrinter.Connect(context.Background()))
+
+	service := core.NewPrintService(blePrinter)
+	defer service.Close()
+	router := SetupRouter(NewHandler(service))
+
+	// Act
+	w := doRequest(router, http.MethodGet, "/printer/status")
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var status core.PrinterStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Equal(t, 37, status.Battery)
+	assert.False(t, status.PaperPresent)
+	assert.Equal(t, "V2.11", status.Firmware)
+	assert.Equal(t, "A6-1", status.Serial)
+}
+
+func TestHandler_PrinterStatus_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		printer        core.Printer
+		path           string
+		expectedStatus int
+	}{
+		{
+			name:           "unknown printer",
+			printer:        new(mocks.MockPrinter),
+			path:           "/printer/status?printer=bar",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "printer without status support",
+			printer:        new(mocks.MockPrinter),
+			path:           "/printer/status",
+			expectedStatus: http.StatusNotImplemented,
+		},
+		{
+			name: "printer not connected",
+			printer: func() core.Printer {
+				p, _ := printer.NewBLEPrinter(printer.BLEPrinterConfig{
+					Transport: printer.NewSimulatedPeripage("PeriPage_A6", "AA:BB:CC:DD:EE:FF"),
+					Logger:    log.New(&bytes.Buffer{}, "", 0),
+				})
+				return p
+			}(),
+			path:           "/printer/status?printer=default",
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service := core.NewPrintService(tt.printer)
+			defer service.Close()
+			router := SetupRouter(NewHandler(service))
+
+			// Act
+			w := doRequest(router, http.MethodGet, tt.path)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var response ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.NotEmpty(t, response.Error)
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/princem/peripage-printer/internal/core"
+)
+
+// TemplateRequest represents the request body for creating or replacing a
+// template.
+type TemplateRequest struct {
+	// Name is required when creating a template with POST /templates and
+	// ignored by PUT, which takes the name from the path.
+	Name        string `json:"name,omitempty" example:"visitor-badge"`
+	Description string `json:"description,omitempty" example:"Badge for front desk visitors"`
+	// Format selects how the rendered template is printed. Empty means text.
+	Format string `json:"format,omitempty" example:"markdown" enums:"text,markdown"`
+	// Body is the template in Go text/template syntax.
+	Body string `json:"body" binding:"required" example:"# {{.name | upper}}\nVisiting {{.host}}"`
+}
+
+// template converts the request to a core template named name.
+func (r TemplateRequest) template(name string) core.Template {
+	return core.Template{
+		Name:        name,
+		Description: r.Description,
+		Format:      core.JobKind(r.Format),
+		Body:        r.Body,
+	}
+}
+
+// TemplateListResponse represents the response for the template listing endpoint.
+type TemplateListResponse struct {
+	Templates []core.Template `json:"templates"`
+	Count     int             `json:"count" example:"1"`
+}
+
+// ListTemplates handles the GET /templates endpoint.
+// @Summary List print templates
+// @Description Lists the print templates sorted by name
+// @Tags templates
+// @Produce json
+// @Success 200 {object} TemplateListResponse
+// @Router /templates [get]
+func (h *Handler) ListTemplates(c *gin.Context) {
+	templates := h.service.Templates()
+	if templates == nil {
+		templates = []core.Template{}
+	}
+
+	c.JSON(http.StatusOK, TemplateListResponse{
+		Templates: templates,
+		Count:     len(templates),
+	})
+}
+
+// GetTemplate handles the GET /templates/{name} endpoint.
+// @Summary Get a print template
+// @Description Returns a print template with its source
+// @Tags templates
+// @Produce json
+// @Param name path string true "Template name"
+// @Success 200 {object} core.Template
+// @Failure 404 {object} ErrorResponse
+// @Router /templates/{name} [get]
+func (h *Handler) GetTemplate(c *gin.Context) {
+	template, err := h.service.Template(c.Param("name"))
+	if err != nil {
+		h.templateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// CreateTemplate handles the POST /templates endpoint.
+// @Summary Create a print template
+// @Description Adds a print template written in Go text/template syntax. Templates can use the helpers
+// @Description now, date, currency, padLeft, padRight, center, truncate, repeat, upper, lower and default.
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param request body TemplateRequest true "Template"
+// @Success 201 {object} core.Template
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /templates [post]
+func (h *Handler) CreateTemplate(c *gin.Context) {
+	var req TemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	template, err := h.service.CreateTemplate(req.template(req.Name))
+	if err != nil {
+		h.templateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// PutTemplate handles the PUT /templates/{name} endpoint.
+// @Summary Create or replace a print template
+// @Description Stores the template under the name in the path, replacing any template with that name.
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param name path string true "Template name"
+// @Param request body TemplateRequest true "Template"
+// @Success 200 {object} core.Template
+// @Success 201 {object} core.Template
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /templates/{name} [put]
+func (h *Handler) PutTemplate(c *gin.Context) {
+	var req TemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	template, created, err := h.service.PutTemplate(req.template(c.Param("name")))
+	if err != nil {
+		h.templateError(c, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, template)
+}
+
+// DeleteTemplate handles the DELETE /templates/{name} endpoint.
+// @Summary Delete a print template
+// @Description Removes a print template. Jobs already printed from it are not affected.
+// @Tags templates
+// @Param name path string true "Template name"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /templates/{name} [delete]
+func (h *Handler) DeleteTemplate(c *gin.Context) {
+	if err := h.service.DeleteTemplate(c.Param("name")); err != nil {
+		h.templateError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// PrintTemplate handles the POST /print/template/{name} endpoint.
+// @Summary Print a template
+// @Description Fills the template with the data map in the body and prints the result.
+// @Description The job stores the rendered text, so reprinting it ignores later template changes.
+// @Tags print
+// @Accept json
+// @Produce json
+// @Param name path string true "Template name"
+// @Param printer query string false "Printer or pool to print on; empty selects the default"
+// @Param async query bool false "Queue the job and return 202 without waiting for the printer"
+// @Param data body object false "Template data"
+// @Success 200 {object} PrintResponse
+// @Success 202 {object} PrintResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /print/template/{name} [post]
+func (h *Handler) PrintTemplate(c *gin.Context) {
+	// An empty body prints the template without data.
+	var data map[string]interface{}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&data); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: "Invalid request body: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	async := false
+	if raw := c.Query("async"); raw != "" {
+		var err error
+		async, err = strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: "Invalid async parameter: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	jobReq, err := h.service.RenderTemplate(c.Param("name"), data)
+	if err != nil {
+		h.templateError(c, err)
+		return
+	}
+	jobReq.Printer = c.Query("printer")
+
+	h.dispatch(c, jobReq, async)
+}
+
+// templateError maps a template error to an HTTP response.
+func (h *Handler) templateError(c *gin.Context, err error) {
+	var validationErr *core.ValidationError
+	switch {
+	case errors.Is(err, core.ErrTemplateNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error: err.Error(),
+		})
+	case errors.Is(err, core.ErrTemplateExists):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error: err.Error(),
+		})
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid template: " + err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "Template failed: " + err.Error(),
+		})
+	}
+}
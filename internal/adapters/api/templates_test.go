@@ -0,0 +1,258 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/princem/peripage-printer/internal/adapters/printer"
+	"github.com/princem/peripage-printer/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func jsonRequest(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandler_Templates_CRUD(t *testing.T) {
+	// Arrange
+	service := core.NewPrintService(printer.NewMockPrinter(log.New(&bytes.Buffer{}, "", 0)))
+	defer service.Close()
+	router := SetupRouter(NewHandler(service))
+
+	// Act: create
+	w := jsonRequest(router, http.MethodPost, "/templates", `{"name": "badge", "body": "Hello {{.name}}"}`)
+
+	// Assert
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created core.Template
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "badge", created.Name)
+	assert.Equal(t, core.JobText, created.Format)
+
+	// Act: create again
+	w = jsonRequest(router, http.MethodPost, "/templates", `{"name": "badge", "body": "Hi"}`)
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Act: replace
+	w = jsonRequest(router, http.MethodPut, "/templates/badge", `{"format": "markdown", "body": "# {{.name}}"}`)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var replaced core.Template
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &replaced))
+	assert.Equal(t, core.JobMarkdown, replaced.Format)
+	assert.Equal(t, created.CreatedAt.UnixNano(), replaced.CreatedAt.UnixNano())
+
+	// Act: put a new one
+	w = jsonRequest(router, http.MethodPut, "/templates/address", `{"body": "{{.street}}"}`)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// Act: list
+	w = doRequest(router, http.MethodGet, "/templates")
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var list TemplateListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Equal(t, 2, list.Count)
+	assert.Equal(t, "address", list.Templates[0].Name)
+	assert.Equal(t, "badge", list.Templates[1].Name)
+
+	// Act: get
+	w = doRequest(router, http.MethodGet, "/templates/badge")
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var got core.Template
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, "# {{.name}}", got.Body)
+
+	// Act: delete
+	w = doRequest(router, http.MethodDelete, "/templates/badge")
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(router, http.MethodGet, "/templates/badge").Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(router, http.MethodDelete, "/templates/badge").Code)
+}
+
+func TestHandler_Templates_Invalid(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "missing body",
+			method:         http.MethodPost,
+			path:           "/templates",
+			body:           `{"name": "badge"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid name",
+			method:         http.MethodPost,
+			path:           "/templates",
+			body:           `{"name": "Front Desk", "body": "Hi"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown format",
+			method:         http.MethodPut,
+			path:           "/templates/badge",
+			body:           `{"format": "json", "body": "Hi"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "syntax error",
+			method:         http.MethodPut,
+			path:           "/templates/badge",
+			body:           `{"body": "Hi {{.name"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "malformed JSON",
+			method:         http.MethodPost,
+			path:           "/templates",
+			body:           `{"name":`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service := core.NewPrintService(printer.NewMockPrinter(log.New(&bytes.Buffer{}, "", 0)))
+			defer service.Close()
+			router := SetupRouter(NewHandler(service))
+
+			// Act
+			w := jsonRequest(router, tt.method, tt.path, tt.body)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			assert.Empty(t, service.Templates())
+		})
+	}
+}
+
+func TestHandler_PrintTemplate(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		expectedStatus int
+		expectedKind   string
+		expectedText   string
+	}{
+		{
+			name:           "prints the rendered text",
+			path:           "/print/template/receipt",
+			body:           `{"item": "Coffee", "total": 3.5}`,
+			expectedStatus: http.StatusOK,
+			expectedKind:   "text",
+			expectedText:   "Coffee $3.50",
+		},
+		{
+			name:           "queues when async",
+			path:           "/print/template/receipt?async=true",
+			body:           `{"item": "Tea", "total": 2}`,
+			expectedStatus: http.StatusAccepted,
+			expectedKind:   "text",
+			expectedText:   "Tea $2.00",
+		},
+		{
+			name:           "prints markdown templates as images",
+			path:           "/print/template/badge",
+			body:           `{"name": "Ada"}`,
+			expectedStatus: http.StatusOK,
+			expectedKind:   "image",
+		},
+		{
+			name:           "prints without data",
+			path:           "/print/template/badge",
+			expectedStatus: http.StatusOK,
+			expectedKind:   "image",
+		},
+		{
+			name:           "unknown template",
+			path:           "/print/template/missing",
+			body:           `{}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "helper fails",
+			path:           "/print/template/receipt",
+			body:           `{"item": "Coffee", "total": "free"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "body is not an object",
+			path:           "/print/template/receipt",
+			body:           `[1, 2]`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid async",
+			path:           "/print/template/receipt?async=maybe",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown printer",
+			path:           "/print/template/receipt?printer=bar",
+			body:           `{"item": "Coffee", "total": 3.5}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockPrinter := printer.NewMockPrinter(log.New(&bytes.Buffer{}, "", 0))
+			service := core.NewPrintService(mockPrinter)
+			defer service.Close()
+			_, err := service.CreateTemplate(core.Template{Name: "receipt", Body: `{{.item}} {{.total | currency "$"}}`})
+			require.NoError(t, err)
+			_, err = service.CreateTemplate(core.Template{Name: "badge", Format: core.JobMarkdown, Body: "# {{.name | default \"Guest\"}}"})
+			require.NoError(t, err)
+			router := SetupRouter(NewHandler(service))
+
+			// Act
+			w := jsonRequest(router, http.MethodPost, tt.path, tt.body)
+
+			// Assert
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedKind == "" {
+				assert.Empty(t, mockPrinter.Printed())
+				return
+			}
+			var response PrintResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.True(t, response.Success)
+			require.Eventually(t, func() bool {
+				return len(mockPrinter.Printed()) == 1
+			}, time.Second, 5*time.Millisecond)
+			printed := mockPrinter.Printed()[0]
+			assert.Equal(t, tt.expectedKind, printed.Kind)
+			assert.Equal(t, tt.expectedText, printed.Text)
+		})
+	}
+}
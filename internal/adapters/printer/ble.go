@@ -2,216 +2,700 @@ package printer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"image"
 	"log"
+	"sync"
 	"time"
 
-	"tinygo.org/x/bluetooth"
+	"github.com/princem/peripage-printer/internal/adapters/printer/peripage"
+	"github.com/princem/peripage-printer/internal/core"
+	"github.com/princem/peripage-printer/internal/render"
 )
 
 // BLEPrinter implements the Printer interface for Peripage A6 thermal printers over Bluetooth LE.
+// All radio access goes through a Transport, so the same logic drives real
+// hardware and the in-memory SimulatedPeripage.
+//
+// Connect makes a single connection attempt. Start instead runs a supervisor
+// that keeps the printer connected, retrying with exponential backoff while it
+// is off or out of range; jobs then wait for the link to come back.
+//
+// Status notifications are subscribed to during the handshake. Before every
+// job the printer is asked for its status, so a job is refused with
+// core.ErrPaperOut instead of vanishing into an empty printer.
 type BLEPrinter struct {
-	adapter     *bluetooth.Adapter
-	device      *bluetooth.Device
-	deviceName  string
-	scanTimeout time.Duration
-	logger      *log.Logger
+	transport      Transport
+	match          deviceMatcher
+	matchWindow    time.Duration
+	scanTimeout    time.Duration
+	connectTimeout time.Duration
+	reconnectWait  time.Duration
+	minBackoff     time.Duration
+	maxBackoff     time.Duration
+	statusTimeout  time.Duration
+	packetSize     int
+	settings       core.PrintSettings
+	logger         *log.Logger
+
+	// radio serializes scans, which the Bluetooth stack runs one at a time.
+	radio sync.Mutex
+	// io serializes jobs and status queries on the link.
+	io sync.Mutex
+	// replies receives status notifications from the printer.
+	replies chan []byte
+
+	mu   sync.Mutex
+	link Link
+	// notifying is the link whose status notifications are subscribed to.
+	notifying Link
+	state     core.ConnectionState
+	lastErr   error
+	// changed is closed and replaced whenever the state changes.
+	changed chan struct{}
+	// stop and stopped are set while the supervisor runs.
+	stop    chan struct{}
+	stopped chan struct{}
 }
 
 // BLEPrinterConfig holds configuration for the BLE printer.
 type BLEPrinterConfig struct {
-	DeviceName  string
+	// DeviceName, Address, NamePrefix, NamePattern and ServiceUUID select the
+	// printer among advertising devices. Every criterion that is set must
+	// match; with none set, any device advertising the Peripage service does.
+	DeviceName string
+	// Address is the printer's MAC address, compared case-insensitively.
+	Address    string
+	NamePrefix string
+	// NamePattern is a regular expression the advertised name must match.
+	NamePattern string
+	ServiceUUID string
+	// MatchWindow is how long to keep scanning after the first matching
+	// device for one with a stronger signal. Zero connects to the first
+	// match. A scan by address always stops at the first match.
+	MatchWindow time.Duration
 	ScanTimeout time.Duration
-	Logger      *log.Logger
+	// ConnectTimeout bounds one connection attempt made by the supervisor,
+	// including the scan. Defaults to 30 seconds.
+	ConnectTimeout time.Duration
+	// ReconnectWait is how long a job waits for the supervisor to reconnect
+	// before failing. Defaults to 30 seconds.
+	ReconnectWait time.Duration
+	// MinBackoff and MaxBackoff bound the delay between failed connection
+	// attempts. The delay doubles after every failure. Defaults to 1 and
+	// 30 seconds.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// StatusTimeout is how long to wait for the printer to answer a status
+	// query. Defaults to 2 seconds.
+	StatusTimeout time.Duration
+	// PacketSize is the largest write sent to the printer in one go.
+	// The default of 20 bytes fits the minimum BLE ATT MTU.
+	PacketSize int
+	// Settings are the density, speed and paper type of jobs that do not
+	// set their own. Empty fields use core.DefaultPrintSettings.
+	Settings core.PrintSettings
+	// Transport is the Bluetooth stack used to reach the printer.
+	Transport Transport
+	Logger    *log.Logger
 }
 
 // NewBLEPrinter creates a new BLE printer instance.
 func NewBLEPrinter(config BLEPrinterConfig) (*BLEPrinter, error) {
+	if config.Transport == nil {
+		return nil, fmt.Errorf("BLE transport is required")
+	}
 	if config.Logger == nil {
 		config.Logger = log.Default()
 	}
 	if config.ScanTimeout == 0 {
 		config.ScanTimeout = 10 * time.Second
 	}
-
-	adapter := bluetooth.DefaultAdapter
-	if err := adapter.Enable(); err != nil {
-		return nil, fmt.Errorf("failed to enable BLE adapter: %w", err)
+	if config.ConnectTimeout <= 0 {
+		config.ConnectTimeout = 30 * time.Second
+	}
+	if config.ReconnectWait <= 0 {
+		config.ReconnectWait = 30 * time.Second
+	}
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = time.Second
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 30 * time.Second
+	}
+	if config.MaxBackoff < config.MinBackoff {
+		return nil, fmt.Errorf("max backoff %s is shorter than min backoff %s", config.MaxBackoff, config.MinBackoff)
+	}
+	if config.StatusTimeout <= 0 {
+		config.StatusTimeout = 2 * time.Second
+	}
+	if config.PacketSize <= 0 {
+		config.PacketSize = 20
+	}
+	if err := config.Settings.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid print settings: %w", err)
+	}
+	match, err := newDeviceMatcher(config)
+	if err != nil {
+		return nil, err
 	}
 
 	return &BLEPrinter{
-		adapter:     adapter,
-		deviceName:  config.DeviceName,
-		scanTimeout: config.ScanTimeout,
-		logger:      config.Logger,
+		transport:      config.Transport,
+		match:          match,
+		matchWindow:    config.MatchWindow,
+		scanTimeout:    config.ScanTimeout,
+		connectTimeout: config.ConnectTimeout,
+		reconnectWait:  config.ReconnectWait,
+		minBackoff:     config.MinBackoff,
+		maxBackoff:     config.MaxBackoff,
+		statusTimeout:  config.StatusTimeout,
+		packetSize:     config.PacketSize,
+		settings:       config.Settings.Or(core.DefaultPrintSettings()),
+		logger:         config.Logger,
+		replies:        make(chan []byte, 4),
+		state:          core.ConnectionDisconnected,
+		changed:        make(chan struct{}),
 	}, nil
 }
 
 // Connect discovers and connects to the Peripage printer.
+// It makes a single attempt and does nothing when already connected.
 // TODO: Test with actual Peripage device to verify device name and characteristics.
 func (b *BLEPrinter) Connect(ctx context.Context) error {
-	b.logger.Printf("Scanning for device: %s", b.deviceName)
+	b.mu.Lock()
+	connected := b.link != nil
+	b.mu.Unlock()
+	if connected {
+		return nil
+	}
 
-	// Create a channel to receive the device
-	deviceCh := make(chan bluetooth.ScanResult, 1)
-	var foundDevice bluetooth.ScanResult
-	found := false
+	_, err := b.connect(ctx)
+	return err
+}
 
-	// Create context with timeout
-	scanCtx, cancel := context.WithTimeout(ctx, b.scanTimeout)
-	defer cancel()
+// Start runs the connection supervisor in the background. It connects to the
+// printer, reconnects whenever the link drops and backs off exponentially
+// between failed attempts. Start returns immediately, so the server can come
+// up while the printer is still off. Calling Start again has no effect.
+func (b *BLEPrinter) Start() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.stop != nil {
+		return
+	}
+	b.stop = make(chan struct{})
+	b.stopped = make(chan struct{})
+	go b.supervise(b.stop, b.stopped)
+}
+
+// State returns the current connection state.
+func (b *BLEPrinter) State() core.ConnectionState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// LastError returns the error of the most recent failed connection attempt,
+// or nil once connected.
+func (b *BLEPrinter) LastError() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastErr
+}
 
-	// Start scanning
-	err := b.adapter.Scan(func(adapter *bluetooth.Adapter, result bluetooth.ScanResult) {
-		b.logger.Printf("Found device: %s [%s]", result.LocalName(), result.Address.String())
+// supervise keeps the printer connected until stop is closed.
+func (b *BLEPrinter) supervise(stop <-chan struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
 
-		// TODO: Verify exact device name format for Peripage A6
-		if result.LocalName() == b.deviceName {
-			foundDevice = result
-			found = true
-			deviceCh <- result
-			adapter.StopScan()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	backoff := b.minBackoff
+	for {
+		attemptCtx, cancelAttempt := context.WithTimeout(ctx, b.connectTimeout)
+		link, err := b.connect(attemptCtx)
+		cancelAttempt()
+
+		if err == nil {
+			backoff = b.minBackoff
+			select {
+			case <-link.Done():
+				b.lost(link)
+				continue
+			case <-stop:
+				return
+			}
 		}
-	})
 
+		if ctx.Err() != nil {
+			return
+		}
+		b.logger.Printf("Connection attempt failed: %v; retrying in %s", err, backoff)
+		select {
+		case <-time.After(backoff):
+		case <-stop:
+			return
+		}
+		backoff = min(backoff*2, b.maxBackoff)
+	}
+}
+
+// connect scans for the printer, connects and performs the handshake,
+// tracking the connection state on the way.
+func (b *BLEPrinter) connect(ctx context.Context) (Link, error) {
+	b.setState(core.ConnectionConnecting, nil, nil)
+
+	link, err := b.dial(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to start scan: %w", err)
+		b.setState(core.ConnectionDisconnected, nil, err)
+		return nil, err
 	}
 
-	// Wait for device or timeout
-	select {
-	case <-scanCtx.Done():
-		b.adapter.StopScan()
-		if !found {
-			return fmt.Errorf("device not found within timeout")
-		}
-	case <-deviceCh:
-		b.logger.Printf("Found target device: %s", foundDevice.LocalName())
+	b.setState(core.ConnectionConnected, link, nil)
+	b.logger.Println("Successfully connected to printer")
+	return link, nil
+}
+
+// dial discovers the printer and opens a link to it.
+func (b *BLEPrinter) dial(ctx context.Context) (Link, error) {
+	target, err := b.find(ctx)
+	if err != nil {
+		return nil, err
 	}
+	b.logger.Printf("Found target device: %s [%s] at %d dBm", target.Name, target.Address, target.RSSI)
 
-	// Connect to the device
-	device, err := b.adapter.Connect(foundDevice.Address, bluetooth.ConnectionParams{})
+	link, err := b.transport.Connect(ctx, target.Address)
 	if err != nil {
-		return fmt.Errorf("failed to connect to device: %w", err)
+		return nil, fmt.Errorf("failed to connect to device: %w", err)
 	}
 
-	b.device = &device
-	b.logger.Println("Successfully connected to printer")
+	if err := b.performHandshake(link); err != nil {
+		if disconnectErr := link.Disconnect(); disconnectErr != nil {
+			b.logger.Printf("Error disconnecting after failed handshake: %v", disconnectErr)
+		}
+		return nil, fmt.Errorf("handshake failed: %w", err)
+	}
+	return link, nil
+}
 
-	// TODO: Perform handshake with Peripage device
-	// The Peripage protocol requires specific initialization commands
-	// This will need to be implemented based on the device's protocol documentation
-	if err := b.performHandshake(); err != nil {
-		return fmt.Errorf("handshake failed: %w", err)
+// lost forgets a link that dropped on its own.
+func (b *BLEPrinter) lost(link Link) {
+	b.mu.Lock()
+	current := b.link == link
+	b.mu.Unlock()
+	if !current {
+		return
 	}
 
-	return nil
+	b.logger.Println("Printer connection lost, reconnecting")
+	b.setState(core.ConnectionDisconnected, nil, core.ErrPrinterDisconnected)
+}
+
+// setState records a state change and wakes everyone waiting for one.
+func (b *BLEPrinter) setState(state core.ConnectionState, link Link, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = state
+	b.link = link
+	if err != nil || state == core.ConnectionConnected {
+		b.lastErr = err
+	}
+	close(b.changed)
+	b.changed = make(chan struct{})
 }
 
-// performHandshake initializes communication with the Peripage printer.
-// TODO: Implement actual Peripage A6 handshake protocol.
-// This typically involves:
-// 1. Discovering services and characteristics
-// 2. Enabling notifications
-// 3. Sending initialization commands
-func (b *BLEPrinter) performHandshake() error {
+// awaitLink returns the open link. While the supervisor runs it waits up to
+// the reconnect deadline for one; otherwise it fails straight away.
+func (b *BLEPrinter) awaitLink() (Link, error) {
+	deadline := time.NewTimer(b.reconnectWait)
+	defer deadline.Stop()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for b.link == nil {
+		if b.stop == nil {
+			return nil, fmt.Errorf("not connected to printer: %w", core.ErrPrinterDisconnected)
+		}
+
+		changed := b.changed
+		b.mu.Unlock()
+		select {
+		case <-changed:
+			b.mu.Lock()
+		case <-deadline.C:
+			b.mu.Lock()
+			return nil, fmt.Errorf("printer did not reconnect within %s: %w", b.reconnectWait, core.ErrPrinterDisconnected)
+		}
+	}
+	return b.link, nil
+}
+
+// performHandshake initializes communication with the Peripage printer
+// by subscribing to its status notifications and waking it with a reset
+// command. A printer without notifications can still print, only without
+// status checks.
+func (b *BLEPrinter) performHandshake(link Link) error {
 	b.logger.Println("Performing handshake with printer...")
 
-	// TODO: Discover services
-	// services, err := b.device.DiscoverServices(nil)
-	// if err != nil {
-	//     return fmt.Errorf("failed to discover services: %w", err)
-	// }
+	if err := link.Subscribe(b.notified); err != nil {
+		b.logger.Printf("Status notifications unavailable: %v", err)
+	} else {
+		b.mu.Lock()
+		b.notifying = link
+		b.mu.Unlock()
+	}
+
+	if err := b.write(link, peripage.Encode(peripage.Reset{})); err != nil {
+		return fmt.Errorf("failed to wake printer: %w", err)
+	}
+
+	b.logger.Println("Handshake completed")
+	return nil
+}
+
+// notified receives a status notification from the printer.
+func (b *BLEPrinter) notified(data []byte) {
+	reply := append([]byte(nil), data...)
+	select {
+	case b.replies <- reply:
+	default:
+		b.logger.Printf("Dropped unexpected notification % x", reply)
+	}
+}
+
+// Status implements core.StatusReporter. It queries the connected printer and
+// fails with core.ErrPrinterDisconnected instead of waiting for a reconnect.
+func (b *BLEPrinter) Status() (core.PrinterStatus, error) {
+	b.mu.Lock()
+	link, notifying := b.link, b.notifying
+	b.mu.Unlock()
+	if link == nil {
+		return core.PrinterStatus{}, fmt.Errorf("not connected to printer: %w", core.ErrPrinterDisconnected)
+	}
+	if notifying != link {
+		return core.PrinterStatus{}, core.ErrStatusUnavailable
+	}
+
+	b.io.Lock()
+	defer b.io.Unlock()
+	return b.readStatus(link)
+}
+
+// readStatus asks the printer for every status value in turn.
+// The caller must hold b.io.
+func (b *BLEPrinter) readStatus(link Link) (core.PrinterStatus, error) {
+	reply, err := b.query(link, peripage.QueryStatus)
+	if err != nil {
+		return core.PrinterStatus{}, err
+	}
+	flags, err := peripage.ParseStatus(reply)
+	if err != nil {
+		return core.PrinterStatus{}, err
+	}
+
+	reply, err = b.query(link, peripage.QueryBattery)
+	if err != nil {
+		return core.PrinterStatus{}, err
+	}
+	battery, err := peripage.ParseBattery(reply)
+	if err != nil {
+		return core.PrinterStatus{}, err
+	}
+
+	firmware, err := b.query(link, peripage.QueryFirmware)
+	if err != nil {
+		return core.PrinterStatus{}, err
+	}
+	serial, err := b.query(link, peripage.QuerySerial)
+	if err != nil {
+		return core.PrinterStatus{}, err
+	}
+
+	status := flagStatus(flags)
+	status.Battery = battery
+	status.Firmware = peripage.ParseText(firmware)
+	status.Serial = peripage.ParseText(serial)
+	return status, nil
+}
+
+// flagStatus converts the printer's status flags to a core.PrinterStatus.
+func flagStatus(flags peripage.StatusFlags) core.PrinterStatus {
+	return core.PrinterStatus{
+		PaperPresent: !flags.Has(peripage.StatusPaperOut),
+		LidOpen:      flags.Has(peripage.StatusLidOpen),
+		Overheated:   flags.Has(peripage.StatusOverheated),
+		CheckedAt:    time.Now(),
+	}
+}
+
+// query sends q and waits for the printer's reply.
+// The caller must hold b.io.
+func (b *BLEPrinter) query(link Link, q peripage.Query) ([]byte, error) {
+	// Discard replies that arrived after an earlier query timed out.
+	for len(b.replies) > 0 {
+		<-b.replies
+	}
 
-	// TODO: Find the printer service and characteristic UUIDs
-	// Common printer characteristics include:
-	// - Write characteristic for sending data
-	// - Notify characteristic for receiving status
+	if err := b.write(link, q.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to send %s query: %w", q, err)
+	}
 
-	// TODO: Send initialization commands
-	// Example: Send wake-up command, set print density, etc.
+	timeout := time.NewTimer(b.statusTimeout)
+	defer timeout.Stop()
+	select {
+	case reply := <-b.replies:
+		return reply, nil
+	case <-link.Done():
+		return nil, fmt.Errorf("waiting for %s reply: %w", q, core.ErrPrinterDisconnected)
+	case <-timeout.C:
+		return nil, fmt.Errorf("printer did not answer %s query within %s", q, b.statusTimeout)
+	}
+}
 
-	b.logger.Println("Handshake completed (placeholder)")
+// checkReady refuses a job the printer cannot print. A printer that does not
+// answer is given the benefit of the doubt.
+// The caller must hold b.io.
+func (b *BLEPrinter) checkReady(link Link) error {
+	b.mu.Lock()
+	notifying := b.notifying == link
+	b.mu.Unlock()
+	if !notifying {
+		return nil
+	}
+
+	reply, err := b.query(link, peripage.QueryStatus)
+	if errors.Is(err, core.ErrPrinterDisconnected) {
+		return err
+	}
+	if err != nil {
+		b.logger.Printf("Printing without status check: %v", err)
+		return nil
+	}
+	flags, err := peripage.ParseStatus(reply)
+	if err != nil {
+		b.logger.Printf("Printing without status check: %v", err)
+		return nil
+	}
+
+	if err := flagStatus(flags).Ready(); err != nil {
+		return fmt.Errorf("printer refused job: %w", err)
+	}
 	return nil
 }
 
 // PrintText converts text to bitmap and sends it to the printer.
 func (b *BLEPrinter) PrintText(text string) error {
-	if b.device == nil {
-		return fmt.Errorf("not connected to printer")
+	return b.PrintTextWithSettings(text, core.PrintSettings{})
+}
+
+// PrintTextWithSettings prints text with settings that override the
+// printer's defaults.
+func (b *BLEPrinter) PrintTextWithSettings(text string, settings core.PrintSettings) error {
+	link, err := b.awaitLink()
+	if err != nil {
+		return err
 	}
 
 	b.logger.Printf("Printing text: %s", text)
 
-	// TODO: Convert text to bitmap
-	// The Peripage A6 expects bitmap data in a specific format
 	bitmap, err := b.textToBitmap(text)
 	if err != nil {
 		return fmt.Errorf("failed to convert text to bitmap: %w", err)
 	}
 
-	// TODO: Send bitmap to printer in packets
-	// The Peripage has a maximum packet size (typically 512 bytes)
-	if err := b.sendBitmap(bitmap); err != nil {
-		return fmt.Errorf("failed to send bitmap: %w", err)
+	if err := b.print(link, bitmap, settings); err != nil {
+		return err
 	}
 
 	b.logger.Println("Print job completed successfully")
 	return nil
 }
 
+// PrintImage sends an image to the printer. A *render.Bitmap is printed as
+// is; any other image is scaled to the print head and thresholded.
+func (b *BLEPrinter) PrintImage(img image.Image) error {
+	return b.PrintImageWithSettings(img, core.PrintSettings{})
+}
+
+// PrintImageWithSettings prints an image with settings that override the
+// printer's defaults.
+func (b *BLEPrinter) PrintImageWithSettings(img image.Image, settings core.PrintSettings) error {
+	link, err := b.awaitLink()
+	if err != nil {
+		return err
+	}
+
+	bitmap, err := headRaster(img)
+	if err != nil {
+		return fmt.Errorf("failed to convert image to bitmap: %w", err)
+	}
+
+	b.logger.Printf("Printing %dx%d image", bitmap.Width, bitmap.Height)
+
+	if err := b.print(link, bitmap, settings); err != nil {
+		return err
+	}
+
+	b.logger.Println("Print job completed successfully")
+	return nil
+}
+
+// print checks that the printer is ready and sends the bitmap.
+func (b *BLEPrinter) print(link Link, bitmap *render.Bitmap, settings core.PrintSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	b.io.Lock()
+	defer b.io.Unlock()
+
+	if err := b.checkReady(link); err != nil {
+		return err
+	}
+	if err := b.sendBitmap(link, bitmap, settings.Or(b.settings)); err != nil {
+		return fmt.Errorf("failed to send bitmap: %w", err)
+	}
+	return nil
+}
+
+// Feed implements core.Feeder. It waits for the printer like a print job
+// and refuses to feed a printer that is out of paper or open.
+func (b *BLEPrinter) Feed(lines int) error {
+	cmds, err := peripage.FeedJob(lines)
+	if err != nil {
+		return err
+	}
+
+	link, err := b.awaitLink()
+	if err != nil {
+		return err
+	}
+
+	b.io.Lock()
+	defer b.io.Unlock()
+
+	if err := b.checkReady(link); err != nil {
+		return err
+	}
+	b.logger.Printf("Feeding %d lines (%.1f mm)", lines, render.LengthMM(lines))
+	if err := b.write(link, peripage.Encode(cmds...)); err != nil {
+		return fmt.Errorf("failed to feed paper: %w", err)
+	}
+	return nil
+}
+
 // textToBitmap converts text string to bitmap data for the thermal printer.
-// TODO: Implement actual bitmap rendering algorithm.
-// Requirements:
-// - Render text at appropriate size (typically 384px width for A6)
-// - Convert to 1-bit monochrome bitmap
-// - Format according to Peripage protocol
-func (b *BLEPrinter) textToBitmap(text string) ([]byte, error) {
-	b.logger.Println("Converting text to bitmap (placeholder)")
-
-	// TODO: Use a font rendering library (e.g., golang.org/x/image/font)
-	// to render text as bitmap
-	// 1. Create image with appropriate dimensions (384px width for Peripage A6)
-	// 2. Render text onto image
-	// 3. Convert to 1-bit monochrome
-	// 4. Format as bytes in printer's expected format
-
-	// Placeholder: return empty bitmap
-	return []byte{}, fmt.Errorf("bitmap rendering not yet implemented")
-}
-
-// sendBitmap sends bitmap data to the printer in appropriate packet sizes.
-// TODO: Implement packet protocol for Peripage A6.
-// The printer expects:
-// - Data split into packets (max 512 bytes typically)
-// - Each packet may need headers/checksums
-// - Final packet to trigger printing
-func (b *BLEPrinter) sendBitmap(bitmap []byte) error {
-	b.logger.Printf("Sending %d bytes to printer (placeholder)", len(bitmap))
-
-	// TODO: Split bitmap into packets
-	// TODO: Write each packet to the BLE characteristic
-	// TODO: Wait for acknowledgment if required
-	// TODO: Send print trigger command
-
-	return fmt.Errorf("bitmap sending not yet implemented")
-}
-
-// Disconnect closes the connection to the printer.
+// The text is laid out with the shared renderer so the result matches what
+// every other printer adapter would produce for the same job.
+func (b *BLEPrinter) textToBitmap(text string) (*render.Bitmap, error) {
+	bitmap, err := textRaster(text)
+	if err != nil {
+		return nil, err
+	}
+
+	b.logger.Printf("Rendered text to %dx%d bitmap", bitmap.Width, bitmap.Height)
+	return bitmap, nil
+}
+
+// sendBitmap encodes the bitmap as a Peripage print job and writes it to the
+// printer. The job starts with the commands for its density, speed and paper
+// type, so every job prints the same whatever the job before it set.
+func (b *BLEPrinter) sendBitmap(link Link, bitmap *render.Bitmap, settings core.PrintSettings) error {
+	cmds, err := peripage.Job(bitmap.Stride, bitmap.Pix, jobOptions(settings))
+	if err != nil {
+		return fmt.Errorf("failed to encode print job: %w", err)
+	}
+
+	data := peripage.Encode(cmds...)
+	b.logger.Printf("Sending %d bytes to printer", len(data))
+	return b.write(link, data)
+}
+
+// jobOptions maps complete print settings to protocol options.
+func jobOptions(settings core.PrintSettings) peripage.JobOptions {
+	opts := peripage.JobOptions{Concentration: peripage.ConcentrationNormal}
+	switch settings.Density {
+	case core.DensityLight:
+		opts.Concentration = peripage.ConcentrationLight
+	case core.DensityDark:
+		opts.Concentration = peripage.ConcentrationDark
+	}
+
+	speed := peripage.SpeedNormal
+	switch settings.Speed {
+	case core.SpeedSlow:
+		speed = peripage.SpeedSlow
+	case core.SpeedFast:
+		speed = peripage.SpeedFast
+	}
+	opts.Speed = &speed
+
+	paper := peripage.PaperContinuous
+	switch settings.Paper {
+	case core.PaperLabel:
+		paper = peripage.PaperLabel
+	case core.PaperSticker:
+		paper = peripage.PaperSticker
+	}
+	opts.Paper = &paper
+	return opts
+}
+
+// write sends data over the link, split into packets.
+func (b *BLEPrinter) write(link Link, data []byte) error {
+	for start := 0; start < len(data); start += b.packetSize {
+		end := start + b.packetSize
+		if end > len(data) {
+			end = len(data)
+		}
+		if err := link.Write(data[start:end]); err != nil {
+			select {
+			case <-link.Done():
+				b.logger.Printf("Write failed after the link dropped: %v", err)
+				return fmt.Errorf("failed to write packet at offset %d: %w", start, core.ErrPrinterDisconnected)
+			default:
+			}
+			return fmt.Errorf("failed to write packet at offset %d: %w", start, err)
+		}
+	}
+	return nil
+}
+
+// Disconnect stops the connection supervisor, if running, and closes the
+// connection to the printer.
 func (b *BLEPrinter) Disconnect() error {
-	if b.device == nil {
+	b.mu.Lock()
+	stop, stopped := b.stop, b.stopped
+	b.stop, b.stopped = nil, nil
+	b.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-stopped
+	}
+
+	b.mu.Lock()
+	link := b.link
+	b.mu.Unlock()
+	if link == nil {
+		b.setState(core.ConnectionDisconnected, nil, nil)
 		return nil
 	}
 
 	b.logger.Println("Disconnecting from printer")
 
-	// TODO: Send any cleanup commands before disconnecting
-
-	if err := b.device.Disconnect(); err != nil {
+	b.setState(core.ConnectionDisconnected, nil, nil)
+	if err := link.Disconnect(); err != nil {
 		return fmt.Errorf("failed to disconnect: %w", err)
 	}
 
-	b.device = nil
 	b.logger.Println("Disconnected successfully")
 	return nil
 }
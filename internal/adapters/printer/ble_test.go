@@ -3,59 +3,67 @@
 package printer
 
 import (
+	"bytes"
+	"context"
+	"image"
+	"log"
 	"testing"
+	"time"
 
+	"github.com/princem/peripage-printer/internal/adapters/printer/peripage"
+	"github.com/princem/peripage-printer/internal/core"
+	"github.com/princem/peripage-printer/internal/render"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-// TestNewBLEPrinter_Placeholder is a placeholder test for BLE printer.
-// Real testing requires actual hardware.
-// TODO: Implement once hardware is available and protocol is documented.
-func TestNewBLEPrinter_Placeholder(t *testing.T) {
-	t.Skip("BLE printer tests require actual hardware - use integration tests")
+const testAddress = "AA:BB:CC:DD:EE:FF"
 
-	// This test would verify:
-	// - BLEPrinter configuration validation
-	// - Adapter initialization
-	// - Device name validation
-	// - Timeout validation
-}
-
-// TestBLEPrinter_Connect_Placeholder is a placeholder for connection testing.
-// TODO: Requires actual Peripage A6 device for testing.
-func TestBLEPrinter_Connect_Placeholder(t *testing.T) {
-	t.Skip("BLE connection tests require actual hardware - use integration tests")
+// newTestBLEPrinter creates a BLE printer driven by a simulated Peripage.
+func newTestBLEPrinter(t *testing.T, deviceName string) (*BLEPrinter, *SimulatedPeripage) {
+	t.Helper()
 
-	// This test would verify:
-	// - Device discovery
-	// - Connection establishment
-	// - Handshake protocol
-	// - Error handling for connection failures
-	// - Timeout behavior
+	sim := NewSimulatedPeripage("PeriPage_A6", testAddress)
+	printer, err := NewBLEPrinter(BLEPrinterConfig{
+		DeviceName:  deviceName,
+		ScanTimeout: 50 * time.Millisecond,
+		Transport:   sim,
+		Logger:      log.New(&bytes.Buffer{}, "", 0),
+	})
+	require.NoError(t, err)
+	return printer, sim
 }
 
-// TestBLEPrinter_PrintText_Placeholder is a placeholder for print testing.
-// TODO: Requires actual Peripage A6 device and bitmap implementation.
-func TestBLEPrinter_PrintText_Placeholder(t *testing.T) {
-	t.Skip("BLE print tests require actual hardware - use integration tests")
+// newSupervisedBLEPrinter creates a BLE printer with short reconnect timings
+// and starts its connection supervisor. The simulated Peripage starts off.
+func newSupervisedBLEPrinter(t *testing.T, reconnectWait time.Duration) (*BLEPrinter, *SimulatedPeripage) {
+	t.Helper()
 
-	// This test would verify:
-	// - Text to bitmap conversion
-	// - Packet formatting
-	// - Data transmission
-	// - Print command execution
-	// - Error handling
-}
+	sim := NewSimulatedPeripage("PeriPage_A6", testAddress)
+	sim.SetPowered(false)
+	printer, err := NewBLEPrinter(BLEPrinterConfig{
+		DeviceName:     "PeriPage_A6",
+		ScanTimeout:    20 * time.Millisecond,
+		ConnectTimeout: 50 * time.Millisecond,
+		ReconnectWait:  reconnectWait,
+		MinBackoff:     5 * time.Millisecond,
+		MaxBackoff:     20 * time.Millisecond,
+		Transport:      sim,
+		Logger:         log.New(&bytes.Buffer{}, "", 0),
+	})
+	require.NoError(t, err)
 
-// TestBLEPrinter_Disconnect_Placeholder is a placeholder for disconnect testing.
-// TODO: Requires actual Peripage A6 device.
-func TestBLEPrinter_Disconnect_Placeholder(t *testing.T) {
-	t.Skip("BLE disconnect tests require actual hardware - use integration tests")
+	printer.Start()
+	t.Cleanup(func() { _ = printer.Disconnect() })
+	return printer, sim
+}
 
-	// This test would verify:
-	// - Clean disconnection
-	// - Cleanup of resources
-	// - Error handling during disconnect
+// awaitState waits for the printer to reach the given connection state.
+func awaitState(t *testing.T, printer *BLEPrinter, state core.ConnectionState) {
+	t.Helper()
+	assert.Eventually(t, func() bool {
+		return printer.State() == state
+	}, time.Second, 5*time.Millisecond, "printer never became %s", state)
 }
 
 // TestBLEPrinter_Configuration validates configuration validation logic.
@@ -71,6 +79,7 @@ func TestBLEPrinter_Configuration(t *testing.T) {
 			config: BLEPrinterConfig{
 				DeviceName:  "Peripage",
 				ScanTimeout: 10,
+				Transport:   NewSimulatedPeripage("Peripage", testAddress),
 				Logger:      nil,
 			},
 			shouldErr: false,
@@ -80,41 +89,507 @@ func TestBLEPrinter_Configuration(t *testing.T) {
 			config: BLEPrinterConfig{
 				DeviceName:  "",
 				ScanTimeout: 10,
+				Transport:   NewSimulatedPeripage("Peripage", testAddress),
 				Logger:      nil,
 			},
 			shouldErr: false,
 		},
+		{
+			name: "missing transport",
+			config: BLEPrinterConfig{
+				DeviceName: "Peripage",
+			},
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			printer, err := NewBLEPrinter(tt.config)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				assert.Nil(t, printer)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, printer)
+				assert.NotNil(t, printer.logger)
+				assert.Equal(t, 20, printer.packetSize)
+				assert.Equal(t, core.ConnectionDisconnected, printer.State())
+			}
+		})
+	}
+}
+
+func TestBLEPrinter_Configuration_Backoff(t *testing.T) {
+	_, err := NewBLEPrinter(BLEPrinterConfig{
+		Transport:  NewSimulatedPeripage("Peripage", testAddress),
+		MinBackoff: time.Minute,
+		MaxBackoff: time.Second,
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "max backoff")
+}
+
+func TestBLEPrinter_Connect(t *testing.T) {
+	tests := []struct {
+		name          string
+		deviceName    string
+		powered       bool
+		expectedError string
+	}{
+		{
+			name:       "connects to advertised device",
+			deviceName: "PeriPage_A6",
+			powered:    true,
+		},
+		{
+			name:          "device with another name is not found",
+			deviceName:    "Other",
+			powered:       true,
+			expectedError: "device not found within timeout",
+		},
+		{
+			name:          "powered off device is not found",
+			deviceName:    "PeriPage_A6",
+			powered:       false,
+			expectedError: "device not found within timeout",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Note: We can't actually test this without initializing BLE adapter
-			// which requires hardware/permissions
-			t.Skip("BLE adapter initialization requires system Bluetooth access")
-
-			// This would test:
-			// printer, err := NewBLEPrinter(tt.config)
-			// if tt.shouldErr {
-			//     assert.Error(t, err)
-			//     assert.Nil(t, printer)
-			// } else {
-			//     assert.NoError(t, err)
-			//     assert.NotNil(t, printer)
-			// }
+			// Arrange
+			printer, sim := newTestBLEPrinter(t, tt.deviceName)
+			sim.SetPowered(tt.powered)
+
+			// Act
+			err := printer.Connect(context.Background())
+
+			// Assert
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.False(t, sim.Connected())
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, sim.Connected())
+			assert.Equal(t, []peripage.Command{peripage.Reset{}}, sim.Commands(), "handshake should wake the printer")
 		})
 	}
 }
 
-// TestBLEPrinter_ErrorHandling validates error handling without hardware.
-func TestBLEPrinter_ErrorHandling(t *testing.T) {
-	t.Skip("Error handling tests require mocking Bluetooth adapter")
+func TestBLEPrinter_PrintText(t *testing.T) {
+	// Arrange
+	printer, sim := newTestBLEPrinter(t, "PeriPage_A6")
+	require.NoError(t, printer.Connect(context.Background()))
+
+	expected, err := render.RenderText("Hello, World!", render.DefaultTextOptions())
+	require.NoError(t, err)
+
+	// Act
+	err = printer.PrintText("Hello, World!")
+
+	// Assert
+	require.NoError(t, err)
+
+	jobs := sim.Jobs()
+	require.Len(t, jobs, 1)
+	assert.Equal(t, peripage.ConcentrationNormal, jobs[0].Concentration)
+	assert.Equal(t, expected.Width, jobs[0].Raster.Width)
+	assert.Equal(t, expected.Height, jobs[0].Raster.Height)
+	assert.Equal(t, expected.Pix, jobs[0].Raster.Pix)
+
+	for _, size := range sim.Writes() {
+		assert.LessOrEqual(t, size, 20, "writes must respect the packet size")
+	}
+}
+
+func TestBLEPrinter_PrintSettings(t *testing.T) {
+	tests := []struct {
+		name          string
+		defaults      core.PrintSettings
+		job           core.PrintSettings
+		concentration peripage.Concentration
+		speed         peripage.Speed
+		paper         peripage.PaperType
+	}{
+		{
+			name:          "power-on defaults",
+			concentration: peripage.ConcentrationNormal,
+			speed:         peripage.SpeedNormal,
+			paper:         peripage.PaperContinuous,
+		},
+		{
+			name:          "printer defaults",
+			defaults:      core.PrintSettings{Density: core.DensityLight, Paper: core.PaperSticker},
+			concentration: peripage.ConcentrationLight,
+			speed:         peripage.SpeedNormal,
+			paper:         peripage.PaperSticker,
+		},
+		{
+			name:          "job overrides printer defaults",
+			defaults:      core.PrintSettings{Density: core.DensityLight, Paper: core.PaperSticker},
+			job:           core.PrintSettings{Density: core.DensityDark, Speed: core.SpeedSlow, Paper: core.PaperLabel},
+			concentration: peripage.ConcentrationDark,
+			speed:         peripage.SpeedSlow,
+			paper:         peripage.PaperLabel,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			sim := NewSimulatedPeripage("PeriPage_A6", testAddress)
+			printer, err := NewBLEPrinter(BLEPrinterConfig{
+				DeviceName:  "PeriPage_A6",
+				ScanTimeout: 50 * time.Millisecond,
+				Settings:    tt.defaults,
+				Transport:   sim,
+				Logger:      log.New(&bytes.Buffer{}, "", 0),
+			})
+			require.NoError(t, err)
+			require.NoError(t, printer.Connect(context.Background()))
+
+			// Act
+			err = printer.PrintTextWithSettings("Label", tt.job)
+
+			// Assert
+			require.NoError(t, err)
+			jobs := sim.Jobs()
+			require.Len(t, jobs, 1)
+			assert.Equal(t, tt.concentration, jobs[0].Concentration)
+			assert.Equal(t, tt.speed, jobs[0].Speed)
+			assert.Equal(t, tt.paper, jobs[0].Paper)
+		})
+	}
+}
+
+func TestBLEPrinter_PrintText_MatchesPreview(t *testing.T) {
+	// Arrange
+	printer, sim := newTestBLEPrinter(t, "PeriPage_A6")
+	require.NoError(t, printer.Connect(context.Background()))
+
+	text := "Preview\n\tmust match\nthe paper exactly, even when a long line wraps"
+	preview, err := core.Rasterize(core.JobRequest{Kind: core.JobText, Text: text})
+	require.NoError(t, err)
+
+	// Act
+	require.NoError(t, printer.PrintText(text))
+
+	// Assert
+	jobs := sim.Jobs()
+	require.Len(t, jobs, 1)
+	assert.Equal(t, preview.Height, jobs[0].Raster.Height)
+	assert.Equal(t, preview.Pix, jobs[0].Raster.Pix)
+}
+
+func TestBLEPrinter_PrintText_NotConnected(t *testing.T) {
+	printer, sim := newTestBLEPrinter(t, "PeriPage_A6")
+
+	err := printer.PrintText("Hello")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not connected to printer")
+	assert.Empty(t, sim.Jobs())
+}
+
+func TestBLEPrinter_PrintImage(t *testing.T) {
+	// A narrow bitmap is padded to the head, a plain image is scaled to it.
+	logo := render.NewBitmap(16, 4)
+	logo.Set(3, 2, true)
+
+	tests := []struct {
+		name           string
+		img            image.Image
+		expectedHeight int
+		check          func(t *testing.T, raster *render.Bitmap)
+	}{
+		{
+			name:           "bitmap narrower than the head",
+			img:            logo,
+			expectedHeight: 4,
+			check: func(t *testing.T, raster *render.Bitmap) {
+				assert.True(t, raster.Black(3, 2))
+				assert.False(t, raster.Black(200, 2))
+			},
+		},
+		{
+			name:           "grey image is scaled and thresholded",
+			img:            image.NewGray(image.Rect(0, 0, 96, 48)),
+			expectedHeight: 192,
+			check: func(t *testing.T, raster *render.Bitmap) {
+				assert.True(t, raster.Black(0, 0))
+				assert.True(t, raster.Black(383, 191))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			printer, sim := newTestBLEPrinter(t, "PeriPage_A6")
+			require.NoError(t, printer.Connect(context.Background()))
+
+			// Act
+			err := printer.PrintImage(tt.img)
+
+			// Assert
+			require.NoError(t, err)
+			jobs := sim.Jobs()
+			require.Len(t, jobs, 1)
+			assert.Equal(t, render.HeadWidth, jobs[0].Raster.Width)
+			assert.Equal(t, tt.expectedHeight, jobs[0].Raster.Height)
+			tt.check(t, jobs[0].Raster)
+		})
+	}
+}
+
+func TestBLEPrinter_PrintImage_Errors(t *testing.T) {
+	printer, sim := newTestBLEPrinter(t, "PeriPage_A6")
+
+	err := printer.PrintImage(render.NewBitmap(8, 8))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not connected to printer")
+
+	require.NoError(t, printer.Connect(context.Background()))
+	err = printer.PrintImage(render.NewBitmap(render.HeadWidth+8, 8))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "print head")
+	assert.Empty(t, sim.Jobs())
+}
+
+func TestBLEPrinter_Disconnect(t *testing.T) {
+	// Arrange
+	printer, sim := newTestBLEPrinter(t, "PeriPage_A6")
+	require.NoError(t, printer.Connect(context.Background()))
+
+	// Act
+	err := printer.Disconnect()
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, sim.Connected())
+	assert.Error(t, printer.PrintText("after disconnect"))
+
+	// Disconnecting twice is a no-op
+	assert.NoError(t, printer.Disconnect())
+}
+
+func TestBLEPrinter_ReconnectAfterDisconnect(t *testing.T) {
+	printer, sim := newTestBLEPrinter(t, "PeriPage_A6")
+
+	require.NoError(t, printer.Connect(context.Background()))
+	require.NoError(t, printer.Disconnect())
+	require.NoError(t, printer.Connect(context.Background()))
+	require.NoError(t, printer.PrintText("again"))
+
+	assert.Len(t, sim.Jobs(), 1)
+}
+
+func TestBLEPrinter_Start_PrinterSwitchedOnLater(t *testing.T) {
+	// Arrange
+	printer, sim := newSupervisedBLEPrinter(t, time.Second)
+	time.Sleep(30 * time.Millisecond)
+	require.NotEqual(t, core.ConnectionConnected, printer.State())
+	assert.Error(t, printer.LastError(), "failed attempts should be recorded")
+
+	// Act
+	sim.SetPowered(true)
+
+	// Assert
+	awaitState(t, printer, core.ConnectionConnected)
+	assert.NoError(t, printer.LastError())
+	require.NoError(t, printer.PrintText("hello"))
+	assert.Len(t, sim.Jobs(), 1)
+}
+
+func TestBLEPrinter_Start_ReconnectsAfterDrop(t *testing.T) {
+	// Arrange
+	printer, sim := newSupervisedBLEPrinter(t, time.Second)
+	sim.SetPowered(true)
+	awaitState(t, printer, core.ConnectionConnected)
+
+	// Act: the printer is switched off, and a job arrives before it is back
+	sim.SetPowered(false)
+	require.Eventually(t, func() bool {
+		return printer.State() != core.ConnectionConnected
+	}, time.Second, time.Millisecond, "drop was not noticed")
+
+	result := make(chan error, 1)
+	go func() { result <- printer.PrintText("after the drop") }()
+	time.Sleep(20 * time.Millisecond)
+	sim.SetPowered(true)
+
+	// Assert: the job waited for the reconnect instead of failing
+	select {
+	case err := <-result:
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("job did not finish after the printer came back")
+	}
+	assert.Equal(t, core.ConnectionConnected, printer.State())
+	assert.Len(t, sim.Jobs(), 1)
+}
+
+func TestBLEPrinter_Start_JobTimesOutWaitingForPrinter(t *testing.T) {
+	// Arrange
+	printer, sim := newSupervisedBLEPrinter(t, 30*time.Millisecond)
+
+	// Act
+	err := printer.PrintImage(render.NewBitmap(8, 8))
+
+	// Assert
+	require.Error(t, err)
+	assert.ErrorIs(t, err, core.ErrPrinterDisconnected)
+	assert.Contains(t, err.Error(), "did not reconnect within")
+	assert.Empty(t, sim.Jobs())
+}
+
+func TestBLEPrinter_Disconnect_StopsSupervisor(t *testing.T) {
+	// Arrange
+	printer, sim := newSupervisedBLEPrinter(t, time.Second)
+	sim.SetPowered(true)
+	awaitState(t, printer, core.ConnectionConnected)
+
+	// Act
+	require.NoError(t, printer.Disconnect())
+	time.Sleep(30 * time.Millisecond)
+
+	// Assert: no reconnect, and jobs fail without waiting
+	assert.False(t, sim.Connected())
+	assert.Equal(t, core.ConnectionDisconnected, printer.State())
+	err := printer.PrintText("after stop")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not connected to printer")
+}
+
+func TestBLEPrinter_Status(t *testing.T) {
+	// Arrange
+	printer, sim := newTestBLEPrinter(t, "PeriPage_A6")
+	require.NoError(t, printer.Connect(context.Background()))
+	sim.SetStatus(SimulatedStatus{
+		Battery:  64,
+		LidOpen:  true,
+		Firmware: "V2.11_304dpi",
+		Serial:   "A6-123456",
+	})
+
+	// Act
+	status, err := printer.Status()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 64, status.Battery)
+	assert.True(t, status.PaperPresent)
+	assert.True(t, status.LidOpen)
+	assert.False(t, status.Overheated)
+	assert.Equal(t, "V2.11_304dpi", status.Firmware)
+	assert.Equal(t, "A6-123456", status.Serial)
+	assert.False(t, status.CheckedAt.IsZero())
+}
+
+func TestBLEPrinter_Status_NotConnected(t *testing.T) {
+	printer, _ := newTestBLEPrinter(t, "PeriPage_A6")
+
+	_, err := printer.Status()
+
+	assert.ErrorIs(t, err, core.ErrPrinterDisconnected)
+}
+
+func TestBLEPrinter_RefusesJobsPrinterCannotPrint(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   SimulatedStatus
+		expected error
+	}{
+		{name: "paper out", status: SimulatedStatus{Battery: 80, PaperOut: true}, expected: core.ErrPaperOut},
+		{name: "lid open", status: SimulatedStatus{Battery: 80, LidOpen: true}, expected: core.ErrLidOpen},
+		{name: "overheated", status: SimulatedStatus{Battery: 80, Overheated: true}, expected: core.ErrOverheated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			printer, sim := newTestBLEPrinter(t, "PeriPage_A6")
+			require.NoError(t, printer.Connect(context.Background()))
+			sim.SetStatus(tt.status)
+
+			// Act
+			err := printer.PrintText("never printed")
+
+			// Assert
+			assert.ErrorIs(t, err, tt.expected)
+			assert.NotContains(t, sim.Commands(), peripage.Command(peripage.EndJob{}), "no job should be sent")
+		})
+	}
+}
+
+func TestBLEPrinter_Feed(t *testing.T) {
+	// Arrange
+	printer, sim := newTestBLEPrinter(t, "PeriPage_A6")
+	require.NoError(t, printer.Connect(context.Background()))
+
+	// Act
+	err := printer.Feed(300)
+
+	// Assert
+	require.NoError(t, err)
+	jobs := sim.Jobs()
+	require.Len(t, jobs, 1)
+	assert.Equal(t, 300, jobs[0].FeedLines)
+	assert.Equal(t, 0, jobs[0].Raster.Height, "nothing is printed")
+
+	// Act & Assert: invalid lengths and a printer without paper are refused
+	assert.Error(t, printer.Feed(0))
+	sim.SetStatus(SimulatedStatus{Battery: 80, PaperOut: true})
+	assert.ErrorIs(t, printer.Feed(8), core.ErrPaperOut)
+	assert.Len(t, sim.Jobs(), 1)
+}
+
+// silentLink is a Link whose printer never sends notifications.
+type silentLink struct {
+	Link
+}
+
+func (silentLink) Subscribe(func([]byte)) error { return nil }
+
+// silentTransport wraps a simulator so notifications are never delivered.
+type silentTransport struct {
+	*SimulatedPeripage
+}
+
+func (t silentTransport) Connect(ctx context.Context, address string) (Link, error) {
+	link, err := t.SimulatedPeripage.Connect(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	return silentLink{Link: link}, nil
+}
+
+func TestBLEPrinter_PrintsWhenPrinterDoesNotAnswer(t *testing.T) {
+	// Arrange
+	sim := NewSimulatedPeripage("PeriPage_A6", testAddress)
+	printer, err := NewBLEPrinter(BLEPrinterConfig{
+		DeviceName:    "PeriPage_A6",
+		ScanTimeout:   50 * time.Millisecond,
+		StatusTimeout: 10 * time.Millisecond,
+		Transport:     silentTransport{sim},
+		Logger:        log.New(&bytes.Buffer{}, "", 0),
+	})
+	require.NoError(t, err)
+	require.NoError(t, printer.Connect(context.Background()))
+
+	// Act
+	printErr := printer.PrintText("hello")
+	_, statusErr := printer.Status()
 
-	// TODO: Implement tests for:
-	// - Connection timeout
-	// - Device not found
-	// - Disconnection during print
-	// - Invalid bitmap data
-	// - Transmission errors
+	// Assert
+	require.NoError(t, printErr)
+	assert.Len(t, sim.Jobs(), 1)
+	assert.ErrorContains(t, statusErr, "did not answer")
 }
 
 // Note: For actual BLE testing, see test/integration/ble_integration_test.go
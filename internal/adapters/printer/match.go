@@ -0,0 +1,98 @@
+package printer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/princem/peripage-printer/internal/adapters/printer/peripage"
+)
+
+// deviceMatcher decides which advertising device is the printer.
+// Every criterion that is set must match. Without any criteria it accepts
+// every device advertising the Peripage service.
+type deviceMatcher struct {
+	name        string
+	address     string
+	namePrefix  string
+	namePattern *regexp.Regexp
+	serviceUUID string
+}
+
+// newDeviceMatcher builds the matcher for config.
+func newDeviceMatcher(config BLEPrinterConfig) (deviceMatcher, error) {
+	m := deviceMatcher{
+		name:        config.DeviceName,
+		address:     strings.ToUpper(config.Address),
+		namePrefix:  config.NamePrefix,
+		serviceUUID: strings.ToLower(config.ServiceUUID),
+	}
+	if config.NamePattern != "" {
+		pattern, err := regexp.Compile(config.NamePattern)
+		if err != nil {
+			return deviceMatcher{}, fmt.Errorf("invalid device name pattern: %w", err)
+		}
+		m.namePattern = pattern
+	}
+	if m.name == "" && m.address == "" && m.namePrefix == "" && m.namePattern == nil && m.serviceUUID == "" {
+		m.serviceUUID = peripage.ServiceUUID
+	}
+	return m, nil
+}
+
+// matches reports whether adv is a device the printer may connect to.
+func (m deviceMatcher) matches(adv Advertisement) bool {
+	if m.name != "" && adv.Name != m.name {
+		return false
+	}
+	if m.address != "" && strings.ToUpper(adv.Address) != m.address {
+		return false
+	}
+	if m.namePrefix != "" && !strings.HasPrefix(adv.Name, m.namePrefix) {
+		return false
+	}
+	if m.namePattern != nil && !m.namePattern.MatchString(adv.Name) {
+		return false
+	}
+	if m.serviceUUID != "" && !hasService(adv, m.serviceUUID) {
+		return false
+	}
+	return true
+}
+
+// unique reports whether at most one device can match, so a scan can stop
+// at the first match instead of looking for a stronger signal.
+func (m deviceMatcher) unique() bool {
+	return m.address != ""
+}
+
+// String describes the criteria for log messages.
+func (m deviceMatcher) String() string {
+	var parts []string
+	if m.name != "" {
+		parts = append(parts, fmt.Sprintf("name %q", m.name))
+	}
+	if m.address != "" {
+		parts = append(parts, "address "+m.address)
+	}
+	if m.namePrefix != "" {
+		parts = append(parts, fmt.Sprintf("name prefix %q", m.namePrefix))
+	}
+	if m.namePattern != nil {
+		parts = append(parts, fmt.Sprintf("name pattern %q", m.namePattern))
+	}
+	if m.serviceUUID != "" {
+		parts = append(parts, "service "+m.serviceUUID)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// hasService reports whether adv lists the service UUID.
+func hasService(adv Advertisement, uuid string) bool {
+	for _, advertised := range adv.ServiceUUIDs {
+		if strings.EqualFold(advertised, uuid) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package printer
+
+import (
+	"testing"
+
+	"github.com/princem/peripage-printer/internal/adapters/printer/peripage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceMatcher(t *testing.T) {
+	peripageAd := Advertisement{
+		Name:         "PeriPage_A6_1a2b",
+		Address:      "AA:BB:CC:DD:EE:FF",
+		ServiceUUIDs: []string{peripage.ServiceUUID},
+	}
+	headphones := Advertisement{Name: "Headphones", Address: "11:22:33:44:55:66"}
+
+	tests := []struct {
+		name     string
+		config   BLEPrinterConfig
+		adv      Advertisement
+		expected bool
+	}{
+		{name: "exact name", config: BLEPrinterConfig{DeviceName: "PeriPage_A6_1a2b"}, adv: peripageAd, expected: true},
+		{name: "other name", config: BLEPrinterConfig{DeviceName: "PeriPage"}, adv: peripageAd, expected: false},
+		{name: "address ignores case", config: BLEPrinterConfig{Address: "aa:bb:cc:dd:ee:ff"}, adv: peripageAd, expected: true},
+		{name: "other address", config: BLEPrinterConfig{Address: "AA:BB:CC:DD:EE:00"}, adv: peripageAd, expected: false},
+		{name: "name prefix", config: BLEPrinterConfig{NamePrefix: "PeriPage_"}, adv: peripageAd, expected: true},
+		{name: "name pattern", config: BLEPrinterConfig{NamePattern: `^PeriPage_A[46]_`}, adv: peripageAd, expected: true},
+		{name: "name pattern mismatch", config: BLEPrinterConfig{NamePattern: `^PeriPage_A4_`}, adv: peripageAd, expected: false},
+		{name: "service UUID ignores case", config: BLEPrinterConfig{ServiceUUID: "0000FF00-0000-1000-8000-00805F9B34FB"}, adv: peripageAd, expected: true},
+		{name: "all criteria must match", config: BLEPrinterConfig{NamePrefix: "PeriPage_", Address: "11:22:33:44:55:66"}, adv: peripageAd, expected: false},
+		{name: "no criteria accepts Peripage service", config: BLEPrinterConfig{}, adv: peripageAd, expected: true},
+		{name: "no criteria rejects other devices", config: BLEPrinterConfig{}, adv: headphones, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			m, err := newDeviceMatcher(tt.config)
+			require.NoError(t, err)
+
+			// Act & Assert
+			assert.Equal(t, tt.expected, m.matches(tt.adv))
+		})
+	}
+}
+
+func TestDeviceMatcher_InvalidPattern(t *testing.T) {
+	_, err := newDeviceMatcher(BLEPrinterConfig{NamePattern: "PeriPage_("})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid device name pattern")
+}
+
+func TestDeviceMatcher_String(t *testing.T) {
+	m, err := newDeviceMatcher(BLEPrinterConfig{NamePrefix: "PeriPage_", Address: "aa:bb:cc:dd:ee:ff"})
+	require.NoError(t, err)
+
+	assert.Equal(t, `address AA:BB:CC:DD:EE:FF, name prefix "PeriPage_"`, m.String())
+	assert.True(t, m.unique())
+}
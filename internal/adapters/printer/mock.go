@@ -1,14 +1,80 @@
 package printer
 
 import (
+	"bytes"
 	"fmt"
+	"image"
+	"image/png"
 	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/princem/peripage-printer/internal/core"
+	"github.com/princem/peripage-printer/internal/render"
 )
 
+// RollFile is the name of the continuous paper roll image in the mock
+// printer's output directory.
+const RollFile = "roll.png"
+
+// MaxRollHeight is the most paper, in dot lines, the roll image keeps. Older
+// output is cut from the top so the roll stays about 1 m long however long
+// the printer runs; the job images keep everything.
+const MaxRollHeight = 1000 * render.DotsPerMM
+
+// jobFilePattern matches the numbered job images written by the mock printer.
+var jobFilePattern = regexp.MustCompile(`^job-(\d+)\.png$`)
+
+// PrintedJob describes one job printed by the mock printer.
+type PrintedJob struct {
+	Number    int       `json:"number" example:"1"`
+	Kind      string    `json:"kind" example:"text"`
+	Text      string    `json:"text,omitempty" example:"Hello, World!"`
+	Width     int       `json:"width" example:"384"`
+	Height    int       `json:"height" example:"28"`
+	LengthMM  float64   `json:"length_mm" example:"3.5"`
+	File      string    `json:"file,omitempty" example:"job-0001.png"`
+	PrintedAt time.Time `json:"printed_at"`
+	// PrintSettings are the density, speed and paper type the job printed with.
+	core.PrintSettings
+	// Error is set when the job was cut short, e.g. by a simulated disconnect.
+	Error string `json:"error,omitempty" example:"printer disconnected"`
+}
+
 // MockPrinter is a test implementation that prints to stdout.
 // Useful for development and testing without actual hardware.
+// With an output directory configured it also rasterizes every job the way
+// the BLE printer does and saves it as a numbered PNG and as part of a
+// continuous paper roll. MockFaults make it fail on demand.
 type MockPrinter struct {
-	logger *log.Logger
+	logger    *log.Logger
+	outputDir string
+	settings  core.PrintSettings
+
+	mu       sync.Mutex
+	printed  []PrintedJob
+	next     int
+	roll     *render.Bitmap
+	faults   MockFaults
+	attempts int
+	random   *rand.Rand
+}
+
+// MockPrinterConfig holds configuration for the mock printer.
+type MockPrinterConfig struct {
+	// OutputDir receives job-NNNN.png files and roll.png. Empty disables
+	// PNG output.
+	OutputDir string
+	// Faults are the failures simulated from the start.
+	Faults MockFaults
+	// Settings are the defaults for jobs that do not set their own.
+	Settings core.PrintSettings
+	Logger   *log.Logger
 }
 
 // NewMockPrinter creates a new mock printer instance.
@@ -17,14 +83,277 @@ func NewMockPrinter(logger *log.Logger) *MockPrinter {
 		logger = log.Default()
 	}
 	return &MockPrinter{
-		logger: logger,
+		logger:   logger,
+		settings: core.DefaultPrintSettings(),
+		next:     1,
+		random:   rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
+// NewMockPrinterWithConfig creates a mock printer with the given settings.
+// When the output directory already holds a roll from an earlier run, new
+// jobs are appended to it and numbering continues after the last job file.
+func NewMockPrinterWithConfig(config MockPrinterConfig) (*MockPrinter, error) {
+	m := NewMockPrinter(config.Logger)
+	if err := config.Faults.Validate(); err != nil {
+		return nil, err
+	}
+	m.faults = config.Faults
+	if err := config.Settings.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid print settings: %w", err)
+	}
+	m.settings = config.Settings.Or(m.settings)
+
+	if config.OutputDir == "" {
+		return m, nil
+	}
+
+	if err := os.MkdirAll(config.OutputDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create mock output directory: %w", err)
+	}
+	m.outputDir = config.OutputDir
+
+	if err := m.resume(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // PrintText outputs text to stdout, simulating a real printer.
 func (m *MockPrinter) PrintText(text string) error {
+	return m.PrintTextWithSettings(text, core.PrintSettings{})
+}
+
+// PrintTextWithSettings prints text like PrintText and records the settings
+// it was printed with.
+func (m *MockPrinter) PrintTextWithSettings(text string, settings core.PrintSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	faults, err := m.inject()
+	if err != nil {
+		return err
+	}
+
 	m.logger.Println("=== MOCK PRINTER OUTPUT ===")
 	fmt.Println(text)
 	m.logger.Println("=== END MOCK PRINTER OUTPUT ===")
+
+	var bitmap *render.Bitmap
+	if m.outputDir != "" {
+		bitmap, err = textRaster(text)
+		if err != nil {
+			return fmt.Errorf("failed to convert text to bitmap: %w", err)
+		}
+	}
+	job := PrintedJob{Kind: "text", Text: text, PrintSettings: settings.Or(m.settings)}
+	return m.finish(job, bitmap, faults)
+}
+
+// PrintImage logs the size of the image, and saves it when PNG output is enabled.
+func (m *MockPrinter) PrintImage(img image.Image) error {
+	return m.PrintImageWithSettings(img, core.PrintSettings{})
+}
+
+// PrintImageWithSettings prints an image like PrintImage and records the
+// settings it was printed with.
+func (m *MockPrinter) PrintImageWithSettings(img image.Image, settings core.PrintSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	faults, err := m.inject()
+	if err != nil {
+		return err
+	}
+
+	bounds := img.Bounds()
+	m.logger.Println("=== MOCK PRINTER OUTPUT ===")
+	fmt.Printf("[image %dx%d]\n", bounds.Dx(), bounds.Dy())
+	m.logger.Println("=== END MOCK PRINTER OUTPUT ===")
+
+	job := PrintedJob{Kind: "image", Width: bounds.Dx(), Height: bounds.Dy(), PrintSettings: settings.Or(m.settings)}
+	var bitmap *render.Bitmap
+	if m.outputDir != "" {
+		bitmap, err = headRaster(img)
+		if err != nil {
+			return fmt.Errorf("failed to convert image to bitmap: %w", err)
+		}
+	}
+	return m.finish(job, bitmap, faults)
+}
+
+// Feed implements core.Feeder. With PNG output the paper roll grows by the
+// blank lines fed.
+func (m *MockPrinter) Feed(lines int) error {
+	if lines <= 0 {
+		return fmt.Errorf("invalid feed: %d lines", lines)
+	}
+	if m.Faults().PaperOut {
+		return fmt.Errorf("mock printer: %w", core.ErrPaperOut)
+	}
+
+	m.logger.Printf("=== MOCK PRINTER FEED %d lines (%.1f mm) ===", lines, render.LengthMM(lines))
+	if m.outputDir == "" {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.appendRoll(render.NewBitmap(render.HeadWidth, lines))
+}
+
+// finish records a printed job, saving its raster when PNG output is
+// enabled. A simulated disconnect keeps only the top half of the raster and
+// fails the job.
+func (m *MockPrinter) finish(job PrintedJob, bitmap *render.Bitmap, faults MockFaults) error {
+	var jobErr error
+	if faults.DisconnectMidJob {
+		jobErr = fmt.Errorf("mock printer: %w mid-job", core.ErrPrinterDisconnected)
+		job.Error = core.ErrPrinterDisconnected.Error()
+		if bitmap != nil {
+			bitmap = bitmap.Top(bitmap.Height / 2)
+		}
+	}
+
+	if bitmap == nil || bitmap.Height == 0 {
+		// Nothing is saved without PNG output or when nothing reaches the paper.
+		m.record(job, bitmap)
+		return jobErr
+	}
+	if err := m.save(job, bitmap); err != nil {
+		return err
+	}
+	return jobErr
+}
+
+// Printed returns the jobs printed since the printer was created, oldest first.
+func (m *MockPrinter) Printed() []PrintedJob {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	printed := make([]PrintedJob, len(m.printed))
+	copy(printed, m.printed)
+	return printed
+}
+
+// OutputDir returns the directory PNG files are written to, or "" when PNG
+// output is disabled.
+func (m *MockPrinter) OutputDir() string {
+	return m.outputDir
+}
+
+// record adds a job that was not saved to a file to the listing.
+func (m *MockPrinter) record(job PrintedJob, bitmap *render.Bitmap) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job.Number = m.next
+	m.next++
+	job.PrintedAt = time.Now()
+	if bitmap != nil {
+		job.Width = bitmap.Width
+		job.Height = bitmap.Height
+		job.LengthMM = render.LengthMM(bitmap.Height)
+	}
+	m.printed = append(m.printed, job)
+}
+
+// save writes the job's raster to its own PNG and appends it to the roll.
+func (m *MockPrinter) save(job PrintedJob, bitmap *render.Bitmap) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job.Number = m.next
+	job.File = fmt.Sprintf("job-%04d.png", job.Number)
+	if err := writePNG(filepath.Join(m.outputDir, job.File), bitmap); err != nil {
+		return err
+	}
+
+	if err := m.appendRoll(bitmap); err != nil {
+		return err
+	}
+
+	m.next++
+	job.PrintedAt = time.Now()
+	job.Width = bitmap.Width
+	job.Height = bitmap.Height
+	job.LengthMM = render.LengthMM(bitmap.Height)
+	m.printed = append(m.printed, job)
+
+	m.logger.Printf("Saved %s (%dx%d, %.1f mm)", job.File, job.Width, job.Height, job.LengthMM)
+	return nil
+}
+
+// appendRoll adds part to the end of the paper roll, keeping the last
+// MaxRollHeight lines, and rewrites the roll file. The caller holds m.mu.
+func (m *MockPrinter) appendRoll(part *render.Bitmap) error {
+	roll := render.Stack(m.roll, part)
+	if roll.Height > MaxRollHeight {
+		roll = render.Stack(roll.Bottom(MaxRollHeight))
+	}
+	if err := writePNG(filepath.Join(m.outputDir, RollFile), roll); err != nil {
+		return err
+	}
+	m.roll = roll
+	return nil
+}
+
+// resume picks up numbering and the roll left in the output directory by an
+// earlier run.
+func (m *MockPrinter) resume() error {
+	entries, err := os.ReadDir(m.outputDir)
+	if err != nil {
+		return fmt.Errorf("failed to read mock output directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		match := jobFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		if n, err := strconv.Atoi(match[1]); err == nil && n >= m.next {
+			m.next = n + 1
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(m.outputDir, RollFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read paper roll: %w", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		m.logger.Printf("Starting a new paper roll, existing %s is unreadable: %v", RollFile, err)
+		return nil
+	}
+	roll, err := render.RenderImage(img, render.ImageOptions{
+		Width:  img.Bounds().Dx(),
+		Dither: render.DitherThreshold,
+	})
+	if err != nil {
+		m.logger.Printf("Starting a new paper roll, existing %s is unusable: %v", RollFile, err)
+		return nil
+	}
+	m.roll = roll
+	return nil
+}
+
+// writePNG atomically replaces path with the bitmap encoded as PNG.
+func writePNG(path string, bitmap *render.Bitmap) error {
+	var buf bytes.Buffer
+	if err := render.EncodePNG(&buf, bitmap); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filepath.Base(path), err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
 	return nil
 }
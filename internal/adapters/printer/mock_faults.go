@@ -0,0 +1,115 @@
+package printer
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/princem/peripage-printer/internal/core"
+)
+
+// ErrInjectedFailure is returned by the mock printer for failures injected
+// with MockFaults.FailEvery or MockFaults.FailureRate.
+var ErrInjectedFailure = errors.New("injected print failure")
+
+// MockFaults configures failures the mock printer simulates.
+// The zero value prints every job successfully.
+type MockFaults struct {
+	// FailEvery fails every Nth print attempt. Zero disables it.
+	FailEvery int
+	// FailureRate fails print attempts at random with this probability (0 to 1).
+	FailureRate float64
+	// Latency delays every print attempt, as sending a job over BLE would.
+	Latency time.Duration
+	// PaperOut, LowBattery and Overheated reject jobs until cleared.
+	PaperOut   bool
+	LowBattery bool
+	Overheated bool
+	// DisconnectMidJob drops the connection halfway through every job. Only
+	// the top half of the job reaches the paper roll.
+	DisconnectMidJob bool
+}
+
+// Validate checks that the faults describe a possible configuration.
+func (f MockFaults) Validate() error {
+	if f.FailEvery < 0 {
+		return fmt.Errorf("fail every must not be negative: %d", f.FailEvery)
+	}
+	if f.FailureRate < 0 || f.FailureRate > 1 {
+		return fmt.Errorf("failure rate must be between 0 and 1: %g", f.FailureRate)
+	}
+	if f.Latency < 0 {
+		return fmt.Errorf("latency must not be negative: %s", f.Latency)
+	}
+	return nil
+}
+
+// Faults returns the failures currently simulated.
+func (m *MockPrinter) Faults() MockFaults {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.faults
+}
+
+// SetFaults replaces the simulated failures. The attempt counter used by
+// FailEvery restarts from zero.
+func (m *MockPrinter) SetFaults(faults MockFaults) error {
+	if err := faults.Validate(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.faults = faults
+	m.attempts = 0
+	m.logger.Printf("Mock printer faults set to %+v", faults)
+	return nil
+}
+
+// Status implements core.StatusReporter. It reports the simulated paper,
+// battery and temperature faults.
+func (m *MockPrinter) Status() (core.PrinterStatus, error) {
+	faults := m.Faults()
+
+	status := core.PrinterStatus{
+		Battery:      100,
+		PaperPresent: !faults.PaperOut,
+		Overheated:   faults.Overheated,
+		Firmware:     "mock",
+		Serial:       "MOCK-0001",
+		CheckedAt:    time.Now(),
+	}
+	if faults.LowBattery {
+		status.Battery = 5
+	}
+	return status, nil
+}
+
+// inject applies latency and decides whether the next print attempt fails
+// before anything is printed. It returns the faults in effect for the attempt.
+func (m *MockPrinter) inject() (MockFaults, error) {
+	m.mu.Lock()
+	faults := m.faults
+	m.attempts++
+	attempt := m.attempts
+	random := m.random.Float64()
+	m.mu.Unlock()
+
+	if faults.Latency > 0 {
+		time.Sleep(faults.Latency)
+	}
+
+	switch {
+	case faults.PaperOut:
+		return faults, fmt.Errorf("mock printer: %w", core.ErrPaperOut)
+	case faults.LowBattery:
+		return faults, fmt.Errorf("mock printer: %w", core.ErrLowBattery)
+	case faults.Overheated:
+		return faults, fmt.Errorf("mock printer: %w", core.ErrOverheated)
+	case faults.FailEvery > 0 && attempt%faults.FailEvery == 0:
+		return faults, fmt.Errorf("mock printer: %w (attempt %d)", ErrInjectedFailure, attempt)
+	case random < faults.FailureRate:
+		return faults, fmt.Errorf("mock printer: %w (random)", ErrInjectedFailure)
+	}
+	return faults, nil
+}
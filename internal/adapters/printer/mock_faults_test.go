@@ -0,0 +1,176 @@
+package printer
+
+import (
+	"bytes"
+	"log"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/princem/peripage-printer/internal/core"
+	"github.com/princem/peripage-printer/internal/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFaultyMockPrinter(t *testing.T, faults MockFaults) *MockPrinter {
+	t.Helper()
+	printer, err := NewMockPrinterWithConfig(MockPrinterConfig{
+		Faults: faults,
+		Logger: log.New(&bytes.Buffer{}, "", 0),
+	})
+	require.NoError(t, err)
+	return printer
+}
+
+func TestMockPrinter_Faults_PrinterConditions(t *testing.T) {
+	tests := []struct {
+		name     string
+		faults   MockFaults
+		expected error
+	}{
+		{name: "paper out", faults: MockFaults{PaperOut: true}, expected: core.ErrPaperOut},
+		{name: "low battery", faults: MockFaults{LowBattery: true}, expected: core.ErrLowBattery},
+		{name: "overheated", faults: MockFaults{Overheated: true}, expected: core.ErrOverheated},
+		{name: "always failing", faults: MockFaults{FailureRate: 1}, expected: ErrInjectedFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			printer := newFaultyMockPrinter(t, tt.faults)
+
+			// Act
+			textErr := printer.PrintText("Hello")
+			imageErr := printer.PrintImage(render.NewBitmap(8, 8))
+
+			// Assert
+			assert.ErrorIs(t, textErr, tt.expected)
+			assert.ErrorIs(t, imageErr, tt.expected)
+			assert.Empty(t, printer.Printed(), "failed jobs never reach the paper")
+		})
+	}
+}
+
+func TestMockPrinter_Status(t *testing.T) {
+	tests := []struct {
+		name     string
+		faults   MockFaults
+		expected core.PrinterStatus
+	}{
+		{name: "healthy", faults: MockFaults{}, expected: core.PrinterStatus{Battery: 100, PaperPresent: true}},
+		{name: "paper out", faults: MockFaults{PaperOut: true}, expected: core.PrinterStatus{Battery: 100}},
+		{name: "low battery", faults: MockFaults{LowBattery: true}, expected: core.PrinterStatus{Battery: 5, PaperPresent: true}},
+		{name: "overheated", faults: MockFaults{Overheated: true}, expected: core.PrinterStatus{Battery: 100, PaperPresent: true, Overheated: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			printer := newFaultyMockPrinter(t, tt.faults)
+
+			// Act
+			status, err := printer.Status()
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected.Battery, status.Battery)
+			assert.Equal(t, tt.expected.PaperPresent, status.PaperPresent)
+			assert.Equal(t, tt.expected.Overheated, status.Overheated)
+			assert.Equal(t, "mock", status.Firmware)
+			assert.False(t, status.CheckedAt.IsZero())
+		})
+	}
+}
+
+func TestMockPrinter_Faults_FailEvery(t *testing.T) {
+	// Arrange
+	printer := newFaultyMockPrinter(t, MockFaults{FailEvery: 3})
+
+	// Act
+	var failed []int
+	for attempt := 1; attempt <= 7; attempt++ {
+		if err := printer.PrintText("job"); err != nil {
+			assert.ErrorIs(t, err, ErrInjectedFailure)
+			failed = append(failed, attempt)
+		}
+	}
+
+	// Assert
+	assert.Equal(t, []int{3, 6}, failed)
+	assert.Len(t, printer.Printed(), 5)
+}
+
+func TestMockPrinter_Faults_Latency(t *testing.T) {
+	printer := newFaultyMockPrinter(t, MockFaults{Latency: 30 * time.Millisecond})
+
+	start := time.Now()
+	require.NoError(t, printer.PrintText("slow"))
+
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+}
+
+func TestMockPrinter_Faults_DisconnectMidJob(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	printer, err := NewMockPrinterWithConfig(MockPrinterConfig{
+		OutputDir: dir,
+		Faults:    MockFaults{DisconnectMidJob: true},
+		Logger:    log.New(&bytes.Buffer{}, "", 0),
+	})
+	require.NoError(t, err)
+
+	// Act
+	err = printer.PrintImage(render.NewBitmap(render.HeadWidth, 40))
+
+	// Assert: the job fails and only its top half reaches the roll
+	assert.ErrorIs(t, err, core.ErrPrinterDisconnected)
+	printed := printer.Printed()
+	require.Len(t, printed, 1)
+	assert.Equal(t, 20, printed[0].Height)
+	assert.Equal(t, "printer disconnected", printed[0].Error)
+	roll := decodePNGFile(t, filepath.Join(dir, RollFile))
+	assert.Equal(t, 20, roll.Bounds().Dy())
+}
+
+func TestMockPrinter_SetFaults(t *testing.T) {
+	// Arrange
+	printer := newFaultyMockPrinter(t, MockFaults{PaperOut: true})
+	require.Error(t, printer.PrintText("x"))
+
+	// Act: refill the paper
+	require.NoError(t, printer.SetFaults(MockFaults{}))
+
+	// Assert
+	assert.NoError(t, printer.PrintText("x"))
+	assert.Equal(t, MockFaults{}, printer.Faults())
+}
+
+func TestMockFaults_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		faults  MockFaults
+		wantErr bool
+	}{
+		{name: "zero value", faults: MockFaults{}},
+		{name: "all faults", faults: MockFaults{FailEvery: 2, FailureRate: 0.5, Latency: time.Second, PaperOut: true}},
+		{name: "negative fail every", faults: MockFaults{FailEvery: -1}, wantErr: true},
+		{name: "rate above one", faults: MockFaults{FailureRate: 1.5}, wantErr: true},
+		{name: "negative latency", faults: MockFaults{Latency: -time.Second}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.faults.Validate()
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	_, err := NewMockPrinterWithConfig(MockPrinterConfig{Faults: MockFaults{FailureRate: 2}})
+	assert.Error(t, err)
+}
@@ -2,9 +2,15 @@ package printer
 
 import (
 	"bytes"
+	"image"
+	"image/png"
 	"log"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/princem/peripage-printer/internal/core"
+	"github.com/princem/peripage-printer/internal/render"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -109,3 +115,171 @@ func TestMockPrinter_PrintText_AlwaysSucceeds(t *testing.T) {
 		assert.NoError(t, err, "MockPrinter should never fail")
 	}
 }
+
+func TestMockPrinter_PrintImage(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	logger := log.New(&buf, "", 0)
+	printer := NewMockPrinter(logger)
+
+	// Act
+	err := printer.PrintImage(render.NewBitmap(render.HeadWidth, 120))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "=== MOCK PRINTER OUTPUT ===")
+}
+
+func newPNGMockPrinter(t *testing.T, dir string) *MockPrinter {
+	t.Helper()
+	printer, err := NewMockPrinterWithConfig(MockPrinterConfig{
+		OutputDir: dir,
+		Logger:    log.New(&bytes.Buffer{}, "", 0),
+	})
+	require.NoError(t, err)
+	return printer
+}
+
+func decodePNGFile(t *testing.T, path string) image.Image {
+	t.Helper()
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	img, err := png.Decode(f)
+	require.NoError(t, err)
+	return img
+}
+
+func TestMockPrinter_PNGOutput(t *testing.T) {
+	// Arrange
+	dir := filepath.Join(t.TempDir(), "prints")
+	printer := newPNGMockPrinter(t, dir)
+
+	expected, err := render.RenderText("Hello", render.DefaultTextOptions())
+	require.NoError(t, err)
+	logo := render.NewBitmap(64, 10)
+
+	// Act
+	require.NoError(t, printer.PrintText("Hello"))
+	require.NoError(t, printer.PrintImage(logo))
+
+	// Assert: one PNG per job, rasterized like the BLE printer
+	first := decodePNGFile(t, filepath.Join(dir, "job-0001.png"))
+	assert.Equal(t, image.Rect(0, 0, render.HeadWidth, expected.Height), first.Bounds())
+	second := decodePNGFile(t, filepath.Join(dir, "job-0002.png"))
+	assert.Equal(t, image.Rect(0, 0, render.HeadWidth, 10), second.Bounds())
+
+	// Assert: the roll holds both jobs back to back
+	roll := decodePNGFile(t, filepath.Join(dir, RollFile))
+	assert.Equal(t, expected.Height+10, roll.Bounds().Dy())
+
+	printed := printer.Printed()
+	require.Len(t, printed, 2)
+	assert.Equal(t, PrintedJob{
+		Number:        1,
+		Kind:          "text",
+		Text:          "Hello",
+		Width:         render.HeadWidth,
+		Height:        expected.Height,
+		LengthMM:      render.LengthMM(expected.Height),
+		File:          "job-0001.png",
+		PrintedAt:     printed[0].PrintedAt,
+		PrintSettings: core.DefaultPrintSettings(),
+	}, printed[0])
+	assert.Equal(t, "image", printed[1].Kind)
+	assert.Equal(t, "job-0002.png", printed[1].File)
+}
+
+func TestMockPrinter_PNGOutput_ResumesRoll(t *testing.T) {
+	// Arrange: an earlier run already printed a job
+	dir := t.TempDir()
+	require.NoError(t, newPNGMockPrinter(t, dir).PrintImage(render.NewBitmap(render.HeadWidth, 7)))
+
+	// Act
+	printer := newPNGMockPrinter(t, dir)
+	require.NoError(t, printer.PrintImage(render.NewBitmap(render.HeadWidth, 5)))
+
+	// Assert
+	assert.FileExists(t, filepath.Join(dir, "job-0002.png"))
+	roll := decodePNGFile(t, filepath.Join(dir, RollFile))
+	assert.Equal(t, 12, roll.Bounds().Dy())
+	assert.Equal(t, 2, printer.Printed()[0].Number)
+}
+
+func TestMockPrinter_Feed(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	printer := newPNGMockPrinter(t, dir)
+	require.NoError(t, printer.PrintImage(render.NewBitmap(render.HeadWidth, 7)))
+
+	// Act
+	err := printer.Feed(40)
+
+	// Assert: the roll grows, but no job is recorded
+	require.NoError(t, err)
+	roll := decodePNGFile(t, filepath.Join(dir, RollFile))
+	assert.Equal(t, 47, roll.Bounds().Dy())
+	assert.Len(t, printer.Printed(), 1)
+
+	assert.Error(t, printer.Feed(-1))
+	require.NoError(t, printer.SetFaults(MockFaults{PaperOut: true}))
+	assert.ErrorIs(t, printer.Feed(8), core.ErrPaperOut)
+}
+
+func TestMockPrinter_RollIsCapped(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	printer := newPNGMockPrinter(t, dir)
+	last := render.NewBitmap(render.HeadWidth, 10)
+	last.Set(0, 0, true)
+
+	// Act
+	require.NoError(t, printer.PrintImage(render.NewBitmap(render.HeadWidth, MaxRollHeight-5)))
+	require.NoError(t, printer.PrintImage(last))
+
+	// Assert: the oldest lines were cut, and the latest job ends the roll
+	roll := decodePNGFile(t, filepath.Join(dir, RollFile))
+	require.Equal(t, MaxRollHeight, roll.Bounds().Dy())
+	r, _, _, _ := roll.At(0, MaxRollHeight-10).RGBA()
+	assert.Zero(t, r)
+	first := decodePNGFile(t, filepath.Join(dir, "job-0001.png"))
+	assert.Equal(t, MaxRollHeight-5, first.Bounds().Dy())
+}
+
+func TestMockPrinter_Printed_WithoutOutputDir(t *testing.T) {
+	printer := NewMockPrinter(log.New(&bytes.Buffer{}, "", 0))
+
+	require.NoError(t, printer.PrintText("one"))
+	require.NoError(t, printer.PrintText("two"))
+
+	printed := printer.Printed()
+	require.Len(t, printed, 2)
+	assert.Equal(t, "two", printed[1].Text)
+	assert.Equal(t, 2, printed[1].Number)
+	assert.Empty(t, printed[1].File)
+	assert.Empty(t, printer.OutputDir())
+}
+
+func TestMockPrinter_PrintSettings(t *testing.T) {
+	// Arrange: label paper by default
+	printer, err := NewMockPrinterWithConfig(MockPrinterConfig{
+		Settings: core.PrintSettings{Paper: core.PaperLabel},
+		Logger:   log.New(&bytes.Buffer{}, "", 0),
+	})
+	require.NoError(t, err)
+
+	// Act
+	require.NoError(t, printer.PrintText("default"))
+	require.NoError(t, printer.PrintTextWithSettings("dark", core.PrintSettings{Density: core.DensityDark}))
+	err = printer.PrintTextWithSettings("bad", core.PrintSettings{Speed: "warp"})
+
+	// Assert
+	assert.Error(t, err)
+	printed := printer.Printed()
+	require.Len(t, printed, 2)
+	assert.Equal(t, core.PrintSettings{Density: core.DensityNormal, Speed: core.SpeedNormal, Paper: core.PaperLabel}, printed[0].PrintSettings)
+	assert.Equal(t, core.PrintSettings{Density: core.DensityDark, Speed: core.SpeedNormal, Paper: core.PaperLabel}, printed[1].PrintSettings)
+
+	_, err = NewMockPrinterWithConfig(MockPrinterConfig{Settings: core.PrintSettings{Density: "black"}})
+	assert.Error(t, err)
+}
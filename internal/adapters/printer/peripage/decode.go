@@ -0,0 +1,122 @@
+package peripage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ErrShortFrame is returned when data ends in the middle of a frame.
+// Stream readers should wait for more bytes and try again.
+var ErrShortFrame = errors.New("incomplete frame")
+
+// Decode parses a byte stream produced by Encode back into commands.
+// It is the inverse of Encode and is used to verify what a transport
+// actually delivered, for example by a simulated printer.
+func Decode(data []byte) ([]Command, error) {
+	var cmds []Command
+	for offset := 0; offset < len(data); {
+		cmd, n, err := DecodeFrame(data[offset:])
+		if err != nil {
+			return cmds, fmt.Errorf("offset %d: %w", offset, err)
+		}
+		cmds = append(cmds, cmd)
+		offset += n
+	}
+	return cmds, nil
+}
+
+// DecodeFrame parses the frame at the start of data and returns it together
+// with the number of bytes it occupies.
+func DecodeFrame(data []byte) (Command, int, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0x10, 0xff, 0xfe, 0x01}):
+		if len(data) < 16 {
+			return nil, 0, fmt.Errorf("truncated reset frame: %w", ErrShortFrame)
+		}
+		return Reset{}, 16, nil
+
+	case bytes.HasPrefix(data, []byte{0x10, 0xff, 0xfe, 0x45}):
+		return EndJob{}, 4, nil
+
+	case bytes.HasPrefix(data, []byte{0x10, 0xff, 0x10, 0x00}):
+		if len(data) < 5 {
+			return nil, 0, fmt.Errorf("truncated concentration frame: %w", ErrShortFrame)
+		}
+		cmd, err := NewSetConcentration(Concentration(data[4]))
+		return cmd, 5, err
+
+	case bytes.HasPrefix(data, []byte{0x10, 0xff, 0x10, 0x01}):
+		if len(data) < 5 {
+			return nil, 0, fmt.Errorf("truncated speed frame: %w", ErrShortFrame)
+		}
+		cmd, err := NewSetSpeed(Speed(data[4]))
+		return cmd, 5, err
+
+	case bytes.HasPrefix(data, []byte{0x10, 0xff, 0x10, 0x02}):
+		if len(data) < 5 {
+			return nil, 0, fmt.Errorf("truncated paper type frame: %w", ErrShortFrame)
+		}
+		cmd, err := NewSetPaperType(PaperType(data[4]))
+		return cmd, 5, err
+
+	case bytes.HasPrefix(data, []byte{0x1b, 0x4a}):
+		if len(data) < 3 {
+			return nil, 0, fmt.Errorf("truncated feed frame: %w", ErrShortFrame)
+		}
+		return Feed{Lines: int(data[2])}, 3, nil
+
+	case bytes.HasPrefix(data, []byte{0x1d, 0x76, 0x30, 0x00}):
+		if len(data) < 8 {
+			return nil, 0, fmt.Errorf("truncated raster header: %w", ErrShortFrame)
+		}
+		width := int(binary.LittleEndian.Uint16(data[4:6]))
+		lines := int(binary.LittleEndian.Uint16(data[6:8]))
+		end := 8 + width*lines
+		if len(data) < end {
+			return nil, 0, fmt.Errorf("truncated raster block: want %d bytes, have %d: %w", width*lines, len(data)-8, ErrShortFrame)
+		}
+		block, err := NewRasterBlock(width, data[8:end])
+		return block, end, err
+	}
+
+	if q, ok := decodeQuery(data); ok {
+		return q, 4, nil
+	}
+
+	if len(data) < 4 && isPrefixOfFrame(data) {
+		return nil, 0, fmt.Errorf("truncated frame: %w", ErrShortFrame)
+	}
+
+	n := len(data)
+	if n > 4 {
+		n = 4
+	}
+	return nil, 0, fmt.Errorf("unknown frame % x", data[:n])
+}
+
+// frameStarts lists the leading bytes of every known frame.
+var frameStarts = [][]byte{
+	{0x10, 0xff, 0xfe, 0x01},
+	{0x10, 0xff, 0xfe, 0x45},
+	{0x10, 0xff, 0x10, 0x00},
+	{0x10, 0xff, 0x10, 0x01},
+	{0x10, 0xff, 0x10, 0x02},
+	{0x1b, 0x4a},
+	{0x1d, 0x76, 0x30, 0x00},
+	{0x10, 0xff, 0x40, 0x00},
+	{0x10, 0xff, 0x50, 0xf1},
+	{0x10, 0xff, 0x20, 0xf1},
+	{0x10, 0xff, 0x20, 0xf2},
+}
+
+// isPrefixOfFrame reports whether data could be the start of a known frame.
+func isPrefixOfFrame(data []byte) bool {
+	for _, start := range frameStarts {
+		if len(data) <= len(start) && bytes.HasPrefix(start, data) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package peripage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode_RoundTrip(t *testing.T) {
+	// Arrange
+	cmds, err := Job(2, []byte{0x80, 0x01, 0x01, 0x80}, JobOptions{
+		Concentration: ConcentrationLight,
+		FeedAfter:     8,
+	})
+	require.NoError(t, err)
+
+	// Act
+	decoded, err := Decode(Encode(cmds...))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, cmds, decoded)
+}
+
+func TestDecode_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "unknown frame", data: []byte{0x42, 0x42}},
+		{name: "truncated reset", data: []byte{0x10, 0xff, 0xfe, 0x01, 0x00}},
+		{name: "truncated raster header", data: []byte{0x1d, 0x76, 0x30, 0x00, 0x30}},
+		{name: "truncated raster data", data: []byte{0x1d, 0x76, 0x30, 0x00, 0x01, 0x00, 0x02, 0x00, 0xff}},
+		{name: "invalid concentration", data: []byte{0x10, 0xff, 0x10, 0x00, 0x07}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(tt.data)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDecodeFrame_ShortFrames(t *testing.T) {
+	full := RasterBlock{WidthBytes: 2, Data: []byte{1, 2, 3, 4}}.Bytes()
+
+	for n := 1; n < len(full); n++ {
+		_, _, err := DecodeFrame(full[:n])
+		assert.ErrorIs(t, err, ErrShortFrame, "prefix of %d bytes", n)
+	}
+
+	cmd, n, err := DecodeFrame(append(full, EndJob{}.Bytes()...))
+	require.NoError(t, err)
+	assert.Equal(t, len(full), n)
+	assert.Equal(t, 2, cmd.(RasterBlock).Lines())
+
+	_, _, err = DecodeFrame([]byte{0x42})
+	assert.NotErrorIs(t, err, ErrShortFrame)
+}
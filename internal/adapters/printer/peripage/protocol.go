@@ -0,0 +1,230 @@
+// Package peripage encodes the Peripage A6 command set into the byte frames
+// written to the printer. It performs no I/O, so every command can be checked
+// byte for byte on a machine without a printer; transports only move the
+// resulting bytes.
+package peripage
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// GATT identifiers used by the Peripage A6.
+const (
+	// ServiceUUID is the primary service that carries print data.
+	ServiceUUID = "0000ff00-0000-1000-8000-00805f9b34fb"
+	// NotifyCharacteristicUUID delivers status notifications from the printer.
+	NotifyCharacteristicUUID = "0000ff01-0000-1000-8000-00805f9b34fb"
+	// WriteCharacteristicUUID accepts command frames.
+	WriteCharacteristicUUID = "0000ff02-0000-1000-8000-00805f9b34fb"
+)
+
+// Protocol limits.
+const (
+	// MaxBlockLines is the largest number of raster lines in one block.
+	MaxBlockLines = 0xff
+	// MaxFeedLines is the largest number of dot lines in one feed frame.
+	MaxFeedLines = 0xff
+)
+
+// Command is a single printer instruction.
+type Command interface {
+	// Bytes returns the encoded frame.
+	Bytes() []byte
+}
+
+// Encode concatenates the frames of the given commands in order.
+func Encode(cmds ...Command) []byte {
+	var out []byte
+	for _, cmd := range cmds {
+		out = append(out, cmd.Bytes()...)
+	}
+	return out
+}
+
+// Reset wakes the printer and clears any partially received job.
+type Reset struct{}
+
+// Bytes implements Command.
+func (Reset) Bytes() []byte {
+	return append([]byte{0x10, 0xff, 0xfe, 0x01}, make([]byte, 12)...)
+}
+
+// Concentration is the print darkness level.
+type Concentration byte
+
+const (
+	// ConcentrationLight burns the lightest dots and saves battery.
+	ConcentrationLight Concentration = 0
+	// ConcentrationNormal is the printer's power-on default.
+	ConcentrationNormal Concentration = 1
+	// ConcentrationDark burns the darkest dots.
+	ConcentrationDark Concentration = 2
+)
+
+// SetConcentration changes the print darkness for subsequent raster data.
+type SetConcentration struct {
+	Level Concentration
+}
+
+// NewSetConcentration validates level and builds the command.
+func NewSetConcentration(level Concentration) (SetConcentration, error) {
+	if level > ConcentrationDark {
+		return SetConcentration{}, fmt.Errorf("invalid concentration level: %d", level)
+	}
+	return SetConcentration{Level: level}, nil
+}
+
+// Bytes implements Command.
+func (c SetConcentration) Bytes() []byte {
+	return []byte{0x10, 0xff, 0x10, 0x00, byte(c.Level)}
+}
+
+// RasterBlock carries packed 1-bit raster lines (GS v 0).
+// Each line is WidthBytes long, MSB-first, with a set bit meaning a black dot.
+type RasterBlock struct {
+	WidthBytes int
+	Data       []byte
+}
+
+// NewRasterBlock validates the geometry of data and builds the command.
+func NewRasterBlock(widthBytes int, data []byte) (RasterBlock, error) {
+	if widthBytes <= 0 || widthBytes > 0xffff {
+		return RasterBlock{}, fmt.Errorf("invalid raster width: %d bytes", widthBytes)
+	}
+	if len(data)%widthBytes != 0 {
+		return RasterBlock{}, fmt.Errorf("raster data length %d is not a multiple of width %d", len(data), widthBytes)
+	}
+	if lines := len(data) / widthBytes; lines > MaxBlockLines {
+		return RasterBlock{}, fmt.Errorf("raster block has %d lines, maximum is %d", lines, MaxBlockLines)
+	}
+	return RasterBlock{WidthBytes: widthBytes, Data: data}, nil
+}
+
+// Lines returns the number of raster lines in the block.
+func (r RasterBlock) Lines() int {
+	if r.WidthBytes == 0 {
+		return 0
+	}
+	return len(r.Data) / r.WidthBytes
+}
+
+// Bytes implements Command.
+func (r RasterBlock) Bytes() []byte {
+	out := make([]byte, 8, 8+len(r.Data))
+	copy(out, []byte{0x1d, 0x76, 0x30, 0x00})
+	binary.LittleEndian.PutUint16(out[4:6], uint16(r.WidthBytes))
+	binary.LittleEndian.PutUint16(out[6:8], uint16(r.Lines()))
+	return append(out, r.Data...)
+}
+
+// RasterBlocks splits a packed raster into blocks of at most MaxBlockLines lines.
+func RasterBlocks(widthBytes int, data []byte) ([]RasterBlock, error) {
+	if widthBytes <= 0 {
+		return nil, fmt.Errorf("invalid raster width: %d bytes", widthBytes)
+	}
+
+	var blocks []RasterBlock
+	chunk := widthBytes * MaxBlockLines
+	for start := 0; start < len(data); start += chunk {
+		end := start + chunk
+		if end > len(data) {
+			end = len(data)
+		}
+		block, err := NewRasterBlock(widthBytes, data[start:end])
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+// Feed advances the paper by a number of blank dot lines (ESC J).
+// Feeds longer than MaxFeedLines are encoded as several frames.
+type Feed struct {
+	Lines int
+}
+
+// Bytes implements Command.
+func (f Feed) Bytes() []byte {
+	var out []byte
+	for left := f.Lines; left > 0; left -= MaxFeedLines {
+		n := left
+		if n > MaxFeedLines {
+			n = MaxFeedLines
+		}
+		out = append(out, 0x1b, 0x4a, byte(n))
+	}
+	return out
+}
+
+// EndJob tells the printer that the current job is complete.
+type EndJob struct{}
+
+// Bytes implements Command.
+func (EndJob) Bytes() []byte {
+	return []byte{0x10, 0xff, 0xfe, 0x45}
+}
+
+// JobOptions controls the commands wrapped around a raster job.
+type JobOptions struct {
+	// Concentration is sent before the raster data.
+	Concentration Concentration
+	// Speed and Paper are sent after the concentration when set. Left nil,
+	// the printer keeps its current setting.
+	Speed *Speed
+	Paper *PaperType
+	// FeedAfter is the number of blank dot lines fed after the raster.
+	FeedAfter int
+}
+
+// Job builds the full command sequence for printing one raster:
+// reset, concentration, speed, paper type, raster blocks, feed and
+// end-of-job.
+func Job(widthBytes int, data []byte, opts JobOptions) ([]Command, error) {
+	concentration, err := NewSetConcentration(opts.Concentration)
+	if err != nil {
+		return nil, err
+	}
+	cmds := []Command{Reset{}, concentration}
+	if opts.Speed != nil {
+		speed, err := NewSetSpeed(*opts.Speed)
+		if err != nil {
+			return nil, err
+		}
+		cmds = append(cmds, speed)
+	}
+	if opts.Paper != nil {
+		paper, err := NewSetPaperType(*opts.Paper)
+		if err != nil {
+			return nil, err
+		}
+		cmds = append(cmds, paper)
+	}
+	if opts.FeedAfter < 0 {
+		return nil, fmt.Errorf("invalid feed: %d lines", opts.FeedAfter)
+	}
+
+	blocks, err := RasterBlocks(widthBytes, data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, block := range blocks {
+		cmds = append(cmds, block)
+	}
+	if opts.FeedAfter > 0 {
+		cmds = append(cmds, Feed{Lines: opts.FeedAfter})
+	}
+	return append(cmds, EndJob{}), nil
+}
+
+// FeedJob builds the command sequence for advancing the paper by lines blank
+// dot lines without printing: reset, feed and end-of-job.
+func FeedJob(lines int) ([]Command, error) {
+	if lines <= 0 {
+		return nil, fmt.Errorf("invalid feed: %d lines", lines)
+	}
+	return []Command{Reset{}, Feed{Lines: lines}, EndJob{}}, nil
+}
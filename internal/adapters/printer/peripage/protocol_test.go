@@ -0,0 +1,163 @@
+package peripage
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommands_GoldenBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      Command
+		expected []byte
+	}{
+		{
+			name: "reset",
+			cmd:  Reset{},
+			expected: []byte{
+				0x10, 0xff, 0xfe, 0x01,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			},
+		},
+		{
+			name:     "light concentration",
+			cmd:      SetConcentration{Level: ConcentrationLight},
+			expected: []byte{0x10, 0xff, 0x10, 0x00, 0x00},
+		},
+		{
+			name:     "dark concentration",
+			cmd:      SetConcentration{Level: ConcentrationDark},
+			expected: []byte{0x10, 0xff, 0x10, 0x00, 0x02},
+		},
+		{
+			name:     "raster block of two 2-byte lines",
+			cmd:      RasterBlock{WidthBytes: 2, Data: []byte{0x80, 0x01, 0xff, 0x00}},
+			expected: []byte{0x1d, 0x76, 0x30, 0x00, 0x02, 0x00, 0x02, 0x00, 0x80, 0x01, 0xff, 0x00},
+		},
+		{
+			name:     "raster block at head width",
+			cmd:      RasterBlock{WidthBytes: 48, Data: make([]byte, 48)},
+			expected: append([]byte{0x1d, 0x76, 0x30, 0x00, 0x30, 0x00, 0x01, 0x00}, make([]byte, 48)...),
+		},
+		{
+			name:     "short feed",
+			cmd:      Feed{Lines: 40},
+			expected: []byte{0x1b, 0x4a, 0x28},
+		},
+		{
+			name:     "long feed is split",
+			cmd:      Feed{Lines: 300},
+			expected: []byte{0x1b, 0x4a, 0xff, 0x1b, 0x4a, 0x2d},
+		},
+		{
+			name:     "zero feed is empty",
+			cmd:      Feed{Lines: 0},
+			expected: nil,
+		},
+		{
+			name:     "end of job",
+			cmd:      EndJob{},
+			expected: []byte{0x10, 0xff, 0xfe, 0x45},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.cmd.Bytes())
+		})
+	}
+}
+
+func TestNewSetConcentration(t *testing.T) {
+	cmd, err := NewSetConcentration(ConcentrationNormal)
+	require.NoError(t, err)
+	assert.Equal(t, ConcentrationNormal, cmd.Level)
+
+	_, err = NewSetConcentration(Concentration(3))
+	assert.Error(t, err)
+}
+
+func TestNewRasterBlock_Validation(t *testing.T) {
+	tests := []struct {
+		name       string
+		widthBytes int
+		data       []byte
+		shouldErr  bool
+	}{
+		{name: "valid block", widthBytes: 2, data: make([]byte, 4), shouldErr: false},
+		{name: "zero width", widthBytes: 0, data: nil, shouldErr: true},
+		{name: "ragged data", widthBytes: 3, data: make([]byte, 4), shouldErr: true},
+		{name: "too many lines", widthBytes: 1, data: make([]byte, MaxBlockLines+1), shouldErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRasterBlock(tt.widthBytes, tt.data)
+			if tt.shouldErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRasterBlocks_SplitsTallRasters(t *testing.T) {
+	// Arrange: 600 lines of 48 bytes
+	data := bytes.Repeat([]byte{0xaa}, 48*600)
+
+	// Act
+	blocks, err := RasterBlocks(48, data)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, blocks, 3)
+	assert.Equal(t, 255, blocks[0].Lines())
+	assert.Equal(t, 255, blocks[1].Lines())
+	assert.Equal(t, 90, blocks[2].Lines())
+}
+
+func TestJob_GoldenBytes(t *testing.T) {
+	// Arrange
+	data := []byte{0xf0, 0x0f}
+
+	// Act
+	cmds, err := Job(1, data, JobOptions{Concentration: ConcentrationDark, FeedAfter: 16})
+
+	// Assert
+	require.NoError(t, err)
+	expected := []byte{
+		0x10, 0xff, 0xfe, 0x01, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		0x10, 0xff, 0x10, 0x00, 0x02,
+		0x1d, 0x76, 0x30, 0x00, 0x01, 0x00, 0x02, 0x00, 0xf0, 0x0f,
+		0x1b, 0x4a, 0x10,
+		0x10, 0xff, 0xfe, 0x45,
+	}
+	assert.Equal(t, expected, Encode(cmds...))
+}
+
+func TestJob_Validation(t *testing.T) {
+	_, err := Job(48, make([]byte, 48), JobOptions{Concentration: Concentration(9)})
+	assert.Error(t, err)
+
+	_, err = Job(48, make([]byte, 48), JobOptions{FeedAfter: -1})
+	assert.Error(t, err)
+
+	_, err = Job(48, make([]byte, 47), JobOptions{})
+	assert.Error(t, err)
+}
+
+func TestFeedJob(t *testing.T) {
+	// Act
+	cmds, err := FeedJob(300)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Command{Reset{}, Feed{Lines: 300}, EndJob{}}, cmds)
+
+	_, err = FeedJob(0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,65 @@
+package peripage
+
+import "fmt"
+
+// Speed is the paper feed speed while printing. Slower printing gives the
+// head more time per line and darker, more even output.
+type Speed byte
+
+const (
+	// SpeedSlow prints at the lowest speed.
+	SpeedSlow Speed = 0
+	// SpeedNormal is the printer's power-on default.
+	SpeedNormal Speed = 1
+	// SpeedFast prints at the highest speed.
+	SpeedFast Speed = 2
+)
+
+// SetSpeed changes the print speed for subsequent raster data.
+type SetSpeed struct {
+	Level Speed
+}
+
+// NewSetSpeed validates level and builds the command.
+func NewSetSpeed(level Speed) (SetSpeed, error) {
+	if level > SpeedFast {
+		return SetSpeed{}, fmt.Errorf("invalid speed level: %d", level)
+	}
+	return SetSpeed{Level: level}, nil
+}
+
+// Bytes implements Command.
+func (c SetSpeed) Bytes() []byte {
+	return []byte{0x10, 0xff, 0x10, 0x01, byte(c.Level)}
+}
+
+// PaperType tells the printer what is loaded, so it can find the start of
+// the next label.
+type PaperType byte
+
+const (
+	// PaperContinuous is plain receipt paper, the power-on default.
+	PaperContinuous PaperType = 0
+	// PaperLabel is a label roll with gaps between the labels.
+	PaperLabel PaperType = 1
+	// PaperSticker is sticker paper with a black mark between the stickers.
+	PaperSticker PaperType = 2
+)
+
+// SetPaperType changes the paper type for subsequent jobs.
+type SetPaperType struct {
+	Paper PaperType
+}
+
+// NewSetPaperType validates paper and builds the command.
+func NewSetPaperType(paper PaperType) (SetPaperType, error) {
+	if paper > PaperSticker {
+		return SetPaperType{}, fmt.Errorf("invalid paper type: %d", paper)
+	}
+	return SetPaperType{Paper: paper}, nil
+}
+
+// Bytes implements Command.
+func (c SetPaperType) Bytes() []byte {
+	return []byte{0x10, 0xff, 0x10, 0x02, byte(c.Paper)}
+}
@@ -0,0 +1,72 @@
+package peripage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettings_GoldenBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      Command
+		expected []byte
+	}{
+		{name: "slow speed", cmd: SetSpeed{Level: SpeedSlow}, expected: []byte{0x10, 0xff, 0x10, 0x01, 0x00}},
+		{name: "fast speed", cmd: SetSpeed{Level: SpeedFast}, expected: []byte{0x10, 0xff, 0x10, 0x01, 0x02}},
+		{name: "continuous paper", cmd: SetPaperType{Paper: PaperContinuous}, expected: []byte{0x10, 0xff, 0x10, 0x02, 0x00}},
+		{name: "label paper", cmd: SetPaperType{Paper: PaperLabel}, expected: []byte{0x10, 0xff, 0x10, 0x02, 0x01}},
+		{name: "sticker paper", cmd: SetPaperType{Paper: PaperSticker}, expected: []byte{0x10, 0xff, 0x10, 0x02, 0x02}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.cmd.Bytes())
+		})
+	}
+}
+
+func TestSettings_Validation(t *testing.T) {
+	_, err := NewSetSpeed(Speed(3))
+	assert.Error(t, err)
+	_, err = NewSetPaperType(PaperType(3))
+	assert.Error(t, err)
+
+	_, err = Decode([]byte{0x10, 0xff, 0x10, 0x01, 0x09})
+	assert.Error(t, err)
+	_, err = Decode([]byte{0x10, 0xff, 0x10, 0x02})
+	assert.ErrorIs(t, err, ErrShortFrame)
+}
+
+func TestJob_WithSpeedAndPaper(t *testing.T) {
+	// Arrange
+	speed, paper := SpeedSlow, PaperLabel
+
+	// Act
+	cmds, err := Job(1, []byte{0xff}, JobOptions{
+		Concentration: ConcentrationNormal,
+		Speed:         &speed,
+		Paper:         &paper,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	expected := []Command{
+		Reset{},
+		SetConcentration{Level: ConcentrationNormal},
+		SetSpeed{Level: SpeedSlow},
+		SetPaperType{Paper: PaperLabel},
+		RasterBlock{WidthBytes: 1, Data: []byte{0xff}},
+		EndJob{},
+	}
+	assert.Equal(t, expected, cmds)
+
+	decoded, err := Decode(Encode(cmds...))
+	require.NoError(t, err)
+	assert.Equal(t, expected, decoded)
+
+	invalid := Speed(7)
+	_, err = Job(1, []byte{0xff}, JobOptions{Speed: &invalid})
+	assert.Error(t, err)
+}
@@ -0,0 +1,102 @@
+package peripage
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Query asks the printer for one piece of device information. The printer
+// answers every query with a single notification on NotifyCharacteristicUUID,
+// so queries must be sent one at a time and matched to replies in order.
+type Query int
+
+const (
+	// QueryStatus returns one byte of StatusFlags.
+	QueryStatus Query = iota
+	// QueryBattery returns the battery charge as one byte in percent.
+	QueryBattery
+	// QueryFirmware returns the firmware version as ASCII text.
+	QueryFirmware
+	// QuerySerial returns the serial number as ASCII text.
+	QuerySerial
+)
+
+// queryFrames holds the frame of every query.
+var queryFrames = map[Query][]byte{
+	QueryStatus:   {0x10, 0xff, 0x40, 0x00},
+	QueryBattery:  {0x10, 0xff, 0x50, 0xf1},
+	QueryFirmware: {0x10, 0xff, 0x20, 0xf1},
+	QuerySerial:   {0x10, 0xff, 0x20, 0xf2},
+}
+
+// Bytes implements Command.
+func (q Query) Bytes() []byte {
+	return append([]byte(nil), queryFrames[q]...)
+}
+
+// String returns the name of the queried value.
+func (q Query) String() string {
+	switch q {
+	case QueryStatus:
+		return "status"
+	case QueryBattery:
+		return "battery"
+	case QueryFirmware:
+		return "firmware"
+	case QuerySerial:
+		return "serial"
+	}
+	return fmt.Sprintf("query(%d)", int(q))
+}
+
+// decodeQuery returns the query whose frame starts data, if any.
+func decodeQuery(data []byte) (Query, bool) {
+	for q, frame := range queryFrames {
+		if bytes.HasPrefix(data, frame) {
+			return q, true
+		}
+	}
+	return 0, false
+}
+
+// StatusFlags are the printer conditions reported in reply to QueryStatus.
+type StatusFlags byte
+
+const (
+	// StatusPaperOut is set when no paper is loaded.
+	StatusPaperOut StatusFlags = 1 << iota
+	// StatusLidOpen is set when the paper compartment is open.
+	StatusLidOpen
+	// StatusOverheated is set when the print head is too hot to print.
+	StatusOverheated
+)
+
+// Has reports whether all of the given flags are set.
+func (f StatusFlags) Has(flags StatusFlags) bool {
+	return f&flags == flags
+}
+
+// ParseStatus parses the reply to QueryStatus.
+func ParseStatus(reply []byte) (StatusFlags, error) {
+	if len(reply) != 1 {
+		return 0, fmt.Errorf("status reply must be 1 byte, got %d", len(reply))
+	}
+	return StatusFlags(reply[0]), nil
+}
+
+// ParseBattery parses the reply to QueryBattery into a charge in percent.
+func ParseBattery(reply []byte) (int, error) {
+	if len(reply) != 1 {
+		return 0, fmt.Errorf("battery reply must be 1 byte, got %d", len(reply))
+	}
+	if percent := int(reply[0]); percent <= 100 {
+		return percent, nil
+	}
+	return 0, fmt.Errorf("battery level out of range: %d%%", reply[0])
+}
+
+// ParseText parses the reply to QueryFirmware or QuerySerial. The printer
+// pads the text with NUL bytes.
+func ParseText(reply []byte) string {
+	return string(bytes.TrimSpace(bytes.TrimRight(reply, "\x00")))
+}
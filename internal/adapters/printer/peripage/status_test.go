@@ -0,0 +1,92 @@
+package peripage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuery_RoundTrip(t *testing.T) {
+	// Arrange
+	queries := []Command{QueryStatus, QueryBattery, QueryFirmware, QuerySerial, EndJob{}}
+
+	// Act
+	decoded, err := Decode(Encode(queries...))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, queries, decoded)
+}
+
+func TestQuery_ShortFrame(t *testing.T) {
+	_, _, err := DecodeFrame([]byte{0x10, 0xff, 0x50})
+	assert.ErrorIs(t, err, ErrShortFrame)
+}
+
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		reply    []byte
+		expected StatusFlags
+		wantErr  bool
+	}{
+		{name: "ready", reply: []byte{0x00}, expected: 0},
+		{name: "paper out", reply: []byte{0x01}, expected: StatusPaperOut},
+		{name: "lid open and hot", reply: []byte{0x06}, expected: StatusLidOpen | StatusOverheated},
+		{name: "empty reply", reply: nil, wantErr: true},
+		{name: "too long", reply: []byte{0x00, 0x00}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags, err := ParseStatus(tt.reply)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, flags)
+		})
+	}
+}
+
+func TestStatusFlags_Has(t *testing.T) {
+	flags := StatusPaperOut | StatusOverheated
+
+	assert.True(t, flags.Has(StatusPaperOut))
+	assert.True(t, flags.Has(StatusPaperOut|StatusOverheated))
+	assert.False(t, flags.Has(StatusLidOpen))
+}
+
+func TestParseBattery(t *testing.T) {
+	tests := []struct {
+		name     string
+		reply    []byte
+		expected int
+		wantErr  bool
+	}{
+		{name: "full", reply: []byte{100}, expected: 100},
+		{name: "low", reply: []byte{7}, expected: 7},
+		{name: "out of range", reply: []byte{180}, wantErr: true},
+		{name: "empty reply", reply: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			percent, err := ParseBattery(tt.reply)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, percent)
+		})
+	}
+}
+
+func TestParseText(t *testing.T) {
+	assert.Equal(t, "V2.11", ParseText([]byte("V2.11\x00\x00\x00")))
+	assert.Equal(t, "A6-123456", ParseText([]byte(" A6-123456 ")))
+	assert.Empty(t, ParseText(nil))
+}
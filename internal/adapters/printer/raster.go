@@ -0,0 +1,34 @@
+package printer
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/princem/peripage-printer/internal/render"
+)
+
+// textRaster lays out text with the shared renderer, so every adapter
+// produces the same raster for the same job.
+func textRaster(text string) (*render.Bitmap, error) {
+	return render.RenderText(text, render.DefaultTextOptions())
+}
+
+// headRaster returns img as a bitmap exactly one print head wide.
+// A *render.Bitmap is used as is, padded on the right if it is narrower than
+// the head; any other image is scaled to the head and thresholded.
+func headRaster(img image.Image) (*render.Bitmap, error) {
+	bitmap, ok := img.(*render.Bitmap)
+	if !ok {
+		return render.RenderImage(img, render.ImageOptions{Dither: render.DitherThreshold})
+	}
+
+	if bitmap.Width > render.HeadWidth {
+		return nil, fmt.Errorf("bitmap is %d dots wide, the print head is %d", bitmap.Width, render.HeadWidth)
+	}
+	if bitmap.Width < render.HeadWidth {
+		padded := render.NewBitmap(render.HeadWidth, bitmap.Height)
+		padded.Draw(bitmap, 0, 0)
+		bitmap = padded
+	}
+	return bitmap, nil
+}
@@ -0,0 +1,102 @@
+package printer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/princem/peripage-printer/internal/core"
+)
+
+// find scans for the device to connect to. Unless the criteria can only
+// match one device, it keeps scanning for the match window after the first
+// match and picks the strongest signal.
+func (b *BLEPrinter) find(ctx context.Context) (Advertisement, error) {
+	b.logger.Printf("Scanning for device: %s", b.match)
+
+	b.radio.Lock()
+	defer b.radio.Unlock()
+
+	scanCtx, cancel := context.WithTimeout(ctx, b.scanTimeout)
+	defer cancel()
+
+	var (
+		target Advertisement
+		found  bool
+		window *time.Timer
+	)
+	err := b.transport.Scan(scanCtx, func(adv Advertisement) bool {
+		if !b.match.matches(adv) {
+			return false
+		}
+		if found && adv.RSSI <= target.RSSI {
+			return false
+		}
+		b.logger.Printf("Found device: %s [%s] %d dBm", adv.Name, adv.Address, adv.RSSI)
+		target = adv
+		if found {
+			return false
+		}
+		found = true
+		if b.matchWindow <= 0 || b.match.unique() {
+			return true
+		}
+		window = time.AfterFunc(b.matchWindow, cancel)
+		return false
+	})
+	if window != nil {
+		window.Stop()
+	}
+
+	if !found {
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			return Advertisement{}, fmt.Errorf("failed to scan: %w", err)
+		}
+		return Advertisement{}, fmt.Errorf("device not found within timeout")
+	}
+	return target, nil
+}
+
+// Scan lists the devices advertising nearby within the given duration,
+// strongest signal first. Matches marks the devices the printer would
+// connect to.
+func (b *BLEPrinter) Scan(ctx context.Context, duration time.Duration) ([]core.DiscoveredDevice, error) {
+	b.radio.Lock()
+	defer b.radio.Unlock()
+
+	scanCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	seen := make(map[string]Advertisement)
+	err := b.transport.Scan(scanCtx, func(adv Advertisement) bool {
+		if previous, ok := seen[adv.Address]; ok && adv.Name == "" {
+			// Scan responses without a name must not hide the one seen before.
+			adv.Name = previous.Name
+		}
+		seen[adv.Address] = adv
+		return false
+	})
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("failed to scan: %w", err)
+	}
+
+	devices := make([]core.DiscoveredDevice, 0, len(seen))
+	for _, adv := range seen {
+		devices = append(devices, core.DiscoveredDevice{
+			Name:         adv.Name,
+			Address:      adv.Address,
+			RSSI:         adv.RSSI,
+			ServiceUUIDs: adv.ServiceUUIDs,
+			Matches:      b.match.matches(adv),
+		})
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		if devices[i].RSSI != devices[j].RSSI {
+			return devices[i].RSSI > devices[j].RSSI
+		}
+		return devices[i].Address < devices[j].Address
+	})
+	return devices, nil
+}
@@ -0,0 +1,151 @@
+//go:build !integration
+
+package printer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// simulatedRadio is a Transport with several simulated printers in range.
+type simulatedRadio []*SimulatedPeripage
+
+// Scan implements Transport.
+func (r simulatedRadio) Scan(ctx context.Context, found func(Advertisement) bool) error {
+	for _, sim := range r {
+		if adv, ok := sim.advertisement(); ok && found(adv) {
+			return nil
+		}
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// Connect implements Transport.
+func (r simulatedRadio) Connect(ctx context.Context, address string) (Link, error) {
+	for _, sim := range r {
+		if sim.address == address {
+			return sim.Connect(ctx, address)
+		}
+	}
+	return nil, fmt.Errorf("device %s not reachable", address)
+}
+
+// newOffice returns three printers with different names and signal strengths.
+func newOffice() simulatedRadio {
+	far := NewSimulatedPeripage("PeriPage_A6_far", "AA:00:00:00:00:01")
+	far.SetRSSI(-80)
+	near := NewSimulatedPeripage("PeriPage_A6_near", "AA:00:00:00:00:02")
+	near.SetRSSI(-40)
+	other := NewSimulatedPeripage("PeriPage+A4", "AA:00:00:00:00:03")
+	other.SetRSSI(-30)
+	return simulatedRadio{far, near, other}
+}
+
+func newOfficePrinter(t *testing.T, radio simulatedRadio, config BLEPrinterConfig) *BLEPrinter {
+	t.Helper()
+	config.ScanTimeout = 200 * time.Millisecond
+	config.Transport = radio
+	config.Logger = log.New(&bytes.Buffer{}, "", 0)
+	printer, err := NewBLEPrinter(config)
+	require.NoError(t, err)
+	return printer
+}
+
+func TestBLEPrinter_Connect_Matching(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   BLEPrinterConfig
+		expected int
+	}{
+		{
+			name:     "strongest matching signal wins",
+			config:   BLEPrinterConfig{NamePrefix: "PeriPage_A6", MatchWindow: 20 * time.Millisecond},
+			expected: 1,
+		},
+		{
+			name:     "first match without a window",
+			config:   BLEPrinterConfig{NamePrefix: "PeriPage_A6"},
+			expected: 0,
+		},
+		{
+			name:     "address picks one printer",
+			config:   BLEPrinterConfig{Address: "aa:00:00:00:00:01", MatchWindow: time.Minute},
+			expected: 0,
+		},
+		{
+			name:     "name pattern",
+			config:   BLEPrinterConfig{NamePattern: `A4$`, MatchWindow: 20 * time.Millisecond},
+			expected: 2,
+		},
+		{
+			name:     "any Peripage without criteria",
+			config:   BLEPrinterConfig{MatchWindow: 20 * time.Millisecond},
+			expected: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			office := newOffice()
+			printer := newOfficePrinter(t, office, tt.config)
+
+			// Act
+			err := printer.Connect(context.Background())
+
+			// Assert
+			require.NoError(t, err)
+			for i, sim := range office {
+				assert.Equal(t, i == tt.expected, sim.Connected(), "printer %s", sim.name)
+			}
+		})
+	}
+}
+
+func TestBLEPrinter_Connect_LogsOnlyMatchingDevices(t *testing.T) {
+	// Arrange
+	var logs bytes.Buffer
+	printer, err := NewBLEPrinter(BLEPrinterConfig{
+		NamePattern: `A4$`,
+		ScanTimeout: 200 * time.Millisecond,
+		Transport:   newOffice(),
+		Logger:      log.New(&logs, "", 0),
+	})
+	require.NoError(t, err)
+
+	// Act
+	require.NoError(t, printer.Connect(context.Background()))
+
+	// Assert
+	assert.Contains(t, logs.String(), "Found device: PeriPage+A4")
+	assert.NotContains(t, logs.String(), "PeriPage_A6")
+}
+
+func TestBLEPrinter_Scan(t *testing.T) {
+	// Arrange
+	office := newOffice()
+	office[0].SetPowered(false)
+	printer := newOfficePrinter(t, office, BLEPrinterConfig{NamePrefix: "PeriPage_"})
+
+	// Act
+	devices, err := printer.Scan(context.Background(), 20*time.Millisecond)
+
+	// Assert: strongest first, switched-off printers are missing
+	require.NoError(t, err)
+	require.Len(t, devices, 2)
+	assert.Equal(t, "PeriPage+A4", devices[0].Name)
+	assert.Equal(t, -30, devices[0].RSSI)
+	assert.False(t, devices[0].Matches)
+	assert.Equal(t, "PeriPage_A6_near", devices[1].Name)
+	assert.Equal(t, "AA:00:00:00:00:02", devices[1].Address)
+	assert.True(t, devices[1].Matches)
+	assert.NotEmpty(t, devices[1].ServiceUUIDs)
+}
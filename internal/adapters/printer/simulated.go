@@ -0,0 +1,359 @@
+package printer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/princem/peripage-printer/internal/adapters/printer/peripage"
+	"github.com/princem/peripage-printer/internal/render"
+)
+
+// SimulatedJob is a print job as received by a SimulatedPeripage.
+type SimulatedJob struct {
+	Concentration peripage.Concentration
+	Speed         peripage.Speed
+	Paper         peripage.PaperType
+	Raster        *render.Bitmap
+	FeedLines     int
+}
+
+// SimulatedStatus is the condition a SimulatedPeripage reports when queried.
+type SimulatedStatus struct {
+	Battery    int
+	PaperOut   bool
+	LidOpen    bool
+	Overheated bool
+	Firmware   string
+	Serial     string
+}
+
+// SimulatedPeripage is an in-memory Peripage A6 that implements Transport.
+// It decodes every byte written to it with the peripage package and keeps
+// the raster of each completed job, so tests can check exactly what a
+// BLEPrinter sent without any Bluetooth hardware. Status queries are
+// answered with notifications, and a printer that is out of paper, open
+// or overheated swallows jobs the way the real one does.
+type SimulatedPeripage struct {
+	name    string
+	address string
+	rssi    int
+
+	mu        sync.Mutex
+	poweredOn bool
+	connected bool
+	buffer    []byte
+	writes    []int
+	commands  []peripage.Command
+	current   *SimulatedJob
+	blocks    []peripage.RasterBlock
+	jobs      []SimulatedJob
+	notify    func([]byte)
+	outbox    [][]byte
+	status    SimulatedStatus
+	link      *simulatedLink
+}
+
+// NewSimulatedPeripage creates a powered-on simulated printer that
+// advertises under name at address.
+func NewSimulatedPeripage(name, address string) *SimulatedPeripage {
+	return &SimulatedPeripage{
+		name:      name,
+		address:   address,
+		rssi:      -50,
+		poweredOn: true,
+		status: SimulatedStatus{
+			Battery:  100,
+			Firmware: "V2.11_304dpi",
+			Serial:   "SIM-" + address,
+		},
+	}
+}
+
+// SetStatus changes the condition the printer reports.
+func (s *SimulatedPeripage) SetStatus(status SimulatedStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+// ready reports whether the printer can print in its current condition.
+// It must be called with s.mu held.
+func (s *SimulatedPeripage) ready() bool {
+	return !s.status.PaperOut && !s.status.LidOpen && !s.status.Overheated
+}
+
+// SetPowered switches the simulated printer on or off.
+// A powered-off printer neither advertises nor accepts connections, and
+// switching it off drops the open link.
+func (s *SimulatedPeripage) SetPowered(on bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.poweredOn = on
+	if !on {
+		s.drop()
+	}
+}
+
+// SetRSSI changes the signal strength the printer is seen with.
+func (s *SimulatedPeripage) SetRSSI(rssi int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rssi = rssi
+}
+
+// Scan implements Transport.
+func (s *SimulatedPeripage) Scan(ctx context.Context, found func(Advertisement) bool) error {
+	if adv, ok := s.advertisement(); ok && found(adv) {
+		return nil
+	}
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// advertisement returns what the printer advertises, and false while it is off.
+func (s *SimulatedPeripage) advertisement() (Advertisement, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return Advertisement{
+		Name:         s.name,
+		Address:      s.address,
+		RSSI:         s.rssi,
+		ServiceUUIDs: []string{peripage.ServiceUUID},
+	}, s.poweredOn
+}
+
+// Connect implements Transport.
+func (s *SimulatedPeripage) Connect(ctx context.Context, address string) (Link, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.poweredOn || address != s.address {
+		return nil, fmt.Errorf("device %s not reachable", address)
+	}
+	if s.connected {
+		return nil, fmt.Errorf("device %s already connected", address)
+	}
+
+	s.connected = true
+	s.link = &simulatedLink{sim: s, done: make(chan struct{})}
+	return s.link, nil
+}
+
+// Jobs returns the jobs completed so far, oldest first.
+func (s *SimulatedPeripage) Jobs() []SimulatedJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]SimulatedJob(nil), s.jobs...)
+}
+
+// Commands returns every command received so far, in order.
+func (s *SimulatedPeripage) Commands() []peripage.Command {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]peripage.Command(nil), s.commands...)
+}
+
+// Writes returns the size of every write received so far.
+func (s *SimulatedPeripage) Writes() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]int(nil), s.writes...)
+}
+
+// Connected reports whether a link is currently open.
+func (s *SimulatedPeripage) Connected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.connected
+}
+
+// drop closes the open link, if any.
+// It must be called with s.mu held.
+func (s *SimulatedPeripage) drop() {
+	s.connected = false
+	s.notify = nil
+	s.outbox = nil
+	if s.link != nil {
+		close(s.link.done)
+		s.link = nil
+	}
+}
+
+// receive buffers data and handles every complete frame in it.
+// It must be called with s.mu held.
+func (s *SimulatedPeripage) receive(data []byte) error {
+	s.writes = append(s.writes, len(data))
+	s.buffer = append(s.buffer, data...)
+
+	for len(s.buffer) > 0 {
+		cmd, n, err := peripage.DecodeFrame(s.buffer)
+		if errors.Is(err, peripage.ErrShortFrame) {
+			return nil
+		}
+		if err != nil {
+			s.buffer = nil
+			return fmt.Errorf("simulated printer rejected data: %w", err)
+		}
+		s.buffer = s.buffer[n:]
+		s.handle(cmd)
+	}
+	return nil
+}
+
+// handle applies a decoded command to the simulated printer state.
+func (s *SimulatedPeripage) handle(cmd peripage.Command) {
+	s.commands = append(s.commands, cmd)
+
+	switch c := cmd.(type) {
+	case peripage.Reset:
+		s.current = &SimulatedJob{
+			Concentration: peripage.ConcentrationNormal,
+			Speed:         peripage.SpeedNormal,
+			Paper:         peripage.PaperContinuous,
+		}
+		s.blocks = nil
+	case peripage.SetConcentration:
+		if s.current != nil {
+			s.current.Concentration = c.Level
+		}
+	case peripage.SetSpeed:
+		if s.current != nil {
+			s.current.Speed = c.Level
+		}
+	case peripage.SetPaperType:
+		if s.current != nil {
+			s.current.Paper = c.Paper
+		}
+	case peripage.RasterBlock:
+		s.blocks = append(s.blocks, c)
+	case peripage.Feed:
+		if s.current != nil {
+			s.current.FeedLines += c.Lines
+		}
+	case peripage.EndJob:
+		if s.current == nil {
+			return
+		}
+		s.current.Raster = joinBlocks(s.blocks)
+		if s.ready() {
+			s.jobs = append(s.jobs, *s.current)
+		}
+		s.current = nil
+		s.blocks = nil
+	case peripage.Query:
+		s.answer(c)
+	}
+}
+
+// answer queues the notification replying to q.
+// It must be called with s.mu held.
+func (s *SimulatedPeripage) answer(q peripage.Query) {
+	var reply []byte
+	switch q {
+	case peripage.QueryStatus:
+		var flags peripage.StatusFlags
+		if s.status.PaperOut {
+			flags |= peripage.StatusPaperOut
+		}
+		if s.status.LidOpen {
+			flags |= peripage.StatusLidOpen
+		}
+		if s.status.Overheated {
+			flags |= peripage.StatusOverheated
+		}
+		reply = []byte{byte(flags)}
+	case peripage.QueryBattery:
+		reply = []byte{byte(s.status.Battery)}
+	case peripage.QueryFirmware:
+		reply = append([]byte(s.status.Firmware), 0)
+	case peripage.QuerySerial:
+		reply = append([]byte(s.status.Serial), 0)
+	}
+	if s.notify != nil {
+		s.outbox = append(s.outbox, reply)
+	}
+}
+
+// joinBlocks reassembles raster blocks into one bitmap.
+func joinBlocks(blocks []peripage.RasterBlock) *render.Bitmap {
+	if len(blocks) == 0 {
+		return render.NewBitmap(0, 0)
+	}
+
+	widthBytes := blocks[0].WidthBytes
+	var pix []byte
+	lines := 0
+	for _, b := range blocks {
+		pix = append(pix, b.Data...)
+		lines += b.Lines()
+	}
+	return &render.Bitmap{
+		Width:  widthBytes * 8,
+		Height: lines,
+		Stride: widthBytes,
+		Pix:    pix,
+	}
+}
+
+// simulatedLink is the Link returned by SimulatedPeripage.Connect.
+type simulatedLink struct {
+	sim  *SimulatedPeripage
+	done chan struct{}
+}
+
+// Write implements Link. Notifications triggered by the data are delivered
+// after the write, outside the simulator's lock.
+func (l *simulatedLink) Write(data []byte) error {
+	l.sim.mu.Lock()
+	if l.sim.link != l {
+		l.sim.mu.Unlock()
+		return fmt.Errorf("link closed")
+	}
+	err := l.sim.receive(data)
+	outbox, notify := l.sim.outbox, l.sim.notify
+	l.sim.outbox = nil
+	l.sim.mu.Unlock()
+
+	for _, reply := range outbox {
+		notify(reply)
+	}
+	return err
+}
+
+// Subscribe implements Link.
+func (l *simulatedLink) Subscribe(fn func([]byte)) error {
+	l.sim.mu.Lock()
+	defer l.sim.mu.Unlock()
+
+	l.sim.notify = fn
+	return nil
+}
+
+// Disconnect implements Link.
+func (l *simulatedLink) Disconnect() error {
+	l.sim.mu.Lock()
+	defer l.sim.mu.Unlock()
+
+	if l.sim.link == l {
+		l.sim.drop()
+	}
+	return nil
+}
+
+// Done implements Link.
+func (l *simulatedLink) Done() <-chan struct{} {
+	return l.done
+}
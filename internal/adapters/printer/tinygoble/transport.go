@@ -0,0 +1,266 @@
+// Package tinygoble implements printer.Transport on top of the
+// tinygo.org/x/bluetooth stack. It is the only package that talks to the
+// host Bluetooth adapter, so everything else can be built and tested
+// without Bluetooth support.
+package tinygoble
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/princem/peripage-printer/internal/adapters/printer"
+	"github.com/princem/peripage-printer/internal/adapters/printer/peripage"
+	"tinygo.org/x/bluetooth"
+)
+
+// Transport drives the host's default Bluetooth adapter.
+type Transport struct {
+	adapter *bluetooth.Adapter
+
+	serviceUUID bluetooth.UUID
+	writeUUID   bluetooth.UUID
+	notifyUUID  bluetooth.UUID
+	// watched are the service UUIDs reported in advertisements. The tinygo
+	// stack can only test for a given UUID, not list every advertised one.
+	watched map[string]bluetooth.UUID
+
+	// scanning serializes scans: the adapter runs one at a time, and every
+	// printer sharing the transport scans on its own schedule.
+	scanning sync.Mutex
+
+	mu   sync.Mutex
+	seen map[string]bluetooth.Address
+	// links holds the open links by device address, so the adapter's
+	// connect handler can report disconnects to them.
+	links map[string]*link
+}
+
+// NewTransport enables the default Bluetooth adapter. Advertisements report
+// the Peripage service and any of the extra service UUIDs that are present.
+func NewTransport(serviceUUIDs ...string) (*Transport, error) {
+	serviceUUID, err := bluetooth.ParseUUID(peripage.ServiceUUID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service UUID: %w", err)
+	}
+	writeUUID, err := bluetooth.ParseUUID(peripage.WriteCharacteristicUUID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid write characteristic UUID: %w", err)
+	}
+	notifyUUID, err := bluetooth.ParseUUID(peripage.NotifyCharacteristicUUID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notify characteristic UUID: %w", err)
+	}
+
+	watched := map[string]bluetooth.UUID{peripage.ServiceUUID: serviceUUID}
+	for _, raw := range serviceUUIDs {
+		uuid, err := bluetooth.ParseUUID(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid service UUID %q: %w", raw, err)
+		}
+		watched[raw] = uuid
+	}
+
+	adapter := bluetooth.DefaultAdapter
+	if err := adapter.Enable(); err != nil {
+		return nil, fmt.Errorf("failed to enable BLE adapter: %w", err)
+	}
+
+	t := &Transport{
+		adapter:     adapter,
+		serviceUUID: serviceUUID,
+		writeUUID:   writeUUID,
+		notifyUUID:  notifyUUID,
+		watched:     watched,
+		seen:        make(map[string]bluetooth.Address),
+		links:       make(map[string]*link),
+	}
+	adapter.SetConnectHandler(t.connectionChanged)
+	return t, nil
+}
+
+// connectionChanged is called by the adapter when a device connects or
+// disconnects. A disconnect closes the device's link.
+func (t *Transport) connectionChanged(device bluetooth.Device, connected bool) {
+	if connected {
+		return
+	}
+
+	address := device.Address.String()
+	t.mu.Lock()
+	l := t.links[address]
+	delete(t.links, address)
+	t.mu.Unlock()
+
+	if l != nil {
+		l.close()
+	}
+}
+
+// Scan implements printer.Transport.
+// The tinygo scan call blocks until StopScan, so it runs in its own goroutine
+// and is stopped when found accepts a device or ctx is done. Concurrent
+// scans wait for each other.
+func (t *Transport) Scan(ctx context.Context, found func(printer.Advertisement) bool) error {
+	t.scanning.Lock()
+	defer t.scanning.Unlock()
+
+	var (
+		once sync.Once
+		done = make(chan struct{})
+		stop = func() { once.Do(func() { close(done) }) }
+	)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- t.adapter.Scan(func(adapter *bluetooth.Adapter, result bluetooth.ScanResult) {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			adv := printer.Advertisement{
+				Name:    result.LocalName(),
+				Address: result.Address.String(),
+				RSSI:    int(result.RSSI),
+			}
+			for name, uuid := range t.watched {
+				if result.HasServiceUUID(uuid) {
+					adv.ServiceUUIDs = append(adv.ServiceUUIDs, name)
+				}
+			}
+
+			t.mu.Lock()
+			t.seen[adv.Address] = result.Address
+			t.mu.Unlock()
+
+			if found(adv) {
+				stop()
+				adapter.StopScan()
+			}
+		})
+	}()
+
+	select {
+	case <-done:
+		return <-errCh
+	case err := <-errCh:
+		stop()
+		return err
+	case <-ctx.Done():
+		stop()
+		t.adapter.StopScan()
+		<-errCh
+		return ctx.Err()
+	}
+}
+
+// Connect implements printer.Transport. The address must have been reported
+// by a previous Scan, because platform address types cannot be built from
+// strings portably.
+func (t *Transport) Connect(ctx context.Context, address string) (printer.Link, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	addr, ok := t.seen[address]
+	t.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("device %s has not been seen in a scan", address)
+	}
+
+	device, err := t.adapter.Connect(addr, bluetooth.ConnectionParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := t.discover(device)
+	if err != nil {
+		if disconnectErr := device.Disconnect(); disconnectErr != nil {
+			return nil, fmt.Errorf("%w (disconnect also failed: %v)", err, disconnectErr)
+		}
+		return nil, err
+	}
+
+	t.mu.Lock()
+	t.links[address] = link
+	t.mu.Unlock()
+	return link, nil
+}
+
+// discover finds the Peripage write and notify characteristics on device.
+func (t *Transport) discover(device bluetooth.Device) (*link, error) {
+	services, err := device.DiscoverServices([]bluetooth.UUID{t.serviceUUID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover services: %w", err)
+	}
+	if len(services) == 0 {
+		return nil, fmt.Errorf("printer service %s not found", peripage.ServiceUUID)
+	}
+
+	chars, err := services[0].DiscoverCharacteristics([]bluetooth.UUID{t.writeUUID, t.notifyUUID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover characteristics: %w", err)
+	}
+
+	l := &link{device: device, done: make(chan struct{})}
+	for i := range chars {
+		switch chars[i].UUID() {
+		case t.writeUUID:
+			l.write = &chars[i]
+		case t.notifyUUID:
+			l.notify = &chars[i]
+		}
+	}
+	if l.write == nil {
+		return nil, fmt.Errorf("write characteristic %s not found", peripage.WriteCharacteristicUUID)
+	}
+	return l, nil
+}
+
+// link is the printer.Link for a connected tinygo device.
+type link struct {
+	device bluetooth.Device
+	write  *bluetooth.DeviceCharacteristic
+	notify *bluetooth.DeviceCharacteristic
+
+	once sync.Once
+	done chan struct{}
+}
+
+// Write implements printer.Link. A failed write closes the link: not every
+// platform reports disconnects through the connect handler (BlueZ does not),
+// and a write without response only fails once the connection is gone.
+func (l *link) Write(data []byte) error {
+	_, err := l.write.WriteWithoutResponse(data)
+	if err != nil {
+		l.close()
+	}
+	return err
+}
+
+// Subscribe implements printer.Link.
+func (l *link) Subscribe(fn func([]byte)) error {
+	if l.notify == nil {
+		return fmt.Errorf("notify characteristic %s not found", peripage.NotifyCharacteristicUUID)
+	}
+	return l.notify.EnableNotifications(fn)
+}
+
+// Disconnect implements printer.Link.
+func (l *link) Disconnect() error {
+	defer l.close()
+	return l.device.Disconnect()
+}
+
+// Done implements printer.Link.
+func (l *link) Done() <-chan struct{} {
+	return l.done
+}
+
+// close marks the link as gone.
+func (l *link) close() {
+	l.once.Do(func() { close(l.done) })
+}
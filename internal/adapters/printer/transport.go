@@ -0,0 +1,40 @@
+package printer
+
+import "context"
+
+// Advertisement describes a device seen during a BLE scan.
+type Advertisement struct {
+	Name         string
+	Address      string
+	RSSI         int
+	ServiceUUIDs []string
+}
+
+// Transport abstracts the Bluetooth LE stack beneath BLEPrinter.
+// The tinygo Bluetooth stack is one implementation and SimulatedPeripage
+// is another, which lets the printer logic run without radio hardware.
+type Transport interface {
+	// Scan reports every advertisement to found until found returns true or
+	// ctx is done. It returns ctx.Err() if the scan was cut short by ctx.
+	Scan(ctx context.Context, found func(Advertisement) bool) error
+
+	// Connect opens a link to the device with the given address.
+	Connect(ctx context.Context, address string) (Link, error)
+}
+
+// Link is an open connection to a printer's GATT characteristics.
+type Link interface {
+	// Write sends data to the printer's write characteristic.
+	// Callers are responsible for splitting data into packets.
+	Write(data []byte) error
+
+	// Subscribe delivers notifications from the printer's notify characteristic to fn.
+	Subscribe(fn func([]byte)) error
+
+	// Disconnect closes the link.
+	Disconnect() error
+
+	// Done returns a channel that is closed once the link is lost or closed,
+	// for example because the printer was switched off.
+	Done() <-chan struct{}
+}
@@ -0,0 +1,293 @@
+// Package store provides adapters that persist print jobs and templates.
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/princem/peripage-printer/internal/core"
+)
+
+// compactThreshold is the minimum number of log entries before the log is
+// rewritten. Below it, stale entries are cheaper to keep than to compact.
+const compactThreshold = 256
+
+// MaxStoredPayload is the largest request, in bytes of JSON, kept for a
+// finished job. Larger finished jobs are stored without their payload;
+// unfinished jobs always keep it so they can be printed after a restart.
+const MaxStoredPayload = 256 << 10
+
+// entry is one line of the job log.
+type entry struct {
+	Op  string    `json:"op"`
+	Job *core.Job `json:"job,omitempty"`
+	ID  string    `json:"id,omitempty"`
+}
+
+const (
+	opSave   = "save"
+	opDelete = "delete"
+)
+
+// FileJobStore persists jobs in an append-only JSON-lines log.
+// Every Save or Delete appends one line, so a crash can lose at most the
+// line being written. The log is replayed when the store is opened and
+// compacted once it holds many more entries than live jobs.
+type FileJobStore struct {
+	path string
+
+	mu      sync.Mutex
+	file    *os.File
+	jobs    map[string]core.Job
+	order   []string
+	entries int
+}
+
+// NewFileJobStore opens the log at path, creating it and its directory if needed.
+func NewFileJobStore(path string) (*FileJobStore, error) {
+	if path == "" {
+		return nil, errors.New("job store path is required")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create job store directory: %w", err)
+	}
+
+	s := &FileJobStore{
+		path: path,
+		jobs: make(map[string]core.Job),
+	}
+
+	if err := s.replay(); err != nil {
+		return nil, err
+	}
+
+	// Start from a compact log so a long history is not replayed twice.
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Save inserts or replaces a job.
+func (s *FileJobStore) Save(job core.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job.State.Finished() && !job.PayloadDropped {
+		raw, err := json.Marshal(job.Request)
+		if err != nil {
+			return fmt.Errorf("failed to encode job %s: %w", job.ID, err)
+		}
+		if len(raw) > MaxStoredPayload {
+			job = job.WithoutPayload()
+			job.PayloadDropped = true
+		}
+	}
+
+	if err := s.append(entry{Op: opSave, Job: &job}); err != nil {
+		return err
+	}
+
+	if _, ok := s.jobs[job.ID]; !ok {
+		s.order = append(s.order, job.ID)
+	}
+	s.jobs[job.ID] = job
+
+	return s.maybeCompact()
+}
+
+// Delete removes a job.
+func (s *FileJobStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[id]; !ok {
+		return nil
+	}
+
+	if err := s.append(entry{Op: opDelete, ID: id}); err != nil {
+		return err
+	}
+	s.forget(id)
+
+	return s.maybeCompact()
+}
+
+// Load returns all stored jobs in the order they were first saved.
+func (s *FileJobStore) Load() ([]core.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]core.Job, 0, len(s.order))
+	for _, id := range s.order {
+		jobs = append(jobs, s.jobs[id])
+	}
+	return jobs, nil
+}
+
+// Close closes the underlying file.
+func (s *FileJobStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// replay reads the log into memory. A truncated last line, left behind by a
+// crash mid-write, is ignored; corruption anywhere else is an error.
+func (s *FileJobStore) replay() error {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read job store: %w", err)
+	}
+
+	reader := bufio.NewReader(bytes.NewReader(data))
+	for lineNo := 1; ; lineNo++ {
+		line, readErr := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var e entry
+			if err := json.Unmarshal(line, &e); err != nil {
+				if readErr == io.EOF {
+					break
+				}
+				return fmt.Errorf("job store %s line %d: %w", s.path, lineNo, err)
+			}
+			s.apply(e)
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return fmt.Errorf("failed to read job store: %w", readErr)
+		}
+	}
+
+	return nil
+}
+
+// apply replays a single log entry.
+func (s *FileJobStore) apply(e entry) {
+	switch e.Op {
+	case opSave:
+		if e.Job == nil {
+			return
+		}
+		if _, ok := s.jobs[e.Job.ID]; !ok {
+			s.order = append(s.order, e.Job.ID)
+		}
+		s.jobs[e.Job.ID] = *e.Job
+	case opDelete:
+		s.forget(e.ID)
+	}
+}
+
+// forget removes a job from memory.
+func (s *FileJobStore) forget(id string) {
+	if _, ok := s.jobs[id]; !ok {
+		return
+	}
+	delete(s.jobs, id)
+	for i, existing := range s.order {
+		if existing == id {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// append writes one entry to the end of the log and syncs it to disk.
+func (s *FileJobStore) append(e entry) error {
+	if s.file == nil {
+		return errors.New("job store is closed")
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+	line = append(line, '\n')
+
+	if _, err := s.file.Write(line); err != nil {
+		return fmt.Errorf("failed to write job store: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync job store: %w", err)
+	}
+
+	s.entries++
+	return nil
+}
+
+// maybeCompact rewrites the log once stale entries dominate it.
+func (s *FileJobStore) maybeCompact() error {
+	if s.entries < compactThreshold || s.entries < 2*len(s.jobs) {
+		return nil
+	}
+	return s.compact()
+}
+
+// compact rewrites the log with one entry per live job. The new log is
+// written to a temporary file and renamed over the old one, so the log on
+// disk is always complete.
+func (s *FileJobStore) compact() error {
+	tmpPath := s.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to compact job store: %w", err)
+	}
+
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	for _, id := range s.order {
+		job := s.jobs[id]
+		if err := encoder.Encode(entry{Op: opSave, Job: &job}); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to compact job store: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to compact job store: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to compact job store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to compact job store: %w", err)
+	}
+
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to compact job store: %w", err)
+	}
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open job store: %w", err)
+	}
+	s.file = file
+	s.entries = len(s.order)
+
+	return nil
+}
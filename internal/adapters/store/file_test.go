@@ -0,0 +1,157 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/princem/peripage-printer/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testJob(id string, state core.JobState) core.Job {
+	return core.Job{
+		ID:        id,
+		Request:   core.JobRequest{Kind: core.JobText, Text: "job " + id},
+		State:     state,
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func openStore(t *testing.T, path string) *FileJobStore {
+	t.Helper()
+	s, err := NewFileJobStore(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestFileJobStore_SurvivesReopen(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "data", "jobs.log")
+	s := openStore(t, path)
+
+	require.NoError(t, s.Save(testJob("a", core.JobQueued)))
+	require.NoError(t, s.Save(testJob("b", core.JobQueued)))
+	require.NoError(t, s.Save(testJob("a", core.JobDone)))
+	require.NoError(t, s.Save(testJob("c", core.JobQueued)))
+	require.NoError(t, s.Delete("b"))
+	require.NoError(t, s.Close())
+
+	// Act
+	reopened := openStore(t, path)
+	jobs, err := reopened.Load()
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, "a", jobs[0].ID)
+	assert.Equal(t, core.JobDone, jobs[0].State)
+	assert.Equal(t, "job a", jobs[0].Request.Text)
+	assert.Equal(t, "c", jobs[1].ID)
+	assert.True(t, jobs[1].CreatedAt.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+}
+
+func TestFileJobStore_IgnoresTruncatedLastLine(t *testing.T) {
+	// Arrange: simulate a crash in the middle of the last write
+	path := filepath.Join(t.TempDir(), "jobs.log")
+	s := openStore(t, path)
+	require.NoError(t, s.Save(testJob("a", core.JobQueued)))
+	require.NoError(t, s.Close())
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"op":"save","job":{"id":"b","sta`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// Act
+	reopened := openStore(t, path)
+	jobs, err := reopened.Load()
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "a", jobs[0].ID)
+	require.NoError(t, reopened.Save(testJob("c", core.JobQueued)), "store must stay writable after recovery")
+}
+
+func TestFileJobStore_RejectsCorruptLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.log")
+	require.NoError(t, os.WriteFile(path, []byte("not json\n{\"op\":\"delete\",\"id\":\"x\"}\n"), 0o644))
+
+	_, err := NewFileJobStore(path)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 1")
+}
+
+func TestFileJobStore_Compacts(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "jobs.log")
+	s := openStore(t, path)
+
+	// Act: rewrite the same job far more often than the compaction threshold
+	for i := 0; i < compactThreshold*2; i++ {
+		require.NoError(t, s.Save(testJob("a", core.JobQueued)))
+	}
+
+	// Assert
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Less(t, countLines(data), compactThreshold)
+
+	jobs, err := s.Load()
+	require.NoError(t, err)
+	assert.Len(t, jobs, 1)
+}
+
+func TestFileJobStore_DropsLargeFinishedPayloads(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "jobs.log")
+	s := openStore(t, path)
+
+	large := testJob("large", core.JobQueued)
+	large.Request.Text = strings.Repeat("x", MaxStoredPayload)
+
+	// Act & Assert: an unfinished job keeps its payload so it can print after a restart
+	require.NoError(t, s.Save(large))
+	jobs, err := s.Load()
+	require.NoError(t, err)
+	assert.Equal(t, large.Request.Text, jobs[0].Request.Text)
+	assert.False(t, jobs[0].PayloadDropped)
+
+	// Act & Assert: once finished, only the small parts of the request are kept
+	large.State = core.JobDone
+	require.NoError(t, s.Save(large))
+	require.NoError(t, s.Save(testJob("small", core.JobDone)))
+	s.Close()
+
+	jobs, err = openStore(t, path).Load()
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Empty(t, jobs[0].Request.Text)
+	assert.Equal(t, core.JobText, jobs[0].Request.Kind)
+	assert.True(t, jobs[0].PayloadDropped)
+	assert.Equal(t, "job small", jobs[1].Request.Text)
+	assert.False(t, jobs[1].PayloadDropped)
+}
+
+func TestNewFileJobStore_RequiresPath(t *testing.T) {
+	_, err := NewFileJobStore("")
+
+	assert.Error(t, err)
+}
+
+func countLines(data []byte) int {
+	n := 0
+	for _, b := range data {
+		if b == '\n' {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,143 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/princem/peripage-printer/internal/core"
+)
+
+// FileTemplateStore persists print templates in a single JSON file.
+// Templates change rarely, so every Save or Delete rewrites the whole file:
+// it is written to a temporary file and renamed over the old one, so the
+// file on disk is always complete.
+type FileTemplateStore struct {
+	path string
+
+	mu        sync.Mutex
+	templates map[string]core.Template
+}
+
+// NewFileTemplateStore opens the template file at path, creating its
+// directory if needed. A missing file is an empty store.
+func NewFileTemplateStore(path string) (*FileTemplateStore, error) {
+	if path == "" {
+		return nil, errors.New("template store path is required")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create template store directory: %w", err)
+	}
+
+	s := &FileTemplateStore{
+		path:      path,
+		templates: make(map[string]core.Template),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template store: %w", err)
+	}
+
+	var templates []core.Template
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return nil, fmt.Errorf("template store %s: %w", path, err)
+	}
+	for _, t := range templates {
+		s.templates[t.Name] = t
+	}
+	return s, nil
+}
+
+// Save inserts or replaces a template.
+func (s *FileTemplateStore) Save(t core.Template) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.templates[t.Name]
+	s.templates[t.Name] = t
+	if err := s.write(); err != nil {
+		if existed {
+			s.templates[t.Name] = previous
+		} else {
+			delete(s.templates, t.Name)
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete removes a template.
+func (s *FileTemplateStore) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.templates[name]
+	if !ok {
+		return nil
+	}
+	delete(s.templates, name)
+	if err := s.write(); err != nil {
+		s.templates[name] = previous
+		return err
+	}
+	return nil
+}
+
+// Load returns all stored templates sorted by name.
+func (s *FileTemplateStore) Load() ([]core.Template, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sorted(), nil
+}
+
+// sorted returns the templates sorted by name, so the file is stable.
+func (s *FileTemplateStore) sorted() []core.Template {
+	templates := make([]core.Template, 0, len(s.templates))
+	for _, t := range s.templates {
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates
+}
+
+// write replaces the file with the current templates.
+func (s *FileTemplateStore) write() error {
+	data, err := json.MarshalIndent(s.sorted(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode templates: %w", err)
+	}
+	data = append(data, '\n')
+
+	tmpPath := s.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write template store: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write template store: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync template store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write template store: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to write template store: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/princem/peripage-printer/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTemplate(name, body string) core.Template {
+	return core.Template{
+		Name:      name,
+		Format:    core.JobText,
+		Body:      body,
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestFileTemplateStore_SurvivesReopen(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "data", "templates.json")
+	s, err := NewFileTemplateStore(path)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Save(testTemplate("slip", "Order {{.id}}")))
+	require.NoError(t, s.Save(testTemplate("badge", "{{.name}}")))
+	require.NoError(t, s.Save(testTemplate("slip", "Order #{{.id}}")))
+	require.NoError(t, s.Save(testTemplate("handover", "Shift {{.shift}}")))
+	require.NoError(t, s.Delete("handover"))
+	require.NoError(t, s.Delete("unknown"))
+
+	// Act
+	reopened, err := NewFileTemplateStore(path)
+	require.NoError(t, err)
+	templates, err := reopened.Load()
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, templates, 2)
+	assert.Equal(t, "badge", templates[0].Name)
+	assert.Equal(t, "slip", templates[1].Name)
+	assert.Equal(t, "Order #{{.id}}", templates[1].Body)
+	assert.True(t, templates[1].CreatedAt.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	_, err = os.Stat(path + ".tmp")
+	assert.True(t, os.IsNotExist(err), "no temporary file is left behind")
+}
+
+func TestFileTemplateStore_MissingFileIsEmpty(t *testing.T) {
+	s, err := NewFileTemplateStore(filepath.Join(t.TempDir(), "templates.json"))
+	require.NoError(t, err)
+
+	templates, err := s.Load()
+
+	require.NoError(t, err)
+	assert.Empty(t, templates)
+}
+
+func TestFileTemplateStore_RejectsCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "templates.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"name": "slip"`), 0o644))
+
+	_, err := NewFileTemplateStore(path)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), path)
+}
+
+func TestFileTemplateStore_KeepsStateWhenWriteFails(t *testing.T) {
+	// Arrange: a directory where the temporary file should go makes writes fail
+	path := filepath.Join(t.TempDir(), "templates.json")
+	s, err := NewFileTemplateStore(path)
+	require.NoError(t, err)
+	require.NoError(t, s.Save(testTemplate("slip", "v1")))
+	require.NoError(t, os.Mkdir(path+".tmp", 0o755))
+
+	// Act
+	saveErr := s.Save(testTemplate("slip", "v2"))
+	deleteErr := s.Delete("slip")
+
+	// Assert
+	assert.Error(t, saveErr)
+	assert.Error(t, deleteErr)
+	templates, err := s.Load()
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+	assert.Equal(t, "v1", templates[0].Body)
+}
+
+func TestNewFileTemplateStore_RequiresPath(t *testing.T) {
+	_, err := NewFileTemplateStore("")
+
+	assert.Error(t, err)
+}
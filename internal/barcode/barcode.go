@@ -0,0 +1,125 @@
+// Package barcode encodes data as linear (1D) barcodes: Code 128, EAN-13,
+// UPC-A and Code 39. It checks the data and its check digits and builds the
+// row of bars as modules; drawing it on the print raster is left to the
+// render package.
+package barcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Symbology is a barcode standard.
+type Symbology string
+
+const (
+	// Code128 encodes printable ASCII, packing runs of digits two to a
+	// symbol. It carries a mod 103 checksum that scanners verify.
+	Code128 Symbology = "code128"
+	// EAN13 encodes the 13 digits of a retail product number.
+	EAN13 Symbology = "ean13"
+	// UPCA encodes the 12 digits of a North American product number.
+	UPCA Symbology = "upca"
+	// Code39 encodes upper case letters, digits, space and "-.$/+%".
+	Code39 Symbology = "code39"
+)
+
+// Symbologies lists the supported symbologies.
+var Symbologies = []Symbology{Code128, EAN13, UPCA, Code39}
+
+// ParseSymbology parses a symbology name such as "code128", "EAN-13" or
+// "upc_a", ignoring case, dashes and underscores. An empty string selects
+// Code128.
+func ParseSymbology(s string) (Symbology, error) {
+	name := strings.NewReplacer("-", "", "_", "", " ", "").Replace(strings.ToLower(s))
+	if name == "" {
+		return Code128, nil
+	}
+	for _, sym := range Symbologies {
+		if name == string(sym) {
+			return sym, nil
+		}
+	}
+	return "", fmt.Errorf("unknown barcode type %q: use code128, ean13, upca or code39", s)
+}
+
+// ErrInvalidData is returned, wrapped with the reason, when data cannot be
+// encoded in a symbology.
+var ErrInvalidData = errors.New("invalid barcode data")
+
+// ErrCheckDigit is returned when the check digit of an EAN-13 or UPC-A
+// number does not match the other digits.
+var ErrCheckDigit = errors.New("check digit does not match")
+
+// Code is an encoded barcode.
+type Code struct {
+	Symbology Symbology
+	// Text is the human-readable text printed under the bars, including
+	// any check digit that was added.
+	Text string
+	// QuietZone is the number of light modules the symbology asks for on
+	// each side of the bars.
+	QuietZone int
+	bars      []bool
+}
+
+// Modules returns the width of the bars in modules, without the quiet zone.
+func (c *Code) Modules() int {
+	return len(c.bars)
+}
+
+// Black reports whether module x, counted from the first bar, is dark.
+// Modules outside the bars are light.
+func (c *Code) Black(x int) bool {
+	return x >= 0 && x < len(c.bars) && c.bars[x]
+}
+
+// Encode encodes data in the symbology. EAN-13 and UPC-A take their digits
+// with or without the final check digit: it is added when missing and
+// checked when present.
+func Encode(sym Symbology, data string) (*Code, error) {
+	if data == "" {
+		return nil, fmt.Errorf("%w: data cannot be empty", ErrInvalidData)
+	}
+
+	var code *Code
+	var err error
+	switch sym {
+	case Code128:
+		code, err = encodeCode128(data)
+	case EAN13:
+		code, err = encodeEAN13(data)
+	case UPCA:
+		code, err = encodeUPCA(data)
+	case Code39:
+		code, err = encodeCode39(data)
+	default:
+		return nil, fmt.Errorf("unknown barcode type %q", sym)
+	}
+	if err != nil {
+		return nil, err
+	}
+	code.Symbology = sym
+	return code, nil
+}
+
+// appendWidths appends alternating bars and spaces, starting with a bar,
+// of the given widths in modules.
+func appendWidths(bars []bool, widths ...int) []bool {
+	for i, w := range widths {
+		for ; w > 0; w-- {
+			bars = append(bars, i%2 == 0)
+		}
+	}
+	return bars
+}
+
+// appendPattern appends modules written as a string of '1' for a bar and
+// '0' for a space.
+func appendPattern(bars []bool, pattern string) []bool {
+	for _, c := range pattern {
+		bars = append(bars, c == '1')
+	}
+	return bars
+}
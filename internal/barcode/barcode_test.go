@@ -0,0 +1,122 @@
+package barcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSymbology(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Symbology
+		wantErr  bool
+	}{
+		{input: "", expected: Code128},
+		{input: "code128", expected: Code128},
+		{input: "Code-128", expected: Code128},
+		{input: "EAN-13", expected: EAN13},
+		{input: "ean13", expected: EAN13},
+		{input: "upc_a", expected: UPCA},
+		{input: "UPCA", expected: UPCA},
+		{input: "code 39", expected: Code39},
+		{input: "qr", wantErr: true},
+		{input: "ean8", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		sym, err := ParseSymbology(tt.input)
+		if tt.wantErr {
+			assert.Error(t, err, "input %q", tt.input)
+			continue
+		}
+		require.NoError(t, err, "input %q", tt.input)
+		assert.Equal(t, tt.expected, sym)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name              string
+		sym               Symbology
+		data              string
+		expectedText      string
+		expectedModules   int
+		expectedQuietZone int
+	}{
+		{name: "code128", sym: Code128, data: "BIN-A-17", expectedText: "BIN-A-17", expectedModules: 10*11 + 13, expectedQuietZone: 10},
+		{name: "ean13 adds the check digit", sym: EAN13, data: "400638133393", expectedText: "4006381333931", expectedModules: 95, expectedQuietZone: 11},
+		{name: "ean13 with its check digit", sym: EAN13, data: "5901234123457", expectedText: "5901234123457", expectedModules: 95, expectedQuietZone: 11},
+		{name: "upca adds the check digit", sym: UPCA, data: "03600029145", expectedText: "036000291452", expectedModules: 95, expectedQuietZone: 11},
+		{name: "code39", sym: Code39, data: "BIN 7", expectedText: "BIN 7", expectedModules: 7*15 + 6, expectedQuietZone: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			code, err := Encode(tt.sym, tt.data)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.sym, code.Symbology)
+			assert.Equal(t, tt.expectedText, code.Text)
+			assert.Equal(t, tt.expectedModules, code.Modules())
+			assert.Equal(t, tt.expectedQuietZone, code.QuietZone)
+			assert.True(t, code.Black(0), "codes start with a bar")
+			assert.True(t, code.Black(code.Modules()-1), "codes end with a bar")
+			assert.False(t, code.Black(-1))
+			assert.False(t, code.Black(code.Modules()))
+		})
+	}
+}
+
+func TestEncode_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		sym      Symbology
+		data     string
+		expected error
+	}{
+		{name: "empty", sym: Code128, data: "", expected: ErrInvalidData},
+		{name: "code128 control character", sym: Code128, data: "A\tB", expected: ErrInvalidData},
+		{name: "code128 non-ASCII", sym: Code128, data: "Köln", expected: ErrInvalidData},
+		{name: "ean13 letters", sym: EAN13, data: "40063813339X", expected: ErrInvalidData},
+		{name: "ean13 too short", sym: EAN13, data: "12345", expected: ErrInvalidData},
+		{name: "ean13 too long", sym: EAN13, data: "40063813339312", expected: ErrInvalidData},
+		{name: "ean13 wrong check digit", sym: EAN13, data: "4006381333932", expected: ErrCheckDigit},
+		{name: "upca wrong check digit", sym: UPCA, data: "036000291453", expected: ErrCheckDigit},
+		{name: "upca as ean13 length", sym: UPCA, data: "0036000291452", expected: ErrInvalidData},
+		{name: "code39 lower case", sym: Code39, data: "bin", expected: ErrInvalidData},
+		{name: "code39 asterisk", sym: Code39, data: "A*B", expected: ErrInvalidData},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			code, err := Encode(tt.sym, tt.data)
+
+			// Assert
+			assert.ErrorIs(t, err, tt.expected)
+			assert.Nil(t, code)
+		})
+	}
+}
+
+func TestEncode_UnknownSymbology(t *testing.T) {
+	_, err := Encode(Symbology("ean8"), "1234567")
+	assert.Error(t, err)
+}
+
+// runs returns the widths of the alternating bars and spaces of a code,
+// starting with a bar, the way a scanner measures them.
+func runs(code *Code) []int {
+	var widths []int
+	for x := 0; x < code.Modules(); x++ {
+		if x == 0 || code.Black(x) != code.Black(x-1) {
+			widths = append(widths, 0)
+		}
+		widths[len(widths)-1]++
+	}
+	return widths
+}
@@ -0,0 +1,108 @@
+package barcode
+
+import "fmt"
+
+// code128Patterns are the bar and space widths of the Code 128 symbols by
+// value, each 11 modules wide. The last is the 13-module stop pattern.
+var code128Patterns = [...]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+// Code 128 symbol values with a special meaning.
+const (
+	code128CodeC  = 99
+	code128CodeB  = 100
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+// code128QuietZone is the light space Code 128 asks for on each side.
+const code128QuietZone = 10
+
+// encodeCode128 encodes printable ASCII with code set B, switching to code
+// set C for runs of digits long enough that packing them two to a symbol
+// makes the code shorter.
+func encodeCode128(data string) (*Code, error) {
+	for i := 0; i < len(data); i++ {
+		if data[i] < ' ' || data[i] > '~' {
+			return nil, fmt.Errorf("%w: code128 can only encode printable ASCII, got %q at %d", ErrInvalidData, rune(data[i]), i)
+		}
+	}
+
+	var values []int
+	set := 0
+	for i := 0; i < len(data); {
+		// Code set C takes digits in pairs. An odd digit goes first in
+		// code set B, except at the start where it is left for the end.
+		run := digitRun(data[i:])
+		if run%2 == 1 {
+			if i > 0 {
+				run = 0
+			} else {
+				run--
+			}
+		}
+		// Code set C pays for itself on four digits at either end of the
+		// data and on six in the middle, where it costs two switches.
+		atEdge := i == 0 || i+run == len(data)
+		if run >= 6 || (run >= 4 && atEdge) || (run >= 2 && run == len(data)) {
+			values = append(values, code128Switch(set, code128StartC, code128CodeC))
+			set = code128StartC
+			for end := i + run; i < end; i += 2 {
+				values = append(values, int(data[i]-'0')*10+int(data[i+1]-'0'))
+			}
+			continue
+		}
+		if set != code128StartB {
+			values = append(values, code128Switch(set, code128StartB, code128CodeB))
+			set = code128StartB
+		}
+		values = append(values, int(data[i]-' '))
+		i++
+	}
+
+	sum := values[0]
+	for i, v := range values[1:] {
+		sum += (i + 1) * v
+	}
+	values = append(values, sum%103, code128Stop)
+
+	var bars []bool
+	for _, v := range values {
+		widths := make([]int, len(code128Patterns[v]))
+		for i, c := range code128Patterns[v] {
+			widths[i] = int(c - '0')
+		}
+		bars = appendWidths(bars, widths...)
+	}
+	return &Code{Text: data, QuietZone: code128QuietZone, bars: bars}, nil
+}
+
+// code128Switch returns the symbol that starts the code in a code set, or
+// switches to it when another one is in use.
+func code128Switch(current, start, code int) int {
+	if current == 0 {
+		return start
+	}
+	return code
+}
+
+// digitRun returns how many ASCII digits s starts with.
+func digitRun(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,134 @@
+package barcode
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeCode128_Values(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected []int
+	}{
+		// The checksum is the start value plus each value times its position, mod 103.
+		{name: "code set B", data: "PJJ123C", expected: []int{104, 48, 42, 42, 17, 18, 19, 35, 55}},
+		{name: "even digits use code set C", data: "12345678", expected: []int{105, 12, 34, 56, 78, 47}},
+		{name: "two digits use code set C", data: "42", expected: []int{105, 42, 44}},
+		{name: "odd digits start in code set C", data: "12345", expected: []int{105, 12, 34, 100, 21, 54}},
+		{name: "short run stays in code set B", data: "A12", expected: []int{104, 33, 17, 18, 19}},
+		{name: "trailing run of four", data: "A1234", expected: []int{104, 33, 99, 12, 34, 95}},
+		{name: "odd run starts in code set B", data: "A12345", expected: []int{104, 33, 17, 99, 23, 45, 64}},
+		{name: "middle run of four stays in code set B", data: "A1234B", expected: []int{104, 33, 17, 18, 19, 20, 34, 90}},
+		{name: "middle run of six", data: "A123456B", expected: []int{104, 33, 99, 12, 34, 56, 100, 34, 80}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			code, err := Encode(Code128, tt.data)
+			require.NoError(t, err)
+
+			// Assert
+			values, text := readCode128(t, code)
+			assert.Equal(t, tt.expected, values)
+			assert.Equal(t, tt.data, text)
+		})
+	}
+}
+
+func TestEncodeCode128_AllPrintableASCII(t *testing.T) {
+	// Arrange
+	var data strings.Builder
+	for c := ' '; c <= '~'; c++ {
+		data.WriteRune(c)
+	}
+
+	// Act
+	code, err := Encode(Code128, data.String())
+	require.NoError(t, err)
+
+	// Assert
+	_, text := readCode128(t, code)
+	assert.Equal(t, data.String(), text)
+}
+
+func TestCode128Patterns(t *testing.T) {
+	seen := map[string]bool{}
+	for value, pattern := range code128Patterns {
+		width, bars := 0, 0
+		for i, c := range pattern {
+			width += int(c - '0')
+			if i%2 == 0 {
+				bars += int(c - '0')
+			}
+		}
+		expected := 11
+		if value == code128Stop {
+			expected = 13
+		}
+		assert.Equal(t, expected, width, "value %d", value)
+		assert.Zero(t, bars%2, "value %d has an odd number of bar modules", value)
+		assert.False(t, seen[pattern], "value %d repeats a pattern", value)
+		seen[pattern] = true
+	}
+}
+
+// readCode128 reads the symbol values of a code back from its bars, checks
+// the checksum and stop pattern and decodes the text. The values returned
+// include the start symbol and checksum.
+func readCode128(t *testing.T, code *Code) ([]int, string) {
+	t.Helper()
+
+	widths := runs(code)
+	require.Equal(t, 1, len(widths)%6, "bars and spaces do not make whole symbols")
+	require.Equal(t, code128Patterns[code128Stop], digitsOf(widths[len(widths)-7:]), "stop pattern")
+
+	var values []int
+	for i := 0; i < len(widths)-7; i += 6 {
+		pattern := digitsOf(widths[i : i+6])
+		value := -1
+		for v, p := range code128Patterns {
+			if p == pattern {
+				value = v
+			}
+		}
+		require.NotEqual(t, -1, value, "unknown symbol %s", pattern)
+		values = append(values, value)
+	}
+
+	sum := values[0]
+	for i, v := range values[1 : len(values)-1] {
+		sum += (i + 1) * v
+	}
+	require.Equal(t, sum%103, values[len(values)-1], "checksum")
+
+	var text strings.Builder
+	set := values[0]
+	for _, v := range values[1 : len(values)-1] {
+		switch {
+		case set == code128StartC && v == code128CodeB:
+			set = code128StartB
+		case set == code128StartB && v == code128CodeC:
+			set = code128StartC
+		case set == code128StartC:
+			fmt.Fprintf(&text, "%02d", v)
+		default:
+			text.WriteByte(byte(v + ' '))
+		}
+	}
+	return values, text.String()
+}
+
+// digitsOf writes widths as a pattern string like "212222".
+func digitsOf(widths []int) string {
+	var s strings.Builder
+	for _, w := range widths {
+		s.WriteByte(byte('0' + w))
+	}
+	return s.String()
+}
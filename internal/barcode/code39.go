@@ -0,0 +1,60 @@
+package barcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// code39Chars are the characters Code 39 encodes, in the order of
+// code39Patterns.
+const code39Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ-. $/+%*"
+
+// code39Patterns are the narrow ('n') and wide ('w') bars and spaces of the
+// Code 39 characters, starting with a bar. Each has three wide elements.
+// The last is '*', which starts and stops every code.
+var code39Patterns = [...]string{
+	"nnnwwnwnn", "wnnwnnnnw", "nnwwnnnnw", "wnwwnnnnn", "nnnwwnnnw",
+	"wnnwwnnnn", "nnwwwnnnn", "nnnwnnwnw", "wnnwnnwnn", "nnwwnnwnn",
+	"wnnnnwnnw", "nnwnnwnnw", "wnwnnwnnn", "nnnnwwnnw", "wnnnwwnnn",
+	"nnwnwwnnn", "nnnnnwwnw", "wnnnnwwnn", "nnwnnwwnn", "nnnnwwwnn",
+	"wnnnnnnww", "nnwnnnnww", "wnwnnnnwn", "nnnnwnnww", "wnnnwnnwn",
+	"nnwnwnnwn", "nnnnnnwww", "wnnnnnwwn", "nnwnnnwwn", "nnnnwnwwn",
+	"wwnnnnnnw", "nwwnnnnnw", "wwwnnnnnn", "nwnnwnnnw", "wwnnwnnnn",
+	"nwwnwnnnn", "nwnnnnwnw", "wwnnnnwnn", "nwwnnnwnn", "nwnwnwnnn",
+	"nwnwnnnwn", "nwnnnwnwn", "nnnwnwnwn", "nwnnwnwnn",
+}
+
+// Code 39 element widths in modules. A 3:1 ratio keeps wide elements well
+// apart from narrow ones at the small module sizes of the print head.
+const (
+	code39Narrow    = 1
+	code39Wide      = 3
+	code39QuietZone = 10
+)
+
+// encodeCode39 encodes data between '*' start and stop characters, with a
+// narrow space between characters.
+func encodeCode39(data string) (*Code, error) {
+	for i := 0; i < len(data); i++ {
+		if data[i] == '*' || strings.IndexByte(code39Chars, data[i]) < 0 {
+			return nil, fmt.Errorf("%w: code39 can only encode digits, upper case letters, space and \"-.$/+%%\", got %q at %d", ErrInvalidData, rune(data[i]), i)
+		}
+	}
+
+	var bars []bool
+	for i, c := range "*" + data + "*" {
+		if i > 0 {
+			bars = appendWidths(bars, 0, code39Narrow)
+		}
+		pattern := code39Patterns[strings.IndexRune(code39Chars, c)]
+		widths := make([]int, len(pattern))
+		for j := range pattern {
+			widths[j] = code39Narrow
+			if pattern[j] == 'w' {
+				widths[j] = code39Wide
+			}
+		}
+		bars = appendWidths(bars, widths...)
+	}
+	return &Code{Text: data, QuietZone: code39QuietZone, bars: bars}, nil
+}
@@ -0,0 +1,89 @@
+package barcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeCode39_ReadsBack(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "bin label", data: "BIN-A-17"},
+		{name: "every character", data: strings.TrimSuffix(code39Chars, "*")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			code, err := Encode(Code39, tt.data)
+			require.NoError(t, err)
+
+			// Assert
+			assert.Equal(t, "*"+tt.data+"*", readCode39(t, code))
+		})
+	}
+}
+
+func TestEncodeCode39_Modules(t *testing.T) {
+	// Arrange: '0' is narrow bar, narrow space, narrow bar, wide space,
+	// wide bar, narrow space, wide bar, narrow space, narrow bar.
+	code, err := Encode(Code39, "0")
+	require.NoError(t, err)
+
+	// Assert
+	widths := runs(code)
+	assert.Equal(t, []int{1, 1, 1, 3, 3, 1, 3, 1, 1}, widths[10:19])
+}
+
+func TestCode39Patterns(t *testing.T) {
+	seen := map[string]bool{}
+	for i, pattern := range code39Patterns {
+		wideBars := strings.Count(pattern[0:1]+pattern[2:3]+pattern[4:5]+pattern[6:7]+pattern[8:9], "w")
+		wideSpaces := strings.Count(pattern, "w") - wideBars
+		c := code39Chars[i]
+		if strings.IndexByte("$/+%", c) >= 0 {
+			assert.Equal(t, [2]int{0, 3}, [2]int{wideBars, wideSpaces}, "character %q", c)
+		} else {
+			assert.Equal(t, [2]int{2, 1}, [2]int{wideBars, wideSpaces}, "character %q", c)
+		}
+		assert.False(t, seen[pattern], "character %q repeats a pattern", c)
+		seen[pattern] = true
+	}
+}
+
+// readCode39 reads the characters of a code, including the start and stop
+// characters, from the widths of its bars and spaces.
+func readCode39(t *testing.T, code *Code) string {
+	t.Helper()
+
+	widths := runs(code)
+	require.Equal(t, 9, len(widths)%10, "bars and spaces do not make whole characters")
+	var text strings.Builder
+	for i := 0; i < len(widths); i += 10 {
+		var pattern strings.Builder
+		for _, w := range widths[i : i+9] {
+			if w == code39Wide {
+				pattern.WriteByte('w')
+			} else {
+				pattern.WriteByte('n')
+			}
+		}
+		if i+9 < len(widths) {
+			require.Equal(t, code39Narrow, widths[i+9], "gap after character %d", i/10)
+		}
+		found := false
+		for j, p := range code39Patterns {
+			if p == pattern.String() {
+				text.WriteByte(code39Chars[j])
+				found = true
+			}
+		}
+		require.True(t, found, "unknown pattern %s", pattern.String())
+	}
+	return text.String()
+}
@@ -0,0 +1,124 @@
+package barcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// eanLeftOdd are the odd parity (L) patterns of the digits 0 to 9. The even
+// parity (G) patterns are these reversed and inverted, and the right hand
+// (R) patterns are these inverted.
+var eanLeftOdd = [10]string{
+	"0001101", "0011001", "0010011", "0111101", "0100011",
+	"0110001", "0101111", "0111011", "0110111", "0001011",
+}
+
+// eanParity gives, for the first digit of an EAN-13 number, which of the
+// next six digits use even parity ('G') rather than odd ('L'). The first
+// digit is encoded by this choice alone.
+var eanParity = [10]string{
+	"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG",
+	"LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL",
+}
+
+// EAN-13 guard patterns and quiet zone.
+const (
+	eanEndGuard    = "101"
+	eanCentreGuard = "01010"
+	eanQuietZone   = 11
+)
+
+// encodeEAN13 encodes a 12 digit number with its check digit added, or a
+// 13 digit number whose check digit is correct.
+func encodeEAN13(data string) (*Code, error) {
+	digits, err := checkDigits("ean13", data, 12)
+	if err != nil {
+		return nil, err
+	}
+
+	bars := appendPattern(nil, eanEndGuard)
+	parity := eanParity[digits[0]-'0']
+	for i, d := range digits[1:7] {
+		pattern := eanLeftOdd[d-'0']
+		if parity[i] == 'G' {
+			pattern = reverse(invert(pattern))
+		}
+		bars = appendPattern(bars, pattern)
+	}
+	bars = appendPattern(bars, eanCentreGuard)
+	for _, d := range digits[7:] {
+		bars = appendPattern(bars, invert(eanLeftOdd[d-'0']))
+	}
+	bars = appendPattern(bars, eanEndGuard)
+	return &Code{Text: digits, QuietZone: eanQuietZone, bars: bars}, nil
+}
+
+// encodeUPCA encodes an 11 digit number with its check digit added, or a
+// 12 digit number whose check digit is correct. A UPC-A code is the EAN-13
+// code of the same number with a leading zero.
+func encodeUPCA(data string) (*Code, error) {
+	digits, err := checkDigits("upca", data, 11)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := encodeEAN13("0" + digits)
+	if err != nil {
+		return nil, err
+	}
+	code.Text = digits
+	return code, nil
+}
+
+// checkDigits checks that data is n digits, or n+1 digits ending in the
+// right check digit, and returns the digits with the check digit.
+func checkDigits(name, data string, n int) (string, error) {
+	if digitRun(data) != len(data) {
+		return "", fmt.Errorf("%w: %s can only encode digits, got %q", ErrInvalidData, name, data)
+	}
+	if len(data) != n && len(data) != n+1 {
+		return "", fmt.Errorf("%w: %s takes %d digits or %d with the check digit, got %d", ErrInvalidData, name, n, n+1, len(data))
+	}
+
+	check := checkDigit(data[:n])
+	if len(data) == n {
+		return data + string(check), nil
+	}
+	if data[n] != check {
+		return "", fmt.Errorf("%w: %s check digit of %s is %c, got %c", ErrCheckDigit, name, data[:n], check, data[n])
+	}
+	return data, nil
+}
+
+// checkDigit returns the GS1 mod 10 check digit of digits: counting from
+// the right, digits are weighted 3, 1, 3 and so on.
+func checkDigit(digits string) byte {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		weight := 1
+		if (len(digits)-1-i)%2 == 0 {
+			weight = 3
+		}
+		sum += weight * int(digits[i]-'0')
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// invert swaps bars and spaces in a pattern.
+func invert(pattern string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '1' {
+			return '0'
+		}
+		return '1'
+	}, pattern)
+}
+
+// reverse returns a pattern read right to left.
+func reverse(pattern string) string {
+	b := []byte(pattern)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return string(b)
+}
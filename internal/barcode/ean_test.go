@@ -0,0 +1,110 @@
+package barcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDigit(t *testing.T) {
+	tests := []struct {
+		digits   string
+		expected byte
+	}{
+		{digits: "400638133393", expected: '1'},
+		{digits: "590123412345", expected: '7'},
+		{digits: "03600029145", expected: '2'},
+		{digits: "000000000000", expected: '0'},
+		{digits: "12345678901", expected: '2'},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, string(tt.expected), string(checkDigit(tt.digits)), "digits %s", tt.digits)
+	}
+}
+
+func TestEncodeEAN13_ReadsBack(t *testing.T) {
+	tests := []struct {
+		name     string
+		sym      Symbology
+		data     string
+		expected string
+	}{
+		{name: "ean13", sym: EAN13, data: "4006381333931", expected: "4006381333931"},
+		{name: "ean13 every first digit parity", sym: EAN13, data: "987654321098", expected: "9876543210982"},
+		{name: "upca has a leading zero", sym: UPCA, data: "036000291452", expected: "0036000291452"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			code, err := Encode(tt.sym, tt.data)
+			require.NoError(t, err)
+
+			// Assert
+			assert.Equal(t, tt.expected, readEAN13(t, code))
+		})
+	}
+}
+
+// readEAN13 reads the 13 digits of an EAN-13 or UPC-A code from its bars:
+// the digit patterns between the guards, with the first digit taken from
+// the parity of the left half.
+func readEAN13(t *testing.T, code *Code) string {
+	t.Helper()
+
+	var modules strings.Builder
+	for x := 0; x < code.Modules(); x++ {
+		if code.Black(x) {
+			modules.WriteByte('1')
+		} else {
+			modules.WriteByte('0')
+		}
+	}
+	m := modules.String()
+	require.Len(t, m, 95)
+	require.Equal(t, eanEndGuard, m[:3])
+	require.Equal(t, eanCentreGuard, m[45:50])
+	require.Equal(t, eanEndGuard, m[92:])
+
+	find := func(pattern string, encode func(string) string) int {
+		for d, p := range eanLeftOdd {
+			if encode(p) == pattern {
+				return d
+			}
+		}
+		return -1
+	}
+	odd := func(p string) string { return p }
+	even := func(p string) string { return reverse(invert(p)) }
+
+	var digits, parity strings.Builder
+	for i := 0; i < 6; i++ {
+		pattern := m[3+7*i : 10+7*i]
+		if d := find(pattern, odd); d >= 0 {
+			digits.WriteByte(byte('0' + d))
+			parity.WriteByte('L')
+		} else if d := find(pattern, even); d >= 0 {
+			digits.WriteByte(byte('0' + d))
+			parity.WriteByte('G')
+		} else {
+			t.Fatalf("unknown left digit pattern %s", pattern)
+		}
+	}
+	for i := 0; i < 6; i++ {
+		pattern := m[50+7*i : 57+7*i]
+		d := find(pattern, invert)
+		require.GreaterOrEqual(t, d, 0, "unknown right digit pattern %s", pattern)
+		digits.WriteByte(byte('0' + d))
+	}
+
+	for first, p := range eanParity {
+		if p == parity.String() {
+			return string(rune('0'+first)) + digits.String()
+		}
+	}
+	t.Fatalf("unknown parity %s", parity.String())
+	return ""
+}
@@ -3,14 +3,33 @@ package config
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/princem/peripage-printer/internal/core"
 )
 
+// DefaultPrinterName names the printer configured by the PRINTER_* variables
+// when PRINTERS is not set.
+const DefaultPrinterName = "default"
+
+// printerNamePattern matches the printer names accepted in PRINTERS.
+var printerNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
 // Config holds all application configuration.
 type Config struct {
-	Server  ServerConfig
-	Printer PrinterConfig
-	BLE     BLEConfig
+	Server   ServerConfig
+	Printers []PrinterConfig
+	Pools    []PoolConfig
+	// DefaultPrinter is the name of the printer or pool jobs go to when they
+	// name none.
+	DefaultPrinter string
+	BLE            BLEConfig
+	Jobs           JobsConfig
+	Templates      TemplatesConfig
+	Mock           MockConfig
 }
 
 // ServerConfig holds server-specific configuration.
@@ -20,14 +39,56 @@ type ServerConfig struct {
 
 // PrinterConfig holds printer-specific configuration.
 type PrinterConfig struct {
+	Name       string
 	Type       string // "mock" or "ble"
 	DeviceName string
 	Timeout    time.Duration
+	// Settings are the density, speed and paper type used when a job does
+	// not set them.
+	Settings core.PrintSettings
+
+	// Extra BLE device criteria; every one that is set must match.
+	Address     string // MAC address
+	NamePrefix  string
+	NamePattern string // Regular expression for the advertised name
+	ServiceUUID string
+}
+
+// PoolConfig holds the configuration of a pool of printers that share jobs.
+type PoolConfig struct {
+	Name    string
+	Members []string // Printer names
 }
 
 // BLEConfig holds Bluetooth LE configuration.
 type BLEConfig struct {
-	ScanTimeout time.Duration
+	ScanTimeout         time.Duration
+	ReconnectMinBackoff time.Duration // Delay after the first failed connection attempt
+	ReconnectMaxBackoff time.Duration // Upper bound for the doubling delay between attempts
+	MatchWindow         time.Duration // Keep scanning this long after a match for a stronger signal
+}
+
+// JobsConfig holds print job persistence configuration.
+type JobsConfig struct {
+	StorePath string        // Empty keeps jobs in memory only
+	Retention time.Duration // How long finished jobs are kept; 0 keeps them forever
+}
+
+// TemplatesConfig holds print template persistence configuration.
+type TemplatesConfig struct {
+	StorePath string // Empty keeps templates in memory only
+}
+
+// MockConfig holds mock printer configuration.
+type MockConfig struct {
+	OutputDir        string        // Directory for PNG output; empty disables it
+	FailEvery        int           // Fail every Nth job; 0 disables it
+	FailureRate      float64       // Probability (0 to 1) that a job fails at random
+	Latency          time.Duration // Delay added to every job
+	PaperOut         bool
+	LowBattery       bool
+	Overheated       bool
+	DisconnectMidJob bool
 }
 
 // Load reads configuration from environment variables.
@@ -36,16 +97,35 @@ func Load() (*Config, error) {
 		Server: ServerConfig{
 			Port: getEnv("PORT", "8080"),
 		},
-		Printer: PrinterConfig{
-			Type:       getEnv("PRINTER_TYPE", "mock"),
-			DeviceName: getEnv("PRINTER_DEVICE_NAME", "Peripage"),
-			Timeout:    parseDuration(getEnv("PRINTER_TIMEOUT", "30s")),
-		},
 		BLE: BLEConfig{
-			ScanTimeout: parseDuration(getEnv("BLE_SCAN_TIMEOUT", "10s")),
+			ScanTimeout:         parseDuration(getEnv("BLE_SCAN_TIMEOUT", "10s")),
+			ReconnectMinBackoff: parseDuration(getEnv("BLE_RECONNECT_MIN_BACKOFF", "1s")),
+			ReconnectMaxBackoff: parseDuration(getEnv("BLE_RECONNECT_MAX_BACKOFF", "30s")),
+			MatchWindow:         parseDuration(getEnv("BLE_MATCH_WINDOW", "1s")),
+		},
+		Jobs: JobsConfig{
+			StorePath: getEnv("JOB_STORE_PATH", ""),
+			Retention: parseDuration(getEnv("JOB_RETENTION", "168h")),
+		},
+		Templates: TemplatesConfig{
+			StorePath: getEnv("TEMPLATE_STORE_PATH", ""),
+		},
+		Mock: MockConfig{
+			OutputDir:        getEnv("MOCK_OUTPUT_DIR", ""),
+			FailEvery:        parseInt(getEnv("MOCK_FAIL_EVERY", "0")),
+			FailureRate:      parseFloat(getEnv("MOCK_FAILURE_RATE", "0")),
+			Latency:          parseDuration(getEnv("MOCK_LATENCY", "0s")),
+			PaperOut:         parseBool(getEnv("MOCK_PAPER_OUT", "false")),
+			LowBattery:       parseBool(getEnv("MOCK_LOW_BATTERY", "false")),
+			Overheated:       parseBool(getEnv("MOCK_OVERHEATED", "false")),
+			DisconnectMidJob: parseBool(getEnv("MOCK_DISCONNECT_MID_JOB", "false")),
 		},
 	}
 
+	config.Printers = loadPrinters()
+	config.Pools = loadPools()
+	config.DefaultPrinter = getEnv("DEFAULT_PRINTER", config.Printers[0].Name)
+
 	// Validate configuration
 	if err := config.Validate(); err != nil {
 		return nil, err
@@ -56,17 +136,210 @@ func Load() (*Config, error) {
 
 // Validate checks if the configuration is valid.
 func (c *Config) Validate() error {
-	if c.Printer.Type != "mock" && c.Printer.Type != "ble" {
-		return fmt.Errorf("invalid printer type: %s (must be 'mock' or 'ble')", c.Printer.Type)
+	if len(c.Printers) == 0 {
+		return fmt.Errorf("no printers configured")
+	}
+
+	names := make(map[string]bool)
+	devices := make(map[deviceCriteria]string)
+	for _, printer := range c.Printers {
+		if err := printer.validate(); err != nil {
+			return fmt.Errorf("printer %s: %w", printer.Name, err)
+		}
+		if names[printer.Name] {
+			return fmt.Errorf("printer %s is configured twice", printer.Name)
+		}
+		names[printer.Name] = true
+
+		if printer.Type == "ble" {
+			if other, ok := devices[printer.criteria()]; ok {
+				return fmt.Errorf("printers %s and %s have the same device criteria and could connect to the same printer; give each an address or name", other, printer.Name)
+			}
+			devices[printer.criteria()] = printer.Name
+		}
+	}
+
+	printers := make(map[string]bool, len(names))
+	for name := range names {
+		printers[name] = true
+	}
+	for _, pool := range c.Pools {
+		if err := pool.validate(printers); err != nil {
+			return fmt.Errorf("pool %s: %w", pool.Name, err)
+		}
+		if names[pool.Name] {
+			return fmt.Errorf("pool %s has the name of another printer or pool", pool.Name)
+		}
+		names[pool.Name] = true
+	}
+
+	if !names[c.DefaultPrinter] {
+		return fmt.Errorf("default printer %s is not configured", c.DefaultPrinter)
 	}
 
-	if c.Printer.Type == "ble" && c.Printer.DeviceName == "" {
-		return fmt.Errorf("device name is required for BLE printer")
+	if c.BLE.ReconnectMaxBackoff < c.BLE.ReconnectMinBackoff {
+		return fmt.Errorf("invalid BLE reconnect backoff: max %s is shorter than min %s", c.BLE.ReconnectMaxBackoff, c.BLE.ReconnectMinBackoff)
+	}
+
+	if c.Mock.FailEvery < 0 {
+		return fmt.Errorf("invalid mock fail every: %d (must not be negative)", c.Mock.FailEvery)
+	}
+
+	if c.Mock.FailureRate < 0 || c.Mock.FailureRate > 1 {
+		return fmt.Errorf("invalid mock failure rate: %g (must be between 0 and 1)", c.Mock.FailureRate)
 	}
 
 	return nil
 }
 
+// loadPrinters reads the printers named in PRINTERS, or the single printer
+// described by the PRINTER_* variables when PRINTERS is not set.
+// Settings of a named printer come from PRINTER_<NAME>_* variables, where
+// <NAME> is the upper case name with dashes replaced by underscores. Its type,
+// timeout and print settings fall back to the PRINTER_* values; device
+// criteria do not, because they identify one printer. Only a lone printer
+// without criteria looks for the default device name; with several, each
+// BLE printer must say which device it is.
+func loadPrinters() []PrinterConfig {
+	defaults := loadPrinter(DefaultPrinterName, "PRINTER_", PrinterConfig{
+		Type:    "mock",
+		Timeout: 30 * time.Second,
+	})
+
+	var printers []PrinterConfig
+	for _, name := range splitList(getEnv("PRINTERS", "")) {
+		printers = append(printers, loadPrinter(name, envName("PRINTER_", name), PrinterConfig{
+			Type:     defaults.Type,
+			Timeout:  defaults.Timeout,
+			Settings: defaults.Settings,
+		}))
+	}
+	if len(printers) == 0 {
+		printers = []PrinterConfig{defaults}
+	}
+
+	// Without other criteria, look for the printer by its default name
+	if len(printers) == 1 && !printers[0].hasDeviceCriteria() {
+		printers[0].DeviceName = "Peripage"
+	}
+	return printers
+}
+
+// loadPrinter reads the settings of one printer from the variables starting
+// with prefix.
+func loadPrinter(name, prefix string, defaults PrinterConfig) PrinterConfig {
+	printer := PrinterConfig{
+		Name:        name,
+		Type:        getEnv(prefix+"TYPE", defaults.Type),
+		DeviceName:  getEnv(prefix+"DEVICE_NAME", ""),
+		Timeout:     parseDuration(getEnv(prefix+"TIMEOUT", defaults.Timeout.String())),
+		Address:     getEnv(prefix+"ADDRESS", ""),
+		NamePrefix:  getEnv(prefix+"NAME_PREFIX", ""),
+		NamePattern: getEnv(prefix+"NAME_PATTERN", ""),
+		ServiceUUID: getEnv(prefix+"SERVICE_UUID", ""),
+		Settings: core.PrintSettings{
+			Density: core.Density(getEnv(prefix+"DENSITY", string(defaults.Settings.Density))),
+			Speed:   core.Speed(getEnv(prefix+"SPEED", string(defaults.Settings.Speed))),
+			Paper:   core.PaperType(getEnv(prefix+"PAPER", string(defaults.Settings.Paper))),
+		},
+	}
+	return printer
+}
+
+// loadPools reads the pools named in POOLS. The members of a pool are listed
+// in POOL_<NAME>_MEMBERS.
+func loadPools() []PoolConfig {
+	var pools []PoolConfig
+	for _, name := range splitList(getEnv("POOLS", "")) {
+		pools = append(pools, PoolConfig{
+			Name:    name,
+			Members: splitList(getEnv(envName("POOL_", name)+"MEMBERS", "")),
+		})
+	}
+	return pools
+}
+
+// splitList splits a comma-separated list, dropping empty entries.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// envName returns the prefix of the variables configuring the named printer
+// or pool, e.g. PRINTER_FRONT_DESK_ for the printer "front-desk".
+func envName(kind, name string) string {
+	return kind + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+}
+
+// validate checks the settings of one printer.
+func (p PrinterConfig) validate() error {
+	if !printerNamePattern.MatchString(p.Name) {
+		return fmt.Errorf("invalid printer name (use lower case letters, digits, '-' and '_')")
+	}
+
+	if p.Type != "mock" && p.Type != "ble" {
+		return fmt.Errorf("invalid printer type: %s (must be 'mock' or 'ble')", p.Type)
+	}
+
+	if p.Type == "ble" && !p.hasDeviceCriteria() {
+		return fmt.Errorf("device name, address, name prefix, name pattern or service UUID is required for BLE printer")
+	}
+
+	if p.NamePattern != "" {
+		if _, err := regexp.Compile(p.NamePattern); err != nil {
+			return fmt.Errorf("invalid printer name pattern: %w", err)
+		}
+	}
+
+	if err := p.Settings.Validate(); err != nil {
+		return fmt.Errorf("invalid print settings: %w", err)
+	}
+	return nil
+}
+
+// validate checks the settings of one pool against the configured printers.
+func (p PoolConfig) validate(printers map[string]bool) error {
+	if !printerNamePattern.MatchString(p.Name) {
+		return fmt.Errorf("invalid pool name (use lower case letters, digits, '-' and '_')")
+	}
+	if len(p.Members) == 0 {
+		return fmt.Errorf("no members (set %sMEMBERS)", envName("POOL_", p.Name))
+	}
+	for _, member := range p.Members {
+		if !printers[member] {
+			return fmt.Errorf("member %s is not a configured printer", member)
+		}
+	}
+	return nil
+}
+
+// deviceCriteria are the settings that pick the BLE device of a printer.
+type deviceCriteria struct {
+	deviceName, address, namePrefix, namePattern, serviceUUID string
+}
+
+// criteria returns the BLE device criteria of the printer. Addresses are
+// compared without regard to case.
+func (p PrinterConfig) criteria() deviceCriteria {
+	return deviceCriteria{
+		deviceName:  p.DeviceName,
+		address:     strings.ToUpper(p.Address),
+		namePrefix:  p.NamePrefix,
+		namePattern: p.NamePattern,
+		serviceUUID: strings.ToLower(p.ServiceUUID),
+	}
+}
+
+// hasDeviceCriteria reports whether any BLE device criterion is set.
+func (p PrinterConfig) hasDeviceCriteria() bool {
+	return p.DeviceName != "" || p.Address != "" || p.NamePrefix != "" || p.NamePattern != "" || p.ServiceUUID != ""
+}
+
 // getEnv gets an environment variable or returns a default value.
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -83,3 +356,30 @@ func parseDuration(s string) time.Duration {
 	}
 	return d
 }
+
+// parseInt parses an integer string, returning 0 on error.
+func parseInt(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// parseFloat parses a floating point string, returning 0 on error.
+func parseFloat(s string) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
+
+// parseBool parses a boolean string, returning false on error.
+func parseBool(s string) bool {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false
+	}
+	return b
+}
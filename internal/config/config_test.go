@@ -0,0 +1,245 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/princem/peripage-printer/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+}
+
+func printerNames(printers []PrinterConfig) []string {
+	names := make([]string, len(printers))
+	for i, printer := range printers {
+		names[i] = printer.Name
+	}
+	return names
+}
+
+func TestLoad_Printers(t *testing.T) {
+	tests := []struct {
+		name             string
+		env              map[string]string
+		expectedPrinters []string
+		expectedPools    []PoolConfig
+		expectedDefault  string
+	}{
+		{
+			name:             "single printer without PRINTERS",
+			env:              map[string]string{},
+			expectedPrinters: []string{DefaultPrinterName},
+			expectedDefault:  DefaultPrinterName,
+		},
+		{
+			name:             "first printer is the default",
+			env:              map[string]string{"PRINTERS": "front-desk, kitchen"},
+			expectedPrinters: []string{"front-desk", "kitchen"},
+			expectedDefault:  "front-desk",
+		},
+		{
+			name:             "default printer",
+			env:              map[string]string{"PRINTERS": "front-desk,kitchen", "DEFAULT_PRINTER": "kitchen"},
+			expectedPrinters: []string{"front-desk", "kitchen"},
+			expectedDefault:  "kitchen",
+		},
+		{
+			name: "pool as the default printer",
+			env: map[string]string{
+				"PRINTERS":            "front-desk,kitchen",
+				"POOLS":               "labels",
+				"POOL_LABELS_MEMBERS": "front-desk, kitchen",
+				"DEFAULT_PRINTER":     "labels",
+			},
+			expectedPrinters: []string{"front-desk", "kitchen"},
+			expectedPools:    []PoolConfig{{Name: "labels", Members: []string{"front-desk", "kitchen"}}},
+			expectedDefault:  "labels",
+		},
+		{
+			name: "BLE printers with distinct criteria",
+			env: map[string]string{
+				"PRINTERS":                   "front-desk,kitchen",
+				"PRINTER_TYPE":               "ble",
+				"PRINTER_FRONT_DESK_ADDRESS": "AA:BB:CC:DD:EE:01",
+				"PRINTER_KITCHEN_ADDRESS":    "AA:BB:CC:DD:EE:02",
+			},
+			expectedPrinters: []string{"front-desk", "kitchen"},
+			expectedDefault:  "front-desk",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			setEnv(t, tt.env)
+
+			// Act
+			config, err := Load()
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPrinters, printerNames(config.Printers))
+			assert.Equal(t, tt.expectedPools, config.Pools)
+			assert.Equal(t, tt.expectedDefault, config.DefaultPrinter)
+		})
+	}
+}
+
+func TestLoad_PrinterSettings(t *testing.T) {
+	// Arrange
+	setEnv(t, map[string]string{
+		"PRINTERS":                        "front-desk,kitchen",
+		"PRINTER_TYPE":                    "ble",
+		"PRINTER_DENSITY":                 "dark",
+		"PRINTER_NAME_PREFIX":             "PeriPage",
+		"PRINTER_FRONT_DESK_NAME_PATTERN": "_front$",
+		"PRINTER_KITCHEN_ADDRESS":         "AA:BB:CC:DD:EE:02",
+		"PRINTER_KITCHEN_DENSITY":         "light",
+	})
+
+	// Act
+	config, err := Load()
+
+	// Assert: type and settings fall back to PRINTER_*, device criteria do not
+	require.NoError(t, err)
+	require.Len(t, config.Printers, 2)
+	front, kitchen := config.Printers[0], config.Printers[1]
+	assert.Equal(t, "ble", front.Type)
+	assert.Equal(t, core.DensityDark, front.Settings.Density)
+	assert.Equal(t, "_front$", front.NamePattern)
+	assert.Empty(t, front.NamePrefix)
+	assert.Empty(t, front.DeviceName)
+	assert.Equal(t, "ble", kitchen.Type)
+	assert.Equal(t, core.DensityLight, kitchen.Settings.Density)
+	assert.Equal(t, "AA:BB:CC:DD:EE:02", kitchen.Address)
+}
+
+func TestLoad_DefaultDeviceName(t *testing.T) {
+	tests := []struct {
+		name               string
+		env                map[string]string
+		expectedDeviceName string
+	}{
+		{
+			name:               "lone printer without criteria",
+			env:                map[string]string{"PRINTER_TYPE": "ble"},
+			expectedDeviceName: "Peripage",
+		},
+		{
+			name:               "lone named printer without criteria",
+			env:                map[string]string{"PRINTERS": "kitchen", "PRINTER_TYPE": "ble"},
+			expectedDeviceName: "Peripage",
+		},
+		{
+			name: "lone printer with an address",
+			env:  map[string]string{"PRINTER_TYPE": "ble", "PRINTER_ADDRESS": "AA:BB:CC:DD:EE:01"},
+		},
+		{
+			name:               "device name",
+			env:                map[string]string{"PRINTER_TYPE": "ble", "PRINTER_DEVICE_NAME": "PeriPage_A6"},
+			expectedDeviceName: "PeriPage_A6",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setEnv(t, tt.env)
+
+			config, err := Load()
+
+			require.NoError(t, err)
+			require.Len(t, config.Printers, 1)
+			assert.Equal(t, tt.expectedDeviceName, config.Printers[0].DeviceName)
+		})
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           map[string]string
+		errorContains string
+	}{
+		{
+			name:          "duplicate printer names",
+			env:           map[string]string{"PRINTERS": "front,kitchen,front"},
+			errorContains: "printer front is configured twice",
+		},
+		{
+			name:          "invalid printer name",
+			env:           map[string]string{"PRINTERS": "Front Desk"},
+			errorContains: "printer Front Desk: invalid printer name",
+		},
+		{
+			name:          "unknown default printer",
+			env:           map[string]string{"PRINTERS": "front", "DEFAULT_PRINTER": "kitchen"},
+			errorContains: "default printer kitchen is not configured",
+		},
+		{
+			name:          "unknown pool member",
+			env:           map[string]string{"PRINTERS": "front", "POOLS": "labels", "POOL_LABELS_MEMBERS": "front,back"},
+			errorContains: "pool labels: member back is not a configured printer",
+		},
+		{
+			name:          "pool without members",
+			env:           map[string]string{"PRINTERS": "front", "POOLS": "labels"},
+			errorContains: "no members (set POOL_LABELS_MEMBERS)",
+		},
+		{
+			name:          "pool named like a printer",
+			env:           map[string]string{"PRINTERS": "front,kitchen", "POOLS": "front", "POOL_FRONT_MEMBERS": "kitchen"},
+			errorContains: "pool front has the name of another printer or pool",
+		},
+		{
+			name: "BLE printer without criteria among several",
+			env: map[string]string{
+				"PRINTERS":              "front,kitchen",
+				"PRINTER_TYPE":          "ble",
+				"PRINTER_FRONT_ADDRESS": "AA:BB:CC:DD:EE:01",
+			},
+			errorContains: "printer kitchen: device name, address, name prefix, name pattern or service UUID is required",
+		},
+		{
+			name:          "several BLE printers without criteria",
+			env:           map[string]string{"PRINTERS": "front,kitchen", "PRINTER_TYPE": "ble"},
+			errorContains: "printer front: device name, address",
+		},
+		{
+			name: "BLE printers with the same criteria",
+			env: map[string]string{
+				"PRINTERS":                    "front,kitchen",
+				"PRINTER_TYPE":                "ble",
+				"PRINTER_FRONT_NAME_PREFIX":   "PeriPage_A6",
+				"PRINTER_KITCHEN_NAME_PREFIX": "PeriPage_A6",
+			},
+			errorContains: "printers front and kitchen have the same device criteria",
+		},
+		{
+			name: "BLE printers with addresses differing in case",
+			env: map[string]string{
+				"PRINTERS":                "front,kitchen",
+				"PRINTER_TYPE":            "ble",
+				"PRINTER_FRONT_ADDRESS":   "aa:bb:cc:dd:ee:01",
+				"PRINTER_KITCHEN_ADDRESS": "AA:BB:CC:DD:EE:01",
+			},
+			errorContains: "printers front and kitchen have the same device criteria",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setEnv(t, tt.env)
+
+			_, err := Load()
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorContains)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/princem/peripage-printer/internal/barcode"
+	"github.com/princem/peripage-printer/internal/render"
+)
+
+// MaxBarcodeHeight is the tallest barcode bars can be, in dot lines.
+const MaxBarcodeHeight = 50 * render.DotsPerMM
+
+// Barcode describes a 1D barcode to print, such as a bin label or a product
+// number.
+type Barcode struct {
+	// Type is the symbology: code128, ean13, upca or code39. Empty means code128.
+	Type string `json:"type,omitempty" example:"code128" enums:"code128,ean13,upca,code39"`
+	// Data is the text or number to encode. EAN-13 and UPC-A numbers may
+	// leave out the check digit, which is then added.
+	Data string `json:"data" example:"BIN-A-17"`
+	// Scale is the width of the narrowest bar in dots. Zero prints the code
+	// as wide as fits the head.
+	Scale int `json:"scale,omitempty" example:"2"`
+	// Height is the height of the bars in dot lines. Zero means 80 (10mm).
+	Height int `json:"height,omitempty" example:"80"`
+	// HideText leaves out the human-readable text under the bars.
+	HideText bool `json:"hide_text,omitempty" example:"false"`
+}
+
+// Validate checks that the data can be encoded in the symbology and that
+// the code fits the head at the scale.
+func (b Barcode) Validate() error {
+	_, err := b.encode(render.HeadWidth)
+	return err
+}
+
+// encode builds the barcode and checks that it fits width dots.
+func (b Barcode) encode(width int) (*barcode.Code, error) {
+	if b.Data == "" {
+		return nil, &ValidationError{Message: "barcode data cannot be empty"}
+	}
+	sym, err := barcode.ParseSymbology(b.Type)
+	if err != nil {
+		return nil, &ValidationError{Message: err.Error()}
+	}
+	if b.Scale < 0 {
+		return nil, &ValidationError{Message: fmt.Sprintf("barcode scale must not be negative, got %d", b.Scale)}
+	}
+	if b.Height < 0 || b.Height > MaxBarcodeHeight {
+		return nil, &ValidationError{Message: fmt.Sprintf("barcode height must be between 0 and %d dot lines", MaxBarcodeHeight)}
+	}
+
+	code, err := barcode.Encode(sym, b.Data)
+	if err != nil {
+		return nil, &ValidationError{Message: err.Error()}
+	}
+	fit := render.MaxBarcodeScale(code, width)
+	if fit < 1 {
+		return nil, &ValidationError{Message: fmt.Sprintf("%s barcode of %q is too long for %d dots", sym, b.Data, width)}
+	}
+	if b.Scale > fit {
+		return nil, &ValidationError{Message: fmt.Sprintf("barcode scale %d is too large: this %s code fits at most %d", b.Scale, sym, fit)}
+	}
+	return code, nil
+}
+
+// renderBarcode draws a barcode, with its text unless hidden, across width
+// dots.
+func renderBarcode(b Barcode, width int) (*render.Bitmap, error) {
+	code, err := b.encode(width)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := render.DefaultBarcodeOptions()
+	opts.Width = width
+	opts.Scale = b.Scale
+	if b.Height > 0 {
+		opts.Height = b.Height
+	}
+	opts.HideText = b.HideText
+	bitmap, err := render.RenderBarcode(code, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render barcode: %w", err)
+	}
+	return bitmap, nil
+}
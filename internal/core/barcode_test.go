@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/princem/peripage-printer/internal/core/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBarcode_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    Barcode
+		wantErr string
+	}{
+		{name: "bin label", code: Barcode{Data: "BIN-A-17"}},
+		{name: "ean13 without check digit", code: Barcode{Type: "ean13", Data: "400638133393", Scale: 3, Height: 120}},
+		{name: "upca with check digit", code: Barcode{Type: "UPC-A", Data: "036000291452", HideText: true}},
+		{name: "code39", code: Barcode{Type: "code39", Data: "BIN 7"}},
+		{name: "empty data", code: Barcode{Type: "ean13"}, wantErr: "barcode data cannot be empty"},
+		{name: "unknown type", code: Barcode{Type: "ean8", Data: "1234567"}, wantErr: "unknown barcode type"},
+		{name: "negative scale", code: Barcode{Data: "x", Scale: -1}, wantErr: "must not be negative"},
+		{name: "height too large", code: Barcode{Data: "x", Height: MaxBarcodeHeight + 1}, wantErr: "height must be between"},
+		{name: "wrong check digit", code: Barcode{Type: "ean13", Data: "4006381333932"}, wantErr: "check digit does not match"},
+		{name: "letters in ean13", code: Barcode{Type: "ean13", Data: "ABCDEFGHIJKL"}, wantErr: "can only encode digits"},
+		{name: "lower case code39", code: Barcode{Type: "code39", Data: "bin"}, wantErr: "invalid barcode data"},
+		{name: "too long for the head", code: Barcode{Type: "code39", Data: strings.Repeat("A", 30)}, wantErr: "too long for 384 dots"},
+		{name: "scale too large", code: Barcode{Type: "ean13", Data: "400638133393", Scale: 4}, wantErr: "fits at most 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := tt.code.Validate()
+
+			// Assert
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestPrintService_Barcode(t *testing.T) {
+	// Arrange
+	req := JobRequest{Kind: JobBarcode, Barcode: &Barcode{Type: "ean13", Data: "400638133393"}}
+	expected, err := Rasterize(req)
+	require.NoError(t, err)
+
+	mockPrinter := new(mocks.MockPrinter)
+	mockPrinter.On("PrintImage", expected).Return(nil).Once()
+	service := NewPrintService(mockPrinter)
+	defer service.Close()
+
+	// Act
+	_, err = service.Print(context.Background(), req)
+
+	// Assert
+	require.NoError(t, err)
+	mockPrinter.AssertExpectations(t)
+	mockPrinter.AssertNotCalled(t, "PrintText", mock.Anything)
+}
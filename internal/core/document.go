@@ -0,0 +1,403 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/princem/peripage-printer/internal/render"
+)
+
+// Document limits.
+const (
+	// MaxDocumentBlocks is the most blocks a document may hold, counting the
+	// blocks inside columns.
+	MaxDocumentBlocks = 200
+	// MaxColumnDepth is how deeply columns blocks may be nested.
+	MaxColumnDepth = 3
+	// MaxTableCells is the most cells a table block may hold.
+	MaxTableCells = 1000
+	// MaxCellPadding is the most padding in dots around the text of a
+	// table cell.
+	MaxCellPadding = 5 * render.DotsPerMM
+	// MinTextSize and MaxTextSize bound the font size of text blocks in
+	// pixels.
+	MinTextSize = 8
+	MaxTextSize = 96
+)
+
+// Document geometry in dots.
+const (
+	columnGap        = render.DotsPerMM
+	dividerGap       = render.DotsPerMM
+	dividerThickness = 2
+)
+
+// BlockType identifies what a document block prints.
+type BlockType string
+
+const (
+	// BlockText prints a paragraph of styled text.
+	BlockText BlockType = "text"
+	// BlockImage prints a PNG, JPEG or GIF image, such as a logo.
+	BlockImage BlockType = "image"
+	// BlockQR prints a QR code.
+	BlockQR BlockType = "qr"
+	// BlockBarcode prints a 1D barcode.
+	BlockBarcode BlockType = "barcode"
+	// BlockDivider prints a horizontal line.
+	BlockDivider BlockType = "divider"
+	// BlockSpacer leaves blank paper.
+	BlockSpacer BlockType = "spacer"
+	// BlockTable prints rows of cells in columns.
+	BlockTable BlockType = "table"
+	// BlockColumns prints lists of blocks side by side.
+	BlockColumns BlockType = "columns"
+)
+
+// Document is a print job made of blocks laid out top to bottom into one
+// raster, so a receipt can combine a logo, a header, line items and a QR
+// code in a single job.
+type Document struct {
+	Blocks []Block `json:"blocks"`
+}
+
+// Block is one part of a document. Type selects which of the other fields
+// it uses.
+type Block struct {
+	Type BlockType `json:"type" example:"text" enums:"text,image,qr,barcode,divider,spacer,table,columns"`
+	// Text is the text of text blocks. "\n" starts a new line.
+	Text string `json:"text,omitempty" example:"Corner Café"`
+	// Style sets the font of text blocks.
+	Style *TextStyle `json:"style,omitempty"`
+	// Align places text blocks and image blocks narrower than the paper.
+	Align render.Align `json:"align,omitempty" example:"center" enums:"left,center,right"`
+	// Image is the PNG, JPEG or GIF file of image blocks, base64-encoded in JSON.
+	Image  []byte        `json:"image,omitempty" swaggertype:"string" format:"base64" example:"iVBORw0KGgoAAAANSUhEUgAA..."`
+	Dither render.Dither `json:"dither,omitempty" example:"floyd-steinberg" enums:"threshold,floyd-steinberg,atkinson,ordered"`
+	// Width is the width of image blocks in dots. Zero fills the width.
+	Width int `json:"width,omitempty" example:"192"`
+	// QR is the code of qr blocks.
+	QR *QRCode `json:"qr,omitempty"`
+	// Barcode is the code of barcode blocks.
+	Barcode *Barcode `json:"barcode,omitempty"`
+	// Dashed draws divider blocks as a dashed line.
+	Dashed bool `json:"dashed,omitempty" example:"false"`
+	// Height is the height of spacer blocks in dot lines.
+	Height int `json:"height,omitempty" example:"16"`
+	// Table holds the cells of table blocks.
+	Table *Table `json:"table,omitempty"`
+	// Columns holds the blocks of columns blocks, left to right.
+	Columns []Column `json:"columns,omitempty"`
+}
+
+// TextStyle is the font of a text block. The zero style is 20px Go Regular.
+type TextStyle struct {
+	// Size is the font size in pixels. Zero means 20.
+	Size   int  `json:"size,omitempty" example:"28"`
+	Bold   bool `json:"bold,omitempty" example:"true"`
+	Italic bool `json:"italic,omitempty" example:"false"`
+	// Mono prints in Go Mono, which has no italic.
+	Mono bool `json:"mono,omitempty" example:"false"`
+}
+
+// Table is the content of a table block: an optional bold header row and
+// rows of cells.
+type Table struct {
+	Header []string   `json:"header,omitempty" example:"Item,Qty,Price"`
+	Rows   [][]string `json:"rows"`
+	// Columns sets the width and alignment of each column. Without it the
+	// columns share the width equally and align left.
+	Columns []TableColumn `json:"columns,omitempty"`
+	// Padding is blank space in dots around the text of every cell.
+	Padding int `json:"padding,omitempty" example:"4"`
+	// Borders draws lines around every cell.
+	Borders bool `json:"borders,omitempty" example:"false"`
+}
+
+// TableColumn sets the width and alignment of a table column. Width and
+// Ratio are exclusive; a column with neither takes one share.
+type TableColumn struct {
+	// Width is a fixed width in dots.
+	Width int `json:"width,omitempty" example:"96"`
+	// Ratio is the column's share of the width left over by fixed columns.
+	Ratio int `json:"ratio,omitempty" example:"3"`
+	// Align places the text of the column's cells.
+	Align render.Align `json:"align,omitempty" example:"right" enums:"left,center,right"`
+}
+
+// Column is one column of a columns block. Width and Ratio size it like a
+// TableColumn.
+type Column struct {
+	Width  int     `json:"width,omitempty" example:"128"`
+	Ratio  int     `json:"ratio,omitempty" example:"2"`
+	Blocks []Block `json:"blocks"`
+}
+
+// options returns how the table is laid out width dots wide.
+func (t Table) options(width int) render.TableOptions {
+	opts := render.TableOptions{
+		Width:   width,
+		Gap:     columnGap,
+		Padding: t.Padding,
+		Borders: t.Borders,
+	}
+	for _, c := range t.Columns {
+		opts.Columns = append(opts.Columns, render.ColumnSpec{Width: c.Width, Ratio: c.Ratio, Align: c.Align})
+	}
+	return opts
+}
+
+// columnWidths returns the widths of the columns of a columns block laid
+// out width dots wide.
+func columnWidths(columns []Column, width int) ([]int, error) {
+	specs := make([]render.ColumnSpec, len(columns))
+	for i, c := range columns {
+		specs[i] = render.ColumnSpec{Width: c.Width, Ratio: c.Ratio}
+	}
+	return render.ResolveColumns(width, columnGap, specs)
+}
+
+// Validate checks that every block can be laid out on the head.
+func (d Document) Validate() error {
+	if len(d.Blocks) == 0 {
+		return &ValidationError{Message: "document needs at least one block"}
+	}
+	count := 0
+	for i, b := range d.Blocks {
+		if err := b.validate(render.HeadWidth, 0, &count); err != nil {
+			return &ValidationError{Message: fmt.Sprintf("block %d: %s", i+1, err.Error())}
+		}
+	}
+	return nil
+}
+
+// validate checks a block laid out width dots wide, depth columns blocks
+// deep. count is the number of blocks checked so far.
+func (b Block) validate(width, depth int, count *int) error {
+	if *count++; *count > MaxDocumentBlocks {
+		return fmt.Errorf("document has more than %d blocks", MaxDocumentBlocks)
+	}
+	if err := b.checkFields(); err != nil {
+		return err
+	}
+	if _, err := render.ParseAlign(string(b.Align)); err != nil {
+		return err
+	}
+
+	switch b.Type {
+	case BlockText:
+		if b.Text == "" {
+			return fmt.Errorf("text block needs text")
+		}
+		if b.Style != nil && b.Style.Size != 0 && (b.Style.Size < MinTextSize || b.Style.Size > MaxTextSize) {
+			return fmt.Errorf("text size must be between %d and %d pixels", MinTextSize, MaxTextSize)
+		}
+	case BlockImage:
+		if len(b.Image) == 0 {
+			return fmt.Errorf("image block needs an image")
+		}
+		if _, err := render.ParseDither(string(b.Dither)); err != nil {
+			return err
+		}
+		if b.Width < 0 || b.Width > width {
+			return fmt.Errorf("image width must be between 0 and %d dots", width)
+		}
+		size := width
+		if b.Width > 0 {
+			size = b.Width
+		}
+		if err := render.CheckImageFits(b.Image, size); err != nil {
+			return err
+		}
+	case BlockQR:
+		if b.QR == nil {
+			return fmt.Errorf("qr block needs a qr code")
+		}
+		if _, err := b.QR.encode(width); err != nil {
+			return err
+		}
+	case BlockBarcode:
+		if b.Barcode == nil {
+			return fmt.Errorf("barcode block needs a barcode")
+		}
+		if _, err := b.Barcode.encode(width); err != nil {
+			return err
+		}
+	case BlockDivider:
+	case BlockSpacer:
+		if b.Height <= 0 || b.Height > MaxMargin {
+			return fmt.Errorf("spacer height must be between 1 and %d dot lines", MaxMargin)
+		}
+	case BlockTable:
+		if b.Table == nil || len(b.Table.Header) == 0 && len(b.Table.Rows) == 0 {
+			return fmt.Errorf("table block needs rows")
+		}
+		columns, cells := max(len(b.Table.Header), len(b.Table.Columns)), len(b.Table.Header)
+		for _, row := range b.Table.Rows {
+			columns = max(columns, len(row))
+			cells += len(row)
+		}
+		if cells > MaxTableCells {
+			return fmt.Errorf("table has more than %d cells", MaxTableCells)
+		}
+		if len(b.Table.Columns) > 0 && columns > len(b.Table.Columns) {
+			return fmt.Errorf("table has rows of %d cells but only %d columns", columns, len(b.Table.Columns))
+		}
+		for i, c := range b.Table.Columns {
+			if _, err := render.ParseAlign(string(c.Align)); err != nil {
+				return fmt.Errorf("column %d: %w", i+1, err)
+			}
+		}
+		if b.Table.Padding < 0 || b.Table.Padding > MaxCellPadding {
+			return fmt.Errorf("cell padding must be between 0 and %d dots", MaxCellPadding)
+		}
+		if _, err := b.Table.options(width).ColumnWidths(columns); err != nil {
+			return err
+		}
+	case BlockColumns:
+		if len(b.Columns) == 0 {
+			return fmt.Errorf("columns block needs columns")
+		}
+		if depth >= MaxColumnDepth {
+			return fmt.Errorf("columns blocks cannot be nested more than %d deep", MaxColumnDepth)
+		}
+		widths, err := columnWidths(b.Columns, width)
+		if err != nil {
+			return err
+		}
+		for i, col := range b.Columns {
+			if len(col.Blocks) == 0 {
+				return fmt.Errorf("column %d needs at least one block", i+1)
+			}
+			for j, child := range col.Blocks {
+				if err := child.validate(widths[i], depth+1, count); err != nil {
+					return fmt.Errorf("column %d, block %d: %w", i+1, j+1, err)
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("unknown block type: %q", b.Type)
+	}
+	return nil
+}
+
+// checkFields checks that a block only sets the fields of its type.
+func (b Block) checkFields() error {
+	fields := []struct {
+		name  string
+		set   bool
+		owner BlockType
+	}{
+		{name: "text", set: b.Text != "", owner: BlockText},
+		{name: "style", set: b.Style != nil, owner: BlockText},
+		{name: "image", set: len(b.Image) > 0, owner: BlockImage},
+		{name: "dither", set: b.Dither != "", owner: BlockImage},
+		{name: "width", set: b.Width != 0, owner: BlockImage},
+		{name: "qr", set: b.QR != nil, owner: BlockQR},
+		{name: "barcode", set: b.Barcode != nil, owner: BlockBarcode},
+		{name: "dashed", set: b.Dashed, owner: BlockDivider},
+		{name: "height", set: b.Height != 0, owner: BlockSpacer},
+		{name: "table", set: b.Table != nil, owner: BlockTable},
+		{name: "columns", set: len(b.Columns) > 0, owner: BlockColumns},
+	}
+	for _, f := range fields {
+		if f.set && b.Type != f.owner {
+			return fmt.Errorf("%s can only be set on %s blocks", f.name, f.owner)
+		}
+	}
+	if b.Align != "" && b.Type != BlockText && b.Type != BlockImage {
+		return fmt.Errorf("align can only be set on text and image blocks")
+	}
+	return nil
+}
+
+// renderDocument lays out a validated document across the head.
+func renderDocument(d Document) (*render.Bitmap, error) {
+	bitmap, err := renderBlocks(d.Blocks, render.HeadWidth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render document: %w", err)
+	}
+	return bitmap, nil
+}
+
+// renderBlocks stacks blocks laid out width dots wide.
+func renderBlocks(blocks []Block, width int) (*render.Bitmap, error) {
+	parts := make([]*render.Bitmap, 0, len(blocks)+1)
+	parts = append(parts, render.NewBitmap(width, 0))
+	for _, b := range blocks {
+		bmp, err := b.render(width)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, bmp)
+	}
+	return render.Stack(parts...), nil
+}
+
+// render lays out one block width dots wide.
+func (b Block) render(width int) (*render.Bitmap, error) {
+	switch b.Type {
+	case BlockText:
+		var style TextStyle
+		if b.Style != nil {
+			style = *b.Style
+		}
+		return render.RenderParagraph(b.Text, render.ParagraphOptions{
+			Width:  width,
+			Size:   float64(style.Size),
+			Bold:   style.Bold,
+			Italic: style.Italic,
+			Mono:   style.Mono,
+			Align:  b.Align,
+		})
+
+	case BlockImage:
+		img, err := render.DecodeImage(b.Image)
+		if err != nil {
+			return nil, err
+		}
+		size := width
+		if b.Width > 0 {
+			size = b.Width
+		}
+		bmp, err := render.RenderImage(img, render.ImageOptions{Width: size, Dither: b.Dither})
+		if err != nil {
+			return nil, err
+		}
+		out := render.NewBitmap(width, bmp.Height)
+		out.Draw(bmp, b.Align.Offset(bmp.Width, width), 0)
+		return out, nil
+
+	case BlockQR:
+		return renderQR(*b.QR, width)
+
+	case BlockBarcode:
+		return renderBarcode(*b.Barcode, width)
+
+	case BlockDivider:
+		line := render.DashedLine(width, dividerThickness, width, 0)
+		if b.Dashed {
+			line = render.DashedLine(width, dividerThickness, tearLineDash, tearLineSpace)
+		}
+		return render.Stack(render.NewBitmap(width, dividerGap), line, render.NewBitmap(width, dividerGap)), nil
+
+	case BlockSpacer:
+		return render.NewBitmap(width, b.Height), nil
+
+	case BlockTable:
+		return render.RenderTable(b.Table.Header, b.Table.Rows, b.Table.options(width))
+
+	case BlockColumns:
+		widths, err := columnWidths(b.Columns, width)
+		if err != nil {
+			return nil, err
+		}
+		parts := make([]*render.Bitmap, len(b.Columns))
+		for i, col := range b.Columns {
+			if parts[i], err = renderBlocks(col.Blocks, widths[i]); err != nil {
+				return nil, err
+			}
+		}
+		return render.Beside(width, 0, columnGap, widths, parts), nil
+	}
+	return nil, fmt.Errorf("unknown block type: %q", b.Type)
+}
@@ -0,0 +1,241 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/princem/peripage-printer/internal/core/mocks"
+	"github.com/princem/peripage-printer/internal/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// blackPNG encodes a black PNG image of the given size.
+func blackPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func TestDocument_Validate(t *testing.T) {
+	logo := blackPNG(t, 192, 48)
+	spacer := Block{Type: BlockSpacer, Height: 8}
+	nest := func(b Block) Block {
+		return Block{Type: BlockColumns, Columns: []Column{{Blocks: []Block{b}}}}
+	}
+
+	tests := []struct {
+		name    string
+		doc     Document
+		wantErr string
+	}{
+		{
+			name: "receipt",
+			doc: Document{Blocks: []Block{
+				{Type: BlockImage, Image: logo, Width: 192, Align: render.AlignCenter},
+				{Type: BlockText, Text: "Corner Café", Style: &TextStyle{Size: 28, Bold: true}, Align: render.AlignCenter},
+				{Type: BlockDivider, Dashed: true},
+				{Type: BlockTable, Table: &Table{Header: []string{"Item", "Qty", "Price"}, Rows: [][]string{{"Tea", "2", "5.00"}}}},
+				{Type: BlockColumns, Columns: []Column{
+					{Blocks: []Block{{Type: BlockText, Text: "Scan to pay"}}},
+					{Blocks: []Block{{Type: BlockQR, QR: &QRCode{Data: "https://example.com/pay"}}}},
+				}},
+				{Type: BlockBarcode, Barcode: &Barcode{Data: "ORDER-1042"}},
+				spacer,
+			}},
+		},
+		{name: "no blocks", doc: Document{}, wantErr: "at least one block"},
+		{name: "unknown type", doc: Document{Blocks: []Block{{Type: "video"}}}, wantErr: `block 1: unknown block type: "video"`},
+		{name: "empty text", doc: Document{Blocks: []Block{{Type: BlockText}}}, wantErr: "text block needs text"},
+		{name: "text size", doc: Document{Blocks: []Block{{Type: BlockText, Text: "x", Style: &TextStyle{Size: 200}}}}, wantErr: "text size must be between 8 and 96"},
+		{name: "unknown alignment", doc: Document{Blocks: []Block{{Type: BlockText, Text: "x", Align: "middle"}}}, wantErr: "unknown alignment"},
+		{name: "field of another type", doc: Document{Blocks: []Block{spacer, {Type: BlockText, Text: "x", Height: 10}}}, wantErr: "block 2: height can only be set on spacer blocks"},
+		{name: "align on a divider", doc: Document{Blocks: []Block{{Type: BlockDivider, Align: render.AlignCenter}}}, wantErr: "align can only be set on text and image blocks"},
+		{name: "missing image", doc: Document{Blocks: []Block{{Type: BlockImage}}}, wantErr: "image block needs an image"},
+		{name: "invalid image", doc: Document{Blocks: []Block{{Type: BlockImage, Image: []byte("nope")}}}, wantErr: "unsupported image"},
+		{name: "image too tall once scaled", doc: Document{Blocks: []Block{{Type: BlockImage, Image: blackPNG(t, 10, 1000)}}}, wantErr: "image is too tall"},
+		{name: "image too tall for its width", doc: Document{Blocks: []Block{{Type: BlockImage, Image: blackPNG(t, 10, 1000), Width: 250}}}, wantErr: "image is too tall"},
+		{name: "image too tall for its column", doc: Document{Blocks: []Block{{Type: BlockColumns, Columns: []Column{{Blocks: []Block{{Type: BlockImage, Image: blackPNG(t, 10, 1200)}}}, {Blocks: []Block{{Type: BlockDivider}}}}}}}, wantErr: "image is too tall"},
+		{name: "image wider than the paper", doc: Document{Blocks: []Block{{Type: BlockImage, Image: logo, Width: 400}}}, wantErr: "image width must be between 0 and 384"},
+		{name: "missing qr", doc: Document{Blocks: []Block{{Type: BlockQR}}}, wantErr: "qr block needs a qr code"},
+		{name: "invalid barcode", doc: Document{Blocks: []Block{{Type: BlockBarcode, Barcode: &Barcode{Type: "ean13", Data: "123"}}}}, wantErr: "ean13 takes 12 digits"},
+		{name: "spacer height", doc: Document{Blocks: []Block{{Type: BlockSpacer}}}, wantErr: "spacer height must be between 1"},
+		{name: "empty table", doc: Document{Blocks: []Block{{Type: BlockTable, Table: &Table{}}}}, wantErr: "table block needs rows"},
+		{name: "too many table columns", doc: Document{Blocks: []Block{{Type: BlockTable, Table: &Table{Rows: [][]string{make([]string, 40)}}}}}, wantErr: "40 columns do not fit"},
+		{name: "row wider than the columns", doc: Document{Blocks: []Block{{Type: BlockTable, Table: &Table{Rows: [][]string{{"a", "b", "c"}}, Columns: []TableColumn{{}, {}}}}}}, wantErr: "rows of 3 cells but only 2 columns"},
+		{name: "table column alignment", doc: Document{Blocks: []Block{{Type: BlockTable, Table: &Table{Rows: [][]string{{"a"}}, Columns: []TableColumn{{Align: "middle"}}}}}}, wantErr: "column 1: unknown alignment"},
+		{name: "table column width and ratio", doc: Document{Blocks: []Block{{Type: BlockTable, Table: &Table{Rows: [][]string{{"a"}}, Columns: []TableColumn{{Width: 80, Ratio: 2}}}}}}, wantErr: "either a width or a ratio"},
+		{name: "fixed table columns too wide", doc: Document{Blocks: []Block{{Type: BlockTable, Table: &Table{Rows: [][]string{{"a", "b"}}, Columns: []TableColumn{{Width: 200}, {Width: 200}}}}}}, wantErr: "2 columns do not fit"},
+		{name: "cell padding", doc: Document{Blocks: []Block{{Type: BlockTable, Table: &Table{Rows: [][]string{{"a"}}, Padding: 100}}}}, wantErr: "cell padding must be between 0 and 40"},
+		{name: "padding fills a column", doc: Document{Blocks: []Block{{Type: BlockTable, Table: &Table{Rows: [][]string{{"a", "b"}}, Padding: 10, Columns: []TableColumn{{}, {Width: 20}}}}}}, wantErr: "padding 10 leaves no room for text in column 2"},
+		{name: "empty columns", doc: Document{Blocks: []Block{{Type: BlockColumns}}}, wantErr: "columns block needs columns"},
+		{name: "column without blocks", doc: Document{Blocks: []Block{{Type: BlockColumns, Columns: []Column{{Blocks: []Block{spacer}}, {}}}}}, wantErr: "block 1: column 2 needs at least one block"},
+		{name: "fixed columns too wide", doc: Document{Blocks: []Block{{Type: BlockColumns, Columns: []Column{{Width: 370}, {}}}}}, wantErr: "2 columns do not fit"},
+		{
+			name:    "qr too large for its column",
+			doc:     Document{Blocks: []Block{{Type: BlockColumns, Columns: []Column{{Blocks: []Block{spacer}}, {Blocks: []Block{{Type: BlockQR, QR: &QRCode{Data: "x", Scale: 13}}}}}}}},
+			wantErr: "block 1: column 2, block 1: qr scale 13 is too large",
+		},
+		{name: "columns nested too deep", doc: Document{Blocks: []Block{nest(nest(nest(nest(spacer))))}}, wantErr: "cannot be nested more than 3 deep"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := tt.doc.Validate()
+
+			// Assert
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestDocument_ValidateBlockCount(t *testing.T) {
+	// Arrange
+	blocks := make([]Block, MaxDocumentBlocks+1)
+	for i := range blocks {
+		blocks[i] = Block{Type: BlockSpacer, Height: 1}
+	}
+
+	// Act
+	err := Document{Blocks: blocks}.Validate()
+
+	// Assert
+	assert.ErrorContains(t, err, "more than 200 blocks")
+	assert.NoError(t, Document{Blocks: blocks[1:]}.Validate())
+}
+
+func TestDocument_ValidateTableCells(t *testing.T) {
+	// Arrange
+	rows := make([][]string, MaxTableCells/2+1)
+	for i := range rows {
+		rows[i] = []string{"a", "b"}
+	}
+
+	// Act
+	err := Document{Blocks: []Block{{Type: BlockTable, Table: &Table{Rows: rows}}}}.Validate()
+
+	// Assert
+	assert.ErrorContains(t, err, "more than 1000 cells")
+}
+
+func TestRasterize_Document(t *testing.T) {
+	line, err := render.RenderParagraph("x", render.ParagraphOptions{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		blocks         []Block
+		expectedHeight int
+	}{
+		{name: "spacer", blocks: []Block{{Type: BlockSpacer, Height: 40}}, expectedHeight: 40},
+		{name: "blocks are stacked", blocks: []Block{{Type: BlockSpacer, Height: 40}, {Type: BlockDivider}}, expectedHeight: 40 + 2*dividerGap + dividerThickness},
+		{
+			name: "columns are as tall as the tallest",
+			blocks: []Block{{Type: BlockColumns, Columns: []Column{
+				{Blocks: []Block{{Type: BlockSpacer, Height: 30}}},
+				{Blocks: []Block{{Type: BlockSpacer, Height: 20}, {Type: BlockSpacer, Height: 30}}},
+				{Blocks: []Block{{Type: BlockSpacer, Height: 10}}},
+			}}},
+			expectedHeight: 50,
+		},
+		{name: "image keeps its aspect ratio", blocks: []Block{{Type: BlockImage, Image: blackPNG(t, 192, 48), Width: 96}}, expectedHeight: 24},
+		{
+			name: "bordered table",
+			blocks: []Block{{Type: BlockTable, Table: &Table{
+				Rows:    [][]string{{"Tea", "5.00"}},
+				Columns: []TableColumn{{Ratio: 3}, {Width: 96, Align: render.AlignRight}},
+				Padding: 4,
+				Borders: true,
+			}}},
+			expectedHeight: line.Height + 2*4 + 2*2,
+		},
+		{name: "qr in a column", blocks: []Block{{Type: BlockColumns, Columns: []Column{{Blocks: []Block{{Type: BlockSpacer, Height: 8}}}, {Blocks: []Block{{Type: BlockQR, QR: &QRCode{Data: "HELLO"}}}}}}}, expectedHeight: 29 * 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			bitmap, err := Rasterize(JobRequest{Kind: JobDocument, Document: &Document{Blocks: tt.blocks}})
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, render.HeadWidth, bitmap.Width)
+			assert.Equal(t, tt.expectedHeight, bitmap.Height)
+		})
+	}
+}
+
+func TestRasterize_DocumentLayout(t *testing.T) {
+	// Arrange: a centred half-width image above a dashed divider.
+	req := JobRequest{Kind: JobDocument, Document: &Document{Blocks: []Block{
+		{Type: BlockImage, Image: blackPNG(t, 192, 48), Width: 192, Align: render.AlignCenter},
+		{Type: BlockDivider, Dashed: true},
+	}}}
+
+	// Act
+	bitmap, err := Rasterize(req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, bitmap.Black(95, 0))
+	assert.True(t, bitmap.Black(96, 0))
+	assert.True(t, bitmap.Black(287, 0))
+	assert.False(t, bitmap.Black(288, 0))
+	line := 48 + dividerGap
+	assert.True(t, bitmap.Black(0, line))
+	assert.False(t, bitmap.Black(tearLineDash, line))
+}
+
+func TestRasterize_DocumentColumnWidths(t *testing.T) {
+	// Arrange: a fixed-width image column right of a text column.
+	req := JobRequest{Kind: JobDocument, Document: &Document{Blocks: []Block{
+		{Type: BlockColumns, Columns: []Column{
+			{Blocks: []Block{{Type: BlockSpacer, Height: 48}}},
+			{Width: 96, Blocks: []Block{{Type: BlockImage, Image: blackPNG(t, 96, 48)}}},
+		}},
+	}}}
+
+	// Act
+	bitmap, err := Rasterize(req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, bitmap.Black(render.HeadWidth-97, 0))
+	assert.True(t, bitmap.Black(render.HeadWidth-96, 0))
+	assert.True(t, bitmap.Black(render.HeadWidth-1, 47))
+}
+
+func TestPrintService_Document(t *testing.T) {
+	// Arrange
+	req := JobRequest{Kind: JobDocument, Document: &Document{Blocks: []Block{
+		{Type: BlockText, Text: "Order 1042", Style: &TextStyle{Bold: true}},
+		{Type: BlockQR, QR: &QRCode{Data: "https://example.com/orders/1042", Scale: 4}},
+	}}}
+	expected, err := Rasterize(req)
+	require.NoError(t, err)
+
+	mockPrinter := new(mocks.MockPrinter)
+	mockPrinter.On("PrintImage", expected).Return(nil).Once()
+	service := NewPrintService(mockPrinter)
+	defer service.Close()
+
+	// Act
+	_, err = service.Print(context.Background(), req)
+
+	// Assert
+	require.NoError(t, err)
+	mockPrinter.AssertExpectations(t)
+	mockPrinter.AssertNotCalled(t, "PrintText", mock.Anything)
+}
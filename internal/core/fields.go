@@ -0,0 +1,217 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pathStep is one step of a field path: an object key, an array index, or
+// a wildcard matching every field or element.
+type pathStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parseFieldPath parses a JSONPath-like field path such as
+// "customer.name", "items[0]", "items[*].price" or "$.total". "*" after a
+// dot matches every field of an object and "[*]" every element of an array.
+// "$" on its own selects the whole document.
+func parseFieldPath(path string) ([]pathStep, error) {
+	rest := strings.TrimSpace(path)
+	if rest == "" {
+		return nil, fmt.Errorf("field path cannot be empty")
+	}
+	if strings.HasPrefix(rest, "$") {
+		rest = strings.TrimPrefix(rest[1:], ".")
+	}
+
+	var steps []pathStep
+	for rest != "" {
+		if strings.HasPrefix(rest, "[") {
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid field path %q: missing ]", path)
+			}
+			inner := rest[1:end]
+			rest = strings.TrimPrefix(rest[end+1:], ".")
+			if inner == "*" {
+				steps = append(steps, pathStep{isIndex: true, wildcard: true})
+				continue
+			}
+			index, err := strconv.Atoi(inner)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid field path %q: %q is not an array index", path, inner)
+			}
+			steps = append(steps, pathStep{isIndex: true, index: index})
+			continue
+		}
+
+		end := strings.IndexAny(rest, ".[")
+		if end < 0 {
+			end = len(rest)
+		}
+		key := rest[:end]
+		if key == "" {
+			return nil, fmt.Errorf("invalid field path %q: empty field name", path)
+		}
+		rest = rest[end:]
+		if strings.HasPrefix(rest, ".") {
+			rest = rest[1:]
+			if rest == "" {
+				return nil, fmt.Errorf("invalid field path %q: trailing dot", path)
+			}
+		}
+		steps = append(steps, pathStep{key: key, wildcard: key == "*"})
+	}
+	return steps, nil
+}
+
+// fieldMask records which parts of a document were selected.
+type fieldMask struct {
+	// all selects the value with everything below it.
+	all    bool
+	fields map[string]*fieldMask
+	items  map[int]*fieldMask
+}
+
+// mark selects the values steps lead to in value. It reports whether
+// anything matched; masks are only added for values that did, so paths
+// that match partway leave no empty objects behind.
+func (m *fieldMask) mark(value interface{}, steps []pathStep) bool {
+	if len(steps) == 0 {
+		m.all = true
+		return true
+	}
+	step, rest := steps[0], steps[1:]
+
+	matched := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if step.isIndex {
+			return false
+		}
+		for key, child := range v {
+			if step.wildcard || key == step.key {
+				matched = m.markField(key, child, rest) || matched
+			}
+		}
+
+	case []interface{}:
+		if !step.isIndex {
+			return false
+		}
+		for i, child := range v {
+			if step.wildcard || i == step.index {
+				matched = m.markItem(i, child, rest) || matched
+			}
+		}
+	}
+	return matched
+}
+
+func (m *fieldMask) markField(key string, child interface{}, steps []pathStep) bool {
+	sub, ok := m.fields[key]
+	if !ok {
+		sub = &fieldMask{}
+	}
+	if !sub.mark(child, steps) {
+		return false
+	}
+	if m.fields == nil {
+		m.fields = make(map[string]*fieldMask)
+	}
+	m.fields[key] = sub
+	return true
+}
+
+func (m *fieldMask) markItem(index int, child interface{}, steps []pathStep) bool {
+	sub, ok := m.items[index]
+	if !ok {
+		sub = &fieldMask{}
+	}
+	if !sub.mark(child, steps) {
+		return false
+	}
+	if m.items == nil {
+		m.items = make(map[int]*fieldMask)
+	}
+	m.items[index] = sub
+	return true
+}
+
+// apply copies the selected parts of value. Selected array elements keep
+// their order and close up the gaps between them.
+func (m *fieldMask) apply(value interface{}) interface{} {
+	if m.all {
+		return value
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(m.fields))
+		for key, sub := range m.fields {
+			out[key] = sub.apply(v[key])
+		}
+		return out
+
+	case []interface{}:
+		indexes := make([]int, 0, len(m.items))
+		for i := range m.items {
+			indexes = append(indexes, i)
+		}
+		sort.Ints(indexes)
+		out := make([]interface{}, 0, len(indexes))
+		for _, i := range indexes {
+			out = append(out, m.items[i].apply(v[i]))
+		}
+		return out
+	}
+	return value
+}
+
+// selectFields returns the parts of data the field paths select, keeping
+// the structure around them, so "items[*].name" keeps the items array with
+// only the name of each item. Paths that match nothing are skipped, but at
+// least one path has to match.
+func selectFields(data interface{}, paths []string) (interface{}, error) {
+	value, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	mask := &fieldMask{}
+	matched := false
+	for _, path := range paths {
+		steps, err := parseFieldPath(path)
+		if err != nil {
+			return nil, err
+		}
+		matched = mask.mark(value, steps) || matched
+	}
+	if !matched {
+		return nil, fmt.Errorf("fields %s matched nothing in the data", strings.Join(paths, ", "))
+	}
+	return mask.apply(value), nil
+}
+
+// decodeJSON converts data to the values encoding/json decodes into, so
+// maps and slices of any type can be walked the same way. Numbers keep the
+// digits they were written with.
+func decodeJSON(data interface{}) (interface{}, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON: %w", err)
+	}
+	return value, nil
+}
@@ -0,0 +1,114 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFieldPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		expected []pathStep
+		wantErr  bool
+	}{
+		{name: "field", path: "total", expected: []pathStep{{key: "total"}}},
+		{name: "nested field", path: "customer.name", expected: []pathStep{{key: "customer"}, {key: "name"}}},
+		{name: "root prefix", path: "$.total", expected: []pathStep{{key: "total"}}},
+		{name: "whole document", path: "$", expected: nil},
+		{
+			name:     "index and wildcards",
+			path:     "items[2].*[*]",
+			expected: []pathStep{{key: "items"}, {isIndex: true, index: 2}, {key: "*", wildcard: true}, {isIndex: true, wildcard: true}},
+		},
+		{name: "empty", path: " ", wantErr: true},
+		{name: "empty field name", path: "a..b", wantErr: true},
+		{name: "trailing dot", path: "a.", wantErr: true},
+		{name: "missing bracket", path: "items[0", wantErr: true},
+		{name: "negative index", path: "items[-1]", wantErr: true},
+		{name: "named index", path: "items[name]", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps, err := parseFieldPath(tt.path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, steps)
+		})
+	}
+}
+
+func TestSelectFields(t *testing.T) {
+	const order = `{
+		"id": 42,
+		"customer": {"name": "Jane", "email": "jane@example.com"},
+		"items": [
+			{"name": "Coffee", "qty": 2, "tags": ["hot"]},
+			{"name": "Milk", "qty": 1},
+			{"name": "Tea", "qty": 3}
+		]
+	}`
+
+	tests := []struct {
+		name     string
+		paths    []string
+		expected string
+		wantErr  bool
+	}{
+		{name: "field", paths: []string{"id"}, expected: `{"id": 42}`},
+		{name: "nested field", paths: []string{"customer.name"}, expected: `{"customer": {"name": "Jane"}}`},
+		{
+			name:     "field of every element",
+			paths:    []string{"items[*].name"},
+			expected: `{"items": [{"name": "Coffee"}, {"name": "Milk"}, {"name": "Tea"}]}`,
+		},
+		{
+			name:     "paths are merged",
+			paths:    []string{"items[2].qty", "items[0].name", "items[2].name"},
+			expected: `{"items": [{"name": "Coffee"}, {"name": "Tea", "qty": 3}]}`,
+		},
+		{
+			name:     "elements without the field are dropped",
+			paths:    []string{"items[*].tags"},
+			expected: `{"items": [{"tags": ["hot"]}]}`,
+		},
+		{name: "every field", paths: []string{"customer.*"}, expected: `{"customer": {"name": "Jane", "email": "jane@example.com"}}`},
+		{name: "unmatched paths are skipped", paths: []string{"id", "total", "items[9]"}, expected: `{"id": 42}`},
+		{name: "nothing matches", paths: []string{"total", "customer[0]"}, wantErr: true},
+		{name: "invalid path", paths: []string{"items["}, wantErr: true},
+	}
+
+	var data interface{}
+	require.NoError(t, json.Unmarshal([]byte(order), &data))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			selected, err := selectFields(data, tt.paths)
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			actual, err := json.Marshal(selected)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(actual))
+		})
+	}
+}
+
+func TestSelectFields_KeepsNumbers(t *testing.T) {
+	selected, err := selectFields(map[string]interface{}{"id": int64(9007199254740993)}, []string{"id"})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"id": json.Number("9007199254740993")}, selected)
+}
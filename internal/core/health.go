@@ -0,0 +1,150 @@
+package core
+
+import "time"
+
+// Readiness describes whether the print service can accept work.
+// The top-level fields describe the default printer.
+type Readiness struct {
+	Ready bool `json:"ready" example:"true"`
+	// Reason explains why the service is not ready.
+	Reason     string          `json:"reason,omitempty" example:"printer is connecting"`
+	Connection ConnectionState `json:"connection" example:"connected"`
+	// ConnectionError is why the last connection attempt failed.
+	ConnectionError string `json:"connection_error,omitempty" example:"device not found within timeout"`
+	// QueueDepth is the number of jobs that are queued or printing.
+	QueueDepth  int        `json:"queue_depth" example:"0"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	// LastError and LastErrorAt describe the most recent failed job.
+	LastError   string     `json:"last_error,omitempty" example:"printer is out of paper"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+	// Printers holds the readiness of every printer when more than one is
+	// configured.
+	Printers map[string]Readiness `json:"printers,omitempty"`
+	// Pools holds the readiness of every printer pool.
+	Pools map[string]Readiness `json:"pools,omitempty"`
+}
+
+// Readiness reports whether the printers can accept work, together with the
+// queue depth and the outcome of recent jobs. The service is not ready while
+// any printer is disconnected or reports a condition that stops it printing,
+// such as paper out or an open lid, while a pool has no ready member or after
+// the queues were closed.
+func (s *PrintService) Readiness() Readiness {
+	names := s.registry.Names()
+	pools := s.registry.Pools()
+	if len(names) == 1 && len(pools) == 0 {
+		return s.printerReadiness(names[0])
+	}
+
+	var r Readiness
+	if _, ok := s.pools[s.registry.Default()]; ok {
+		r = s.poolReadiness(s.registry.Default())
+	} else {
+		r = s.printerReadiness(s.registry.Default())
+	}
+	// Start over from ready so every reason names its printer or pool
+	r.Ready, r.Reason = true, ""
+	merge := func(name string, target Readiness) {
+		if !target.Ready && r.Ready {
+			r.Ready = false
+			r.Reason = name + ": " + target.Reason
+		}
+	}
+
+	r.Printers = make(map[string]Readiness, len(names))
+	for _, name := range names {
+		r.Printers[name] = s.printerReadiness(name)
+		merge(name, r.Printers[name])
+	}
+	if len(pools) > 0 {
+		r.Pools = make(map[string]Readiness, len(pools))
+		for _, name := range pools {
+			r.Pools[name] = s.poolReadiness(name)
+			merge(name, r.Pools[name])
+		}
+	}
+	return r
+}
+
+// printerReadiness reports the readiness of a single printer.
+func (s *PrintService) printerReadiness(name string) Readiness {
+	lane := s.lanes[name]
+	stats := lane.queue.Stats()
+	printer := lane.printer
+
+	r := queueReadiness(stats)
+	r.Connection = connectionState(printer)
+	r.QueueDepth = lane.load()
+	if reporter, ok := printer.(ConnectionReporter); ok {
+		if err := reporter.LastError(); err != nil {
+			r.ConnectionError = err.Error()
+		}
+	}
+
+	switch {
+	case stats.Closed:
+		r.Reason = ErrQueueClosed.Error()
+	case r.Connection != ConnectionConnected:
+		r.Reason = "printer is " + string(r.Connection)
+	default:
+		if err := lane.condition(); err != nil {
+			r.Reason = err.Error()
+		} else {
+			r.Ready = true
+		}
+	}
+	return r
+}
+
+// poolReadiness reports the readiness of a pool. A pool is ready while any
+// member is connected and can print; its connection is the best one among
+// the members.
+func (s *PrintService) poolReadiness(name string) Readiness {
+	pool := s.pools[name]
+	stats := pool.queue.Stats()
+
+	r := queueReadiness(stats)
+	r.Connection = ConnectionDisconnected
+	healthy := false
+	for _, member := range pool.members {
+		state := connectionState(member.printer)
+		if state == ConnectionConnected || (state == ConnectionConnecting && r.Connection == ConnectionDisconnected) {
+			r.Connection = state
+		}
+		healthy = healthy || (member.healthy() && member.condition() == nil)
+	}
+
+	switch {
+	case stats.Closed:
+		r.Reason = ErrQueueClosed.Error()
+	case !healthy:
+		r.Reason = "no pool member is ready"
+	default:
+		r.Ready = true
+	}
+	return r
+}
+
+// queueReadiness fills in the queue depth and recent job outcomes.
+func queueReadiness(stats QueueStats) Readiness {
+	r := Readiness{
+		QueueDepth: stats.Depth,
+		LastError:  stats.LastError,
+	}
+	if !stats.LastSuccess.IsZero() {
+		r.LastSuccess = &stats.LastSuccess
+	}
+	if !stats.LastFailure.IsZero() {
+		r.LastErrorAt = &stats.LastFailure
+	}
+	return r
+}
+
+// connectionState returns the connection state of printer. Printers that do
+// not report one are always connected.
+func connectionState(printer Printer) ConnectionState {
+	if reporter, ok := printer.(ConnectionReporter); ok {
+		return reporter.State()
+	}
+	return ConnectionConnected
+}
@@ -0,0 +1,217 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/princem/peripage-printer/internal/core/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// connectionPrinter is a mock printer that reports a connection state.
+type connectionPrinter struct {
+	mocks.MockPrinter
+	state ConnectionState
+	err   error
+}
+
+func (p *connectionPrinter) State() ConnectionState { return p.state }
+
+func (p *connectionPrinter) LastError() error { return p.err }
+
+func TestPrintService_Readiness(t *testing.T) {
+	tests := []struct {
+		name            string
+		printer         Printer
+		expectedReady   bool
+		expectedState   ConnectionState
+		expectedReason  string
+		expectedConnErr string
+	}{
+		{
+			name:          "printer without connection is always connected",
+			printer:       new(mocks.MockPrinter),
+			expectedReady: true,
+			expectedState: ConnectionConnected,
+		},
+		{
+			name:          "connected printer",
+			printer:       &connectionPrinter{state: ConnectionConnected},
+			expectedReady: true,
+			expectedState: ConnectionConnected,
+		},
+		{
+			name:            "printer still connecting",
+			printer:         &connectionPrinter{state: ConnectionConnecting, err: errors.New("device not found within timeout")},
+			expectedState:   ConnectionConnecting,
+			expectedReason:  "printer is connecting",
+			expectedConnErr: "device not found within timeout",
+		},
+		{
+			name:           "printer disconnected",
+			printer:        &connectionPrinter{state: ConnectionDisconnected},
+			expectedState:  ConnectionDisconnected,
+			expectedReason: "printer is disconnected",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service := NewPrintService(tt.printer)
+			defer service.Close()
+
+			// Act
+			r := service.Readiness()
+
+			// Assert
+			assert.Equal(t, tt.expectedReady, r.Ready)
+			assert.Equal(t, tt.expectedState, r.Connection)
+			assert.Equal(t, tt.expectedReason, r.Reason)
+			assert.Equal(t, tt.expectedConnErr, r.ConnectionError)
+			assert.Zero(t, r.QueueDepth)
+			assert.Nil(t, r.LastSuccess)
+			assert.Nil(t, r.LastErrorAt)
+		})
+	}
+}
+
+func TestPrintService_Readiness_RecentJobs(t *testing.T) {
+	// Arrange
+	mockPrinter := new(mocks.MockPrinter)
+	mockPrinter.On("PrintText", "ok").Return(nil)
+	mockPrinter.On("PrintText", "fail").Return(ErrPaperOut)
+	service := NewPrintService(mockPrinter)
+	defer service.Close()
+
+	// Act
+	require.NoError(t, service.PrintText("ok"))
+	require.Error(t, service.PrintText("fail"))
+	r := service.Readiness()
+
+	// Assert
+	assert.True(t, r.Ready)
+	require.NotNil(t, r.LastSuccess)
+	require.NotNil(t, r.LastErrorAt)
+	assert.False(t, r.LastErrorAt.Before(*r.LastSuccess))
+	assert.Equal(t, ErrPaperOut.Error(), r.LastError)
+}
+
+func TestPrintService_Readiness_Closed(t *testing.T) {
+	service := NewPrintService(new(mocks.MockPrinter))
+	service.Close()
+
+	r := service.Readiness()
+
+	assert.False(t, r.Ready)
+	assert.Equal(t, ErrQueueClosed.Error(), r.Reason)
+}
+
+// slowStatusPrinter is a mock printer whose status only arrives once
+// release is closed.
+type slowStatusPrinter struct {
+	statusPrinter
+	release chan struct{}
+}
+
+func (p *slowStatusPrinter) Status() (PrinterStatus, error) {
+	<-p.release
+	return p.statusPrinter.Status()
+}
+
+// statusChecked reports whether the default printer's condition is known.
+func statusChecked(service *PrintService) bool {
+	lane := service.lanes[DefaultPrinterName]
+	lane.statusMu.Lock()
+	defer lane.statusMu.Unlock()
+	return !lane.checkedAt.IsZero()
+}
+
+func TestPrintService_Readiness_PrinterCondition(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         PrinterStatus
+		err            error
+		expectedReason string
+	}{
+		{name: "paper loaded and lid closed", status: PrinterStatus{PaperPresent: true}},
+		{name: "out of paper", status: PrinterStatus{}, expectedReason: ErrPaperOut.Error()},
+		{name: "lid open", status: PrinterStatus{PaperPresent: true, LidOpen: true}, expectedReason: ErrLidOpen.Error()},
+		{name: "status unavailable", err: ErrStatusUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service := NewPrintService(&statusPrinter{status: tt.status, err: tt.err})
+			defer service.Close()
+
+			// Act: the first check asks the printer in the background
+			service.Readiness()
+			require.Eventually(t, func() bool { return statusChecked(service) }, time.Second, 5*time.Millisecond)
+			r := service.Readiness()
+
+			// Assert
+			assert.Equal(t, tt.expectedReason == "", r.Ready)
+			assert.Equal(t, tt.expectedReason, r.Reason)
+			assert.Equal(t, ConnectionConnected, r.Connection)
+		})
+	}
+}
+
+func TestPrintService_Readiness_DoesNotWaitForStatus(t *testing.T) {
+	// Arrange
+	printer := &slowStatusPrinter{release: make(chan struct{})}
+	service := NewPrintService(printer)
+	defer service.Close()
+
+	// Act & Assert: readiness answers from the cache while the printer is asked
+	assert.True(t, service.Readiness().Ready, "an unknown condition counts as ready")
+	assert.True(t, service.Readiness().Ready)
+
+	close(printer.release)
+	require.Eventually(t, func() bool {
+		return service.Readiness().Reason == ErrPaperOut.Error()
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestPrintService_Readiness_JobOutcomeUpdatesCondition(t *testing.T) {
+	// Arrange
+	printer := &statusPrinter{status: PrinterStatus{PaperPresent: true}}
+	printer.On("PrintText", "fail").Return(fmt.Errorf("printer refused job: %w", ErrPaperOut)).Once()
+	printer.On("PrintText", "ok").Return(nil).Once()
+	service := NewPrintService(printer)
+	defer service.Close()
+
+	// Act & Assert: a refused job marks the printer out of paper right away
+	require.ErrorIs(t, service.PrintText("fail"), ErrPaperOut)
+	r := service.Readiness()
+	assert.False(t, r.Ready)
+	assert.Equal(t, ErrPaperOut.Error(), r.Reason)
+
+	// Act & Assert: a printed job shows the paper was reloaded
+	require.NoError(t, service.PrintText("ok"))
+	assert.True(t, service.Readiness().Ready)
+}
+
+func TestPrintService_Readiness_MultiplePrinters(t *testing.T) {
+	// Arrange
+	service := newRoutingService(t,
+		&connectionPrinter{state: ConnectionDisconnected},
+		&connectionPrinter{state: ConnectionConnected},
+	)
+
+	// Act
+	r := service.Readiness()
+
+	// Assert
+	assert.False(t, r.Ready)
+	assert.Equal(t, "front: printer is disconnected", r.Reason)
+	assert.Equal(t, ConnectionConnected, r.Connection, "top level describes the default printer")
+	require.Len(t, r.Printers, 2)
+	assert.False(t, r.Printers["front"].Ready)
+	assert.True(t, r.Printers["kitchen"].Ready)
+}
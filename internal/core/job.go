@@ -0,0 +1,198 @@
+package core
+
+import (
+	"errors"
+	"time"
+
+	"github.com/princem/peripage-printer/internal/render"
+)
+
+// JobState is the lifecycle state of a print job.
+type JobState string
+
+const (
+	// JobQueued means the job is waiting for the printer.
+	JobQueued JobState = "queued"
+	// JobPrinting means the job is being sent to the printer.
+	JobPrinting JobState = "printing"
+	// JobDone means the job was printed successfully.
+	JobDone JobState = "done"
+	// JobFailed means the printer returned an error.
+	JobFailed JobState = "failed"
+	// JobCancelled means the job was cancelled before it was printed.
+	JobCancelled JobState = "cancelled"
+)
+
+// Finished reports whether the state is terminal.
+func (s JobState) Finished() bool {
+	return s == JobDone || s == JobFailed || s == JobCancelled
+}
+
+// JobKind identifies what a job prints.
+type JobKind string
+
+const (
+	// JobText prints plain text.
+	JobText JobKind = "text"
+	// JobJSON prints JSON data laid out as a receipt.
+	JobJSON JobKind = "json"
+	// JobImage prints a PNG, JPEG or GIF image.
+	JobImage JobKind = "image"
+	// JobMarkdown prints text formatted as Markdown.
+	JobMarkdown JobKind = "markdown"
+	// JobQR prints a QR code.
+	JobQR JobKind = "qr"
+	// JobBarcode prints a 1D barcode.
+	JobBarcode JobKind = "barcode"
+	// JobDocument prints a document of mixed blocks.
+	JobDocument JobKind = "document"
+)
+
+// JobRequest describes what a job prints. It only holds plain data so jobs
+// can be listed and resubmitted.
+type JobRequest struct {
+	Kind JobKind     `json:"kind"`
+	Text string      `json:"text,omitempty"`
+	Data interface{} `json:"data,omitempty"`
+	// Fields selects the parts of Data a JSON job prints, as field paths
+	// like "customer.name" or "items[*].price". Empty prints everything.
+	Fields []string `json:"fields,omitempty"`
+	// Image holds the encoded image file for image jobs.
+	Image  []byte        `json:"image,omitempty"`
+	Dither render.Dither `json:"dither,omitempty"`
+	// QR describes the code of QR code jobs.
+	QR *QRCode `json:"qr,omitempty"`
+	// Barcode describes the code of barcode jobs.
+	Barcode *Barcode `json:"barcode,omitempty"`
+	// Document holds the blocks of document jobs.
+	Document *Document `json:"document,omitempty"`
+	// Printer names the printer the job is routed to. Empty means the
+	// default printer; the service fills in the name when queueing.
+	Printer string `json:"printer,omitempty"`
+	// PrintSettings override the printer's defaults for this job.
+	PrintSettings
+	// JobLayout adds margins and a tear line around the job.
+	JobLayout
+}
+
+// Validate checks that the request can be printed at all.
+func (r JobRequest) Validate() error {
+	switch r.Kind {
+	case JobText, JobMarkdown:
+		if r.Text == "" {
+			return &ValidationError{Message: "text cannot be empty"}
+		}
+		if r.Kind == JobMarkdown {
+			if err := render.CheckMarkdown(r.Text, render.DefaultMarkdownOptions()); err != nil {
+				return &ValidationError{Message: err.Error()}
+			}
+		}
+	case JobJSON:
+		if r.Data == nil {
+			return &ValidationError{Message: "data cannot be nil"}
+		}
+		if len(r.Fields) > 0 {
+			if _, err := selectFields(r.Data, r.Fields); err != nil {
+				return &ValidationError{Message: err.Error()}
+			}
+		}
+	case JobImage:
+		if len(r.Image) == 0 {
+			return &ValidationError{Message: "image cannot be empty"}
+		}
+		if err := render.CheckImageFits(r.Image, render.HeadWidth); err != nil {
+			return &ValidationError{Message: err.Error()}
+		}
+		if _, err := render.ParseDither(string(r.Dither)); err != nil {
+			return &ValidationError{Message: err.Error()}
+		}
+	case JobQR:
+		if r.QR == nil {
+			return &ValidationError{Message: "qr cannot be empty"}
+		}
+		if err := r.QR.Validate(); err != nil {
+			return err
+		}
+	case JobBarcode:
+		if r.Barcode == nil {
+			return &ValidationError{Message: "barcode cannot be empty"}
+		}
+		if err := r.Barcode.Validate(); err != nil {
+			return err
+		}
+	case JobDocument:
+		if r.Document == nil {
+			return &ValidationError{Message: "document cannot be empty"}
+		}
+		if err := r.Document.Validate(); err != nil {
+			return err
+		}
+	default:
+		return &ValidationError{Message: "unknown job kind: " + string(r.Kind)}
+	}
+	if len(r.Fields) > 0 && r.Kind != JobJSON {
+		return &ValidationError{Message: "fields can only select from json data"}
+	}
+	if r.QR != nil && r.Kind != JobQR {
+		return &ValidationError{Message: "qr can only be set on qr jobs"}
+	}
+	if r.Barcode != nil && r.Kind != JobBarcode {
+		return &ValidationError{Message: "barcode can only be set on barcode jobs"}
+	}
+	if r.Document != nil && r.Kind != JobDocument {
+		return &ValidationError{Message: "document can only be set on document jobs"}
+	}
+	if err := r.PrintSettings.Validate(); err != nil {
+		return &ValidationError{Message: err.Error()}
+	}
+	if err := r.JobLayout.Validate(); err != nil {
+		return &ValidationError{Message: err.Error()}
+	}
+	return nil
+}
+
+// Job is a print request together with its progress.
+type Job struct {
+	ID         string     `json:"id"`
+	Request    JobRequest `json:"request"`
+	State      JobState   `json:"state"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	// PayloadDropped is set when a job store kept the job without what it
+	// prints. Such a job can still be listed but not reprinted.
+	PayloadDropped bool `json:"payload_dropped,omitempty"`
+}
+
+// WithoutPayload returns a copy of the job without the text, data, image
+// and document it prints. The rest of the request is small and kept.
+func (j Job) WithoutPayload() Job {
+	j.Request.Text = ""
+	j.Request.Data = nil
+	j.Request.Image = nil
+	j.Request.Document = nil
+	return j
+}
+
+// ValidationError reports a print request that can never succeed,
+// as opposed to a printer failure.
+type ValidationError struct {
+	Message string
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+var (
+	// ErrJobNotFound is returned for unknown job IDs.
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobNotCancellable is returned when cancelling a job that has already started.
+	ErrJobNotCancellable = errors.New("job can no longer be cancelled")
+	// ErrJobCancelled is returned when waiting for a job that was cancelled.
+	ErrJobCancelled = errors.New("job was cancelled")
+	// ErrQueueClosed is returned when submitting to a queue that has been shut down.
+	ErrQueueClosed = errors.New("print queue is closed")
+)
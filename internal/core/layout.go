@@ -0,0 +1,71 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/princem/peripage-printer/internal/render"
+)
+
+// Layout limits, in dot lines (8 per millimetre).
+const (
+	// MaxMargin is the largest top or bottom margin of a job.
+	MaxMargin = 100 * render.DotsPerMM
+	// MaxFeedLines is the most paper a single feed request advances.
+	MaxFeedLines = 200 * render.DotsPerMM
+)
+
+// Tear line geometry in dots.
+const (
+	tearLineGap       = 2 * render.DotsPerMM
+	tearLineThickness = 2
+	tearLineDash      = 12
+	tearLineSpace     = 8
+)
+
+// JobLayout adds blank paper around a job and an optional tear line after
+// it. Margins are in dot lines, 8 per millimetre.
+type JobLayout struct {
+	// MarginTop is blank paper fed before the job.
+	MarginTop int `json:"margin_top,omitempty" example:"0"`
+	// MarginBottom is blank paper fed after the job and its tear line, so
+	// the last line clears the tear bar.
+	MarginBottom int `json:"margin_bottom,omitempty" example:"80"`
+	// TearLine prints a dashed line below the job to tear along.
+	TearLine bool `json:"tear_line,omitempty" example:"true"`
+}
+
+// IsZero reports whether the layout leaves the job as it is.
+func (l JobLayout) IsZero() bool {
+	return l == JobLayout{}
+}
+
+// Validate checks that the margins are within range.
+func (l JobLayout) Validate() error {
+	if l.MarginTop < 0 || l.MarginTop > MaxMargin {
+		return fmt.Errorf("margin_top must be between 0 and %d dot lines", MaxMargin)
+	}
+	if l.MarginBottom < 0 || l.MarginBottom > MaxMargin {
+		return fmt.Errorf("margin_bottom must be between 0 and %d dot lines", MaxMargin)
+	}
+	return nil
+}
+
+// apply returns bitmap with the margins and tear line added, or bitmap
+// itself when there is nothing to add.
+func (l JobLayout) apply(bitmap *render.Bitmap) *render.Bitmap {
+	if l.IsZero() {
+		return bitmap
+	}
+	parts := []*render.Bitmap{
+		render.NewBitmap(render.HeadWidth, l.MarginTop),
+		bitmap,
+	}
+	if l.TearLine {
+		parts = append(parts,
+			render.NewBitmap(render.HeadWidth, tearLineGap),
+			render.DashedLine(render.HeadWidth, tearLineThickness, tearLineDash, tearLineSpace),
+		)
+	}
+	parts = append(parts, render.NewBitmap(render.HeadWidth, l.MarginBottom))
+	return render.Stack(parts...)
+}
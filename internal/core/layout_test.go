@@ -0,0 +1,126 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/princem/peripage-printer/internal/core/mocks"
+	"github.com/princem/peripage-printer/internal/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// feedingPrinter is a mock printer that can feed paper.
+type feedingPrinter struct {
+	mocks.MockPrinter
+	fed []int
+}
+
+func (p *feedingPrinter) Feed(lines int) error {
+	p.fed = append(p.fed, lines)
+	return nil
+}
+
+func TestJobLayout_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		layout  JobLayout
+		wantErr bool
+	}{
+		{name: "empty", layout: JobLayout{}},
+		{name: "margins and tear line", layout: JobLayout{MarginTop: 16, MarginBottom: MaxMargin, TearLine: true}},
+		{name: "negative top margin", layout: JobLayout{MarginTop: -1}, wantErr: true},
+		{name: "bottom margin too large", layout: JobLayout{MarginBottom: MaxMargin + 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.layout.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRasterize_Layout(t *testing.T) {
+	// Arrange
+	text, err := render.RenderText("Hello", render.DefaultTextOptions())
+	require.NoError(t, err)
+	req := JobRequest{
+		Kind:      JobText,
+		Text:      "Hello",
+		JobLayout: JobLayout{MarginTop: 24, MarginBottom: 40, TearLine: true},
+	}
+
+	// Act
+	bitmap, err := Rasterize(req)
+
+	// Assert
+	require.NoError(t, err)
+	tearLine := 24 + text.Height + tearLineGap
+	assert.Equal(t, tearLine+tearLineThickness+40, bitmap.Height)
+	assert.Equal(t, text.Pix, bitmap.Pix[24*bitmap.Stride:(24+text.Height)*bitmap.Stride])
+	assert.True(t, bitmap.Black(0, tearLine))
+	assert.False(t, bitmap.Black(tearLineDash, tearLine), "the tear line is dashed")
+	for y := tearLine + tearLineThickness; y < bitmap.Height; y++ {
+		assert.Equal(t, make([]byte, bitmap.Stride), bitmap.Row(y), "bottom margin row %d is blank", y)
+	}
+}
+
+func TestPrintService_Layout(t *testing.T) {
+	// Arrange
+	req := JobRequest{Kind: JobText, Text: "Order 42", JobLayout: JobLayout{MarginBottom: 80}}
+	expected, err := Rasterize(req)
+	require.NoError(t, err)
+
+	mockPrinter := new(mocks.MockPrinter)
+	mockPrinter.On("PrintImage", expected).Return(nil).Once()
+	service := NewPrintService(mockPrinter)
+	defer service.Close()
+
+	// Act
+	_, err = service.Print(context.Background(), req)
+
+	// Assert
+	require.NoError(t, err)
+	mockPrinter.AssertExpectations(t)
+	mockPrinter.AssertNotCalled(t, "PrintText", mock.Anything)
+}
+
+func TestPrintService_Feed(t *testing.T) {
+	t.Run("feeds the printer", func(t *testing.T) {
+		printer := &feedingPrinter{}
+		service := NewPrintService(printer)
+		defer service.Close()
+
+		require.NoError(t, service.Feed("", 80))
+		assert.Equal(t, []int{80}, printer.fed)
+	})
+
+	t.Run("validates the length", func(t *testing.T) {
+		service := NewPrintService(&feedingPrinter{})
+		defer service.Close()
+
+		var validationErr *ValidationError
+		assert.ErrorAs(t, service.Feed("", 0), &validationErr)
+		assert.ErrorAs(t, service.Feed("", MaxFeedLines+1), &validationErr)
+	})
+
+	t.Run("unknown printer", func(t *testing.T) {
+		service := NewPrintService(&feedingPrinter{})
+		defer service.Close()
+
+		assert.ErrorIs(t, service.Feed("bar", 8), ErrPrinterNotFound)
+	})
+
+	t.Run("printer cannot feed", func(t *testing.T) {
+		service := NewPrintService(new(mocks.MockPrinter))
+		defer service.Close()
+
+		assert.ErrorIs(t, service.Feed("", 8), ErrFeedUnavailable)
+	})
+}
@@ -1,6 +1,8 @@
 package mocks
 
 import (
+	"image"
+
 	"github.com/stretchr/testify/mock"
 )
 
@@ -14,3 +16,9 @@ func (m *MockPrinter) PrintText(text string) error {
 	args := m.Called(text)
 	return args.Error(0)
 }
+
+// PrintImage is a mock implementation of the Printer.PrintImage method.
+func (m *MockPrinter) PrintImage(img image.Image) error {
+	args := m.Called(img)
+	return args.Error(0)
+}
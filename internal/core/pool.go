@@ -0,0 +1,210 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// statusTTL is how long the condition a printer last reported is trusted
+// before readiness asks the printer again.
+const statusTTL = 15 * time.Second
+
+// blockingConditions are the printer conditions that stop every job.
+var blockingConditions = []error{ErrPaperOut, ErrLidOpen, ErrOverheated}
+
+// printerLane runs the jobs of one printer one at a time, whether they come
+// from the printer's own queue or from a pool the printer belongs to.
+type printerLane struct {
+	name    string
+	printer Printer
+	queue   *JobQueue
+
+	// mu is held while a job prints.
+	mu sync.Mutex
+	// pooled counts the pool jobs waiting for or holding the lane.
+	pooled atomic.Int32
+
+	// statusMu guards the condition the printer last reported.
+	statusMu sync.Mutex
+	// blocked is what stops the printer from printing, or nil.
+	blocked error
+	// checkedAt is when blocked was last brought up to date.
+	checkedAt time.Time
+	// refreshing is set while the printer is being asked for its status.
+	refreshing bool
+}
+
+// run prints a job from the printer's own queue.
+func (l *printerLane) run(req JobRequest) error {
+	output, err := prepare(req)
+	if err != nil {
+		return err
+	}
+	return l.print(output)
+}
+
+// print sends a prepared job to the printer once the lane is free.
+func (l *printerLane) print(output printOutput) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	err := output(l.printer)
+	l.observe(err)
+	return err
+}
+
+// load is the number of jobs waiting for or holding the printer.
+func (l *printerLane) load() int {
+	return l.queue.Depth() + int(l.pooled.Load())
+}
+
+// condition returns what stopped the printer from printing when it last
+// reported its status, or nil when it could print or cannot report a status.
+// It never waits for the printer: once the answer is older than statusTTL
+// the printer is asked again in the background and the old answer is
+// returned meanwhile.
+func (l *printerLane) condition() error {
+	reporter, ok := l.printer.(StatusReporter)
+	if !ok {
+		return nil
+	}
+
+	l.statusMu.Lock()
+	defer l.statusMu.Unlock()
+	if !l.refreshing && time.Since(l.checkedAt) > statusTTL {
+		l.refreshing = true
+		go func() {
+			status, err := reporter.Status()
+			l.statusMu.Lock()
+			l.refreshing = false
+			l.statusMu.Unlock()
+			l.record(status, err)
+		}()
+	}
+	return l.blocked
+}
+
+// record updates the condition from a status the printer reported. A
+// status that could not be read says nothing, so the printer counts as able
+// to print.
+func (l *printerLane) record(status PrinterStatus, err error) {
+	l.statusMu.Lock()
+	defer l.statusMu.Unlock()
+	l.blocked = nil
+	if err == nil {
+		l.blocked = status.Ready()
+	}
+	l.checkedAt = time.Now()
+}
+
+// observe updates the condition from the outcome of a job. A job refused
+// because of the printer's condition marks it blocked and a printed job
+// clears it; other failures say nothing about the condition.
+func (l *printerLane) observe(err error) {
+	l.statusMu.Lock()
+	defer l.statusMu.Unlock()
+	if err == nil {
+		l.blocked = nil
+		l.checkedAt = time.Now()
+		return
+	}
+	for _, condition := range blockingConditions {
+		if errors.Is(err, condition) {
+			l.blocked = condition
+			l.checkedAt = time.Now()
+			return
+		}
+	}
+}
+
+// healthy reports whether the printer can take work: it is connected and
+// its queue is open.
+func (l *printerLane) healthy() bool {
+	return connectionState(l.printer) == ConnectionConnected && !l.queue.Stats().Closed
+}
+
+// printerPool dispatches jobs to the least busy healthy member. A job whose
+// member fails moves on to another member until every member has been tried.
+type printerPool struct {
+	name    string
+	members []*printerLane
+	queue   *JobQueue
+	logger  *log.Logger
+
+	// mu makes picking a member and counting the job against it one step,
+	// so concurrent pool jobs spread over the members.
+	mu sync.Mutex
+}
+
+// run prints a job from the pool's queue. Errors preparing the job are
+// returned as they are because no member could print it either.
+func (p *printerPool) run(req JobRequest) error {
+	output, err := prepare(req)
+	if err != nil {
+		return err
+	}
+
+	tried := make(map[*printerLane]bool)
+	var names []string
+	var lastErr error
+	for {
+		lane := p.pick(tried)
+		if lane == nil {
+			return fmt.Errorf("pool %s: no member could print the job (tried %s): %w",
+				p.name, strings.Join(names, ", "), lastErr)
+		}
+		tried[lane] = true
+		names = append(names, lane.name)
+
+		err := lane.print(output)
+		lane.pooled.Add(-1)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		if len(tried) < len(p.members) {
+			p.logf("Pool %s: printer %s failed, moving the job to another member: %v", p.name, lane.name, err)
+		}
+	}
+}
+
+// pick chooses the member with the fewest jobs among the ones not tried
+// yet, preferring healthy members, and counts the job against it. Ties go
+// to the member listed first. It returns nil when every member was tried.
+func (p *printerPool) pick(tried map[*printerLane]bool) *printerLane {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var best *printerLane
+	var bestHealthy bool
+	var bestLoad int
+	for _, lane := range p.members {
+		if tried[lane] {
+			continue
+		}
+		healthy, load := lane.healthy(), lane.load()
+		switch {
+		case best == nil,
+			healthy && !bestHealthy,
+			healthy == bestHealthy && load < bestLoad:
+			best, bestHealthy, bestLoad = lane, healthy, load
+		}
+	}
+
+	if best != nil {
+		best.pooled.Add(1)
+	}
+	return best
+}
+
+// logf logs through the configured logger, if any.
+func (p *printerPool) logf(format string, args ...interface{}) {
+	if p.logger != nil {
+		p.logger.Printf(format, args...)
+	}
+}
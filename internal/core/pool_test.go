@@ -0,0 +1,212 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/princem/peripage-printer/internal/core/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newPoolService creates a service with printers a6-1 and a6-2 in a pool
+// named labels.
+func newPoolService(t *testing.T, first, second Printer) *PrintService {
+	t.Helper()
+	registry := NewPrinterRegistry()
+	require.NoError(t, registry.Register("a6-1", "mock", first))
+	require.NoError(t, registry.Register("a6-2", "mock", second))
+	require.NoError(t, registry.RegisterPool("labels", "a6-1", "a6-2"))
+
+	service, err := NewPrintServiceWithRegistry(registry, PrintServiceConfig{})
+	require.NoError(t, err)
+	t.Cleanup(service.Close)
+	return service
+}
+
+// holdPrinting makes printer block on text until release is closed, and
+// reports on started when it begins.
+func holdPrinting(printer *mocks.MockPrinter, text string, started chan<- string, name string, release <-chan struct{}) {
+	printer.On("PrintText", text).
+		Run(func(args mock.Arguments) {
+			started <- name
+			<-release
+		}).
+		Return(nil).
+		Once()
+}
+
+func TestPrinterPool_PicksLeastBusyMember(t *testing.T) {
+	// Arrange: a6-1 is busy with a job of its own
+	first := new(mocks.MockPrinter)
+	second := new(mocks.MockPrinter)
+	started := make(chan string, 1)
+	release := make(chan struct{})
+	holdPrinting(first, "own job", started, "a6-1", release)
+	second.On("PrintText", "label").Return(nil).Once()
+	service := newPoolService(t, first, second)
+
+	_, err := service.Submit(JobRequest{Kind: JobText, Text: "own job", Printer: "a6-1"})
+	require.NoError(t, err)
+	<-started
+
+	// Act
+	job, err := service.Print(context.Background(), JobRequest{Kind: JobText, Text: "label", Printer: "labels"})
+	close(release)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, JobDone, job.State)
+	assert.Equal(t, "labels", job.Request.Printer)
+	second.AssertExpectations(t)
+}
+
+func TestPrinterPool_SpreadsConcurrentJobs(t *testing.T) {
+	// Arrange
+	first := new(mocks.MockPrinter)
+	second := new(mocks.MockPrinter)
+	started := make(chan string, 2)
+	release := make(chan struct{})
+	holdPrinting(first, "label", started, "a6-1", release)
+	holdPrinting(second, "label", started, "a6-2", release)
+	service := newPoolService(t, first, second)
+
+	// Act
+	for i := 0; i < 2; i++ {
+		_, err := service.Submit(JobRequest{Kind: JobText, Text: "label", Printer: "labels"})
+		require.NoError(t, err)
+	}
+
+	// Assert: both members print at the same time
+	assert.ElementsMatch(t, []string{"a6-1", "a6-2"}, []string{<-started, <-started})
+	close(release)
+}
+
+func TestPrinterPool_PrefersHealthyMembers(t *testing.T) {
+	// Arrange: a6-1 is listed first but disconnected
+	first := &connectionPrinter{state: ConnectionDisconnected}
+	second := &connectionPrinter{state: ConnectionConnected}
+	second.On("PrintText", "label").Return(nil).Once()
+	service := newPoolService(t, first, second)
+
+	// Act
+	_, err := service.Print(context.Background(), JobRequest{Kind: JobText, Text: "label", Printer: "labels"})
+
+	// Assert
+	require.NoError(t, err)
+	first.AssertNotCalled(t, "PrintText", mock.Anything)
+	second.AssertExpectations(t)
+}
+
+func TestPrinterPool_Failover(t *testing.T) {
+	tests := []struct {
+		name          string
+		firstErr      error
+		secondErr     error
+		expectedState JobState
+		expectedError error
+	}{
+		{
+			name:          "job moves to the next member",
+			firstErr:      ErrPaperOut,
+			expectedState: JobDone,
+		},
+		{
+			name:          "every member fails",
+			firstErr:      ErrPaperOut,
+			secondErr:     ErrLidOpen,
+			expectedState: JobFailed,
+			expectedError: ErrLidOpen,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			first := new(mocks.MockPrinter)
+			first.On("PrintText", "label").Return(tt.firstErr).Once()
+			second := new(mocks.MockPrinter)
+			second.On("PrintText", "label").Return(tt.secondErr).Once()
+			service := newPoolService(t, first, second)
+
+			// Act
+			job, err := service.Print(context.Background(), JobRequest{Kind: JobText, Text: "label", Printer: "labels"})
+
+			// Assert
+			assert.Equal(t, tt.expectedState, job.State)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Contains(t, err.Error(), "tried a6-1, a6-2")
+			} else {
+				assert.NoError(t, err)
+			}
+			first.AssertExpectations(t)
+			second.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPrinterPool_DoesNotRetryBadJobs(t *testing.T) {
+	// Arrange: data that cannot be formatted fails before any member prints
+	first := new(mocks.MockPrinter)
+	second := new(mocks.MockPrinter)
+	service := newPoolService(t, first, second)
+
+	// Act
+	job, err := service.Print(context.Background(), JobRequest{
+		Kind:    JobJSON,
+		Data:    map[string]interface{}{"bad": make(chan int)},
+		Printer: "labels",
+	})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, JobFailed, job.State)
+	first.AssertNotCalled(t, "PrintText", mock.Anything)
+	second.AssertNotCalled(t, "PrintText", mock.Anything)
+}
+
+func TestPrintService_Pools(t *testing.T) {
+	// Arrange
+	service := newPoolService(t,
+		&connectionPrinter{state: ConnectionConnecting},
+		&connectionPrinter{state: ConnectionConnected},
+	)
+
+	// Act
+	pools := service.Pools()
+	r := service.Readiness()
+
+	// Assert
+	assert.Equal(t, []PoolInfo{
+		{Name: "labels", Members: []string{"a6-1", "a6-2"}, HealthyMembers: 1},
+	}, pools)
+	require.Contains(t, r.Pools, "labels")
+	assert.True(t, r.Pools["labels"].Ready)
+	assert.Equal(t, ConnectionConnected, r.Pools["labels"].Connection)
+	assert.False(t, r.Ready, "a6-1 is still connecting")
+	assert.Equal(t, "a6-1: printer is connecting", r.Reason)
+}
+
+func TestPrintService_Readiness_PoolMemberOutOfPaper(t *testing.T) {
+	// Arrange
+	service := newPoolService(t,
+		&statusPrinter{status: PrinterStatus{}},
+		&connectionPrinter{state: ConnectionConnecting},
+	)
+
+	// Act
+	var r Readiness
+	require.Eventually(t, func() bool {
+		r = service.Readiness()
+		return r.Printers["a6-1"].Reason != ""
+	}, time.Second, 5*time.Millisecond)
+
+	// Assert
+	require.Contains(t, r.Pools, "labels")
+	assert.False(t, r.Pools["labels"].Ready, "the only connected member is out of paper")
+	assert.Equal(t, "no pool member is ready", r.Pools["labels"].Reason)
+	assert.Equal(t, ErrPaperOut.Error(), r.Printers["a6-1"].Reason)
+}
@@ -1,9 +1,167 @@
 package core
 
+import (
+	"context"
+	"errors"
+	"image"
+	"time"
+)
+
 // Printer defines the port for interacting with printer devices.
 // This is the core domain interface that all printer adapters must implement.
 type Printer interface {
 	// PrintText sends text to the printer for printing.
 	// Returns an error if the printing operation fails.
 	PrintText(text string) error
+
+	// PrintImage prints an image. The print service passes a 1-bit
+	// *render.Bitmap that is already scaled to the print head; adapters
+	// convert any other image themselves.
+	PrintImage(img image.Image) error
+}
+
+// SettingsPrinter is implemented by printers that take print settings per
+// job. It is optional: the print service checks for it with a type
+// assertion. Settings left empty use the printer's defaults.
+type SettingsPrinter interface {
+	// PrintTextWithSettings prints text like PrintText, with settings.
+	PrintTextWithSettings(text string, settings PrintSettings) error
+
+	// PrintImageWithSettings prints an image like PrintImage, with settings.
+	PrintImageWithSettings(img image.Image, settings PrintSettings) error
+}
+
+// Feeder is implemented by printers that can advance the paper without
+// printing. It is optional: the print service checks for it with a type
+// assertion.
+type Feeder interface {
+	// Feed advances the paper by lines blank dot lines, 8 per millimetre.
+	Feed(lines int) error
+}
+
+// StatusReporter is implemented by printers that can report their condition.
+// It is optional: the print service checks for it with a type assertion.
+type StatusReporter interface {
+	// Status queries the printer for its current condition.
+	Status() (PrinterStatus, error)
+}
+
+// PrinterStatus is a snapshot of the printer's condition.
+type PrinterStatus struct {
+	// Battery is the charge in percent.
+	Battery      int       `json:"battery" example:"85"`
+	PaperPresent bool      `json:"paper_present" example:"true"`
+	LidOpen      bool      `json:"lid_open" example:"false"`
+	Overheated   bool      `json:"overheated" example:"false"`
+	Firmware     string    `json:"firmware,omitempty" example:"V2.11"`
+	Serial       string    `json:"serial,omitempty" example:"A6-123456"`
+	CheckedAt    time.Time `json:"checked_at"`
+}
+
+// Ready returns the condition that stops the printer from printing, or nil.
+func (s PrinterStatus) Ready() error {
+	switch {
+	case !s.PaperPresent:
+		return ErrPaperOut
+	case s.LidOpen:
+		return ErrLidOpen
+	case s.Overheated:
+		return ErrOverheated
+	}
+	return nil
+}
+
+// DeviceScanner is implemented by printers that can discover nearby devices.
+// It is optional: the print service checks for it with a type assertion.
+type DeviceScanner interface {
+	// Scan lists the devices seen within duration, strongest signal first.
+	Scan(ctx context.Context, duration time.Duration) ([]DiscoveredDevice, error)
+}
+
+// DiscoveredDevice is a device seen during a scan.
+type DiscoveredDevice struct {
+	Name         string   `json:"name" example:"PeriPage_A6_1a2b"`
+	Address      string   `json:"address" example:"AA:BB:CC:DD:EE:FF"`
+	RSSI         int      `json:"rssi" example:"-58"`
+	ServiceUUIDs []string `json:"service_uuids,omitempty"`
+	// Matches is set when the configured criteria select this device.
+	Matches bool `json:"matches" example:"true"`
+}
+
+// ConnectionReporter is implemented by printers whose connection can drop.
+// It is optional: printers without it are treated as always connected.
+type ConnectionReporter interface {
+	// State returns the current connection state.
+	State() ConnectionState
+
+	// LastError returns why the last connection attempt failed, or nil.
+	LastError() error
+}
+
+// ConnectionState describes the link between the server and a printer.
+type ConnectionState string
+
+const (
+	// ConnectionDisconnected means there is no link and none is being set up.
+	ConnectionDisconnected ConnectionState = "disconnected"
+	// ConnectionConnecting means the printer is being scanned for or connected to.
+	ConnectionConnecting ConnectionState = "connecting"
+	// ConnectionConnected means the printer is ready to receive jobs.
+	ConnectionConnected ConnectionState = "connected"
+)
+
+// Printer conditions that stop a job. Adapters wrap these so callers can
+// tell a printer that needs attention from other failures with errors.Is.
+var (
+	// ErrPrinterDisconnected is returned when the printer is not reachable or
+	// the connection drops during a job.
+	ErrPrinterDisconnected = errors.New("printer disconnected")
+	// ErrPaperOut is returned when the printer has no paper.
+	ErrPaperOut = errors.New("printer is out of paper")
+	// ErrLowBattery is returned when the printer battery is too low to print.
+	ErrLowBattery = errors.New("printer battery is too low")
+	// ErrOverheated is returned when the print head is too hot to print.
+	ErrOverheated = errors.New("printer is overheated")
+	// ErrLidOpen is returned when the paper compartment is open.
+	ErrLidOpen = errors.New("printer lid is open")
+)
+
+// Optional printer capabilities that are missing.
+var (
+	// ErrStatusUnavailable is returned when the printer cannot report its status.
+	ErrStatusUnavailable = errors.New("printer does not report its status")
+	// ErrScanUnavailable is returned when the printer cannot scan for devices.
+	ErrScanUnavailable = errors.New("printer cannot scan for devices")
+	// ErrSettingsUnavailable is returned when a job has print settings but
+	// the printer cannot apply them.
+	ErrSettingsUnavailable = errors.New("printer does not support print settings")
+	// ErrFeedUnavailable is returned when the printer cannot feed paper.
+	ErrFeedUnavailable = errors.New("printer cannot feed paper")
+)
+
+// JobStore defines the port for persisting print jobs across restarts.
+// Implementations must be safe for concurrent use by the queue workers of
+// all printers and by API requests.
+type JobStore interface {
+	// Save inserts or replaces the job with the same ID.
+	Save(job Job) error
+
+	// Delete removes a job. Deleting an unknown job is not an error.
+	Delete(id string) error
+
+	// Load returns every stored job in the order it was first saved.
+	Load() ([]Job, error)
+}
+
+// TemplateStore defines the port for persisting print templates.
+// Implementations must be safe for concurrent use.
+type TemplateStore interface {
+	// Save inserts or replaces the template with the same name.
+	Save(template Template) error
+
+	// Delete removes a template. Deleting an unknown template is not an error.
+	Delete(name string) error
+
+	// Load returns every stored template.
+	Load() ([]Template, error)
 }
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/princem/peripage-printer/internal/qr"
+	"github.com/princem/peripage-printer/internal/render"
+)
+
+// QRCode describes a QR code to print, such as a link or a Wi-Fi join code
+// like "WIFI:T:WPA;S:Office;P:secret;;".
+type QRCode struct {
+	Data string `json:"data" example:"https://example.com/menu"`
+	// Level is the error correction level: L, M, Q or H. Empty means M.
+	Level string `json:"level,omitempty" example:"M" enums:"L,M,Q,H"`
+	// Scale is the size of one module in dots. Zero prints the code as
+	// large as fits the head.
+	Scale int `json:"scale,omitempty" example:"6"`
+	// Caption is printed centred under the code.
+	Caption string `json:"caption,omitempty" example:"Scan for the menu"`
+}
+
+// Validate checks that the data fits a QR code at the level and that the
+// code fits the head at the scale.
+func (q QRCode) Validate() error {
+	_, err := q.encode(render.HeadWidth)
+	return err
+}
+
+// encode builds the QR code symbol and checks that it fits width dots.
+func (q QRCode) encode(width int) (*qr.Code, error) {
+	if q.Data == "" {
+		return nil, &ValidationError{Message: "qr data cannot be empty"}
+	}
+	level, err := qr.ParseLevel(q.Level)
+	if err != nil {
+		return nil, &ValidationError{Message: err.Error()}
+	}
+	if q.Scale < 0 {
+		return nil, &ValidationError{Message: fmt.Sprintf("qr scale must not be negative, got %d", q.Scale)}
+	}
+
+	code, err := qr.Encode(q.Data, level)
+	if err != nil {
+		return nil, &ValidationError{Message: err.Error()}
+	}
+	fit := render.MaxQRScale(code.Size, width)
+	if fit < 1 {
+		return nil, &ValidationError{Message: fmt.Sprintf("a version %d qr code does not fit in %d dots", code.Version, width)}
+	}
+	if q.Scale > fit {
+		return nil, &ValidationError{Message: fmt.Sprintf("qr scale %d is too large: a version %d code fits at most %d", q.Scale, code.Version, fit)}
+	}
+	return code, nil
+}
+
+// renderQR draws a QR code, with its caption, across width dots.
+func renderQR(q QRCode, width int) (*render.Bitmap, error) {
+	code, err := q.encode(width)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := render.DefaultQROptions()
+	opts.Width = width
+	opts.Scale = q.Scale
+	opts.Caption = q.Caption
+	bitmap, err := render.RenderQR(code, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render QR code: %w", err)
+	}
+	return bitmap, nil
+}
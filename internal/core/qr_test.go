@@ -0,0 +1,65 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/princem/peripage-printer/internal/core/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQRCode_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    QRCode
+		wantErr string
+	}{
+		{name: "link", code: QRCode{Data: "https://example.com"}},
+		{name: "wifi at high level", code: QRCode{Data: "WIFI:T:WPA;S:Office;P:secret;;", Level: "H", Scale: 6, Caption: "Office Wi-Fi"}},
+		{name: "largest version at the smallest scale", code: QRCode{Data: strings.Repeat("a", 2953), Level: "L", Scale: 2}},
+		{name: "empty data", code: QRCode{}, wantErr: "qr data cannot be empty"},
+		{name: "unknown level", code: QRCode{Data: "x", Level: "Z"}, wantErr: "unknown error correction level"},
+		{name: "negative scale", code: QRCode{Data: "x", Scale: -1}, wantErr: "must not be negative"},
+		{name: "too long for the level", code: QRCode{Data: strings.Repeat("a", 1274), Level: "H"}, wantErr: "data too long"},
+		{name: "scale too large", code: QRCode{Data: strings.Repeat("a", 2953), Level: "L", Scale: 3}, wantErr: "fits at most 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := tt.code.Validate()
+
+			// Assert
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestPrintService_QR(t *testing.T) {
+	// Arrange
+	req := JobRequest{Kind: JobQR, QR: &QRCode{Data: "https://example.com", Scale: 4, Caption: "Example"}}
+	expected, err := Rasterize(req)
+	require.NoError(t, err)
+
+	mockPrinter := new(mocks.MockPrinter)
+	mockPrinter.On("PrintImage", expected).Return(nil).Once()
+	service := NewPrintService(mockPrinter)
+	defer service.Close()
+
+	// Act
+	_, err = service.Print(context.Background(), req)
+
+	// Assert
+	require.NoError(t, err)
+	mockPrinter.AssertExpectations(t)
+	mockPrinter.AssertNotCalled(t, "PrintText", mock.Anything)
+}
@@ -0,0 +1,418 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RunFunc prints a single job request.
+type RunFunc func(JobRequest) error
+
+// JobQueue runs print jobs in submission order. By default a single worker
+// goroutine runs one job at a time; each printer gets its own queue so a
+// printer is never driven by two jobs at once. A pool's queue has one worker
+// per member so its jobs print side by side.
+type JobQueue struct {
+	run       RunFunc
+	store     JobStore
+	retention time.Duration
+	logger    *log.Logger
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	records map[string]*jobRecord
+	order   []string
+	pending []string
+	closed  bool
+	stopped chan struct{}
+
+	lastSuccess time.Time
+	lastFailure time.Time
+	lastError   string
+}
+
+// QueueStats summarizes a queue for health checks.
+type QueueStats struct {
+	// Depth is the number of jobs that are queued or printing.
+	Depth  int
+	Closed bool
+	// LastSuccess is when a job last printed, zero if none has yet.
+	LastSuccess time.Time
+	// LastFailure and LastError describe the most recent failed job.
+	LastFailure time.Time
+	LastError   string
+}
+
+// jobRecord is the queue's bookkeeping for one job.
+type jobRecord struct {
+	job  Job
+	err  error
+	done chan struct{}
+}
+
+// QueueConfig holds optional settings for a JobQueue.
+type QueueConfig struct {
+	// Store persists jobs across restarts. Nil keeps jobs in memory only.
+	Store JobStore
+
+	// Retention is how long finished jobs are kept. Zero keeps them forever.
+	Retention time.Duration
+
+	// Logger receives store errors that cannot be returned to a caller.
+	Logger *log.Logger
+
+	// Workers is the number of jobs run at once. Zero runs one at a time.
+	Workers int
+}
+
+// NewJobQueue creates an in-memory queue and starts its worker.
+func NewJobQueue(run RunFunc) *JobQueue {
+	q, _ := NewJobQueueWithConfig(run, QueueConfig{})
+	return q
+}
+
+// NewJobQueueWithConfig creates a queue and starts its workers.
+// Jobs found in the store are restored first: finished jobs become history
+// and jobs that were queued or printing when the server stopped are queued
+// again in their original order.
+func NewJobQueueWithConfig(run RunFunc, config QueueConfig) (*JobQueue, error) {
+	q := &JobQueue{
+		run:       run,
+		store:     config.Store,
+		retention: config.Retention,
+		logger:    config.Logger,
+		records:   make(map[string]*jobRecord),
+		stopped:   make(chan struct{}),
+	}
+	q.cond = sync.NewCond(&q.mu)
+
+	if err := q.restore(); err != nil {
+		return nil, err
+	}
+
+	var workers sync.WaitGroup
+	for i := 0; i < max(config.Workers, 1); i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			q.work()
+		}()
+	}
+	go func() {
+		workers.Wait()
+		close(q.stopped)
+	}()
+	return q, nil
+}
+
+// Submit validates req and appends it to the queue.
+func (q *JobQueue) Submit(req JobRequest) (Job, error) {
+	if err := req.Validate(); err != nil {
+		return Job{}, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return Job{}, ErrQueueClosed
+	}
+
+	rec := &jobRecord{
+		job: Job{
+			ID:        newJobID(),
+			Request:   req,
+			State:     JobQueued,
+			CreatedAt: time.Now(),
+		},
+		done: make(chan struct{}),
+	}
+	if q.store != nil {
+		if err := q.store.Save(rec.job); err != nil {
+			return Job{}, fmt.Errorf("failed to persist job: %w", err)
+		}
+	}
+
+	q.records[rec.job.ID] = rec
+	q.order = append(q.order, rec.job.ID)
+	q.pending = append(q.pending, rec.job.ID)
+	q.cond.Signal()
+
+	return rec.job, nil
+}
+
+// Get returns a snapshot of the job with the given ID.
+func (q *JobQueue) Get(id string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	rec, ok := q.records[id]
+	if !ok {
+		return Job{}, ErrJobNotFound
+	}
+	return rec.job, nil
+}
+
+// List returns snapshots of all jobs in submission order.
+func (q *JobQueue) List() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs := make([]Job, 0, len(q.order))
+	for _, id := range q.order {
+		jobs = append(jobs, q.records[id].job)
+	}
+	return jobs
+}
+
+// Depth returns the number of jobs that are queued or printing.
+func (q *JobQueue) Depth() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.depth()
+}
+
+// Stats returns the queue's depth and the outcome of recent jobs.
+func (q *JobQueue) Stats() QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return QueueStats{
+		Depth:       q.depth(),
+		Closed:      q.closed,
+		LastSuccess: q.lastSuccess,
+		LastFailure: q.lastFailure,
+		LastError:   q.lastError,
+	}
+}
+
+// depth counts the jobs that are queued or printing.
+// It must be called with q.mu held.
+func (q *JobQueue) depth() int {
+	depth := len(q.pending)
+	for _, rec := range q.records {
+		if rec.job.State == JobPrinting {
+			depth++
+		}
+	}
+	return depth
+}
+
+// Cancel cancels a job that has not started printing yet.
+func (q *JobQueue) Cancel(id string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	rec, ok := q.records[id]
+	if !ok {
+		return Job{}, ErrJobNotFound
+	}
+	if rec.job.State != JobQueued {
+		return rec.job, ErrJobNotCancellable
+	}
+
+	for i, pendingID := range q.pending {
+		if pendingID == id {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			break
+		}
+	}
+	q.finish(rec, JobCancelled, ErrJobCancelled)
+	return rec.job, nil
+}
+
+// Wait blocks until the job finishes or ctx is done. It returns the final
+// job snapshot together with the error the job failed with, if any.
+func (q *JobQueue) Wait(ctx context.Context, id string) (Job, error) {
+	q.mu.Lock()
+	rec, ok := q.records[id]
+	q.mu.Unlock()
+	if !ok {
+		return Job{}, ErrJobNotFound
+	}
+
+	select {
+	case <-rec.done:
+	case <-q.stopped:
+		select {
+		case <-rec.done:
+		default:
+			job, _ := q.Get(id)
+			return job, ErrQueueClosed
+		}
+	case <-ctx.Done():
+		job, _ := q.Get(id)
+		return job, ctx.Err()
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return rec.job, rec.err
+}
+
+// Close stops accepting jobs and waits for the jobs being printed, if any.
+// Jobs that are still queued stay queued.
+func (q *JobQueue) Close() {
+	q.mu.Lock()
+	q.closed = true
+	q.cond.Broadcast()
+	q.mu.Unlock()
+
+	<-q.stopped
+}
+
+// work is the loop of one of the queue's workers.
+func (q *JobQueue) work() {
+	for {
+		q.mu.Lock()
+		for len(q.pending) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if q.closed {
+			q.mu.Unlock()
+			return
+		}
+
+		id := q.pending[0]
+		q.pending = q.pending[1:]
+		rec := q.records[id]
+		started := time.Now()
+		rec.job.State = JobPrinting
+		rec.job.StartedAt = &started
+		q.persist(rec.job)
+		req := rec.job.Request
+		q.mu.Unlock()
+
+		err := q.run(req)
+
+		q.mu.Lock()
+		if err != nil {
+			q.finish(rec, JobFailed, err)
+		} else {
+			q.finish(rec, JobDone, nil)
+		}
+		q.mu.Unlock()
+	}
+}
+
+// finish moves a job to a terminal state and wakes its waiters.
+// It must be called with q.mu held.
+func (q *JobQueue) finish(rec *jobRecord, state JobState, err error) {
+	finished := time.Now()
+	rec.job.State = state
+	rec.job.FinishedAt = &finished
+	rec.err = err
+	if err != nil && state == JobFailed {
+		rec.job.Error = err.Error()
+		q.lastFailure = finished
+		q.lastError = rec.job.Error
+	}
+	if state == JobDone {
+		q.lastSuccess = finished
+	}
+	close(rec.done)
+	q.persist(rec.job)
+	q.prune(finished)
+}
+
+// restore loads jobs from the store. Unfinished jobs are queued again; an
+// interrupted job is reprinted from the start because there is no way to
+// know how much of it reached the paper.
+func (q *JobQueue) restore() error {
+	if q.store == nil {
+		return nil
+	}
+
+	jobs, err := q.store.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load jobs: %w", err)
+	}
+
+	for _, job := range jobs {
+		rec := &jobRecord{job: job, done: make(chan struct{})}
+
+		if job.State.Finished() {
+			switch job.State {
+			case JobFailed:
+				rec.err = errors.New(job.Error)
+			case JobCancelled:
+				rec.err = ErrJobCancelled
+			}
+			close(rec.done)
+		} else {
+			rec.job.State = JobQueued
+			rec.job.StartedAt = nil
+			if err := q.store.Save(rec.job); err != nil {
+				return fmt.Errorf("failed to requeue job %s: %w", job.ID, err)
+			}
+			q.pending = append(q.pending, job.ID)
+		}
+
+		q.records[job.ID] = rec
+		q.order = append(q.order, job.ID)
+	}
+
+	q.prune(time.Now())
+	return nil
+}
+
+// prune forgets finished jobs that are older than the retention window.
+// It must be called with q.mu held.
+func (q *JobQueue) prune(now time.Time) {
+	if q.retention <= 0 {
+		return
+	}
+
+	cutoff := now.Add(-q.retention)
+	kept := q.order[:0]
+	for _, id := range q.order {
+		job := q.records[id].job
+		if job.State.Finished() && job.FinishedAt != nil && job.FinishedAt.Before(cutoff) {
+			delete(q.records, id)
+			if q.store != nil {
+				if err := q.store.Delete(id); err != nil {
+					q.logf("Failed to delete expired job %s: %v", id, err)
+				}
+			}
+			continue
+		}
+		kept = append(kept, id)
+	}
+	q.order = kept
+}
+
+// persist saves a job state change. Failures are logged rather than
+// returned because the job itself has already moved on.
+// It must be called with q.mu held.
+func (q *JobQueue) persist(job Job) {
+	if q.store == nil {
+		return
+	}
+	if err := q.store.Save(job); err != nil {
+		q.logf("Failed to persist job %s: %v", job.ID, err)
+	}
+}
+
+// logf logs through the configured logger, if any.
+func (q *JobQueue) logf(format string, args ...interface{}) {
+	if q.logger != nil {
+		q.logger.Printf(format, args...)
+	}
+}
+
+// newJobID returns a random 16-character hex identifier.
+func newJobID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms; fall back to the clock.
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,414 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingRunner records printed texts and holds each job until released.
+type blockingRunner struct {
+	mu      sync.Mutex
+	printed []string
+	active  int
+	overlap bool
+	started chan string
+	release chan error
+}
+
+func newBlockingRunner() *blockingRunner {
+	return &blockingRunner{
+		started: make(chan string, 10),
+		release: make(chan error),
+	}
+}
+
+func (r *blockingRunner) run(req JobRequest) error {
+	r.mu.Lock()
+	r.active++
+	if r.active > 1 {
+		r.overlap = true
+	}
+	r.mu.Unlock()
+
+	r.started <- req.Text
+	err := <-r.release
+
+	r.mu.Lock()
+	r.active--
+	r.printed = append(r.printed, req.Text)
+	r.mu.Unlock()
+	return err
+}
+
+func textJob(text string) JobRequest {
+	return JobRequest{Kind: JobText, Text: text}
+}
+
+func TestJobQueue_RunsJobsInOrderOneAtATime(t *testing.T) {
+	// Arrange
+	runner := newBlockingRunner()
+	queue := NewJobQueue(runner.run)
+	defer queue.Close()
+
+	var ids []string
+	for _, text := range []string{"first", "second", "third"} {
+		job, err := queue.Submit(textJob(text))
+		require.NoError(t, err)
+		assert.Equal(t, JobQueued, job.State)
+		ids = append(ids, job.ID)
+	}
+
+	// Act
+	for range ids {
+		<-runner.started
+		runner.release <- nil
+	}
+	for _, id := range ids {
+		job, err := queue.Wait(context.Background(), id)
+		require.NoError(t, err)
+		assert.Equal(t, JobDone, job.State)
+		assert.NotNil(t, job.StartedAt)
+		assert.NotNil(t, job.FinishedAt)
+	}
+
+	// Assert
+	assert.Equal(t, []string{"first", "second", "third"}, runner.printed)
+	assert.False(t, runner.overlap, "jobs must never run concurrently")
+	assert.Equal(t, 0, queue.Depth())
+}
+
+func TestJobQueue_FailedJob(t *testing.T) {
+	queue := NewJobQueue(func(JobRequest) error { return errors.New("printer offline") })
+	defer queue.Close()
+
+	job, err := queue.Submit(textJob("x"))
+	require.NoError(t, err)
+
+	job, err = queue.Wait(context.Background(), job.ID)
+
+	require.Error(t, err)
+	assert.Equal(t, "printer offline", err.Error())
+	assert.Equal(t, JobFailed, job.State)
+	assert.Equal(t, "printer offline", job.Error)
+}
+
+func TestJobQueue_Cancel(t *testing.T) {
+	// Arrange: the first job blocks the worker so the second stays queued
+	runner := newBlockingRunner()
+	queue := NewJobQueue(runner.run)
+	defer queue.Close()
+
+	printing, err := queue.Submit(textJob("printing"))
+	require.NoError(t, err)
+	<-runner.started
+	queued, err := queue.Submit(textJob("queued"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, queue.Depth())
+
+	// Act
+	cancelled, cancelErr := queue.Cancel(queued.ID)
+	_, printingErr := queue.Cancel(printing.ID)
+	_, missingErr := queue.Cancel("missing")
+
+	// Assert
+	require.NoError(t, cancelErr)
+	assert.Equal(t, JobCancelled, cancelled.State)
+	assert.ErrorIs(t, printingErr, ErrJobNotCancellable)
+	assert.ErrorIs(t, missingErr, ErrJobNotFound)
+
+	_, err = queue.Wait(context.Background(), queued.ID)
+	assert.ErrorIs(t, err, ErrJobCancelled)
+
+	runner.release <- nil
+	_, err = queue.Wait(context.Background(), printing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"printing"}, runner.printed, "cancelled job must not print")
+}
+
+func TestJobQueue_Submit_Validation(t *testing.T) {
+	queue := NewJobQueue(func(JobRequest) error { return nil })
+	defer queue.Close()
+
+	tests := []struct {
+		name string
+		req  JobRequest
+	}{
+		{name: "empty text", req: JobRequest{Kind: JobText}},
+		{name: "nil data", req: JobRequest{Kind: JobJSON}},
+		{name: "empty markdown", req: JobRequest{Kind: JobMarkdown}},
+		{name: "missing qr", req: JobRequest{Kind: JobQR}},
+		{name: "missing barcode", req: JobRequest{Kind: JobBarcode}},
+		{name: "missing document", req: JobRequest{Kind: JobDocument}},
+		{name: "unknown kind", req: JobRequest{Kind: "fax", Text: "x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := queue.Submit(tt.req)
+
+			var validationErr *ValidationError
+			assert.ErrorAs(t, err, &validationErr)
+		})
+	}
+	assert.Empty(t, queue.List())
+}
+
+func TestJobQueue_GetAndList(t *testing.T) {
+	queue := NewJobQueue(func(JobRequest) error { return nil })
+	defer queue.Close()
+
+	first, err := queue.Submit(textJob("a"))
+	require.NoError(t, err)
+	second, err := queue.Submit(textJob("b"))
+	require.NoError(t, err)
+	_, err = queue.Wait(context.Background(), second.ID)
+	require.NoError(t, err)
+
+	got, err := queue.Get(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "a", got.Request.Text)
+
+	_, err = queue.Get("missing")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+
+	jobs := queue.List()
+	require.Len(t, jobs, 2)
+	assert.Equal(t, first.ID, jobs[0].ID)
+	assert.Equal(t, second.ID, jobs[1].ID)
+	assert.NotEqual(t, first.ID, second.ID)
+}
+
+func TestJobQueue_Close(t *testing.T) {
+	runner := newBlockingRunner()
+	queue := NewJobQueue(runner.run)
+
+	printing, err := queue.Submit(textJob("printing"))
+	require.NoError(t, err)
+	<-runner.started
+	queued, err := queue.Submit(textJob("queued"))
+	require.NoError(t, err)
+
+	closed := make(chan struct{})
+	go func() {
+		queue.Close()
+		close(closed)
+	}()
+	require.Eventually(t, func() bool {
+		queue.mu.Lock()
+		defer queue.mu.Unlock()
+		return queue.closed
+	}, time.Second, time.Millisecond)
+	runner.release <- nil
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close did not return after the current job finished")
+	}
+
+	job, err := queue.Get(printing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, JobDone, job.State)
+
+	_, err = queue.Wait(context.Background(), queued.ID)
+	assert.ErrorIs(t, err, ErrQueueClosed)
+
+	_, err = queue.Submit(textJob("late"))
+	assert.ErrorIs(t, err, ErrQueueClosed)
+}
+
+func TestJobQueue_Wait_ContextCancelled(t *testing.T) {
+	runner := newBlockingRunner()
+	queue := NewJobQueue(runner.run)
+	defer func() {
+		runner.release <- nil
+		queue.Close()
+	}()
+
+	job, err := queue.Submit(textJob("slow"))
+	require.NoError(t, err)
+	<-runner.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	job, err = queue.Wait(ctx, job.ID)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, JobPrinting, job.State)
+}
+
+func TestJobQueue_Workers(t *testing.T) {
+	// Arrange
+	runner := newBlockingRunner()
+	queue, err := NewJobQueueWithConfig(runner.run, QueueConfig{Workers: 2})
+	require.NoError(t, err)
+	defer queue.Close()
+
+	// Act
+	for _, text := range []string{"first", "second", "third"} {
+		_, err := queue.Submit(textJob(text))
+		require.NoError(t, err)
+	}
+
+	// Assert: two jobs print side by side, the third waits for a worker
+	assert.ElementsMatch(t, []string{"first", "second"}, []string{<-runner.started, <-runner.started})
+	assert.Equal(t, 3, queue.Depth())
+	runner.release <- nil
+	assert.Equal(t, "third", <-runner.started)
+	runner.release <- nil
+	runner.release <- nil
+	assert.Eventually(t, func() bool { return queue.Depth() == 0 }, time.Second, time.Millisecond)
+}
+
+// memoryStore is an in-memory JobStore for queue tests.
+type memoryStore struct {
+	mu    sync.Mutex
+	jobs  map[string]Job
+	order []string
+}
+
+func newMemoryStore(jobs ...Job) *memoryStore {
+	s := &memoryStore{jobs: make(map[string]Job)}
+	for _, job := range jobs {
+		_ = s.Save(job)
+	}
+	return s
+}
+
+func (s *memoryStore) Save(job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[job.ID]; !ok {
+		s.order = append(s.order, job.ID)
+	}
+	s.jobs[job.ID] = job
+	return nil
+}
+
+func (s *memoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.jobs, id)
+	for i, existing := range s.order {
+		if existing == id {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) Load() ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]Job, 0, len(s.order))
+	for _, id := range s.order {
+		jobs = append(jobs, s.jobs[id])
+	}
+	return jobs, nil
+}
+
+func (s *memoryStore) get(id string) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	return job, ok
+}
+
+func TestJobQueue_PersistsStateChanges(t *testing.T) {
+	// Arrange
+	store := newMemoryStore()
+	runner := newBlockingRunner()
+	queue, err := NewJobQueueWithConfig(runner.run, QueueConfig{Store: store})
+	require.NoError(t, err)
+	defer queue.Close()
+
+	// Act & Assert
+	job, err := queue.Submit(textJob("persisted"))
+	require.NoError(t, err)
+	stored, ok := store.get(job.ID)
+	require.True(t, ok)
+	assert.Equal(t, "persisted", stored.Request.Text)
+
+	<-runner.started
+	stored, _ = store.get(job.ID)
+	assert.Equal(t, JobPrinting, stored.State)
+
+	runner.release <- nil
+	_, err = queue.Wait(context.Background(), job.ID)
+	require.NoError(t, err)
+	stored, _ = store.get(job.ID)
+	assert.Equal(t, JobDone, stored.State)
+	assert.NotNil(t, stored.FinishedAt)
+}
+
+func TestJobQueue_RestoresJobsFromStore(t *testing.T) {
+	// Arrange: a previous run left one job done, one interrupted mid-print
+	// and one still queued
+	created := time.Now().Add(-time.Minute)
+	finished := created.Add(time.Second)
+	store := newMemoryStore(
+		Job{ID: "done", Request: textJob("done"), State: JobDone, CreatedAt: created, FinishedAt: &finished},
+		Job{ID: "interrupted", Request: textJob("interrupted"), State: JobPrinting, CreatedAt: created, StartedAt: &finished},
+		Job{ID: "queued", Request: textJob("queued"), State: JobQueued, CreatedAt: created},
+	)
+	runner := newBlockingRunner()
+
+	// Act
+	queue, err := NewJobQueueWithConfig(runner.run, QueueConfig{Store: store})
+	require.NoError(t, err)
+	defer queue.Close()
+
+	// Assert: unfinished jobs are printed again in their original order
+	for _, want := range []string{"interrupted", "queued"} {
+		assert.Equal(t, want, <-runner.started)
+		runner.release <- nil
+		job, err := queue.Wait(context.Background(), want)
+		require.NoError(t, err)
+		assert.Equal(t, JobDone, job.State)
+	}
+
+	history, err := queue.Get("done")
+	require.NoError(t, err)
+	assert.Equal(t, JobDone, history.State)
+	assert.Equal(t, []string{"interrupted", "queued"}, runner.printed, "finished jobs must not print again")
+
+	ids := []string{}
+	for _, job := range queue.List() {
+		ids = append(ids, job.ID)
+	}
+	assert.Equal(t, []string{"done", "interrupted", "queued"}, ids)
+}
+
+func TestJobQueue_Retention(t *testing.T) {
+	// Arrange
+	old := time.Now().Add(-2 * time.Hour)
+	recent := time.Now().Add(-time.Minute)
+	store := newMemoryStore(
+		Job{ID: "old", Request: textJob("old"), State: JobDone, CreatedAt: old, FinishedAt: &old},
+		Job{ID: "recent", Request: textJob("recent"), State: JobFailed, Error: "jam", CreatedAt: recent, FinishedAt: &recent},
+	)
+
+	// Act
+	queue, err := NewJobQueueWithConfig(func(JobRequest) error { return nil }, QueueConfig{
+		Store:     store,
+		Retention: time.Hour,
+	})
+	require.NoError(t, err)
+	defer queue.Close()
+
+	// Assert
+	_, err = queue.Get("old")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+	_, ok := store.get("old")
+	assert.False(t, ok, "expired job must be removed from the store")
+
+	_, err = queue.Wait(context.Background(), "recent")
+	assert.EqualError(t, err, "jam")
+}
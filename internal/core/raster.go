@@ -0,0 +1,96 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/princem/peripage-printer/internal/render"
+)
+
+// Rasterize returns the 1-bit raster that printing req produces on the
+// print head. Printer adapters render text with the same defaults, so the
+// result matches what the printer receives dot for dot.
+func Rasterize(req JobRequest) (*render.Bitmap, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	return rasterize(req)
+}
+
+// rasterize renders a validated request.
+func rasterize(req JobRequest) (*render.Bitmap, error) {
+	var bitmap *render.Bitmap
+	var err error
+	switch req.Kind {
+	case JobText:
+		bitmap, err = renderText(req.Text)
+	case JobJSON:
+		bitmap, err = renderJSON(req)
+	case JobMarkdown:
+		bitmap, err = renderMarkdown(req.Text)
+	case JobImage:
+		bitmap, err = renderImage(req)
+	case JobQR:
+		bitmap, err = renderQR(*req.QR, render.HeadWidth)
+	case JobBarcode:
+		bitmap, err = renderBarcode(*req.Barcode, render.HeadWidth)
+	case JobDocument:
+		bitmap, err = renderDocument(*req.Document)
+	default:
+		return nil, fmt.Errorf("unknown job kind: %s", req.Kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return req.JobLayout.apply(bitmap), nil
+}
+
+// renderText lays out text the way printer adapters do.
+func renderText(text string) (*render.Bitmap, error) {
+	bitmap, err := render.RenderText(text, render.DefaultTextOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to render text: %w", err)
+	}
+	return bitmap, nil
+}
+
+// renderMarkdown lays out a Markdown document. Printer adapters only know
+// plain text, so Markdown is always sent to them as an image.
+func renderMarkdown(source string) (*render.Bitmap, error) {
+	bitmap, err := render.RenderMarkdown(source, render.DefaultMarkdownOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to render markdown: %w", err)
+	}
+	return bitmap, nil
+}
+
+// renderJSON lays out the selected fields of a JSON job as a receipt.
+func renderJSON(req JobRequest) (*render.Bitmap, error) {
+	data := req.Data
+	if len(req.Fields) > 0 {
+		var err error
+		data, err = selectFields(data, req.Fields)
+		if err != nil {
+			return nil, &ValidationError{Message: err.Error()}
+		}
+	}
+
+	bitmap, err := render.RenderJSON(data, render.DefaultJSONOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to render JSON: %w", err)
+	}
+	return bitmap, nil
+}
+
+// renderImage decodes an image job and converts it to a head-wide bitmap.
+func renderImage(req JobRequest) (*render.Bitmap, error) {
+	img, err := render.DecodeImage(req.Image)
+	if err != nil {
+		return nil, err
+	}
+
+	bitmap, err := render.RenderImage(img, render.ImageOptions{Dither: req.Dither})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render image: %w", err)
+	}
+	return bitmap, nil
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/princem/peripage-printer/internal/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRasterize(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 192, 48))))
+
+	expectedText, err := render.RenderText("Hello", render.DefaultTextOptions())
+	require.NoError(t, err)
+	expectedJSON, err := render.RenderJSON(map[string]interface{}{"total": 42}, render.DefaultJSONOptions())
+	require.NoError(t, err)
+	expectedMarkdown, err := render.RenderMarkdown("# Total\n- **42**", render.DefaultMarkdownOptions())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		req            JobRequest
+		expected       *render.Bitmap
+		expectedHeight int
+	}{
+		{
+			name:     "text",
+			req:      JobRequest{Kind: JobText, Text: "Hello"},
+			expected: expectedText,
+		},
+		{
+			name:     "json is laid out as a receipt",
+			req:      JobRequest{Kind: JobJSON, Data: map[string]interface{}{"total": 42}},
+			expected: expectedJSON,
+		},
+		{
+			name:     "markdown",
+			req:      JobRequest{Kind: JobMarkdown, Text: "# Total\n- **42**"},
+			expected: expectedMarkdown,
+		},
+		{
+			name:           "image is scaled to the print head",
+			req:            JobRequest{Kind: JobImage, Image: buf.Bytes(), Dither: render.DitherThreshold},
+			expectedHeight: 96,
+		},
+		{
+			name:           "qr code fills the head at a whole scale",
+			req:            JobRequest{Kind: JobQR, QR: &QRCode{Data: "HELLO"}},
+			expectedHeight: 29 * 13,
+		},
+		{
+			name:           "barcode bars without text",
+			req:            JobRequest{Kind: JobBarcode, Barcode: &Barcode{Data: "BIN-A-17", Height: 120, HideText: true}},
+			expectedHeight: 120,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			bitmap, err := Rasterize(tt.req)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, render.HeadWidth, bitmap.Width)
+			if tt.expected != nil {
+				assert.Equal(t, tt.expected.Height, bitmap.Height)
+				assert.Equal(t, tt.expected.Pix, bitmap.Pix)
+			} else {
+				assert.Equal(t, tt.expectedHeight, bitmap.Height)
+			}
+		})
+	}
+}
+
+func TestRasterize_InvalidRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		req  JobRequest
+	}{
+		{name: "empty text", req: JobRequest{Kind: JobText}},
+		{name: "fields on text", req: JobRequest{Kind: JobText, Text: "Hello", Fields: []string{"total"}}},
+		{name: "invalid field path", req: JobRequest{Kind: JobJSON, Data: map[string]interface{}{"a": 1}, Fields: []string{"items[x]"}}},
+		{name: "fields match nothing", req: JobRequest{Kind: JobJSON, Data: map[string]interface{}{"a": 1}, Fields: []string{"b"}}},
+		{name: "qr on text", req: JobRequest{Kind: JobText, Text: "Hello", QR: &QRCode{Data: "x"}}},
+		{name: "qr scale too large", req: JobRequest{Kind: JobQR, QR: &QRCode{Data: "HELLO", Scale: 14}}},
+		{name: "barcode on qr", req: JobRequest{Kind: JobQR, QR: &QRCode{Data: "x"}, Barcode: &Barcode{Data: "x"}}},
+		{name: "document on text", req: JobRequest{Kind: JobText, Text: "Hello", Document: &Document{}}},
+		{name: "empty document", req: JobRequest{Kind: JobDocument, Document: &Document{}}},
+		{name: "markdown barcode fence", req: JobRequest{Kind: JobMarkdown, Text: "# Bin\n```barcode ean13\nabc\n```"}},
+		{name: "markdown table fence", req: JobRequest{Kind: JobMarkdown, Text: "```table 10px 10px\na | b\n```"}},
+		{name: "barcode check digit", req: JobRequest{Kind: JobBarcode, Barcode: &Barcode{Type: "upca", Data: "036000291453"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Rasterize(tt.req)
+
+			var validationErr *ValidationError
+			assert.ErrorAs(t, err, &validationErr)
+		})
+	}
+}
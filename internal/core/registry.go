@@ -0,0 +1,188 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// DefaultPrinterName is the name of the printer of a single-printer service.
+const DefaultPrinterName = "default"
+
+// ErrPrinterNotFound is returned for unknown printer names.
+var ErrPrinterNotFound = errors.New("printer not found")
+
+// printerNamePattern restricts printer names to what fits in URLs and
+// environment variable names.
+var printerNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// PrinterRegistry holds the printers and printer pools jobs can be routed
+// to, by name. Printers and pools share one namespace. The first printer
+// registered is the default until SetDefault picks another printer or pool.
+// A registry is filled once at startup and only read afterwards.
+type PrinterRegistry struct {
+	names     []string
+	printers  map[string]registeredPrinter
+	poolNames []string
+	pools     map[string][]string
+	def       string
+}
+
+// registeredPrinter is a printer together with its adapter type.
+type registeredPrinter struct {
+	printer     Printer
+	printerType string
+}
+
+// PrinterInfo describes a registered printer.
+type PrinterInfo struct {
+	Name string `json:"name" example:"front-desk"`
+	// Type is the adapter driving the printer, e.g. "ble" or "mock".
+	Type       string          `json:"type" example:"ble"`
+	Default    bool            `json:"default" example:"true"`
+	Connection ConnectionState `json:"connection" example:"connected"`
+	// QueueDepth is the number of jobs that are queued or printing.
+	QueueDepth int `json:"queue_depth" example:"0"`
+}
+
+// PoolInfo describes a registered printer pool.
+type PoolInfo struct {
+	Name    string   `json:"name" example:"labels"`
+	Members []string `json:"members" example:"a6-1,a6-2"`
+	Default bool     `json:"default" example:"false"`
+	// HealthyMembers is the number of members that can take work.
+	HealthyMembers int `json:"healthy_members" example:"2"`
+	// QueueDepth is the number of pool jobs that are queued or printing.
+	QueueDepth int `json:"queue_depth" example:"0"`
+}
+
+// NewPrinterRegistry creates an empty registry.
+func NewPrinterRegistry() *PrinterRegistry {
+	return &PrinterRegistry{
+		printers: make(map[string]registeredPrinter),
+		pools:    make(map[string][]string),
+	}
+}
+
+// Register adds a printer under name. Names are lower case letters, digits,
+// dashes and underscores.
+func (r *PrinterRegistry) Register(name, printerType string, printer Printer) error {
+	if err := r.checkName(name); err != nil {
+		return err
+	}
+	if printer == nil {
+		return fmt.Errorf("printer %q is nil", name)
+	}
+
+	r.names = append(r.names, name)
+	r.printers[name] = registeredPrinter{printer: printer, printerType: printerType}
+	if r.def == "" {
+		r.def = name
+	}
+	return nil
+}
+
+// RegisterPool adds a pool of registered printers under name. Jobs sent to
+// the pool are printed by one of its members.
+func (r *PrinterRegistry) RegisterPool(name string, members ...string) error {
+	if err := r.checkName(name); err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		return fmt.Errorf("pool %q has no members", name)
+	}
+
+	seen := make(map[string]bool)
+	for _, member := range members {
+		if _, ok := r.printers[member]; !ok {
+			return fmt.Errorf("pool %q: %w: %s", name, ErrPrinterNotFound, member)
+		}
+		if seen[member] {
+			return fmt.Errorf("pool %q lists printer %q twice", name, member)
+		}
+		seen[member] = true
+	}
+
+	r.poolNames = append(r.poolNames, name)
+	r.pools[name] = append([]string(nil), members...)
+	return nil
+}
+
+// checkName checks that name is valid and not taken by a printer or pool.
+func (r *PrinterRegistry) checkName(name string) error {
+	if !printerNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid printer name %q: use lower case letters, digits, '-' and '_'", name)
+	}
+	if _, ok := r.printers[name]; ok {
+		return fmt.Errorf("printer %q is already registered", name)
+	}
+	if _, ok := r.pools[name]; ok {
+		return fmt.Errorf("pool %q is already registered", name)
+	}
+	return nil
+}
+
+// SetDefault makes the named printer or pool the one jobs without a printer
+// go to.
+func (r *PrinterRegistry) SetDefault(name string) error {
+	if !r.Has(name) {
+		return fmt.Errorf("%w: %s", ErrPrinterNotFound, name)
+	}
+	r.def = name
+	return nil
+}
+
+// Has reports whether name is a registered printer or pool.
+func (r *PrinterRegistry) Has(name string) bool {
+	_, printer := r.printers[name]
+	_, pool := r.pools[name]
+	return printer || pool
+}
+
+// Default returns the name of the default printer or pool.
+func (r *PrinterRegistry) Default() string {
+	return r.def
+}
+
+// Names returns the printer names in registration order.
+func (r *PrinterRegistry) Names() []string {
+	return append([]string(nil), r.names...)
+}
+
+// Pools returns the pool names in registration order.
+func (r *PrinterRegistry) Pools() []string {
+	return append([]string(nil), r.poolNames...)
+}
+
+// Members returns the printers of the named pool, or nil if there is no
+// such pool.
+func (r *PrinterRegistry) Members(pool string) []string {
+	return append([]string(nil), r.pools[pool]...)
+}
+
+// Get returns the named printer, or the default printer for an empty name.
+// Pools are not printers, so asking for one is an error.
+func (r *PrinterRegistry) Get(name string) (Printer, error) {
+	resolved := r.resolve(name)
+	entry, ok := r.printers[resolved]
+	if !ok {
+		if _, pool := r.pools[resolved]; pool {
+			return nil, fmt.Errorf("%w: %s is a pool", ErrPrinterNotFound, resolved)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrPrinterNotFound, name)
+	}
+	return entry.printer, nil
+}
+
+// Type returns the adapter type the named printer was registered with.
+func (r *PrinterRegistry) Type(name string) string {
+	return r.printers[r.resolve(name)].printerType
+}
+
+// resolve maps an empty name to the default printer.
+func (r *PrinterRegistry) resolve(name string) string {
+	if name == "" {
+		return r.def
+	}
+	return name
+}
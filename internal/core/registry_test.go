@@ -0,0 +1,129 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/princem/peripage-printer/internal/core/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrinterRegistry_Register(t *testing.T) {
+	tests := []struct {
+		name        string
+		printerName string
+		printer     Printer
+		wantErr     string
+	}{
+		{name: "valid name", printerName: "front-desk", printer: new(mocks.MockPrinter)},
+		{name: "digits and underscore", printerName: "kitchen_2", printer: new(mocks.MockPrinter)},
+		{name: "empty name", printerName: "", printer: new(mocks.MockPrinter), wantErr: "invalid printer name"},
+		{name: "upper case", printerName: "Kitchen", printer: new(mocks.MockPrinter), wantErr: "invalid printer name"},
+		{name: "leading dash", printerName: "-bar", printer: new(mocks.MockPrinter), wantErr: "invalid printer name"},
+		{name: "duplicate", printerName: "default", printer: new(mocks.MockPrinter), wantErr: "already registered"},
+		{name: "nil printer", printerName: "bar", printer: nil, wantErr: "is nil"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			registry := NewPrinterRegistry()
+			require.NoError(t, registry.Register(DefaultPrinterName, "mock", new(mocks.MockPrinter)))
+
+			// Act
+			err := registry.Register(tt.printerName, "mock", tt.printer)
+
+			// Assert
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []string{DefaultPrinterName, tt.printerName}, registry.Names())
+		})
+	}
+}
+
+func TestPrinterRegistry_Default(t *testing.T) {
+	// Arrange
+	front := new(mocks.MockPrinter)
+	kitchen := new(mocks.MockPrinter)
+	registry := NewPrinterRegistry()
+	require.NoError(t, registry.Register("front", "ble", front))
+	require.NoError(t, registry.Register("kitchen", "mock", kitchen))
+
+	// Act & Assert
+	assert.Equal(t, "front", registry.Default(), "first printer is the default")
+	got, err := registry.Get("")
+	require.NoError(t, err)
+	assert.Same(t, front, got)
+
+	require.NoError(t, registry.SetDefault("kitchen"))
+	got, err = registry.Get("")
+	require.NoError(t, err)
+	assert.Same(t, kitchen, got)
+	assert.Equal(t, "mock", registry.Type(""))
+	assert.Equal(t, "ble", registry.Type("front"))
+
+	assert.ErrorIs(t, registry.SetDefault("bar"), ErrPrinterNotFound)
+	_, err = registry.Get("bar")
+	assert.ErrorIs(t, err, ErrPrinterNotFound)
+}
+
+func TestPrinterRegistry_RegisterPool(t *testing.T) {
+	tests := []struct {
+		name     string
+		poolName string
+		members  []string
+		wantErr  string
+	}{
+		{name: "valid pool", poolName: "labels", members: []string{"a6-1", "a6-2"}},
+		{name: "no members", poolName: "labels", wantErr: "has no members"},
+		{name: "unknown member", poolName: "labels", members: []string{"a6-1", "a6-3"}, wantErr: "printer not found: a6-3"},
+		{name: "member listed twice", poolName: "labels", members: []string{"a6-1", "a6-1"}, wantErr: "twice"},
+		{name: "name taken by a printer", poolName: "a6-1", members: []string{"a6-2"}, wantErr: "already registered"},
+		{name: "invalid name", poolName: "Labels", members: []string{"a6-1"}, wantErr: "invalid printer name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			registry := NewPrinterRegistry()
+			require.NoError(t, registry.Register("a6-1", "mock", new(mocks.MockPrinter)))
+			require.NoError(t, registry.Register("a6-2", "mock", new(mocks.MockPrinter)))
+
+			// Act
+			err := registry.RegisterPool(tt.poolName, tt.members...)
+
+			// Assert
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.Empty(t, registry.Pools())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []string{"labels"}, registry.Pools())
+			assert.Equal(t, tt.members, registry.Members("labels"))
+			assert.True(t, registry.Has("labels"))
+			assert.Error(t, registry.Register("labels", "mock", new(mocks.MockPrinter)), "pool names are taken")
+		})
+	}
+}
+
+func TestPrinterRegistry_DefaultPool(t *testing.T) {
+	// Arrange
+	registry := NewPrinterRegistry()
+	require.NoError(t, registry.Register("a6-1", "mock", new(mocks.MockPrinter)))
+	require.NoError(t, registry.RegisterPool("labels", "a6-1"))
+
+	// Act
+	err := registry.SetDefault("labels")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "labels", registry.Default())
+	_, err = registry.Get("")
+	assert.ErrorIs(t, err, ErrPrinterNotFound, "a pool is not a printer")
+}
@@ -1,43 +1,454 @@
 package core
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"image"
+	"sort"
+	"time"
+
+	"github.com/princem/peripage-printer/internal/render"
 )
 
 // PrintService orchestrates printing operations.
 // It follows the hexagonal architecture pattern by depending only on the Printer port.
+// Every job goes through a JobQueue, so concurrent requests are printed one
+// after another instead of driving the printer at the same time. With
+// several named printers each gets its own queue, and jobs are routed by
+// JobRequest.Printer. Pools have a queue of their own whose jobs are
+// dispatched to the members.
 type PrintService struct {
-	printer Printer
+	registry *PrinterRegistry
+	queues   map[string]*JobQueue
+	lanes    map[string]*printerLane
+	pools    map[string]*printerPool
+
+	templates *TemplateRegistry
+}
+
+// PrintServiceConfig holds optional settings for a PrintService.
+type PrintServiceConfig struct {
+	// Queue configures job persistence and retention. The store is shared by
+	// all printers.
+	Queue QueueConfig
+	// Templates persists print templates. Nil keeps them in memory only.
+	Templates TemplateStore
 }
 
 // NewPrintService creates a new print service with the given printer implementation.
+// It starts the worker that feeds the printer; call Close to stop it.
+// Jobs are kept in memory only.
 func NewPrintService(printer Printer) *PrintService {
-	return &PrintService{
-		printer: printer,
+	s, _ := NewPrintServiceWithConfig(printer, PrintServiceConfig{})
+	return s
+}
+
+// NewPrintServiceWithConfig creates a print service with the given settings.
+// Jobs left unfinished in the configured store are queued again.
+func NewPrintServiceWithConfig(printer Printer, config PrintServiceConfig) (*PrintService, error) {
+	registry := NewPrinterRegistry()
+	if err := registry.Register(DefaultPrinterName, "", printer); err != nil {
+		return nil, err
 	}
+	return NewPrintServiceWithRegistry(registry, config)
 }
 
-// PrintText sends plain text to the printer.
-func (s *PrintService) PrintText(text string) error {
-	if text == "" {
-		return fmt.Errorf("text cannot be empty")
+// NewPrintServiceWithRegistry creates a print service for every printer and
+// pool in the registry. Stored jobs go back to the queue of the printer or
+// pool they were submitted to; jobs without a printer go to the default one.
+func NewPrintServiceWithRegistry(registry *PrinterRegistry, config PrintServiceConfig) (*PrintService, error) {
+	if len(registry.Names()) == 0 {
+		return nil, fmt.Errorf("no printers registered")
 	}
-	return s.printer.PrintText(text)
+
+	templates, err := NewTemplateRegistry(config.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &PrintService{
+		registry:  registry,
+		queues:    make(map[string]*JobQueue),
+		lanes:     make(map[string]*printerLane),
+		pools:     make(map[string]*printerPool),
+		templates: templates,
+	}
+	for _, name := range registry.Names() {
+		printer, _ := registry.Get(name)
+		lane := &printerLane{name: name, printer: printer}
+
+		queue, err := NewJobQueueWithConfig(lane.run, s.queueConfig(name, config.Queue, 1))
+		if err != nil {
+			s.Close()
+			return nil, fmt.Errorf("printer %s: %w", name, err)
+		}
+		lane.queue = queue
+		s.lanes[name] = lane
+		s.queues[name] = queue
+	}
+	for _, name := range registry.Pools() {
+		pool := &printerPool{name: name, logger: config.Queue.Logger}
+		for _, member := range registry.Members(name) {
+			pool.members = append(pool.members, s.lanes[member])
+		}
+
+		queue, err := NewJobQueueWithConfig(pool.run, s.queueConfig(name, config.Queue, len(pool.members)))
+		if err != nil {
+			s.Close()
+			return nil, fmt.Errorf("pool %s: %w", name, err)
+		}
+		pool.queue = queue
+		s.pools[name] = pool
+		s.queues[name] = queue
+	}
+
+	return s, nil
+}
+
+// queueConfig returns the settings of the queue of the named printer or
+// pool: it sees only its own jobs in the shared store.
+func (s *PrintService) queueConfig(name string, config QueueConfig, workers int) QueueConfig {
+	config.Workers = workers
+	if config.Store != nil {
+		config.Store = &printerJobStore{
+			JobStore:  config.Store,
+			printer:   name,
+			isDefault: name == s.registry.Default(),
+			known:     s.registry.Has,
+		}
+	}
+	return config
+}
+
+// PrintText sends plain text to the printer and waits for it to be printed.
+func (s *PrintService) PrintText(text string) error {
+	_, err := s.Print(context.Background(), JobRequest{Kind: JobText, Text: text})
+	return err
 }
 
-// PrintJSON formats JSON data and sends it to the printer.
-// The JSON is pretty-printed with indentation for better readability.
+// PrintJSON lays out JSON data as a receipt and sends it to the printer.
 func (s *PrintService) PrintJSON(data interface{}) error {
-	if data == nil {
-		return fmt.Errorf("data cannot be nil")
+	_, err := s.Print(context.Background(), JobRequest{Kind: JobJSON, Data: data})
+	return err
+}
+
+// PrintImage prints an encoded PNG, JPEG or GIF image using the given
+// dithering mode and waits for it to be printed.
+func (s *PrintService) PrintImage(data []byte, dither render.Dither) error {
+	_, err := s.Print(context.Background(), JobRequest{Kind: JobImage, Image: data, Dither: dither})
+	return err
+}
+
+// Print queues a job and waits until it has been printed.
+func (s *PrintService) Print(ctx context.Context, req JobRequest) (Job, error) {
+	job, err := s.Submit(req)
+	if err != nil {
+		return Job{}, err
 	}
+	return s.queues[job.Request.Printer].Wait(ctx, job.ID)
+}
 
-	// Pretty-print the JSON with indentation
-	jsonBytes, err := json.MarshalIndent(data, "", "  ")
+// Submit queues a job on its printer or pool and returns immediately.
+func (s *PrintService) Submit(req JobRequest) (Job, error) {
+	if req.Printer == "" {
+		req.Printer = s.registry.Default()
+	}
+	queue, ok := s.queues[req.Printer]
+	if !ok {
+		return Job{}, &ValidationError{Message: "unknown printer: " + req.Printer}
+	}
+	return queue.Submit(req)
+}
+
+// Job returns the job with the given ID.
+func (s *PrintService) Job(id string) (Job, error) {
+	queue, err := s.queueOf(id)
+	if err != nil {
+		return Job{}, err
+	}
+	return queue.Get(id)
+}
+
+// Jobs returns all known jobs of every printer and pool in submission order.
+func (s *PrintService) Jobs() []Job {
+	var jobs []Job
+	for _, queue := range s.queues {
+		jobs = append(jobs, queue.List()...)
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+	return jobs
+}
+
+// Cancel cancels a job that has not started printing.
+func (s *PrintService) Cancel(id string) (Job, error) {
+	queue, err := s.queueOf(id)
+	if err != nil {
+		return Job{}, err
+	}
+	return queue.Cancel(id)
+}
+
+// Reprint queues a new job with the same request, on the same printer or
+// pool, as an existing one.
+func (s *PrintService) Reprint(id string) (Job, error) {
+	job, err := s.Job(id)
 	if err != nil {
-		return fmt.Errorf("failed to marshal JSON: %w", err)
+		return Job{}, err
+	}
+	if job.PayloadDropped {
+		return Job{}, &ValidationError{Message: "job " + id + " was stored without its payload and cannot be reprinted"}
+	}
+	return s.Submit(job.Request)
+}
+
+// queueOf returns the queue holding the job with the given ID.
+func (s *PrintService) queueOf(id string) (*JobQueue, error) {
+	for _, queue := range s.queues {
+		if _, err := queue.Get(id); err == nil {
+			return queue, nil
+		}
+	}
+	return nil, ErrJobNotFound
+}
+
+// Printers lists the registered printers in registration order.
+func (s *PrintService) Printers() []PrinterInfo {
+	names := s.registry.Names()
+	infos := make([]PrinterInfo, 0, len(names))
+	for _, name := range names {
+		lane := s.lanes[name]
+		infos = append(infos, PrinterInfo{
+			Name:       name,
+			Type:       s.registry.Type(name),
+			Default:    name == s.registry.Default(),
+			Connection: connectionState(lane.printer),
+			QueueDepth: lane.load(),
+		})
+	}
+	return infos
+}
+
+// Pools lists the registered pools in registration order.
+func (s *PrintService) Pools() []PoolInfo {
+	names := s.registry.Pools()
+	infos := make([]PoolInfo, 0, len(names))
+	for _, name := range names {
+		pool := s.pools[name]
+		info := PoolInfo{
+			Name:       name,
+			Members:    s.registry.Members(name),
+			Default:    name == s.registry.Default(),
+			QueueDepth: pool.queue.Depth(),
+		}
+		for _, member := range pool.members {
+			if member.healthy() {
+				info.HealthyMembers++
+			}
+		}
+		infos = append(infos, info)
 	}
+	return infos
+}
+
+// Preview returns the raster that printing req would send to the printer,
+// without queueing it.
+func (s *PrintService) Preview(req JobRequest) (*render.Bitmap, error) {
+	return Rasterize(req)
+}
 
-	return s.printer.PrintText(string(jsonBytes))
+// PrinterStatus queries the named printer, or the default printer for an
+// empty name, for its condition. It returns ErrStatusUnavailable when the
+// printer adapter cannot report one.
+func (s *PrintService) PrinterStatus(name string) (PrinterStatus, error) {
+	printer, err := s.registry.Get(name)
+	if err != nil {
+		return PrinterStatus{}, err
+	}
+	reporter, ok := printer.(StatusReporter)
+	if !ok {
+		return PrinterStatus{}, ErrStatusUnavailable
+	}
+	status, err := reporter.Status()
+	s.lanes[s.registry.resolve(name)].record(status, err)
+	return status, err
+}
+
+// ScanDevices lists the devices near the named printer's adapter, or the
+// default printer's for an empty name. It returns ErrScanUnavailable when
+// the adapter cannot scan.
+func (s *PrintService) ScanDevices(ctx context.Context, name string, duration time.Duration) ([]DiscoveredDevice, error) {
+	printer, err := s.registry.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	scanner, ok := printer.(DeviceScanner)
+	if !ok {
+		return nil, ErrScanUnavailable
+	}
+	return scanner.Scan(ctx, duration)
+}
+
+// Feed advances the paper of the named printer, or the default printer for
+// an empty name, by lines blank dot lines. A job printing on the printer
+// finishes first. It returns ErrFeedUnavailable when the adapter cannot feed.
+func (s *PrintService) Feed(name string, lines int) error {
+	if lines < 1 || lines > MaxFeedLines {
+		return &ValidationError{Message: fmt.Sprintf("lines must be between 1 and %d", MaxFeedLines)}
+	}
+	printer, err := s.registry.Get(name)
+	if err != nil {
+		return err
+	}
+	feeder, ok := printer.(Feeder)
+	if !ok {
+		return ErrFeedUnavailable
+	}
+	return s.lanes[s.registry.resolve(name)].print(func(Printer) error {
+		return feeder.Feed(lines)
+	})
+}
+
+// Templates returns every print template sorted by name.
+func (s *PrintService) Templates() []Template {
+	return s.templates.List()
+}
+
+// Template returns the named print template.
+func (s *PrintService) Template(name string) (Template, error) {
+	return s.templates.Get(name)
+}
+
+// CreateTemplate adds a print template whose name is not taken yet.
+func (s *PrintService) CreateTemplate(t Template) (Template, error) {
+	return s.templates.Create(t)
+}
+
+// PutTemplate creates or replaces a print template and reports whether it
+// is new.
+func (s *PrintService) PutTemplate(t Template) (Template, bool, error) {
+	return s.templates.Put(t)
+}
+
+// DeleteTemplate removes a print template.
+func (s *PrintService) DeleteTemplate(name string) error {
+	return s.templates.Delete(name)
+}
+
+// RenderTemplate fills the named template with data and returns the job
+// printing the result. The job holds the rendered text, so reprinting it
+// prints the same thing even after the template changes.
+func (s *PrintService) RenderTemplate(name string, data map[string]interface{}) (JobRequest, error) {
+	t, err := s.templates.Get(name)
+	if err != nil {
+		return JobRequest{}, err
+	}
+	return t.Render(data)
+}
+
+// Close stops the print workers after their current jobs. Pools stop first
+// because their jobs print on the members.
+func (s *PrintService) Close() {
+	for _, pool := range s.pools {
+		pool.queue.Close()
+	}
+	for _, lane := range s.lanes {
+		lane.queue.Close()
+	}
+}
+
+// printOutput sends a prepared job to a printer.
+type printOutput func(Printer) error
+
+// prepare does the work of a job that does not need the printer, such as
+// laying out JSON or rendering an image, so that a job moved to another
+// printer is not prepared twice. Plain text without a layout is left to the
+// printer; everything else, including text with margins or a tear line, is
+// rasterized here and sent as an image.
+func prepare(req JobRequest) (printOutput, error) {
+	settings := req.PrintSettings
+
+	if req.Kind == JobText && req.JobLayout.IsZero() {
+		text := req.Text
+		return func(printer Printer) error {
+			return printText(printer, text, settings)
+		}, nil
+	}
+
+	bitmap, err := rasterize(req)
+	if err != nil {
+		return nil, err
+	}
+	return func(printer Printer) error {
+		return printImage(printer, bitmap, settings)
+	}, nil
+}
+
+// printText prints text with the job's settings. Jobs without settings
+// print on any printer.
+func printText(printer Printer, text string, settings PrintSettings) error {
+	if settings.IsZero() {
+		return printer.PrintText(text)
+	}
+	sp, ok := printer.(SettingsPrinter)
+	if !ok {
+		return ErrSettingsUnavailable
+	}
+	return sp.PrintTextWithSettings(text, settings)
+}
+
+// printImage prints an image with the job's settings. Jobs without settings
+// print on any printer.
+func printImage(printer Printer, img image.Image, settings PrintSettings) error {
+	if settings.IsZero() {
+		return printer.PrintImage(img)
+	}
+	sp, ok := printer.(SettingsPrinter)
+	if !ok {
+		return ErrSettingsUnavailable
+	}
+	return sp.PrintImageWithSettings(img, settings)
+}
+
+// printerJobStore is the view of a shared JobStore used by the queue of one
+// printer or pool: it only loads that printer's or pool's jobs.
+type printerJobStore struct {
+	JobStore
+	printer   string
+	isDefault bool
+	// known reports whether a printer or pool is still configured.
+	known func(name string) bool
+}
+
+// Load implements JobStore. Jobs stored without a printer belong to the
+// default printer, and so do the jobs of a printer or pool that is no longer
+// configured. Those are marked failed rather than printed somewhere else, so
+// they are listed once and removed with the rest of the history.
+func (s *printerJobStore) Load() ([]Job, error) {
+	jobs, err := s.JobStore.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var own []Job
+	for _, job := range jobs {
+		switch {
+		case job.Request.Printer == s.printer || (job.Request.Printer == "" && s.isDefault):
+		case s.isDefault && !s.known(job.Request.Printer):
+			if !job.State.Finished() {
+				now := time.Now()
+				job.State = JobFailed
+				job.Error = fmt.Sprintf("printer %s is no longer configured", job.Request.Printer)
+				job.FinishedAt = &now
+				if err := s.JobStore.Save(job); err != nil {
+					return nil, fmt.Errorf("failed to fail job %s: %w", job.ID, err)
+				}
+			}
+		default:
+			continue
+		}
+		own = append(own, job)
+	}
+	return own, nil
 }
@@ -1,11 +1,16 @@
 package core
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
 	"errors"
+	"image"
+	"image/png"
 	"testing"
+	"time"
 
 	"github.com/princem/peripage-printer/internal/core/mocks"
+	"github.com/princem/peripage-printer/internal/render"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -20,7 +25,10 @@ func TestNewPrintService(t *testing.T) {
 
 	// Assert
 	require.NotNil(t, service, "Service should not be nil")
-	assert.Equal(t, mockPrinter, service.printer, "Service should use provided printer")
+	printer, err := service.registry.Get("")
+	require.NoError(t, err)
+	assert.Equal(t, mockPrinter, printer, "Service should use provided printer")
+	assert.Equal(t, DefaultPrinterName, service.registry.Default())
 }
 
 func TestPrintService_PrintText(t *testing.T) {
@@ -101,7 +109,7 @@ func TestPrintService_PrintJSON(t *testing.T) {
 	tests := []struct {
 		name          string
 		data          interface{}
-		mockSetup     func(*mocks.MockPrinter, string)
+		mockSetup     func(*mocks.MockPrinter, *render.Bitmap)
 		expectedError string
 	}{
 		{
@@ -110,8 +118,8 @@ func TestPrintService_PrintJSON(t *testing.T) {
 				"name": "John",
 				"age":  30,
 			},
-			mockSetup: func(m *mocks.MockPrinter, expectedJSON string) {
-				m.On("PrintText", expectedJSON).Return(nil).Once()
+			mockSetup: func(m *mocks.MockPrinter, expected *render.Bitmap) {
+				m.On("PrintImage", expected).Return(nil).Once()
 			},
 			expectedError: "",
 		},
@@ -128,8 +136,8 @@ func TestPrintService_PrintJSON(t *testing.T) {
 				},
 				"timestamp": "2025-12-12T10:00:00Z",
 			},
-			mockSetup: func(m *mocks.MockPrinter, expectedJSON string) {
-				m.On("PrintText", expectedJSON).Return(nil).Once()
+			mockSetup: func(m *mocks.MockPrinter, expected *render.Bitmap) {
+				m.On("PrintImage", expected).Return(nil).Once()
 			},
 			expectedError: "",
 		},
@@ -139,16 +147,16 @@ func TestPrintService_PrintJSON(t *testing.T) {
 				map[string]interface{}{"id": 1, "name": "Item 1"},
 				map[string]interface{}{"id": 2, "name": "Item 2"},
 			},
-			mockSetup: func(m *mocks.MockPrinter, expectedJSON string) {
-				m.On("PrintText", expectedJSON).Return(nil).Once()
+			mockSetup: func(m *mocks.MockPrinter, expected *render.Bitmap) {
+				m.On("PrintImage", expected).Return(nil).Once()
 			},
 			expectedError: "",
 		},
 		{
 			name: "nil data returns error",
 			data: nil,
-			mockSetup: func(m *mocks.MockPrinter, expectedJSON string) {
-				// No call to PrintText expected
+			mockSetup: func(m *mocks.MockPrinter, expected *render.Bitmap) {
+				// No call to PrintImage expected
 			},
 			expectedError: "data cannot be nil",
 		},
@@ -157,8 +165,8 @@ func TestPrintService_PrintJSON(t *testing.T) {
 			data: map[string]interface{}{
 				"test": "data",
 			},
-			mockSetup: func(m *mocks.MockPrinter, expectedJSON string) {
-				m.On("PrintText", expectedJSON).Return(errors.New("connection lost")).Once()
+			mockSetup: func(m *mocks.MockPrinter, expected *render.Bitmap) {
+				m.On("PrintImage", expected).Return(errors.New("connection lost")).Once()
 			},
 			expectedError: "connection lost",
 		},
@@ -169,15 +177,15 @@ func TestPrintService_PrintJSON(t *testing.T) {
 			// Arrange
 			mockPrinter := new(mocks.MockPrinter)
 
-			// Generate expected JSON if data is not nil
-			var expectedJSON string
+			// Render the expected receipt if data is not nil
+			var expected *render.Bitmap
 			if tt.data != nil {
-				jsonBytes, err := json.MarshalIndent(tt.data, "", "  ")
-				require.NoError(t, err, "Test setup failed: could not marshal test data")
-				expectedJSON = string(jsonBytes)
+				var err error
+				expected, err = render.RenderJSON(tt.data, render.DefaultJSONOptions())
+				require.NoError(t, err, "Test setup failed: could not render test data")
 			}
 
-			tt.mockSetup(mockPrinter, expectedJSON)
+			tt.mockSetup(mockPrinter, expected)
 			service := NewPrintService(mockPrinter)
 
 			// Act
@@ -192,37 +200,468 @@ func TestPrintService_PrintJSON(t *testing.T) {
 			}
 
 			mockPrinter.AssertExpectations(t)
+			mockPrinter.AssertNotCalled(t, "PrintText", mock.Anything)
 		})
 	}
 }
 
-func TestPrintService_PrintJSON_VerifyFormatting(t *testing.T) {
-	// This test verifies that JSON is pretty-printed with proper indentation
+func TestPrintService_PrintJSON_Fields(t *testing.T) {
+	// Arrange: only the selected fields are printed
+	data := map[string]interface{}{
+		"customer": map[string]interface{}{"name": "Jane", "email": "jane@example.com"},
+		"items": []interface{}{
+			map[string]interface{}{"name": "Coffee", "sku": "C-1"},
+			map[string]interface{}{"name": "Milk", "sku": "M-7"},
+		},
+		"total": 12.5,
+	}
+	expected, err := render.RenderJSON(map[string]interface{}{
+		"customer": map[string]interface{}{"name": "Jane"},
+		"items": []interface{}{
+			map[string]interface{}{"name": "Coffee"},
+			map[string]interface{}{"name": "Milk"},
+		},
+		"total": 12.5,
+	}, render.DefaultJSONOptions())
+	require.NoError(t, err)
+
 	mockPrinter := new(mocks.MockPrinter)
+	mockPrinter.On("PrintImage", expected).Return(nil).Once()
 	service := NewPrintService(mockPrinter)
+	defer service.Close()
 
-	data := map[string]interface{}{
-		"name": "Test User",
-		"age":  25,
-	}
+	// Act
+	_, err = service.Print(context.Background(), JobRequest{
+		Kind:   JobJSON,
+		Data:   data,
+		Fields: []string{"customer.name", "items[*].name", "$.total", "missing"},
+	})
 
-	// Capture the actual text sent to printer
-	var capturedText string
-	mockPrinter.On("PrintText", mock.AnythingOfType("string")).
-		Run(func(args mock.Arguments) {
-			capturedText = args.Get(0).(string)
-		}).
-		Return(nil).
-		Once()
+	// Assert
+	require.NoError(t, err)
+	mockPrinter.AssertExpectations(t)
+}
+
+func TestPrintService_Reprint(t *testing.T) {
+	// Arrange
+	mockPrinter := new(mocks.MockPrinter)
+	mockPrinter.On("PrintText", "Receipt").Return(nil).Twice()
+	service := NewPrintService(mockPrinter)
+	defer service.Close()
+
+	original, err := service.Print(context.Background(), JobRequest{Kind: JobText, Text: "Receipt"})
+	require.NoError(t, err)
 
 	// Act
-	err := service.PrintJSON(data)
+	reprint, err := service.Reprint(original.ID)
+	require.NoError(t, err)
+	reprint, err = service.queues[DefaultPrinterName].Wait(context.Background(), reprint.ID)
 
 	// Assert
 	require.NoError(t, err)
-	assert.Contains(t, capturedText, "  ", "JSON should be indented")
-	assert.Contains(t, capturedText, "\"name\": \"Test User\"")
-	assert.Contains(t, capturedText, "\"age\": 25")
+	assert.NotEqual(t, original.ID, reprint.ID)
+	assert.Equal(t, original.Request, reprint.Request)
+	assert.Len(t, service.Jobs(), 2)
+
+	_, err = service.Reprint("missing")
+	assert.ErrorIs(t, err, ErrJobNotFound)
 
 	mockPrinter.AssertExpectations(t)
 }
+
+func TestPrintService_Reprint_PayloadDropped(t *testing.T) {
+	// Arrange: the store kept a large finished job without its image
+	finished := time.Now()
+	store := newMemoryStore(Job{
+		ID:             "large",
+		Request:        JobRequest{Kind: JobImage},
+		State:          JobDone,
+		CreatedAt:      finished,
+		FinishedAt:     &finished,
+		PayloadDropped: true,
+	})
+	service, err := NewPrintServiceWithConfig(new(mocks.MockPrinter), PrintServiceConfig{Queue: QueueConfig{Store: store}})
+	require.NoError(t, err)
+	defer service.Close()
+
+	// Act
+	_, err = service.Reprint("large")
+
+	// Assert
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Contains(t, err.Error(), "without its payload")
+}
+
+func TestPrintService_PrintImage(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 192, 96))))
+	validPNG := buf.Bytes()
+
+	tests := []struct {
+		name          string
+		data          []byte
+		dither        render.Dither
+		mockSetup     func(*mocks.MockPrinter)
+		expectedError string
+	}{
+		{
+			name:   "image is scaled to the print head",
+			data:   validPNG,
+			dither: render.DitherAtkinson,
+			mockSetup: func(m *mocks.MockPrinter) {
+				m.On("PrintImage", mock.MatchedBy(func(b *render.Bitmap) bool {
+					return b.Width == render.HeadWidth && b.Height == 192
+				})).Return(nil).Once()
+			},
+		},
+		{
+			name:          "empty image returns error",
+			mockSetup:     func(m *mocks.MockPrinter) {},
+			expectedError: "image cannot be empty",
+		},
+		{
+			name:          "undecodable image returns error",
+			data:          []byte("definitely not an image"),
+			mockSetup:     func(m *mocks.MockPrinter) {},
+			expectedError: "unsupported image",
+		},
+		{
+			name:          "unknown dither returns error",
+			data:          validPNG,
+			dither:        "halftone",
+			mockSetup:     func(m *mocks.MockPrinter) {},
+			expectedError: "unknown dither mode",
+		},
+		{
+			name: "printer error is propagated",
+			data: validPNG,
+			mockSetup: func(m *mocks.MockPrinter) {
+				m.On("PrintImage", mock.Anything).Return(errors.New("paper jam")).Once()
+			},
+			expectedError: "paper jam",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockPrinter := new(mocks.MockPrinter)
+			tt.mockSetup(mockPrinter)
+			service := NewPrintService(mockPrinter)
+			defer service.Close()
+
+			// Act
+			err := service.PrintImage(tt.data, tt.dither)
+
+			// Assert
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+			mockPrinter.AssertExpectations(t)
+		})
+	}
+}
+
+// statusPrinter is a mock printer that also reports its status.
+type statusPrinter struct {
+	mocks.MockPrinter
+	status PrinterStatus
+	err    error
+}
+
+func (p *statusPrinter) Status() (PrinterStatus, error) {
+	return p.status, p.err
+}
+
+func TestPrintService_Markdown(t *testing.T) {
+	// Arrange
+	req := JobRequest{Kind: JobMarkdown, Text: "# Shopping\n- [ ] milk"}
+	expected, err := Rasterize(req)
+	require.NoError(t, err)
+
+	mockPrinter := new(mocks.MockPrinter)
+	mockPrinter.On("PrintImage", expected).Return(nil).Once()
+	service := NewPrintService(mockPrinter)
+	defer service.Close()
+
+	// Act
+	_, err = service.Print(context.Background(), req)
+
+	// Assert
+	require.NoError(t, err)
+	mockPrinter.AssertExpectations(t)
+	mockPrinter.AssertNotCalled(t, "PrintText", mock.Anything)
+}
+
+func TestPrintService_PrinterStatus(t *testing.T) {
+	status := PrinterStatus{Battery: 42, PaperPresent: true, Firmware: "V2.11"}
+
+	tests := []struct {
+		name          string
+		printer       Printer
+		expected      PrinterStatus
+		expectedError error
+	}{
+		{
+			name:     "printer reports its status",
+			printer:  &statusPrinter{status: status},
+			expected: status,
+		},
+		{
+			name:          "status query fails",
+			printer:       &statusPrinter{err: ErrPrinterDisconnected},
+			expectedError: ErrPrinterDisconnected,
+		},
+		{
+			name:          "printer without status support",
+			printer:       new(mocks.MockPrinter),
+			expectedError: ErrStatusUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service := NewPrintService(tt.printer)
+			defer service.Close()
+
+			// Act
+			got, err := service.PrinterStatus("")
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestPrinterStatus_Ready(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   PrinterStatus
+		expected error
+	}{
+		{name: "ready", status: PrinterStatus{PaperPresent: true}},
+		{name: "paper out", status: PrinterStatus{}, expected: ErrPaperOut},
+		{name: "lid open", status: PrinterStatus{PaperPresent: true, LidOpen: true}, expected: ErrLidOpen},
+		{name: "overheated", status: PrinterStatus{PaperPresent: true, Overheated: true}, expected: ErrOverheated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.status.Ready())
+		})
+	}
+}
+
+// scanningPrinter is a mock printer that can scan for devices.
+type scanningPrinter struct {
+	mocks.MockPrinter
+	devices []DiscoveredDevice
+}
+
+func (p *scanningPrinter) Scan(ctx context.Context, duration time.Duration) ([]DiscoveredDevice, error) {
+	return p.devices, nil
+}
+
+func TestPrintService_ScanDevices(t *testing.T) {
+	devices := []DiscoveredDevice{{Name: "PeriPage_A6", Address: "AA:BB:CC:DD:EE:FF", RSSI: -50, Matches: true}}
+
+	service := NewPrintService(&scanningPrinter{devices: devices})
+	defer service.Close()
+	got, err := service.ScanDevices(context.Background(), "", time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, devices, got)
+
+	unsupported := NewPrintService(new(mocks.MockPrinter))
+	defer unsupported.Close()
+	_, err = unsupported.ScanDevices(context.Background(), "", time.Second)
+	assert.ErrorIs(t, err, ErrScanUnavailable)
+}
+
+// newRoutingService creates a service with a front and a kitchen printer,
+// with the kitchen printer as the default.
+func newRoutingService(t *testing.T, front, kitchen Printer) *PrintService {
+	t.Helper()
+	registry := NewPrinterRegistry()
+	require.NoError(t, registry.Register("front", "mock", front))
+	require.NoError(t, registry.Register("kitchen", "ble", kitchen))
+	require.NoError(t, registry.SetDefault("kitchen"))
+
+	service, err := NewPrintServiceWithRegistry(registry, PrintServiceConfig{})
+	require.NoError(t, err)
+	t.Cleanup(service.Close)
+	return service
+}
+
+func TestPrintService_Routing(t *testing.T) {
+	tests := []struct {
+		name            string
+		printer         string
+		expectedPrinter string
+		expectedError   string
+	}{
+		{name: "named printer", printer: "front", expectedPrinter: "front"},
+		{name: "default printer", printer: "", expectedPrinter: "kitchen"},
+		{name: "unknown printer", printer: "bar", expectedError: "unknown printer: bar"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			front := new(mocks.MockPrinter)
+			kitchen := new(mocks.MockPrinter)
+			printers := map[string]*mocks.MockPrinter{"front": front, "kitchen": kitchen}
+			if p, ok := printers[tt.expectedPrinter]; ok {
+				p.On("PrintText", "Order 42").Return(nil).Once()
+			}
+			service := newRoutingService(t, front, kitchen)
+
+			// Act
+			job, err := service.Print(context.Background(), JobRequest{Kind: JobText, Text: "Order 42", Printer: tt.printer})
+
+			// Assert
+			if tt.expectedError != "" {
+				var validationErr *ValidationError
+				require.ErrorAs(t, err, &validationErr)
+				assert.Equal(t, tt.expectedError, err.Error())
+				assert.Empty(t, service.Jobs())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPrinter, job.Request.Printer)
+			assert.Equal(t, JobDone, job.State)
+			front.AssertExpectations(t)
+			kitchen.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPrintService_RoutingAcrossQueues(t *testing.T) {
+	// Arrange
+	front := new(mocks.MockPrinter)
+	front.On("PrintText", "first").Return(nil).Twice()
+	kitchen := new(mocks.MockPrinter)
+	kitchen.On("PrintText", "second").Return(nil)
+	service := newRoutingService(t, front, kitchen)
+
+	first, err := service.Print(context.Background(), JobRequest{Kind: JobText, Text: "first", Printer: "front"})
+	require.NoError(t, err)
+	second, err := service.Print(context.Background(), JobRequest{Kind: JobText, Text: "second"})
+	require.NoError(t, err)
+
+	// Act
+	jobs := service.Jobs()
+	found, findErr := service.Job(first.ID)
+	reprint, reprintErr := service.Reprint(first.ID)
+
+	// Assert
+	require.Len(t, jobs, 2)
+	assert.Equal(t, []string{first.ID, second.ID}, []string{jobs[0].ID, jobs[1].ID})
+	require.NoError(t, findErr)
+	assert.Equal(t, "front", found.Request.Printer)
+	require.NoError(t, reprintErr)
+	assert.Equal(t, "front", reprint.Request.Printer)
+	_, err = service.queues["front"].Wait(context.Background(), reprint.ID)
+	require.NoError(t, err)
+	front.AssertExpectations(t)
+	kitchen.AssertExpectations(t)
+}
+
+func TestPrintService_Printers(t *testing.T) {
+	// Arrange
+	service := newRoutingService(t,
+		new(mocks.MockPrinter),
+		&connectionPrinter{state: ConnectionConnecting},
+	)
+
+	// Act
+	printers := service.Printers()
+
+	// Assert
+	assert.Equal(t, []PrinterInfo{
+		{Name: "front", Type: "mock", Connection: ConnectionConnected},
+		{Name: "kitchen", Type: "ble", Default: true, Connection: ConnectionConnecting},
+	}, printers)
+
+	_, err := service.PrinterStatus("bar")
+	assert.ErrorIs(t, err, ErrPrinterNotFound)
+	_, err = service.ScanDevices(context.Background(), "bar", time.Second)
+	assert.ErrorIs(t, err, ErrPrinterNotFound)
+}
+
+func TestNewPrintServiceWithRegistry_RestoresJobsPerPrinter(t *testing.T) {
+	// Arrange
+	store := newMemoryStore(
+		Job{ID: "a", State: JobQueued, Request: JobRequest{Kind: JobText, Text: "to front", Printer: "front"}},
+		Job{ID: "b", State: JobQueued, Request: JobRequest{Kind: JobText, Text: "to default"}},
+	)
+	front := new(mocks.MockPrinter)
+	front.On("PrintText", "to front").Return(nil)
+	kitchen := new(mocks.MockPrinter)
+	kitchen.On("PrintText", "to default").Return(nil)
+
+	registry := NewPrinterRegistry()
+	require.NoError(t, registry.Register("kitchen", "mock", kitchen))
+	require.NoError(t, registry.Register("front", "mock", front))
+
+	// Act
+	service, err := NewPrintServiceWithRegistry(registry, PrintServiceConfig{Queue: QueueConfig{Store: store}})
+	require.NoError(t, err)
+	defer service.Close()
+
+	// Assert
+	for _, id := range []string{"a", "b"} {
+		queue, err := service.queueOf(id)
+		require.NoError(t, err)
+		job, err := queue.Wait(context.Background(), id)
+		require.NoError(t, err)
+		assert.Equal(t, JobDone, job.State)
+	}
+	front.AssertExpectations(t)
+	kitchen.AssertExpectations(t)
+}
+
+func TestNewPrintServiceWithRegistry_FailsJobsOfRemovedPrinters(t *testing.T) {
+	// Arrange: the store holds jobs of a printer that is no longer configured
+	created := time.Now().Add(-48 * time.Hour)
+	finished := created.Add(time.Second)
+	store := newMemoryStore(
+		Job{ID: "queued", State: JobQueued, CreatedAt: created, Request: JobRequest{Kind: JobText, Text: "to bar", Printer: "bar"}},
+		Job{ID: "old", State: JobDone, CreatedAt: created, FinishedAt: &finished, Request: JobRequest{Kind: JobText, Text: "to bar", Printer: "bar"}},
+	)
+	kitchen := new(mocks.MockPrinter)
+
+	registry := NewPrinterRegistry()
+	require.NoError(t, registry.Register("kitchen", "mock", kitchen))
+	require.NoError(t, registry.Register("front", "mock", new(mocks.MockPrinter)))
+
+	// Act
+	service, err := NewPrintServiceWithRegistry(registry, PrintServiceConfig{Queue: QueueConfig{Store: store, Retention: 24 * time.Hour}})
+	require.NoError(t, err)
+	defer service.Close()
+
+	// Assert: the unfinished job fails instead of printing elsewhere
+	job, err := service.Job("queued")
+	require.NoError(t, err)
+	assert.Equal(t, JobFailed, job.State)
+	assert.Equal(t, "printer bar is no longer configured", job.Error)
+	stored, ok := store.get("queued")
+	require.True(t, ok)
+	assert.Equal(t, JobFailed, stored.State)
+	kitchen.AssertNotCalled(t, "PrintText", mock.Anything)
+
+	// Assert: finished jobs of the printer are pruned like any other
+	_, err = service.Job("old")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+	_, ok = store.get("old")
+	assert.False(t, ok)
+}
@@ -0,0 +1,95 @@
+package core
+
+import "fmt"
+
+// Density is the print darkness.
+type Density string
+
+const (
+	// DensityLight prints lighter, saving battery and print head wear.
+	DensityLight Density = "light"
+	// DensityNormal is the printer's usual darkness.
+	DensityNormal Density = "normal"
+	// DensityDark prints darker, for faint paper or fine detail.
+	DensityDark Density = "dark"
+)
+
+// Speed is the print speed. Slower printing gives darker, more even output.
+type Speed string
+
+const (
+	// SpeedSlow gives the darkest, most even output.
+	SpeedSlow Speed = "slow"
+	// SpeedNormal is the printer's usual speed.
+	SpeedNormal Speed = "normal"
+	// SpeedFast prints quickest, at the cost of lighter output.
+	SpeedFast Speed = "fast"
+)
+
+// PaperType is the kind of paper loaded in the printer.
+type PaperType string
+
+const (
+	// PaperContinuous is plain receipt paper.
+	PaperContinuous PaperType = "continuous"
+	// PaperLabel is a label roll with gaps between the labels.
+	PaperLabel PaperType = "label"
+	// PaperSticker is sticker paper with a black mark between the stickers.
+	PaperSticker PaperType = "sticker"
+)
+
+// PrintSettings control how the printer prints a job. Empty fields fall
+// back to the printer's defaults, and from there to DefaultPrintSettings.
+type PrintSettings struct {
+	Density Density   `json:"density,omitempty" example:"normal" enums:"light,normal,dark"`
+	Speed   Speed     `json:"speed,omitempty" example:"normal" enums:"slow,normal,fast"`
+	Paper   PaperType `json:"paper,omitempty" example:"continuous" enums:"continuous,label,sticker"`
+}
+
+// DefaultPrintSettings returns the settings a printer starts with.
+func DefaultPrintSettings() PrintSettings {
+	return PrintSettings{
+		Density: DensityNormal,
+		Speed:   SpeedNormal,
+		Paper:   PaperContinuous,
+	}
+}
+
+// IsZero reports whether no setting is given.
+func (s PrintSettings) IsZero() bool {
+	return s == PrintSettings{}
+}
+
+// Or returns s with its empty fields taken from defaults.
+func (s PrintSettings) Or(defaults PrintSettings) PrintSettings {
+	if s.Density == "" {
+		s.Density = defaults.Density
+	}
+	if s.Speed == "" {
+		s.Speed = defaults.Speed
+	}
+	if s.Paper == "" {
+		s.Paper = defaults.Paper
+	}
+	return s
+}
+
+// Validate checks that every given setting is known.
+func (s PrintSettings) Validate() error {
+	switch s.Density {
+	case "", DensityLight, DensityNormal, DensityDark:
+	default:
+		return fmt.Errorf("unknown density %q (use light, normal or dark)", s.Density)
+	}
+	switch s.Speed {
+	case "", SpeedSlow, SpeedNormal, SpeedFast:
+	default:
+		return fmt.Errorf("unknown speed %q (use slow, normal or fast)", s.Speed)
+	}
+	switch s.Paper {
+	case "", PaperContinuous, PaperLabel, PaperSticker:
+	default:
+		return fmt.Errorf("unknown paper type %q (use continuous, label or sticker)", s.Paper)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+	"image"
+	"testing"
+
+	"github.com/princem/peripage-printer/internal/core/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// settingsPrinter is a mock printer that records the settings of each job.
+type settingsPrinter struct {
+	mocks.MockPrinter
+	settings []PrintSettings
+}
+
+func (p *settingsPrinter) PrintTextWithSettings(text string, settings PrintSettings) error {
+	p.settings = append(p.settings, settings)
+	return nil
+}
+
+func (p *settingsPrinter) PrintImageWithSettings(img image.Image, settings PrintSettings) error {
+	p.settings = append(p.settings, settings)
+	return nil
+}
+
+func TestPrintSettings_Or(t *testing.T) {
+	// Arrange
+	job := PrintSettings{Density: DensityDark}
+	printer := PrintSettings{Density: DensityLight, Paper: PaperLabel}
+
+	// Act
+	got := job.Or(printer).Or(DefaultPrintSettings())
+
+	// Assert
+	assert.Equal(t, PrintSettings{Density: DensityDark, Speed: SpeedNormal, Paper: PaperLabel}, got)
+}
+
+func TestPrintSettings_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings PrintSettings
+		wantErr  string
+	}{
+		{name: "empty", settings: PrintSettings{}},
+		{name: "all set", settings: PrintSettings{Density: DensityLight, Speed: SpeedFast, Paper: PaperSticker}},
+		{name: "unknown density", settings: PrintSettings{Density: "black"}, wantErr: `unknown density "black"`},
+		{name: "unknown speed", settings: PrintSettings{Speed: "warp"}, wantErr: `unknown speed "warp"`},
+		{name: "unknown paper", settings: PrintSettings{Paper: "a4"}, wantErr: `unknown paper type "a4"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.settings.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPrintService_PrintSettings(t *testing.T) {
+	t.Run("settings reach the printer", func(t *testing.T) {
+		// Arrange
+		printer := new(settingsPrinter)
+		service := NewPrintService(printer)
+		defer service.Close()
+		settings := PrintSettings{Density: DensityDark, Paper: PaperLabel}
+
+		// Act
+		_, err := service.Print(context.Background(), JobRequest{Kind: JobText, Text: "label", PrintSettings: settings})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []PrintSettings{settings}, printer.settings)
+	})
+
+	t.Run("printer without settings support", func(t *testing.T) {
+		// Arrange
+		service := NewPrintService(new(mocks.MockPrinter))
+		defer service.Close()
+
+		// Act
+		_, err := service.Print(context.Background(), JobRequest{Kind: JobText, Text: "label", PrintSettings: PrintSettings{Speed: SpeedSlow}})
+
+		// Assert
+		assert.ErrorIs(t, err, ErrSettingsUnavailable)
+	})
+
+	t.Run("invalid settings are rejected", func(t *testing.T) {
+		// Arrange
+		service := NewPrintService(new(settingsPrinter))
+		defer service.Close()
+
+		// Act
+		_, err := service.Submit(JobRequest{Kind: JobText, Text: "label", PrintSettings: PrintSettings{Density: "black"}})
+
+		// Assert
+		var validationErr *ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+	})
+}
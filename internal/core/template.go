@@ -0,0 +1,233 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Template limits.
+const (
+	// MaxTemplateSize is the largest template body accepted, in bytes.
+	MaxTemplateSize = 64 << 10
+	// MaxTemplateOutput is the most text a template may render, in bytes.
+	MaxTemplateOutput = 256 << 10
+)
+
+// templateNamePattern restricts template names to what fits in a URL path
+// segment. It is kept apart from printerNamePattern so printer naming rules
+// can change without changing which templates are valid.
+var templateNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Template errors.
+var (
+	// ErrTemplateNotFound is returned for unknown template names.
+	ErrTemplateNotFound = errors.New("template not found")
+	// ErrTemplateExists is returned when creating a template whose name is taken.
+	ErrTemplateExists = errors.New("template already exists")
+)
+
+// Template is a named print layout written in Go text/template syntax.
+// Printing it fills it with a data map and prints the result as text or
+// Markdown.
+type Template struct {
+	Name        string `json:"name" example:"visitor-badge"`
+	Description string `json:"description,omitempty" example:"Badge for front desk visitors"`
+	// Format is the kind of job the rendered template prints.
+	Format JobKind `json:"format" example:"markdown" enums:"text,markdown"`
+	// Body is the template source.
+	Body      string    `json:"body" example:"# {{.name | upper}}\nVisiting {{.host}}\n{{now | date \"02 Jan 15:04\"}}"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks the name, format and syntax of the template.
+func (t Template) Validate() error {
+	if !templateNamePattern.MatchString(t.Name) {
+		return &ValidationError{Message: fmt.Sprintf("invalid template name %q: use lowercase letters, digits, '-' and '_'", t.Name)}
+	}
+	if t.Format != JobText && t.Format != JobMarkdown {
+		return &ValidationError{Message: fmt.Sprintf("unknown template format %q: use text or markdown", t.Format)}
+	}
+	if strings.TrimSpace(t.Body) == "" {
+		return &ValidationError{Message: "template body cannot be empty"}
+	}
+	if len(t.Body) > MaxTemplateSize {
+		return &ValidationError{Message: fmt.Sprintf("template body is larger than %d bytes", MaxTemplateSize)}
+	}
+	if _, err := t.parse(); err != nil {
+		return &ValidationError{Message: err.Error()}
+	}
+	return nil
+}
+
+// parse compiles the template body with the helper functions.
+func (t Template) parse() (*template.Template, error) {
+	tmpl := template.New(t.Name).Funcs(templateFuncs)
+	if t.Format != JobMarkdown {
+		tmpl.Funcs(template.FuncMap{"qr": qrNeedsMarkdown, "barcode": barcodeNeedsMarkdown, "table": tableNeedsMarkdown})
+	}
+	return tmpl.Parse(t.Body)
+}
+
+// Render fills the template with data and returns the print job it makes.
+// Errors executing the template, such as a helper given a value it cannot
+// format, are validation errors.
+func (t Template) Render(data map[string]interface{}) (JobRequest, error) {
+	tmpl, err := t.parse()
+	if err != nil {
+		return JobRequest{}, &ValidationError{Message: err.Error()}
+	}
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+
+	out := &limitedWriter{left: MaxTemplateOutput}
+	if err := tmpl.Execute(out, data); err != nil {
+		return JobRequest{}, &ValidationError{Message: err.Error()}
+	}
+	text := out.buf.String()
+	if strings.TrimSpace(text) == "" {
+		return JobRequest{}, &ValidationError{Message: fmt.Sprintf("template %s rendered no text", t.Name)}
+	}
+	return JobRequest{Kind: t.Format, Text: text}, nil
+}
+
+// limitedWriter collects template output and fails once it would grow past
+// a byte limit, so a template that loops without bound stops early.
+type limitedWriter struct {
+	buf  strings.Builder
+	left int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > w.left {
+		return 0, fmt.Errorf("template output is larger than %d bytes", MaxTemplateOutput)
+	}
+	w.left -= len(p)
+	return w.buf.Write(p)
+}
+
+// TemplateRegistry holds the print templates by name and writes changes
+// through to an optional store. It is safe for concurrent use.
+type TemplateRegistry struct {
+	store TemplateStore
+
+	mu        sync.RWMutex
+	templates map[string]Template
+}
+
+// NewTemplateRegistry creates a registry holding the templates in store.
+// A nil store keeps templates in memory only.
+func NewTemplateRegistry(store TemplateStore) (*TemplateRegistry, error) {
+	r := &TemplateRegistry{
+		store:     store,
+		templates: make(map[string]Template),
+	}
+	if store == nil {
+		return r, nil
+	}
+
+	templates, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load templates: %w", err)
+	}
+	for _, t := range templates {
+		r.templates[t.Name] = t
+	}
+	return r, nil
+}
+
+// List returns every template sorted by name.
+func (r *TemplateRegistry) List() []Template {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	templates := make([]Template, 0, len(r.templates))
+	for _, t := range r.templates {
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates
+}
+
+// Get returns the named template.
+func (r *TemplateRegistry) Get(name string) (Template, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.templates[name]
+	if !ok {
+		return Template{}, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+	return t, nil
+}
+
+// Create adds a new template. It fails with ErrTemplateExists when the name
+// is taken.
+func (r *TemplateRegistry) Create(t Template) (Template, error) {
+	saved, _, err := r.save(t, false)
+	return saved, err
+}
+
+// Put creates the template or replaces the one with the same name, keeping
+// its creation time. It reports whether the template is new.
+func (r *TemplateRegistry) Put(t Template) (Template, bool, error) {
+	return r.save(t, true)
+}
+
+// save validates and stores a template and reports whether it is new. An
+// empty format means text.
+func (r *TemplateRegistry) save(t Template, replace bool) (Template, bool, error) {
+	if t.Format == "" {
+		t.Format = JobText
+	}
+	if err := t.Validate(); err != nil {
+		return Template{}, false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	t.CreatedAt, t.UpdatedAt = now, now
+	existing, exists := r.templates[t.Name]
+	if exists {
+		if !replace {
+			return Template{}, false, fmt.Errorf("%w: %s", ErrTemplateExists, t.Name)
+		}
+		t.CreatedAt = existing.CreatedAt
+	}
+
+	if r.store != nil {
+		if err := r.store.Save(t); err != nil {
+			return Template{}, false, fmt.Errorf("failed to save template: %w", err)
+		}
+	}
+	r.templates[t.Name] = t
+	return t, !exists, nil
+}
+
+// Delete removes the named template.
+func (r *TemplateRegistry) Delete(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.templates[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+	if r.store != nil {
+		if err := r.store.Delete(name); err != nil {
+			return fmt.Errorf("failed to delete template: %w", err)
+		}
+	}
+	delete(r.templates, name)
+	return nil
+}
@@ -0,0 +1,238 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/princem/peripage-printer/internal/core/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryTemplateStore is a TemplateStore that keeps templates in a map.
+type memoryTemplateStore struct {
+	mu        sync.Mutex
+	templates map[string]Template
+	err       error
+}
+
+func newMemoryTemplateStore(templates ...Template) *memoryTemplateStore {
+	s := &memoryTemplateStore{templates: make(map[string]Template)}
+	for _, t := range templates {
+		s.templates[t.Name] = t
+	}
+	return s
+}
+
+func (s *memoryTemplateStore) Save(t Template) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.templates[t.Name] = t
+	return nil
+}
+
+func (s *memoryTemplateStore) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	delete(s.templates, name)
+	return nil
+}
+
+func (s *memoryTemplateStore) Load() ([]Template, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	templates := make([]Template, 0, len(s.templates))
+	for _, t := range s.templates {
+		templates = append(templates, t)
+	}
+	return templates, s.err
+}
+
+func TestTemplate_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template Template
+		wantErr  string
+	}{
+		{name: "text", template: Template{Name: "order-slip", Format: JobText, Body: "Order {{.id}}"}},
+		{name: "markdown", template: Template{Name: "badge_2", Format: JobMarkdown, Body: "# {{.name}}"}},
+		{name: "invalid name", template: Template{Name: "Order Slip", Format: JobText, Body: "x"}, wantErr: "invalid template name"},
+		{name: "unknown format", template: Template{Name: "a", Format: JobImage, Body: "x"}, wantErr: "unknown template format"},
+		{name: "empty body", template: Template{Name: "a", Format: JobText, Body: " \n"}, wantErr: "body cannot be empty"},
+		{name: "too large", template: Template{Name: "a", Format: JobText, Body: strings.Repeat("x", MaxTemplateSize+1)}, wantErr: "larger than"},
+		{name: "syntax error", template: Template{Name: "a", Format: JobText, Body: "{{.id"}, wantErr: "unclosed action"},
+		{name: "unknown function", template: Template{Name: "a", Format: JobText, Body: "{{money .id}}"}, wantErr: `function "money" not defined`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.template.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestTemplate_Render(t *testing.T) {
+	// Arrange
+	tmpl := Template{
+		Name:   "order-slip",
+		Format: JobMarkdown,
+		Body:   "# Order {{.id}}\n{{range .items}}- {{.name}} x{{.qty}}\n{{end}}",
+	}
+
+	// Act
+	req, err := tmpl.Render(map[string]interface{}{
+		"id": "A-17",
+		"items": []interface{}{
+			map[string]interface{}{"name": "Coffee", "qty": 2},
+			map[string]interface{}{"name": "Milk", "qty": 1},
+		},
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, JobRequest{Kind: JobMarkdown, Text: "# Order A-17\n- Coffee x2\n- Milk x1\n"}, req)
+}
+
+func TestTemplate_RenderNothing(t *testing.T) {
+	_, err := Template{Name: "a", Format: JobText, Body: "{{if .show}}hi{{end}}"}.Render(nil)
+
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+}
+
+func TestTemplate_RenderTooLarge(t *testing.T) {
+	// Arrange: a loop over a large list writes more than a template may.
+	items := make([]interface{}, MaxTemplateOutput/10)
+	tmpl := Template{Name: "a", Format: JobText, Body: "{{range .items}}0123456789X{{end}}"}
+
+	// Act
+	_, err := tmpl.Render(map[string]interface{}{"items": items})
+
+	// Assert
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Contains(t, err.Error(), "template output is larger than")
+}
+
+func TestTemplateRegistry(t *testing.T) {
+	badge := Template{Name: "badge", Format: JobMarkdown, Body: "# {{.name}}"}
+
+	t.Run("create, replace and delete", func(t *testing.T) {
+		// Arrange
+		store := newMemoryTemplateStore()
+		registry, err := NewTemplateRegistry(store)
+		require.NoError(t, err)
+
+		// Act & Assert
+		created, err := registry.Create(badge)
+		require.NoError(t, err)
+		assert.False(t, created.CreatedAt.IsZero())
+		assert.Equal(t, created.CreatedAt, created.UpdatedAt)
+
+		_, err = registry.Create(badge)
+		assert.ErrorIs(t, err, ErrTemplateExists)
+
+		replaced, isNew, err := registry.Put(Template{Name: "badge", Body: "{{.name}}"})
+		require.NoError(t, err)
+		assert.False(t, isNew)
+		assert.Equal(t, JobText, replaced.Format, "format defaults to text")
+		assert.Equal(t, created.CreatedAt, replaced.CreatedAt)
+		assert.Equal(t, replaced, store.templates["badge"])
+
+		got, err := registry.Get("badge")
+		require.NoError(t, err)
+		assert.Equal(t, replaced, got)
+
+		require.NoError(t, registry.Delete("badge"))
+		assert.Empty(t, store.templates)
+		assert.ErrorIs(t, registry.Delete("badge"), ErrTemplateNotFound)
+		_, err = registry.Get("badge")
+		assert.ErrorIs(t, err, ErrTemplateNotFound)
+	})
+
+	t.Run("loads the store and lists by name", func(t *testing.T) {
+		registry, err := NewTemplateRegistry(newMemoryTemplateStore(
+			Template{Name: "slip", Format: JobText, Body: "x"},
+			badge,
+		))
+		require.NoError(t, err)
+
+		list := registry.List()
+		require.Len(t, list, 2)
+		assert.Equal(t, "badge", list[0].Name)
+		assert.Equal(t, "slip", list[1].Name)
+	})
+
+	t.Run("invalid templates are not stored", func(t *testing.T) {
+		store := newMemoryTemplateStore()
+		registry, err := NewTemplateRegistry(store)
+		require.NoError(t, err)
+
+		_, _, err = registry.Put(Template{Name: "bad", Body: "{{"})
+
+		var validationErr *ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Empty(t, store.templates)
+		assert.Empty(t, registry.List())
+	})
+
+	t.Run("store errors", func(t *testing.T) {
+		store := newMemoryTemplateStore()
+		registry, err := NewTemplateRegistry(store)
+		require.NoError(t, err)
+		store.err = errors.New("disk full")
+
+		_, err = registry.Create(badge)
+
+		assert.ErrorContains(t, err, "disk full")
+		assert.Empty(t, registry.List(), "a template that was not saved is not kept")
+	})
+
+	t.Run("store fails to load", func(t *testing.T) {
+		store := newMemoryTemplateStore()
+		store.err = errors.New("corrupt")
+
+		_, err := NewTemplateRegistry(store)
+
+		assert.ErrorContains(t, err, "corrupt")
+	})
+}
+
+func TestPrintService_RenderTemplate(t *testing.T) {
+	// Arrange
+	mockPrinter := new(mocks.MockPrinter)
+	mockPrinter.On("PrintText", "Visitor: Ada\n").Return(nil).Once()
+	service, err := NewPrintServiceWithConfig(mockPrinter, PrintServiceConfig{
+		Templates: newMemoryTemplateStore(Template{Name: "badge", Format: JobText, Body: "Visitor: {{.name}}\n"}),
+	})
+	require.NoError(t, err)
+	defer service.Close()
+
+	// Act
+	req, err := service.RenderTemplate("badge", map[string]interface{}{"name": "Ada"})
+	require.NoError(t, err)
+	_, err = service.Print(context.Background(), req)
+
+	// Assert
+	require.NoError(t, err)
+	mockPrinter.AssertExpectations(t)
+
+	_, err = service.RenderTemplate("missing", nil)
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+}
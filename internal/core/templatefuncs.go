@@ -0,0 +1,347 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/princem/peripage-printer/internal/barcode"
+	"github.com/princem/peripage-printer/internal/qr"
+	"github.com/princem/peripage-printer/internal/render"
+)
+
+// templateFuncs are the helpers available in print templates. Helpers take
+// the value last so they work at the end of a pipeline, as in
+// {{.total | currency "$"}} or {{.name | padRight 16}}. Widths count
+// characters, which line up in monospace text such as Markdown code blocks.
+var templateFuncs = template.FuncMap{
+	"now":      time.Now,
+	"date":     formatDate,
+	"currency": formatCurrency,
+	"padLeft":  padLeft,
+	"padRight": padRight,
+	"center":   center,
+	"truncate": truncate,
+	"repeat":   repeat,
+	"upper":    func(v interface{}) string { return strings.ToUpper(toText(v)) },
+	"lower":    func(v interface{}) string { return strings.ToLower(toText(v)) },
+	"default":  defaultValue,
+	"qr":       qrBlock,
+	"barcode":  barcodeBlock,
+	"table":    tableBlock,
+	"cell":     cellText,
+}
+
+// dateLayouts are the string formats date accepts, besides RFC 3339.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// formatDate formats a time with a Go layout such as "02 Jan 2006 15:04".
+// The time may be a time.Time, a string in RFC 3339 or "2006-01-02" form,
+// or a number of seconds since the Unix epoch.
+func formatDate(layout string, v interface{}) (string, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return t.Format(layout), nil
+	case string:
+		for _, l := range dateLayouts {
+			if parsed, err := time.Parse(l, t); err == nil {
+				return parsed.Format(layout), nil
+			}
+		}
+		return "", fmt.Errorf("date: cannot parse %q as a date", t)
+	}
+
+	seconds, err := toNumber(v)
+	if err != nil {
+		return "", fmt.Errorf("date: %w", err)
+	}
+	sec, frac := math.Modf(seconds)
+	return time.Unix(int64(sec), int64(frac*1e9)).Format(layout), nil
+}
+
+// formatCurrency formats an amount with two decimals, thousands separators
+// and the symbol in front, as in "-$1,234.50".
+func formatCurrency(symbol string, v interface{}) (string, error) {
+	amount, err := toNumber(v)
+	if err != nil {
+		return "", fmt.Errorf("currency: %w", err)
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+	}
+	digits := strconv.FormatFloat(math.Abs(amount), 'f', 2, 64)
+	whole, cents := digits[:len(digits)-3], digits[len(digits)-2:]
+
+	var grouped strings.Builder
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(d)
+	}
+	if grouped.String() == "0" && cents == "00" {
+		sign = ""
+	}
+	return sign + symbol + grouped.String() + "." + cents, nil
+}
+
+// checkLength fails when a helper would make text longer than a template
+// may render.
+func checkLength(helper string, n int) error {
+	if n > MaxTemplateOutput {
+		return fmt.Errorf("%s: result would be longer than %d bytes", helper, MaxTemplateOutput)
+	}
+	return nil
+}
+
+// repeat writes s count times.
+func repeat(count int, s string) (string, error) {
+	count = max(count, 0)
+	if len(s) > 0 && count > MaxTemplateOutput/len(s) {
+		return "", fmt.Errorf("repeat: result would be longer than %d bytes", MaxTemplateOutput)
+	}
+	return strings.Repeat(s, count), nil
+}
+
+// padLeft right-aligns a value in width characters.
+func padLeft(width int, v interface{}) (string, error) {
+	if err := checkLength("padLeft", width); err != nil {
+		return "", err
+	}
+	s := toText(v)
+	return strings.Repeat(" ", max(width-utf8.RuneCountInString(s), 0)) + s, nil
+}
+
+// padRight left-aligns a value in width characters.
+func padRight(width int, v interface{}) (string, error) {
+	if err := checkLength("padRight", width); err != nil {
+		return "", err
+	}
+	s := toText(v)
+	return s + strings.Repeat(" ", max(width-utf8.RuneCountInString(s), 0)), nil
+}
+
+// center centers a value in width characters, with the odd space on the right.
+func center(width int, v interface{}) (string, error) {
+	if err := checkLength("center", width); err != nil {
+		return "", err
+	}
+	s := toText(v)
+	space := max(width-utf8.RuneCountInString(s), 0)
+	return strings.Repeat(" ", space/2) + s + strings.Repeat(" ", space-space/2), nil
+}
+
+// truncate shortens a value to at most width characters, ending it with
+// "..." when it was cut.
+func truncate(width int, v interface{}) string {
+	s := toText(v)
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width <= 3 {
+		return string(runes[:max(width, 0)])
+	}
+	return string(runes[:width-3]) + "..."
+}
+
+// qrBlock prints a QR code of a value, with an optional caption under it.
+// It writes a fenced "qr" block on lines of its own, which RenderMarkdown
+// draws as the code, so it only works in Markdown templates.
+func qrBlock(v interface{}, caption ...string) (string, error) {
+	data := toText(v)
+	if data == "" {
+		return "", fmt.Errorf("qr: data cannot be empty")
+	}
+	if _, err := qr.Encode(data, qr.Medium); err != nil {
+		return "", fmt.Errorf("qr: %w", err)
+	}
+
+	fence := "```"
+	for strings.Contains(data, fence) {
+		fence += "`"
+	}
+	info := strings.Join(strings.Fields(strings.Join(caption, " ")), " ")
+	return "\n" + fence + "qr " + info + "\n" + data + "\n" + fence + "\n", nil
+}
+
+// qrNeedsMarkdown replaces qr in text templates, which print the fenced
+// block as written.
+func qrNeedsMarkdown(v interface{}, caption ...string) (string, error) {
+	return "", fmt.Errorf("qr: QR codes need a markdown template")
+}
+
+// barcodeBlock prints a value as a barcode of a type such as "code128" or
+// "ean13". Like qrBlock, it writes a fenced block that RenderMarkdown draws,
+// so it only works in Markdown templates.
+func barcodeBlock(typ string, v interface{}) (string, error) {
+	data := toText(v)
+	if data == "" {
+		return "", fmt.Errorf("barcode: data cannot be empty")
+	}
+	sym, err := barcode.ParseSymbology(typ)
+	if err != nil {
+		return "", fmt.Errorf("barcode: %w", err)
+	}
+	if _, err := (Barcode{Type: string(sym), Data: data}).encode(render.HeadWidth); err != nil {
+		return "", fmt.Errorf("barcode: %w", err)
+	}
+	fence := "```"
+	for strings.Contains(data, fence) {
+		fence += "`"
+	}
+	return "\n" + fence + "barcode " + string(sym) + "\n" + data + "\n" + fence + "\n", nil
+}
+
+// barcodeNeedsMarkdown replaces barcode in text templates.
+func barcodeNeedsMarkdown(typ string, v interface{}) (string, error) {
+	return "", fmt.Errorf("barcode: barcodes need a markdown template")
+}
+
+// tableBlock prints a list of objects, such as the items of an order, as a
+// table with a bold header. columns has a word per column written as
+// "[LABEL=]KEY[:WIDTH][:ALIGN]": the header label, which defaults to the
+// key, the key of the value printed, and the width and alignment as read
+// by render.ParseColumnSpec, as in "Item=name:3 Price=price:96px:right".
+// Like qrBlock, it writes a fenced block that RenderMarkdown draws, so it
+// only works in Markdown templates.
+func tableBlock(columns string, v interface{}) (string, error) {
+	words := strings.Fields(columns)
+	if len(words) == 0 {
+		return "", fmt.Errorf("table: at least one column is needed")
+	}
+	keys := make([]string, len(words))
+	labels := make([]string, len(words))
+	specs := make([]render.ColumnSpec, len(words))
+	info := make([]string, len(words))
+	for i, word := range words {
+		column, spec, _ := strings.Cut(word, ":")
+		label, key, hasLabel := strings.Cut(column, "=")
+		if !hasLabel {
+			key = label
+		}
+		if key == "" {
+			return "", fmt.Errorf("table: column %q has no key", word)
+		}
+		parsed, err := render.ParseColumnSpec(spec)
+		if err != nil {
+			return "", fmt.Errorf("table: %w", err)
+		}
+		keys[i], labels[i], specs[i] = key, cellText(label), parsed
+		info[i] = formatColumnSpec(parsed)
+	}
+	if _, err := (render.TableOptions{Columns: specs}).ColumnWidths(len(specs)); err != nil {
+		return "", fmt.Errorf("table: %w", err)
+	}
+
+	value, err := decodeJSON(v)
+	if err != nil {
+		return "", fmt.Errorf("table: %w", err)
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return "", fmt.Errorf("table: expected a list of objects")
+	}
+	rows := []string{strings.Join(labels, " | "), "---"}
+	for i, item := range items {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("table: item %d is not an object", i+1)
+		}
+		cells := make([]string, len(keys))
+		for j, key := range keys {
+			cells[j] = cellText(fields[key])
+		}
+		rows = append(rows, strings.Join(cells, " | "))
+	}
+
+	body := strings.Join(rows, "\n")
+	fence := "```"
+	for strings.Contains(body, fence) {
+		fence += "`"
+	}
+	return "\n" + fence + "table " + strings.Join(info, " ") + "\n" + body + "\n" + fence + "\n", nil
+}
+
+// tableNeedsMarkdown replaces table in text templates.
+func tableNeedsMarkdown(columns string, v interface{}) (string, error) {
+	return "", fmt.Errorf("table: tables need a markdown template")
+}
+
+// formatColumnSpec writes a column spec the way render.ParseColumnSpec
+// reads it.
+func formatColumnSpec(spec render.ColumnSpec) string {
+	width := strconv.Itoa(max(spec.Ratio, 1))
+	if spec.Width > 0 {
+		width = strconv.Itoa(spec.Width) + "px"
+	}
+	return width + ":" + string(spec.Align)
+}
+
+// cellText formats a value as one cell of a fenced table, escaping bars
+// and joining lines with spaces.
+func cellText(v interface{}) string {
+	s := strings.Join(strings.Fields(toText(v)), " ")
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// defaultValue returns def when v is missing, nil or an empty string.
+func defaultValue(def, v interface{}) interface{} {
+	if v == nil {
+		return def
+	}
+	if s, ok := v.(string); ok && s == "" {
+		return def
+	}
+	return v
+}
+
+// toText formats a template value for printing. Missing values print as
+// nothing.
+func toText(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// toNumber converts a number, or a string holding one, to float64.
+func toNumber(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case float32:
+		return float64(n), nil
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case json.Number:
+		return n.Float64()
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a number", n)
+		}
+		return f, nil
+	}
+	return 0, fmt.Errorf("%v is not a number", v)
+}
@@ -0,0 +1,259 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		data     map[string]interface{}
+		expected string
+	}{
+		{name: "date from string", body: `{{.when | date "02 Jan 2006 15:04"}}`, data: map[string]interface{}{"when": "2025-03-07T09:05:00Z"}, expected: "07 Mar 2025 09:05"},
+		{name: "date from day", body: `{{.when | date "Mon 2 Jan"}}`, data: map[string]interface{}{"when": "2025-03-07"}, expected: "Fri 7 Mar"},
+		{name: "date from time", body: `{{.when | date "2006"}}`, data: map[string]interface{}{"when": time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}, expected: "2024"},
+		{name: "currency", body: `{{.total | currency "$"}}`, data: map[string]interface{}{"total": 1234.5}, expected: "$1,234.50"},
+		{name: "negative currency", body: `{{.total | currency "€"}}`, data: map[string]interface{}{"total": -0.456}, expected: "-€0.46"},
+		{name: "currency from string", body: `{{.total | currency ""}}`, data: map[string]interface{}{"total": "1000000"}, expected: "1,000,000.00"},
+		{name: "currency from json number", body: `{{.total | currency "£"}}`, data: map[string]interface{}{"total": json.Number("12")}, expected: "£12.00"},
+		{name: "rounds to zero without a sign", body: `{{.total | currency "$"}}`, data: map[string]interface{}{"total": -0.001}, expected: "$0.00"},
+		{name: "padding", body: `[{{.a | padRight 5}}|{{.b | padLeft 5}}|{{.c | center 6}}]`, data: map[string]interface{}{"a": "ab", "b": 42.0, "c": "é"}, expected: "[ab   |   42|  é   ]"},
+		{name: "padding never cuts", body: `{{.a | padLeft 2}}`, data: map[string]interface{}{"a": "abcd"}, expected: "abcd"},
+		{name: "truncate", body: `{{.a | truncate 8}}|{{.b | truncate 8}}|{{.a | truncate 2}}`, data: map[string]interface{}{"a": "Espresso doppio", "b": "Latte"}, expected: "Espre...|Latte|Es"},
+		{name: "repeat", body: `{{repeat 5 "-"}}`, expected: "-----"},
+		{name: "case", body: `{{.a | upper}} {{.a | lower}}`, data: map[string]interface{}{"a": "Visitor"}, expected: "VISITOR visitor"},
+		{name: "default", body: `{{.a | default "none"}} {{.b | default "none"}} {{.c | default "none"}}`, data: map[string]interface{}{"a": "", "b": "set"}, expected: "none set none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tmpl := Template{Name: "test", Format: JobText, Body: tt.body}
+
+			// Act
+			req, err := tmpl.Render(tt.data)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, req.Text)
+		})
+	}
+}
+
+func TestTemplateFuncs_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		data map[string]interface{}
+	}{
+		{name: "unparseable date", body: `{{.when | date "2006"}}`, data: map[string]interface{}{"when": "yesterday"}},
+		{name: "currency of text", body: `{{.total | currency "$"}}`, data: map[string]interface{}{"total": "lots"}},
+		{name: "currency of nothing", body: `{{.total | currency "$"}}`},
+		{name: "repeat too long", body: `{{repeat 1000000000 "x"}}`},
+		{name: "padding too wide", body: `{{.a | padLeft 1000000000}}`, data: map[string]interface{}{"a": "x"}},
+		{name: "output too long", body: `{{repeat 200000 "x"}}{{repeat 200000 "x"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Template{Name: "test", Format: JobText, Body: "x " + tt.body}.Render(tt.data)
+
+			var validationErr *ValidationError
+			assert.ErrorAs(t, err, &validationErr)
+		})
+	}
+}
+
+func TestTemplateFuncs_Now(t *testing.T) {
+	req, err := Template{Name: "test", Format: JobText, Body: `{{now | date "2006"}}`}.Render(nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, time.Now().Format("2006"), strings.TrimSpace(req.Text))
+}
+
+func TestTemplateFuncs_QR(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		data     map[string]interface{}
+		expected string
+	}{
+		{name: "code", body: `{{qr .url}}`, data: map[string]interface{}{"url": "https://example.com"}, expected: "\n```qr \nhttps://example.com\n```\n"},
+		{name: "caption", body: `Menu{{qr .url "Scan  for the\nmenu"}}`, data: map[string]interface{}{"url": "https://example.com"}, expected: "Menu\n```qr Scan for the menu\nhttps://example.com\n```\n"},
+		{name: "data with a fence", body: "{{qr .data}}", data: map[string]interface{}{"data": "a\n```\nb"}, expected: "\n````qr \na\n```\nb\n````\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tmpl := Template{Name: "test", Format: JobMarkdown, Body: tt.body}
+
+			// Act
+			req, err := tmpl.Render(tt.data)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, req.Text)
+			_, err = Rasterize(req)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestTemplateFuncs_QRErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format JobKind
+		data   map[string]interface{}
+	}{
+		{name: "text template", format: JobText, data: map[string]interface{}{"url": "https://example.com"}},
+		{name: "missing data", format: JobMarkdown},
+		{name: "too long", format: JobMarkdown, data: map[string]interface{}{"url": strings.Repeat("x", 3000)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Template{Name: "test", Format: tt.format, Body: "x {{qr .url}}"}.Render(tt.data)
+
+			var validationErr *ValidationError
+			assert.ErrorAs(t, err, &validationErr)
+		})
+	}
+}
+
+func TestTemplateFuncs_Barcode(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		data     map[string]interface{}
+		expected string
+	}{
+		{name: "code128", body: `Bin{{barcode "code128" .bin}}`, data: map[string]interface{}{"bin": "BIN-A-17"}, expected: "Bin\n```barcode code128\nBIN-A-17\n```\n"},
+		{name: "backticks in the data", body: `{{barcode "code128" .code}}`, data: map[string]interface{}{"code": "```x"}, expected: "\n````barcode code128\n```x\n````\n"},
+		{name: "pipeline with a number", body: `{{.sku | barcode "EAN-13"}}`, data: map[string]interface{}{"sku": 400638133393}, expected: "\n```barcode ean13\n400638133393\n```\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tmpl := Template{Name: "test", Format: JobMarkdown, Body: tt.body}
+
+			// Act
+			req, err := tmpl.Render(tt.data)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, req.Text)
+			_, err = Rasterize(req)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestTemplateFuncs_BarcodeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format JobKind
+		body   string
+		data   map[string]interface{}
+	}{
+		{name: "text template", format: JobText, body: `{{barcode "code128" .bin}}`, data: map[string]interface{}{"bin": "BIN-A-17"}},
+		{name: "missing data", format: JobMarkdown, body: `{{barcode "code128" .bin}}`},
+		{name: "unknown type", format: JobMarkdown, body: `{{barcode "ean8" .bin}}`, data: map[string]interface{}{"bin": "1234567"}},
+		{name: "wrong check digit", format: JobMarkdown, body: `{{barcode "ean13" .bin}}`, data: map[string]interface{}{"bin": "4006381333932"}},
+		{name: "too long", format: JobMarkdown, body: `{{barcode "code39" .bin}}`, data: map[string]interface{}{"bin": strings.Repeat("A", 30)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Template{Name: "test", Format: tt.format, Body: "x " + tt.body}.Render(tt.data)
+
+			var validationErr *ValidationError
+			assert.ErrorAs(t, err, &validationErr)
+		})
+	}
+}
+
+func TestTemplateFuncs_Table(t *testing.T) {
+	items := []interface{}{
+		map[string]interface{}{"name": "Flat white", "qty": 2, "price": 7},
+		map[string]interface{}{"name": "Croissant | butter", "qty": 1, "price": "3.50"},
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		data     map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "labels, widths and alignment",
+			body:     `{{.items | table "Item=name:3 Qty=qty:right Price=price:96px:right"}}`,
+			data:     map[string]interface{}{"items": items},
+			expected: "\n```table 3:left 1:right 96px:right\nItem | Qty | Price\n---\nFlat white | 2 | 7\nCroissant \\| butter | 1 | 3.50\n```\n",
+		},
+		{
+			name:     "keys label the header",
+			body:     `{{table "name price" .items}}`,
+			data:     map[string]interface{}{"items": []map[string]string{{"name": "Tea"}}},
+			expected: "\n```table 1:left 1:left\nname | price\n---\nTea | \n```\n",
+		},
+		{
+			name:     "cells written by hand",
+			body:     "```table 3 right\n{{range .items}}{{.name | cell}} | {{.price | cell}}\n{{end}}```",
+			data:     map[string]interface{}{"items": items},
+			expected: "```table 3 right\nFlat white | 7\nCroissant \\| butter | 3.50\n```",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tmpl := Template{Name: "test", Format: JobMarkdown, Body: tt.body}
+
+			// Act
+			req, err := tmpl.Render(tt.data)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, req.Text)
+			_, err = Rasterize(req)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestTemplateFuncs_TableErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format JobKind
+		body   string
+		data   map[string]interface{}
+	}{
+		{name: "text template", format: JobText, body: `{{table "name" .items}}`, data: map[string]interface{}{"items": []interface{}{}}},
+		{name: "no columns", format: JobMarkdown, body: `{{table "" .items}}`, data: map[string]interface{}{"items": []interface{}{}}},
+		{name: "missing key", format: JobMarkdown, body: `{{table "Item=:3" .items}}`, data: map[string]interface{}{"items": []interface{}{}}},
+		{name: "invalid width", format: JobMarkdown, body: `{{table "name:wide" .items}}`, data: map[string]interface{}{"items": []interface{}{}}},
+		{name: "columns too wide", format: JobMarkdown, body: `{{table "a:300px b:300px" .items}}`, data: map[string]interface{}{"items": []interface{}{}}},
+		{name: "not a list", format: JobMarkdown, body: `{{table "name" .items}}`, data: map[string]interface{}{"items": "Tea"}},
+		{name: "missing list", format: JobMarkdown, body: `{{table "name" .items}}`},
+		{name: "not objects", format: JobMarkdown, body: `{{table "name" .items}}`, data: map[string]interface{}{"items": []interface{}{"Tea"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Template{Name: "test", Format: tt.format, Body: "x " + tt.body}.Render(tt.data)
+
+			var validationErr *ValidationError
+			assert.ErrorAs(t, err, &validationErr)
+		})
+	}
+}
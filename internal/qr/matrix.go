@@ -0,0 +1,318 @@
+package qr
+
+// Penalty weights for the mask evaluation rules of ISO/IEC 18004.
+const (
+	penaltyRun     = 3
+	penaltyBlock   = 3
+	penaltyFinder  = 40
+	penaltyBalance = 10
+)
+
+// matrix is the grid of modules of a symbol under construction.
+type matrix struct {
+	size    int
+	modules []bool
+	// function marks the modules of the fixed patterns and the format and
+	// version information, which hold no data and are not masked.
+	function []bool
+}
+
+func newMatrix(version int) *matrix {
+	size := symbolSize(version)
+	return &matrix{
+		size:     size,
+		modules:  make([]bool, size*size),
+		function: make([]bool, size*size),
+	}
+}
+
+// get reports whether the module at column x and row y is dark.
+func (m *matrix) get(x, y int) bool {
+	return m.modules[y*m.size+x]
+}
+
+// setFunction sets a module that is part of a function pattern.
+func (m *matrix) setFunction(x, y int, dark bool) {
+	m.modules[y*m.size+x] = dark
+	m.function[y*m.size+x] = true
+}
+
+// drawFunctionPatterns draws the timing, finder and alignment patterns and
+// the version information, and reserves the format information modules.
+func (m *matrix) drawFunctionPatterns(version int) {
+	for i := 0; i < m.size; i++ {
+		m.setFunction(6, i, i%2 == 0)
+		m.setFunction(i, 6, i%2 == 0)
+	}
+
+	m.drawFinder(3, 3)
+	m.drawFinder(m.size-4, 3)
+	m.drawFinder(3, m.size-4)
+
+	positions := alignmentPositions(version)
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			// The corners next to the finder patterns have no alignment pattern.
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			m.drawAlignment(x, y)
+		}
+	}
+
+	m.drawFormatBits(Low, 0)
+	m.drawVersionBits(version)
+}
+
+// drawFinder draws a finder pattern centred on (x, y) with the light
+// separator around it, clipped to the symbol.
+func (m *matrix) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || yy < 0 || xx >= m.size || yy >= m.size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			m.setFunction(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+// drawAlignment draws a 5x5 alignment pattern centred on (x, y).
+func (m *matrix) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			m.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// formatBits returns the 15 bits of format information for a level and
+// mask: five data bits protected by a BCH code and XORed with a fixed
+// pattern so they are never all light.
+func formatBits(level Level, mask int) int {
+	data := level.formatBits()<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// drawFormatBits draws both copies of the format information, and the dark
+// module that always sits next to the second copy.
+func (m *matrix) drawFormatBits(level Level, mask int) {
+	bits := formatBits(level, mask)
+
+	// Around the top left finder pattern.
+	for i := 0; i <= 5; i++ {
+		m.setFunction(8, i, bit(bits, i))
+	}
+	m.setFunction(8, 7, bit(bits, 6))
+	m.setFunction(8, 8, bit(bits, 7))
+	m.setFunction(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		m.setFunction(14-i, 8, bit(bits, i))
+	}
+
+	// Split between the top right and bottom left finder patterns.
+	for i := 0; i < 8; i++ {
+		m.setFunction(m.size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		m.setFunction(8, m.size-15+i, bit(bits, i))
+	}
+	m.setFunction(8, m.size-8, true)
+}
+
+// versionBits returns the 18 bits of version information: the version
+// number followed by a 12-bit BCH code.
+func versionBits(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	return version<<12 | rem
+}
+
+// drawVersionBits draws both copies of the version information, which only
+// versions 7 and up carry.
+func (m *matrix) drawVersionBits(version int) {
+	if version < 7 {
+		return
+	}
+	bits := versionBits(version)
+	for i := 0; i < 18; i++ {
+		a, b := m.size-11+i%3, i/3
+		m.setFunction(a, b, bit(bits, i))
+		m.setFunction(b, a, bit(bits, i))
+	}
+}
+
+// drawCodewords places the codewords in the modules left free by the
+// function patterns, in two-module wide columns zigzagging up and down from
+// the bottom right corner and skipping the vertical timing pattern.
+// Remainder modules after the last codeword stay light.
+func (m *matrix) drawCodewords(codewords []byte) {
+	i := 0
+	for right := m.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < m.size; vert++ {
+			y := vert
+			if upward {
+				y = m.size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if m.function[y*m.size+x] || i >= len(codewords)*8 {
+					continue
+				}
+				m.modules[y*m.size+x] = codewords[i/8]>>uint(7-i%8)&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+// maskApplies reports whether a data mask pattern inverts the module at
+// column x and row y.
+func maskApplies(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// applyMask inverts the data modules selected by a mask pattern. Applying
+// the same mask again undoes it.
+func (m *matrix) applyMask(mask int) {
+	for y := 0; y < m.size; y++ {
+		for x := 0; x < m.size; x++ {
+			if !m.function[y*m.size+x] && maskApplies(mask, x, y) {
+				m.modules[y*m.size+x] = !m.modules[y*m.size+x]
+			}
+		}
+	}
+}
+
+// chooseMask returns the mask pattern that gives the symbol the lowest
+// penalty, which makes it easiest to scan.
+func (m *matrix) chooseMask(level Level) int {
+	best, lowest := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		m.applyMask(mask)
+		m.drawFormatBits(level, mask)
+		if p := m.penalty(); lowest < 0 || p < lowest {
+			best, lowest = mask, p
+		}
+		m.applyMask(mask)
+	}
+	return best
+}
+
+// penalty scores the symbol with the four mask evaluation rules: runs of
+// five or more modules of one colour, 2x2 blocks of one colour, patterns
+// that look like a finder pattern and an uneven share of dark modules.
+func (m *matrix) penalty() int {
+	total := 0
+	for i := 0; i < m.size; i++ {
+		total += m.linePenalty(func(j int) bool { return m.get(j, i) })
+		total += m.linePenalty(func(j int) bool { return m.get(i, j) })
+	}
+
+	for y := 0; y+1 < m.size; y++ {
+		for x := 0; x+1 < m.size; x++ {
+			c := m.get(x, y)
+			if c == m.get(x+1, y) && c == m.get(x, y+1) && c == m.get(x+1, y+1) {
+				total += penaltyBlock
+			}
+		}
+	}
+
+	dark := 0
+	for _, module := range m.modules {
+		if module {
+			dark++
+		}
+	}
+	modules := m.size * m.size
+	// Every full 5% away from half dark costs penaltyBalance.
+	total += abs(dark*2-modules) * 10 / modules * penaltyBalance
+	return total
+}
+
+// finderLike is the 1:1:3:1:1 dark and light pattern of a finder pattern.
+var finderLike = [7]bool{true, false, true, true, true, false, true}
+
+// linePenalty scores the runs and finder-like patterns of one row or
+// column, whose modules at returns.
+func (m *matrix) linePenalty(at func(int) bool) int {
+	total := 0
+	run := 0
+	for j := 0; j < m.size; j++ {
+		if j > 0 && at(j) == at(j-1) {
+			run++
+		} else {
+			run = 1
+		}
+		if run == 5 {
+			total += penaltyRun
+		} else if run > 5 {
+			total++
+		}
+	}
+
+	// Modules past the edge are light, like the quiet zone.
+	light := func(from, to int) bool {
+		for j := from; j < to; j++ {
+			if j >= 0 && j < m.size && at(j) {
+				return false
+			}
+		}
+		return true
+	}
+	for j := 0; j+len(finderLike) <= m.size; j++ {
+		match := true
+		for k, dark := range finderLike {
+			if at(j+k) != dark {
+				match = false
+				break
+			}
+		}
+		if match && (light(j-4, j) || light(j+7, j+11)) {
+			total += penaltyFinder
+		}
+	}
+	return total
+}
+
+// bit reports whether bit i of value is set.
+func bit(value, i int) bool {
+	return value>>uint(i)&1 == 1
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,108 @@
+package qr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatBits(t *testing.T) {
+	tests := []struct {
+		level    Level
+		mask     int
+		expected int
+	}{
+		{level: Low, mask: 0, expected: 0b111011111000100},
+		{level: Low, mask: 4, expected: 0b110011000101111},
+		{level: Medium, mask: 0, expected: 0b101010000010010},
+		{level: Medium, mask: 5, expected: 0b100000011001110},
+		{level: Quartile, mask: 0, expected: 0b011010101011111},
+		{level: High, mask: 0, expected: 0b001011010001001},
+		{level: High, mask: 7, expected: 0b000100000111011},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, formatBits(tt.level, tt.mask), "level %s mask %d", tt.level, tt.mask)
+	}
+}
+
+func TestVersionBits(t *testing.T) {
+	assert.Equal(t, 0b000111110010010100, versionBits(7))
+	assert.Equal(t, 0b101000110001101001, versionBits(40))
+}
+
+func TestMatrix_FunctionPatterns(t *testing.T) {
+	// Arrange
+	m := newMatrix(7)
+
+	// Act
+	m.drawFunctionPatterns(7)
+
+	// Assert: finder patterns with their light separators.
+	for _, corner := range [][2]int{{0, 0}, {m.size - 7, 0}, {0, m.size - 7}} {
+		x, y := corner[0], corner[1]
+		assert.True(t, m.get(x, y), "outer ring at %v", corner)
+		assert.False(t, m.get(x+1, y+1), "light ring at %v", corner)
+		assert.True(t, m.get(x+3, y+3), "centre at %v", corner)
+	}
+	assert.False(t, m.get(7, 7))
+
+	// Timing patterns alternate between the finder patterns.
+	for i := 8; i < m.size-8; i++ {
+		assert.Equal(t, i%2 == 0, m.get(i, 6), "row timing at %d", i)
+		assert.Equal(t, i%2 == 0, m.get(6, i), "column timing at %d", i)
+	}
+
+	// The dark module and the alignment pattern in the middle.
+	assert.True(t, m.get(8, m.size-8))
+	assert.True(t, m.get(22, 22))
+	assert.False(t, m.get(21, 22))
+	assert.True(t, m.get(20, 22))
+
+	// Everything that is not a function module holds data.
+	free := 0
+	for _, function := range m.function {
+		if !function {
+			free++
+		}
+	}
+	assert.Equal(t, rawDataModules(7), free)
+}
+
+func TestMatrix_RawDataModules(t *testing.T) {
+	for version := MinVersion; version <= MaxVersion; version++ {
+		// Arrange
+		m := newMatrix(version)
+
+		// Act
+		m.drawFunctionPatterns(version)
+
+		// Assert
+		free := 0
+		for _, function := range m.function {
+			if !function {
+				free++
+			}
+		}
+		assert.Equal(t, rawDataModules(version), free, "version %d", version)
+	}
+}
+
+func TestMatrix_ApplyMaskTwiceRestores(t *testing.T) {
+	// Arrange
+	m := newMatrix(2)
+	m.drawFunctionPatterns(2)
+	m.drawCodewords(make([]byte, rawDataModules(2)/8))
+	before := append([]bool{}, m.modules...)
+
+	for mask := 0; mask < 8; mask++ {
+		// Act
+		m.applyMask(mask)
+		changed := !assert.ObjectsAreEqual(before, m.modules)
+		m.applyMask(mask)
+
+		// Assert
+		assert.True(t, changed, "mask %d", mask)
+		assert.Equal(t, before, m.modules, "mask %d", mask)
+	}
+}
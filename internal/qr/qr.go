@@ -0,0 +1,127 @@
+// Package qr encodes data as QR Code symbols (ISO/IEC 18004) of versions 1
+// to 40 at all four error correction levels. It only builds the grid of
+// modules; drawing it on the print raster is left to the render package.
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Level is the error correction level of a symbol: how much of it can be
+// damaged or covered and still scan.
+type Level int
+
+const (
+	// Low recovers about 7% of the codewords.
+	Low Level = iota
+	// Medium recovers about 15% of the codewords.
+	Medium
+	// Quartile recovers about 25% of the codewords.
+	Quartile
+	// High recovers about 30% of the codewords.
+	High
+)
+
+// ParseLevel parses an error correction level written as "L", "M", "Q" or
+// "H", in either case. An empty string selects Medium.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToUpper(s) {
+	case "L":
+		return Low, nil
+	case "", "M":
+		return Medium, nil
+	case "Q":
+		return Quartile, nil
+	case "H":
+		return High, nil
+	}
+	return 0, fmt.Errorf("unknown error correction level %q: use L, M, Q or H", s)
+}
+
+// String returns the letter of the level.
+func (l Level) String() string {
+	switch l {
+	case Low:
+		return "L"
+	case Medium:
+		return "M"
+	case Quartile:
+		return "Q"
+	case High:
+		return "H"
+	}
+	return fmt.Sprintf("Level(%d)", int(l))
+}
+
+// formatBits returns the two bits that identify the level in the format
+// information.
+func (l Level) formatBits() int {
+	return [...]int{Low: 1, Medium: 0, Quartile: 3, High: 2}[l]
+}
+
+// ErrTooLong is returned when data does not fit in a version 40 symbol at
+// the requested level.
+var ErrTooLong = errors.New("data too long for a QR code")
+
+// Code is an encoded QR code symbol.
+type Code struct {
+	// Version is the symbol version, from 1 to 40.
+	Version int
+	Level   Level
+	// Mask is the data mask pattern, from 0 to 7.
+	Mask int
+	// Size is the number of modules along each side, 17 + 4*Version.
+	Size    int
+	modules []bool
+}
+
+// Black reports whether the module at column x and row y is dark. Modules
+// outside the symbol belong to the quiet zone and are light.
+func (c *Code) Black(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false
+	}
+	return c.modules[y*c.Size+x]
+}
+
+// Encode encodes data in the smallest symbol that holds it at the given
+// error correction level. Digits only are encoded in numeric mode, text
+// made of upper-case letters, digits and " $%*+-./:" in alphanumeric mode,
+// and anything else as its UTF-8 bytes. The data mask is chosen by the
+// standard's penalty rules.
+func Encode(data string, level Level) (*Code, error) {
+	if level < Low || level > High {
+		return nil, fmt.Errorf("unknown error correction level %d", int(level))
+	}
+
+	seg := makeSegment(data)
+	version := 0
+	for v := MinVersion; v <= MaxVersion; v++ {
+		if n := seg.bitLength(v); n >= 0 && n <= dataCodewords(v, level)*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("%w: %d bytes do not fit at level %s", ErrTooLong, len(data), level)
+	}
+
+	codewords := seg.codewords(version, dataCodewords(version, level))
+	m := newMatrix(version)
+	m.drawFunctionPatterns(version)
+	m.drawCodewords(addErrorCorrection(codewords, version, level))
+
+	mask := m.chooseMask(level)
+	m.applyMask(mask)
+	m.drawFormatBits(level, mask)
+
+	return &Code{
+		Version: version,
+		Level:   level,
+		Mask:    mask,
+		Size:    m.size,
+		modules: m.modules,
+	}, nil
+}
@@ -0,0 +1,267 @@
+package qr
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Level
+		wantErr  bool
+	}{
+		{input: "L", expected: Low},
+		{input: "m", expected: Medium},
+		{input: "", expected: Medium},
+		{input: "Q", expected: Quartile},
+		{input: "h", expected: High},
+		{input: "X", wantErr: true},
+		{input: "low", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		level, err := ParseLevel(tt.input)
+		if tt.wantErr {
+			assert.Error(t, err, "input %q", tt.input)
+			continue
+		}
+		require.NoError(t, err, "input %q", tt.input)
+		assert.Equal(t, tt.expected, level)
+		if tt.input != "" {
+			assert.Equal(t, strings.ToUpper(tt.input), level.String())
+		}
+	}
+}
+
+func TestEncode_Version(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		level    Level
+		expected int
+	}{
+		{name: "HELLO WORLD", data: "HELLO WORLD", level: Quartile, expected: 1},
+		{name: "full version 1 bytes", data: strings.Repeat("a", 17), level: Low, expected: 1},
+		{name: "one byte over version 1", data: strings.Repeat("a", 18), level: Low, expected: 2},
+		{name: "full version 1 digits at H", data: strings.Repeat("7", 17), level: High, expected: 1},
+		{name: "wifi card", data: "WIFI:T:WPA;S:Office;P:correct horse battery staple;;", level: Medium, expected: 4},
+		{name: "full version 40 bytes", data: strings.Repeat("a", 2953), level: Low, expected: 40},
+		{name: "full version 40 digits", data: strings.Repeat("1", 7089), level: Low, expected: 40},
+		{name: "full version 40 alphanumeric", data: strings.Repeat("A", 4296), level: Low, expected: 40},
+		{name: "full version 40 bytes at H", data: strings.Repeat("a", 1273), level: High, expected: 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			code, err := Encode(tt.data, tt.level)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, code.Version)
+			assert.Equal(t, symbolSize(tt.expected), code.Size)
+			assert.Equal(t, tt.level, code.Level)
+		})
+	}
+}
+
+func TestEncode_TooLong(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		level Level
+	}{
+		{name: "bytes at L", data: strings.Repeat("a", 2954), level: Low},
+		{name: "digits at L", data: strings.Repeat("1", 7090), level: Low},
+		{name: "bytes at H", data: strings.Repeat("a", 1274), level: High},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			code, err := Encode(tt.data, tt.level)
+
+			// Assert
+			assert.ErrorIs(t, err, ErrTooLong)
+			assert.Nil(t, code)
+		})
+	}
+}
+
+func TestEncode_UnknownLevel(t *testing.T) {
+	_, err := Encode("x", Level(7))
+	assert.Error(t, err)
+}
+
+func TestEncode_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		level Level
+	}{
+		{name: "numeric", data: "0123456789012", level: Medium},
+		{name: "alphanumeric", data: "HTTPS://EXAMPLE.COM/BIN/A-17", level: Quartile},
+		{name: "url", data: "https://example.com/menu?table=12", level: Low},
+		{name: "wifi", data: "WIFI:T:WPA;S:Front Desk;P:hunter2;;", level: High},
+		{name: "utf-8", data: "Grüße aus Köln ☕", level: Medium},
+		{name: "version 7 with version information", data: strings.Repeat("QR ", 40), level: High},
+		{name: "several blocks of two sizes", data: strings.Repeat("x", 300), level: Quartile},
+		{name: "version 40", data: strings.Repeat("0123456789abcdef", 184), level: Low},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			code, err := Encode(tt.data, tt.level)
+			require.NoError(t, err)
+
+			// Assert
+			assert.Equal(t, tt.data, readSymbol(t, code))
+		})
+	}
+}
+
+func TestCode_Black_QuietZone(t *testing.T) {
+	// Arrange
+	code, err := Encode("quiet", Medium)
+	require.NoError(t, err)
+
+	// Act & Assert: the corners are finder patterns and the outside is light.
+	assert.True(t, code.Black(0, 0))
+	assert.True(t, code.Black(code.Size-1, 0))
+	assert.True(t, code.Black(0, code.Size-1))
+	assert.False(t, code.Black(-1, 0))
+	assert.False(t, code.Black(code.Size, code.Size))
+}
+
+// readSymbol decodes a symbol the way a scanner does once it has located
+// it. It reads the format information, checks the function patterns,
+// unmasks the data modules and reads the codewords back in placement order,
+// checks the error correction of every block and decodes the segment.
+func readSymbol(t *testing.T, code *Code) string {
+	t.Helper()
+
+	// Format information, from the copy around the top left finder pattern.
+	format := 0
+	formatModules := [15][2]int{{8, 0}, {8, 1}, {8, 2}, {8, 3}, {8, 4}, {8, 5}, {8, 7}, {8, 8}, {7, 8}, {5, 8}, {4, 8}, {3, 8}, {2, 8}, {1, 8}, {0, 8}}
+	for i, pos := range formatModules {
+		if code.Black(pos[0], pos[1]) {
+			format |= 1 << i
+		}
+	}
+	level, mask, found := Low, 0, false
+	for l := Low; l <= High && !found; l++ {
+		for msk := 0; msk < 8; msk++ {
+			if formatBits(l, msk) == format {
+				level, mask, found = l, msk, true
+				break
+			}
+		}
+	}
+	require.True(t, found, "format information %015b is not valid", format)
+	require.Equal(t, code.Level, level)
+	require.Equal(t, code.Mask, mask)
+
+	// The function patterns are where a scanner expects them.
+	m := newMatrix(code.Version)
+	m.drawFunctionPatterns(code.Version)
+	m.drawFormatBits(level, mask)
+	for i, function := range m.function {
+		if function {
+			require.Equal(t, m.modules[i], code.modules[i], "function module %d,%d", i%m.size, i/m.size)
+		}
+	}
+
+	// Codewords, unmasked, in placement order.
+	var bits bitBuffer
+	for right := m.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < m.size; vert++ {
+			y := vert
+			if (right+1)&2 == 0 {
+				y = m.size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if !m.function[y*m.size+x] {
+					bits = append(bits, code.Black(x, y) != maskApplies(mask, x, y))
+				}
+			}
+		}
+	}
+	raw := rawDataModules(code.Version) / 8
+	codewords := bits[:raw*8].bytes()
+
+	// De-interleave the blocks and check their error correction.
+	blocks := errorCorrectionBlocks[level][code.Version]
+	eccLen := eccCodewordsPerBlock[level][code.Version]
+	shortBlocks := blocks - raw%blocks
+	shortLen := raw/blocks - eccLen
+	dataBlocks := make([][]byte, blocks)
+	next := 0
+	for i := 0; i <= shortLen; i++ {
+		for b := range dataBlocks {
+			if i < shortLen || b >= shortBlocks {
+				dataBlocks[b] = append(dataBlocks[b], codewords[next])
+				next++
+			}
+		}
+	}
+	divisor := rsDivisor(eccLen)
+	var data bitBuffer
+	for b, block := range dataBlocks {
+		full := append([]byte{}, block...)
+		for i := 0; i < eccLen; i++ {
+			full = append(full, codewords[next+i*blocks+b])
+		}
+		require.Equal(t, make([]byte, eccLen), rsRemainder(full, divisor), "block %d", b)
+		for _, c := range block {
+			data.append(uint32(c), 8)
+		}
+	}
+
+	// The single segment.
+	read := func(n int) int {
+		value := 0
+		for i := 0; i < n; i++ {
+			value <<= 1
+			if data[0] {
+				value |= 1
+			}
+			data = data[1:]
+		}
+		return value
+	}
+	var out strings.Builder
+	switch indicator := read(4); indicator {
+	case 0x1:
+		for count := read(numericMode.countBits(code.Version)); count > 0; count -= 3 {
+			digits := min(count, 3)
+			fmt.Fprintf(&out, "%0*d", digits, read(digits*3+1))
+		}
+	case 0x2:
+		for count := read(alphanumericMode.countBits(code.Version)); count > 0; count -= 2 {
+			if count == 1 {
+				out.WriteByte(alphanumericChars[read(6)])
+				break
+			}
+			pair := read(11)
+			out.WriteByte(alphanumericChars[pair/45])
+			out.WriteByte(alphanumericChars[pair%45])
+		}
+	case 0x4:
+		for count := read(byteMode.countBits(code.Version)); count > 0; count-- {
+			out.WriteByte(byte(read(8)))
+		}
+	default:
+		t.Fatalf("unexpected mode indicator %x", indicator)
+	}
+	return out.String()
+}
@@ -0,0 +1,65 @@
+package qr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRSRemainder(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected []byte
+	}{
+		{
+			name:     "HELLO WORLD at 1-Q",
+			data:     []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236},
+			expected: []byte{168, 72, 22, 82, 217, 54, 156, 0, 46, 15, 180, 122, 16},
+		},
+		{
+			name:     "01234567 at 1-M",
+			data:     []byte{16, 32, 12, 86, 97, 128, 236, 17, 236, 17, 236, 17, 236, 17, 236, 17},
+			expected: []byte{165, 36, 212, 193, 237, 54, 199, 135, 44, 85},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			ecc := rsRemainder(tt.data, rsDivisor(len(tt.expected)))
+
+			// Assert
+			assert.Equal(t, tt.expected, ecc)
+		})
+	}
+}
+
+func TestRSRemainder_CodewordIsMultipleOfGenerator(t *testing.T) {
+	// Arrange
+	data := []byte("the quick brown fox")
+	divisor := rsDivisor(22)
+
+	// Act
+	codeword := append(append([]byte{}, data...), rsRemainder(data, divisor)...)
+
+	// Assert: data followed by its error correction divides evenly.
+	assert.Equal(t, make([]byte, 22), rsRemainder(codeword, divisor))
+}
+
+func TestAddErrorCorrection_Interleaves(t *testing.T) {
+	// Arrange: 5-Q has two blocks of 15 and two of 16 data codewords.
+	data := make([]byte, dataCodewords(5, Quartile))
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	// Act
+	out := addErrorCorrection(data, 5, Quartile)
+
+	// Assert
+	assert.Len(t, out, rawDataModules(5)/8)
+	assert.Equal(t, []byte{0, 15, 30, 46, 1, 16, 31, 47}, out[:8])
+	// The extra codewords of the long blocks come last.
+	assert.Equal(t, []byte{14, 29, 44, 60, 45, 61}, out[56:62])
+}
@@ -0,0 +1,159 @@
+package qr
+
+import "strings"
+
+// mode is the way a segment encodes its characters.
+type mode int
+
+const (
+	numericMode mode = iota
+	alphanumericMode
+	byteMode
+)
+
+// alphanumericChars is the character set of alphanumeric mode, in the
+// order of their values.
+const alphanumericChars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// indicator returns the 4-bit mode indicator that starts a segment.
+func (m mode) indicator() uint32 {
+	switch m {
+	case numericMode:
+		return 0x1
+	case alphanumericMode:
+		return 0x2
+	}
+	return 0x4
+}
+
+// countBits returns the width of the character count field of a version.
+func (m mode) countBits(version int) int {
+	group := 0
+	switch {
+	case version >= 27:
+		group = 2
+	case version >= 10:
+		group = 1
+	}
+	switch m {
+	case numericMode:
+		return [...]int{10, 12, 14}[group]
+	case alphanumericMode:
+		return [...]int{9, 11, 13}[group]
+	}
+	return [...]int{8, 16, 16}[group]
+}
+
+// bitBuffer is a sequence of bits, most significant first.
+type bitBuffer []bool
+
+// append adds the low n bits of value.
+func (b *bitBuffer) append(value uint32, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, value>>uint(i)&1 == 1)
+	}
+}
+
+// bytes packs the bits into bytes. A partial last byte is padded with zeros.
+func (b bitBuffer) bytes() []byte {
+	out := make([]byte, (len(b)+7)/8)
+	for i, bit := range b {
+		if bit {
+			out[i/8] |= 0x80 >> uint(i%8)
+		}
+	}
+	return out
+}
+
+// segment is the encoded data of a symbol in a single mode.
+type segment struct {
+	mode mode
+	// chars is the character count: digits, characters or bytes.
+	chars int
+	bits  bitBuffer
+}
+
+// makeSegment encodes data in the most compact mode that can hold all of
+// it: numeric for digits only, alphanumeric for upper-case letters, digits
+// and " $%*+-./:", and otherwise the UTF-8 bytes.
+func makeSegment(data string) segment {
+	var bits bitBuffer
+	switch {
+	case isNumeric(data):
+		for i := 0; i < len(data); i += 3 {
+			group := data[i:min(i+3, len(data))]
+			value := uint32(0)
+			for _, d := range group {
+				value = value*10 + uint32(d-'0')
+			}
+			bits.append(value, len(group)*3+1)
+		}
+		return segment{mode: numericMode, chars: len(data), bits: bits}
+
+	case isAlphanumeric(data):
+		for i := 0; i+1 < len(data); i += 2 {
+			pair := strings.IndexByte(alphanumericChars, data[i])*45 + strings.IndexByte(alphanumericChars, data[i+1])
+			bits.append(uint32(pair), 11)
+		}
+		if len(data)%2 == 1 {
+			bits.append(uint32(strings.IndexByte(alphanumericChars, data[len(data)-1])), 6)
+		}
+		return segment{mode: alphanumericMode, chars: len(data), bits: bits}
+	}
+
+	for i := 0; i < len(data); i++ {
+		bits.append(uint32(data[i]), 8)
+	}
+	return segment{mode: byteMode, chars: len(data), bits: bits}
+}
+
+// bitLength returns the number of bits the segment takes in a version, or
+// -1 when its character count does not fit the count field.
+func (s segment) bitLength(version int) int {
+	countBits := s.mode.countBits(version)
+	if s.chars >= 1<<uint(countBits) {
+		return -1
+	}
+	return 4 + countBits + len(s.bits)
+}
+
+// codewords returns the data codewords of a version holding the segment,
+// filling capacity codewords with a terminator and the alternating pad
+// bytes 0xEC and 0x11.
+func (s segment) codewords(version, capacity int) []byte {
+	var bits bitBuffer
+	bits.append(s.mode.indicator(), 4)
+	bits.append(uint32(s.chars), s.mode.countBits(version))
+	bits = append(bits, s.bits...)
+	bits.append(0, min(4, capacity*8-len(bits)))
+
+	out := bits.bytes()
+	for pad := byte(0xEC); len(out) < capacity; pad ^= 0xEC ^ 0x11 {
+		out = append(out, pad)
+	}
+	return out
+}
+
+func isNumeric(data string) bool {
+	if data == "" {
+		return false
+	}
+	for i := 0; i < len(data); i++ {
+		if data[i] < '0' || data[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlphanumeric(data string) bool {
+	if data == "" {
+		return false
+	}
+	for i := 0; i < len(data); i++ {
+		if strings.IndexByte(alphanumericChars, data[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+package qr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMakeSegment_Mode(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected mode
+		bits     int
+	}{
+		{name: "digits", data: "01234567", expected: numericMode, bits: 27},
+		{name: "upper case", data: "HELLO WORLD", expected: alphanumericMode, bits: 61},
+		{name: "url in upper case", data: "HTTPS://EXAMPLE.COM/A", expected: alphanumericMode, bits: 116},
+		{name: "lower case", data: "hello", expected: byteMode, bits: 40},
+		{name: "utf-8", data: "café", expected: byteMode, bits: 40},
+		{name: "empty", data: "", expected: byteMode, bits: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			seg := makeSegment(tt.data)
+
+			// Assert
+			assert.Equal(t, tt.expected, seg.mode)
+			assert.Len(t, seg.bits, tt.bits)
+		})
+	}
+}
+
+func TestSegment_Codewords(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		version  int
+		level    Level
+		expected []byte
+	}{
+		{
+			name:     "alphanumeric",
+			data:     "HELLO WORLD",
+			version:  1,
+			level:    Quartile,
+			expected: []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236},
+		},
+		{
+			name:     "numeric",
+			data:     "01234567",
+			version:  1,
+			level:    Medium,
+			expected: []byte{16, 32, 12, 86, 97, 128, 236, 17, 236, 17, 236, 17, 236, 17, 236, 17},
+		},
+		{
+			name:     "bytes",
+			data:     "Hi",
+			version:  1,
+			level:    High,
+			expected: []byte{0x40, 0x24, 0x86, 0x90, 0xEC, 0x11, 0xEC, 0x11, 0xEC},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			codewords := makeSegment(tt.data).codewords(tt.version, dataCodewords(tt.version, tt.level))
+
+			// Assert
+			assert.Equal(t, tt.expected, codewords)
+		})
+	}
+}
+
+func TestSegment_BitLength_CountOverflow(t *testing.T) {
+	// Arrange: the byte count field holds 8 bits up to version 9.
+	seg := segment{mode: byteMode, chars: 256}
+
+	// Act & Assert
+	assert.Equal(t, -1, seg.bitLength(9))
+	assert.Equal(t, 4+16, seg.bitLength(10))
+}
@@ -0,0 +1,59 @@
+package qr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataCodewords(t *testing.T) {
+	tests := []struct {
+		version  int
+		expected [4]int
+	}{
+		{version: 1, expected: [4]int{Low: 19, Medium: 16, Quartile: 13, High: 9}},
+		{version: 2, expected: [4]int{Low: 34, Medium: 28, Quartile: 22, High: 16}},
+		{version: 7, expected: [4]int{Low: 156, Medium: 124, Quartile: 88, High: 66}},
+		{version: 10, expected: [4]int{Low: 274, Medium: 216, Quartile: 154, High: 122}},
+		{version: 40, expected: [4]int{Low: 2956, Medium: 2334, Quartile: 1666, High: 1276}},
+	}
+
+	for _, tt := range tests {
+		for level := Low; level <= High; level++ {
+			assert.Equal(t, tt.expected[level], dataCodewords(tt.version, level), "version %d level %s", tt.version, level)
+		}
+	}
+}
+
+func TestDataCodewords_BlocksSplitEvenly(t *testing.T) {
+	for version := MinVersion; version <= MaxVersion; version++ {
+		for level := Low; level <= High; level++ {
+			// Arrange
+			blocks := errorCorrectionBlocks[level][version]
+			raw := rawDataModules(version) / 8
+
+			// Assert: blocks differ by at most one data codeword and none is empty.
+			assert.Greater(t, raw/blocks-eccCodewordsPerBlock[level][version], 0, "version %d level %s", version, level)
+			assert.Less(t, raw%blocks, blocks)
+		}
+	}
+}
+
+func TestAlignmentPositions(t *testing.T) {
+	tests := []struct {
+		version  int
+		expected []int
+	}{
+		{version: 1, expected: nil},
+		{version: 2, expected: []int{6, 18}},
+		{version: 7, expected: []int{6, 22, 38}},
+		{version: 15, expected: []int{6, 26, 48, 70}},
+		{version: 32, expected: []int{6, 34, 60, 86, 112, 138}},
+		{version: 36, expected: []int{6, 24, 50, 76, 102, 128, 154}},
+		{version: 40, expected: []int{6, 30, 58, 86, 114, 142, 170}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, alignmentPositions(tt.version), "version %d", tt.version)
+	}
+}
@@ -0,0 +1,101 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/princem/peripage-printer/internal/barcode"
+)
+
+// barcodeTextGap is the space in dots between the bars and the text under
+// them.
+const barcodeTextGap = 4
+
+// BarcodeOptions controls how a barcode is drawn.
+// Zero values are replaced by the defaults from DefaultBarcodeOptions.
+type BarcodeOptions struct {
+	// Width is the bitmap width in dots. The code is centred in it.
+	Width int
+	// Margin is blank space in dots kept on the left and right edges.
+	Margin int
+	// Scale is the width of one module in dots. Zero picks the largest
+	// scale at which the bars and their quiet zones fit between the margins.
+	Scale int
+	// Height is the height of the bars in dots.
+	Height int
+	// HideText leaves out the human-readable text under the bars.
+	HideText bool
+	// Size is the text font size in pixels.
+	Size float64
+}
+
+// DefaultBarcodeOptions returns the layout used for barcode print jobs: as
+// wide as fits the head, with 10mm bars and 20px Go Mono text.
+func DefaultBarcodeOptions() BarcodeOptions {
+	return BarcodeOptions{
+		Width:  HeadWidth,
+		Height: 10 * DotsPerMM,
+		Size:   20,
+	}
+}
+
+func (o BarcodeOptions) withDefaults() BarcodeOptions {
+	d := DefaultBarcodeOptions()
+	if o.Width <= 0 {
+		o.Width = d.Width
+	}
+	if o.Height <= 0 {
+		o.Height = d.Height
+	}
+	if o.Size <= 0 {
+		o.Size = d.Size
+	}
+	return o
+}
+
+// MaxBarcodeScale returns the largest module width in dots at which code
+// fits width dots together with its quiet zones.
+func MaxBarcodeScale(code *barcode.Code, width int) int {
+	return width / (code.Modules() + 2*code.QuietZone)
+}
+
+// RenderBarcode draws a barcode at a whole number of dots per module, so
+// every bar and space keeps its exact width ratio, with its quiet zones
+// and the human-readable text centred under the bars.
+func RenderBarcode(code *barcode.Code, opts BarcodeOptions) (*Bitmap, error) {
+	opts = opts.withDefaults()
+
+	avail := opts.Width - 2*opts.Margin
+	fit := MaxBarcodeScale(code, avail)
+	if fit < 1 {
+		return nil, fmt.Errorf("a %s code of %d modules does not fit in %d dots", code.Symbology, code.Modules(), avail)
+	}
+	scale := opts.Scale
+	if scale <= 0 {
+		scale = fit
+	}
+	if scale > fit {
+		return nil, fmt.Errorf("scale %d is too large for a %s code of %d modules: at most %d fits", scale, code.Symbology, code.Modules(), fit)
+	}
+
+	bmp := NewBitmap(opts.Width, opts.Height)
+	left := opts.Margin + (avail-code.Modules()*scale)/2
+	for x := 0; x < code.Modules(); x++ {
+		if !code.Black(x) {
+			continue
+		}
+		for dx := 0; dx < scale; dx++ {
+			for y := 0; y < opts.Height; y++ {
+				bmp.Set(left+x*scale+dx, y, true)
+			}
+		}
+	}
+
+	if opts.HideText {
+		return bmp, nil
+	}
+	text, err := renderCaption(code.Text, Mono, opts.Size, opts.Width, opts.Margin)
+	if err != nil {
+		return nil, err
+	}
+	return Stack(bmp, NewBitmap(opts.Width, barcodeTextGap), text), nil
+}
@@ -0,0 +1,109 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/princem/peripage-printer/internal/barcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderBarcode_Scale(t *testing.T) {
+	// EAN-13 is 95 modules, 117 with its quiet zones.
+	ean, err := barcode.Encode(barcode.EAN13, "400638133393")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		opts          BarcodeOptions
+		expectedScale int
+	}{
+		{name: "largest that fits", opts: BarcodeOptions{HideText: true}, expectedScale: 3},
+		{name: "explicit scale", opts: BarcodeOptions{Scale: 2, HideText: true}, expectedScale: 2},
+		{name: "margins shrink the fit", opts: BarcodeOptions{Margin: 80, HideText: true}, expectedScale: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			bmp, err := RenderBarcode(ean, tt.opts)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, HeadWidth, bmp.Width)
+			assert.Equal(t, DefaultBarcodeOptions().Height, bmp.Height)
+			left := leftmostInk(bmp)
+			right := HeadWidth - 1
+			for !bmp.Black(right, 0) {
+				right--
+			}
+			assert.Equal(t, ean.Modules()*tt.expectedScale, right-left+1)
+		})
+	}
+}
+
+func TestRenderBarcode_DrawsEveryModule(t *testing.T) {
+	// Arrange
+	code, err := barcode.Encode(barcode.Code128, "BIN-A-17")
+	require.NoError(t, err)
+	scale, height := 2, 40
+
+	// Act
+	bmp, err := RenderBarcode(code, BarcodeOptions{Scale: scale, Height: height, HideText: true})
+
+	// Assert: the bars are centred and run the full height.
+	require.NoError(t, err)
+	require.Equal(t, height, bmp.Height)
+	left := (HeadWidth - code.Modules()*scale) / 2
+	for x := -code.QuietZone; x < code.Modules()+code.QuietZone; x++ {
+		for d := 0; d < scale; d++ {
+			require.Equal(t, code.Black(x), bmp.Black(left+x*scale+d, 0), "module %d", x)
+			require.Equal(t, code.Black(x), bmp.Black(left+x*scale+d, height-1), "module %d", x)
+		}
+	}
+}
+
+func TestRenderBarcode_Text(t *testing.T) {
+	// Arrange
+	code, err := barcode.Encode(barcode.UPCA, "03600029145")
+	require.NoError(t, err)
+	bars, err := RenderBarcode(code, BarcodeOptions{HideText: true})
+	require.NoError(t, err)
+
+	// Act
+	labelled, err := RenderBarcode(code, BarcodeOptions{})
+
+	// Assert: the digits, with the check digit, are printed under the bars.
+	require.NoError(t, err)
+	expectedText, err := renderCaption("036000291452", Mono, 20, HeadWidth, 0)
+	require.NoError(t, err)
+	assert.Equal(t, bars.Height+barcodeTextGap+expectedText.Height, labelled.Height)
+	assert.Equal(t, bars.Pix, labelled.Top(bars.Height).Pix)
+	text := NewBitmap(HeadWidth, expectedText.Height)
+	text.Draw(labelled, 0, -bars.Height-barcodeTextGap)
+	assert.Equal(t, expectedText.Pix, text.Pix)
+}
+
+func TestRenderBarcode_Errors(t *testing.T) {
+	short, err := barcode.Encode(barcode.EAN13, "400638133393")
+	require.NoError(t, err)
+	long, err := barcode.Encode(barcode.Code39, strings.Repeat("A", 30))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		code *barcode.Code
+		opts BarcodeOptions
+	}{
+		{name: "scale too large", code: short, opts: BarcodeOptions{Scale: 4}},
+		{name: "too long for the head", code: long, opts: BarcodeOptions{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := RenderBarcode(tt.code, tt.opts)
+			assert.Error(t, err)
+		})
+	}
+}
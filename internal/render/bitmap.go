@@ -0,0 +1,200 @@
+// Package render rasterizes printable content into the 1-bit bitmaps consumed
+// by thermal printers. It has no knowledge of Bluetooth or any particular
+// printer protocol, so it can be shared by every printer adapter and tested
+// on any machine.
+package render
+
+import (
+	"image"
+	"image/color"
+)
+
+// HeadWidth is the width in dots of the Peripage A6 print head.
+const HeadWidth = 384
+
+// DotsPerMM is the print resolution (203 dpi) in dots per millimetre.
+const DotsPerMM = 8
+
+// LengthMM returns the length of paper in millimetres taken by the given
+// number of raster lines.
+func LengthMM(lines int) float64 {
+	return float64(lines) / DotsPerMM
+}
+
+// Bitmap is a 1-bit raster image. Rows are packed MSB-first: the leftmost
+// pixel of a row is the most significant bit of the row's first byte, and a
+// set bit means a black (burned) dot.
+type Bitmap struct {
+	Width  int
+	Height int
+	Stride int
+	Pix    []byte
+}
+
+// NewBitmap creates a blank (all white) bitmap of the given size.
+func NewBitmap(width, height int) *Bitmap {
+	if width < 0 {
+		width = 0
+	}
+	if height < 0 {
+		height = 0
+	}
+	stride := (width + 7) / 8
+	return &Bitmap{
+		Width:  width,
+		Height: height,
+		Stride: stride,
+		Pix:    make([]byte, stride*height),
+	}
+}
+
+// Set paints the dot at (x, y) black or white. Out-of-range coordinates are ignored.
+func (b *Bitmap) Set(x, y int, black bool) {
+	if x < 0 || y < 0 || x >= b.Width || y >= b.Height {
+		return
+	}
+	i := y*b.Stride + x/8
+	mask := byte(0x80) >> uint(x%8)
+	if black {
+		b.Pix[i] |= mask
+	} else {
+		b.Pix[i] &^= mask
+	}
+}
+
+// Black reports whether the dot at (x, y) is black. Out-of-range coordinates are white.
+func (b *Bitmap) Black(x, y int) bool {
+	if x < 0 || y < 0 || x >= b.Width || y >= b.Height {
+		return false
+	}
+	return b.Pix[y*b.Stride+x/8]&(byte(0x80)>>uint(x%8)) != 0
+}
+
+// Row returns the packed bytes of row y.
+func (b *Bitmap) Row(y int) []byte {
+	return b.Pix[y*b.Stride : (y+1)*b.Stride]
+}
+
+// Draw copies the black dots of src onto b with src's top-left corner at (x, y).
+// Dots that fall outside b are clipped.
+func (b *Bitmap) Draw(src *Bitmap, x, y int) {
+	for sy := 0; sy < src.Height; sy++ {
+		for sx := 0; sx < src.Width; sx++ {
+			if src.Black(sx, sy) {
+				b.Set(x+sx, y+sy, true)
+			}
+		}
+	}
+}
+
+// ColorModel implements image.Image.
+func (b *Bitmap) ColorModel() color.Model {
+	return color.GrayModel
+}
+
+// Bounds implements image.Image.
+func (b *Bitmap) Bounds() image.Rectangle {
+	return image.Rect(0, 0, b.Width, b.Height)
+}
+
+// At implements image.Image. Black dots are returned as black, everything else as white.
+func (b *Bitmap) At(x, y int) color.Color {
+	if b.Black(x, y) {
+		return color.Gray{Y: 0}
+	}
+	return color.Gray{Y: 0xff}
+}
+
+// Top returns the first lines rows of the bitmap. The result shares pixel
+// memory with b.
+func (b *Bitmap) Top(lines int) *Bitmap {
+	if lines < 0 {
+		lines = 0
+	}
+	if lines > b.Height {
+		lines = b.Height
+	}
+	return &Bitmap{
+		Width:  b.Width,
+		Height: lines,
+		Stride: b.Stride,
+		Pix:    b.Pix[:lines*b.Stride],
+	}
+}
+
+// Bottom returns the last lines rows of the bitmap. The result shares pixel
+// memory with b.
+func (b *Bitmap) Bottom(lines int) *Bitmap {
+	if lines < 0 {
+		lines = 0
+	}
+	if lines > b.Height {
+		lines = b.Height
+	}
+	return &Bitmap{
+		Width:  b.Width,
+		Height: lines,
+		Stride: b.Stride,
+		Pix:    b.Pix[(b.Height-lines)*b.Stride:],
+	}
+}
+
+// Stack joins bitmaps vertically, top to bottom. The result is as wide as the
+// widest part; narrower parts are left-aligned. Nil parts are skipped.
+func Stack(parts ...*Bitmap) *Bitmap {
+	width, height := 0, 0
+	for _, p := range parts {
+		if p == nil {
+			continue
+		}
+		if p.Width > width {
+			width = p.Width
+		}
+		height += p.Height
+	}
+
+	out := NewBitmap(width, height)
+	y := 0
+	for _, p := range parts {
+		if p == nil {
+			continue
+		}
+		if p.Stride == out.Stride {
+			copy(out.Pix[y*out.Stride:], p.Pix)
+		} else {
+			out.Draw(p, 0, y)
+		}
+		y += p.Height
+	}
+	return out
+}
+
+// DashedLine returns a horizontal line of the given width and thickness made
+// of dash-dot dashes separated by gap-dot spaces, starting with a dash.
+func DashedLine(width, thickness, dash, gap int) *Bitmap {
+	out := NewBitmap(width, thickness)
+	if dash <= 0 {
+		return out
+	}
+	for x := 0; x < width; x++ {
+		if gap > 0 && x%(dash+gap) >= dash {
+			continue
+		}
+		for y := 0; y < thickness; y++ {
+			out.Set(x, y, true)
+		}
+	}
+	return out
+}
+
+// thresholdAlpha paints b black wherever the coverage in src is at least half.
+func (b *Bitmap) thresholdAlpha(src *image.Alpha) {
+	bounds := src.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if src.AlphaAt(x, y).A >= 0x80 {
+				b.Set(x, y, true)
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package render
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBitmap(t *testing.T) {
+	tests := []struct {
+		name           string
+		width, height  int
+		expectedStride int
+	}{
+		{name: "full head width", width: HeadWidth, height: 10, expectedStride: 48},
+		{name: "width not a multiple of 8", width: 10, height: 2, expectedStride: 2},
+		{name: "empty bitmap", width: 0, height: 0, expectedStride: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			bmp := NewBitmap(tt.width, tt.height)
+
+			// Assert
+			require.NotNil(t, bmp)
+			assert.Equal(t, tt.expectedStride, bmp.Stride)
+			assert.Len(t, bmp.Pix, tt.expectedStride*tt.height)
+		})
+	}
+}
+
+func TestBitmap_Set_IsMSBFirst(t *testing.T) {
+	// Arrange
+	bmp := NewBitmap(16, 2)
+
+	// Act
+	bmp.Set(0, 0, true)
+	bmp.Set(9, 0, true)
+	bmp.Set(15, 1, true)
+	bmp.Set(100, 100, true) // out of range is ignored
+
+	// Assert
+	assert.Equal(t, []byte{0x80, 0x40, 0x00, 0x01}, bmp.Pix)
+	assert.True(t, bmp.Black(9, 0))
+	assert.False(t, bmp.Black(8, 0))
+	assert.False(t, bmp.Black(-1, 0))
+
+	bmp.Set(0, 0, false)
+	assert.False(t, bmp.Black(0, 0))
+}
+
+func TestBitmap_ImplementsImage(t *testing.T) {
+	bmp := NewBitmap(8, 1)
+	bmp.Set(3, 0, true)
+
+	assert.Equal(t, 8, bmp.Bounds().Dx())
+	assert.Equal(t, color.Gray{Y: 0}, bmp.At(3, 0))
+	assert.Equal(t, color.Gray{Y: 0xff}, bmp.At(4, 0))
+}
+
+func TestStack(t *testing.T) {
+	// Arrange
+	top := NewBitmap(16, 1)
+	top.Set(0, 0, true)
+	bottom := NewBitmap(8, 2)
+	bottom.Set(7, 1, true)
+
+	// Act
+	out := Stack(top, nil, bottom)
+
+	// Assert
+	assert.Equal(t, 16, out.Width)
+	assert.Equal(t, 3, out.Height)
+	assert.True(t, out.Black(0, 0))
+	assert.True(t, out.Black(7, 2))
+	assert.False(t, out.Black(7, 1))
+}
+
+func TestBitmap_Top(t *testing.T) {
+	bmp := NewBitmap(16, 4)
+	bmp.Set(1, 0, true)
+	bmp.Set(1, 3, true)
+
+	top := bmp.Top(2)
+
+	assert.Equal(t, 2, top.Height)
+	assert.Equal(t, bmp.Stride, top.Stride)
+	assert.True(t, top.Black(1, 0))
+	assert.False(t, top.Black(1, 3), "rows below the cut are not part of the result")
+	assert.Equal(t, 4, bmp.Top(10).Height)
+	assert.Equal(t, 0, bmp.Top(-1).Height)
+}
+
+func TestBitmap_Bottom(t *testing.T) {
+	bmp := NewBitmap(16, 4)
+	bmp.Set(1, 0, true)
+	bmp.Set(1, 3, true)
+
+	bottom := bmp.Bottom(2)
+
+	assert.Equal(t, 2, bottom.Height)
+	assert.Equal(t, bmp.Stride, bottom.Stride)
+	assert.True(t, bottom.Black(1, 1))
+	assert.False(t, bottom.Black(1, 0), "rows above the cut are not part of the result")
+	assert.Equal(t, 4, bmp.Bottom(10).Height)
+	assert.Equal(t, 0, bmp.Bottom(-1).Height)
+}
+
+func TestDashedLine(t *testing.T) {
+	line := DashedLine(20, 2, 4, 3)
+
+	assert.Equal(t, 20, line.Width)
+	assert.Equal(t, 2, line.Height)
+	for _, x := range []int{0, 3, 7, 10, 14, 17} {
+		assert.True(t, line.Black(x, 1), "dot %d is part of a dash", x)
+	}
+	for _, x := range []int{4, 6, 11, 13, 18, 19} {
+		assert.False(t, line.Black(x, 0), "dot %d is part of a gap", x)
+	}
+}
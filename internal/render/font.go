@@ -0,0 +1,90 @@
+package render
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gobolditalic"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+)
+
+// Family selects one of the fonts embedded in the binary.
+type Family int
+
+const (
+	// Mono is Go Mono, the default for receipts because columns line up.
+	Mono Family = iota
+	// MonoBold is Go Mono Bold.
+	MonoBold
+	// Regular is the proportional Go Regular face.
+	Regular
+	// Bold is Go Bold.
+	Bold
+	// Italic is Go Italic.
+	Italic
+	// BoldItalic is Go Bold Italic.
+	BoldItalic
+)
+
+var fontData = map[Family][]byte{
+	Mono:       gomono.TTF,
+	MonoBold:   gomonobold.TTF,
+	Regular:    goregular.TTF,
+	Bold:       gobold.TTF,
+	Italic:     goitalic.TTF,
+	BoldItalic: gobolditalic.TTF,
+}
+
+var (
+	fontsMu sync.Mutex
+	fonts   = map[Family]*opentype.Font{}
+)
+
+// loadFont parses an embedded font once and caches it.
+// Parsed fonts are safe for concurrent use; faces created from them are not.
+func loadFont(family Family) (*opentype.Font, error) {
+	fontsMu.Lock()
+	defer fontsMu.Unlock()
+
+	if f, ok := fonts[family]; ok {
+		return f, nil
+	}
+
+	data, ok := fontData[family]
+	if !ok {
+		return nil, fmt.Errorf("unknown font family: %d", family)
+	}
+
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font: %w", err)
+	}
+
+	fonts[family] = f
+	return f, nil
+}
+
+// newFace creates a face of the given family at size pixels.
+// The caller owns the face and must close it.
+func newFace(family Family, size float64) (font.Face, error) {
+	f, err := loadFont(family)
+	if err != nil {
+		return nil, err
+	}
+
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create font face: %w", err)
+	}
+	return face, nil
+}
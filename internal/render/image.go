@@ -0,0 +1,278 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"  // register GIF decoding
+	_ "image/jpeg" // register JPEG decoding
+	_ "image/png"  // register PNG decoding
+
+	"golang.org/x/image/draw"
+)
+
+// MaxImagePixels bounds the size of images accepted for decoding, so a small
+// compressed upload cannot expand into an enormous pixel buffer.
+const MaxImagePixels = 25_000_000
+
+// MaxImageHeight bounds the height of a rendered image in dots (2.5 m of paper).
+const MaxImageHeight = 20000
+
+// Dither selects how grey levels are reduced to black and white.
+type Dither string
+
+const (
+	// DitherThreshold turns every pixel darker than the threshold black.
+	// Best for logos and line art.
+	DitherThreshold Dither = "threshold"
+	// DitherFloydSteinberg diffuses the full quantization error to neighbouring pixels.
+	DitherFloydSteinberg Dither = "floyd-steinberg"
+	// DitherAtkinson diffuses three quarters of the error, keeping more contrast.
+	DitherAtkinson Dither = "atkinson"
+	// DitherOrdered compares pixels against an 8x8 Bayer matrix.
+	DitherOrdered Dither = "ordered"
+)
+
+// DefaultDither is used when no dithering mode is given.
+const DefaultDither = DitherFloydSteinberg
+
+// ParseDither validates a dithering mode name. The empty string selects DefaultDither.
+func ParseDither(name string) (Dither, error) {
+	switch d := Dither(name); d {
+	case "":
+		return DefaultDither, nil
+	case DitherThreshold, DitherFloydSteinberg, DitherAtkinson, DitherOrdered:
+		return d, nil
+	default:
+		return "", fmt.Errorf("unknown dither mode: %s", name)
+	}
+}
+
+// ImageOptions controls how an image is converted to a bitmap.
+type ImageOptions struct {
+	Width     int    // Output width in dots; the image is scaled to fill it
+	Dither    Dither // Dithering mode
+	Threshold uint8  // Grey level below which a pixel is black in threshold mode
+}
+
+// DefaultImageOptions returns options that scale to the full print head.
+func DefaultImageOptions() ImageOptions {
+	return ImageOptions{
+		Width:     HeadWidth,
+		Dither:    DefaultDither,
+		Threshold: 0x80,
+	}
+}
+
+// withDefaults fills zero fields from DefaultImageOptions.
+func (o ImageOptions) withDefaults() ImageOptions {
+	def := DefaultImageOptions()
+	if o.Width == 0 {
+		o.Width = def.Width
+	}
+	if o.Dither == "" {
+		o.Dither = def.Dither
+	}
+	if o.Threshold == 0 {
+		o.Threshold = def.Threshold
+	}
+	return o
+}
+
+// CheckImage reports whether data holds a supported image of acceptable
+// size, without decoding its pixels.
+func CheckImage(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("image is empty")
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("unsupported image: %w", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return fmt.Errorf("%s image has no pixels", format)
+	}
+	if config.Width*config.Height > MaxImagePixels {
+		return fmt.Errorf("%s image is too large: %dx%d", format, config.Width, config.Height)
+	}
+	return nil
+}
+
+// CheckImageFits reports whether data holds a supported image that, scaled
+// to width dots, is no taller than MaxImageHeight. Like CheckImage it reads
+// only the image header.
+func CheckImageFits(data []byte, width int) error {
+	if err := CheckImage(data); err != nil {
+		return err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("unsupported image: %w", err)
+	}
+	if width == 0 {
+		width = HeadWidth
+	}
+	if height := scaledHeight(config.Width, config.Height, width); height > MaxImageHeight {
+		return fmt.Errorf("image is too tall: %d dots after scaling (max %d)", height, MaxImageHeight)
+	}
+	return nil
+}
+
+// scaledHeight is the height of a w by h image scaled to width, keeping its
+// aspect ratio. It is at least one dot.
+func scaledHeight(w, h, width int) int {
+	return max((h*width+w/2)/w, 1)
+}
+
+// DecodeImage decodes a PNG, JPEG or GIF image.
+func DecodeImage(data []byte) (image.Image, error) {
+	if err := CheckImage(data); err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return img, nil
+}
+
+// RenderImage scales img to the configured width, keeping its aspect ratio,
+// and converts it to a 1-bit bitmap. Transparent areas print as white paper.
+func RenderImage(img image.Image, opts ImageOptions) (*Bitmap, error) {
+	opts = opts.withDefaults()
+
+	dither, err := ParseDither(string(opts.Dither))
+	if err != nil {
+		return nil, err
+	}
+	if opts.Width < 0 {
+		return nil, fmt.Errorf("invalid image width: %d", opts.Width)
+	}
+
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return nil, fmt.Errorf("image has no pixels")
+	}
+
+	height := scaledHeight(bounds.Dx(), bounds.Dy(), opts.Width)
+	if height > MaxImageHeight {
+		return nil, fmt.Errorf("image is too tall: %d dots after scaling (max %d)", height, MaxImageHeight)
+	}
+
+	gray := image.NewGray(image.Rect(0, 0, opts.Width, height))
+	draw.Draw(gray, gray.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(gray, gray.Bounds(), img, bounds, draw.Over, nil)
+
+	switch dither {
+	case DitherThreshold:
+		return threshold(gray, opts.Threshold), nil
+	case DitherOrdered:
+		return ordered(gray), nil
+	case DitherAtkinson:
+		return diffuse(gray, atkinsonKernel), nil
+	default:
+		return diffuse(gray, floydSteinbergKernel), nil
+	}
+}
+
+// threshold turns pixels darker than level black.
+func threshold(gray *image.Gray, level uint8) *Bitmap {
+	b := NewBitmap(gray.Rect.Dx(), gray.Rect.Dy())
+	for y := 0; y < b.Height; y++ {
+		row := gray.Pix[y*gray.Stride:]
+		for x := 0; x < b.Width; x++ {
+			if row[x] < level {
+				b.Set(x, y, true)
+			}
+		}
+	}
+	return b
+}
+
+// bayer8 is the 8x8 Bayer threshold matrix.
+var bayer8 = [8][8]uint8{
+	{0, 32, 8, 40, 2, 34, 10, 42},
+	{48, 16, 56, 24, 50, 18, 58, 26},
+	{12, 44, 4, 36, 14, 46, 6, 38},
+	{60, 28, 52, 20, 62, 30, 54, 22},
+	{3, 35, 11, 43, 1, 33, 9, 41},
+	{51, 19, 59, 27, 49, 17, 57, 25},
+	{15, 47, 7, 39, 13, 45, 5, 37},
+	{63, 31, 55, 23, 61, 29, 53, 21},
+}
+
+// ordered dithers against the Bayer matrix.
+func ordered(gray *image.Gray) *Bitmap {
+	b := NewBitmap(gray.Rect.Dx(), gray.Rect.Dy())
+	for y := 0; y < b.Height; y++ {
+		row := gray.Pix[y*gray.Stride:]
+		for x := 0; x < b.Width; x++ {
+			// Scale the matrix entry to the middle of its 4-level band.
+			level := int(bayer8[y%8][x%8])*4 + 2
+			if int(row[x]) < level {
+				b.Set(x, y, true)
+			}
+		}
+	}
+	return b
+}
+
+// diffusion is one weighted neighbour in an error diffusion kernel.
+type diffusion struct {
+	dx, dy int
+	weight float32
+}
+
+var floydSteinbergKernel = []diffusion{
+	{dx: 1, dy: 0, weight: 7.0 / 16},
+	{dx: -1, dy: 1, weight: 3.0 / 16},
+	{dx: 0, dy: 1, weight: 5.0 / 16},
+	{dx: 1, dy: 1, weight: 1.0 / 16},
+}
+
+var atkinsonKernel = []diffusion{
+	{dx: 1, dy: 0, weight: 1.0 / 8},
+	{dx: 2, dy: 0, weight: 1.0 / 8},
+	{dx: -1, dy: 1, weight: 1.0 / 8},
+	{dx: 0, dy: 1, weight: 1.0 / 8},
+	{dx: 1, dy: 1, weight: 1.0 / 8},
+	{dx: 0, dy: 2, weight: 1.0 / 8},
+}
+
+// diffuse dithers by pushing each pixel's quantization error onto the
+// neighbours listed in kernel.
+func diffuse(gray *image.Gray, kernel []diffusion) *Bitmap {
+	width, height := gray.Rect.Dx(), gray.Rect.Dy()
+	b := NewBitmap(width, height)
+
+	levels := make([]float32, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			levels[y*width+x] = float32(gray.Pix[y*gray.Stride+x])
+		}
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			old := levels[y*width+x]
+			var quantized float32 = 255
+			if old < 128 {
+				quantized = 0
+				b.Set(x, y, true)
+			}
+
+			diff := old - quantized
+			for _, k := range kernel {
+				nx, ny := x+k.dx, y+k.dy
+				if nx < 0 || nx >= width || ny >= height {
+					continue
+				}
+				levels[ny*width+nx] += diff * k.weight
+			}
+		}
+	}
+	return b
+}
@@ -0,0 +1,225 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// uniformGray returns a w x h image filled with one grey level.
+func uniformGray(w, h int, level uint8) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = level
+	}
+	return img
+}
+
+// blackRatio returns the fraction of black dots in b.
+func blackRatio(b *Bitmap) float64 {
+	black := 0
+	for y := 0; y < b.Height; y++ {
+		for x := 0; x < b.Width; x++ {
+			if b.Black(x, y) {
+				black++
+			}
+		}
+	}
+	return float64(black) / float64(b.Width*b.Height)
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestParseDither(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Dither
+		wantErr  bool
+	}{
+		{input: "", expected: DitherFloydSteinberg},
+		{input: "threshold", expected: DitherThreshold},
+		{input: "floyd-steinberg", expected: DitherFloydSteinberg},
+		{input: "atkinson", expected: DitherAtkinson},
+		{input: "ordered", expected: DitherOrdered},
+		{input: "halftone", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			dither, err := ParseDither(tt.input)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, dither)
+		})
+	}
+}
+
+func TestRenderImage_ScalesToHeadWidth(t *testing.T) {
+	tests := []struct {
+		name           string
+		width, height  int
+		expectedHeight int
+	}{
+		{name: "downscale", width: 768, height: 200, expectedHeight: 100},
+		{name: "upscale", width: 96, height: 96, expectedHeight: 384},
+		{name: "very wide image keeps one line", width: 10000, height: 1, expectedHeight: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			bmp, err := RenderImage(uniformGray(tt.width, tt.height, 0), DefaultImageOptions())
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, HeadWidth, bmp.Width)
+			assert.Equal(t, tt.expectedHeight, bmp.Height)
+		})
+	}
+}
+
+func TestRenderImage_DitherPreservesTone(t *testing.T) {
+	tests := []struct {
+		mode  Dither
+		delta float64
+	}{
+		{mode: DitherFloydSteinberg, delta: 0.02},
+		{mode: DitherOrdered, delta: 0.02},
+		// Atkinson drops a quarter of the error, so mid-tones come out lighter.
+		{mode: DitherAtkinson, delta: 0.1},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			// Arrange: 25% grey should come out roughly a quarter black
+			img := uniformGray(64, 64, 191)
+
+			// Act
+			bmp, err := RenderImage(img, ImageOptions{Width: 64, Dither: tt.mode})
+
+			// Assert
+			require.NoError(t, err)
+			assert.InDelta(t, 0.25, blackRatio(bmp), tt.delta)
+		})
+	}
+}
+
+func TestRenderImage_Threshold(t *testing.T) {
+	// Arrange: left half dark grey, right half light grey
+	img := image.NewGray(image.Rect(0, 0, 16, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 16; x++ {
+			level := uint8(0xc0)
+			if x < 8 {
+				level = 0x40
+			}
+			img.SetGray(x, y, color.Gray{Y: level})
+		}
+	}
+
+	// Act
+	bmp, err := RenderImage(img, ImageOptions{Width: 16, Dither: DitherThreshold})
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, bmp.Black(2, 1))
+	assert.False(t, bmp.Black(13, 1))
+	assert.InDelta(t, 0.5, blackRatio(bmp), 0.1)
+}
+
+func TestRenderImage_TransparentIsWhite(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 32, 32))
+
+	bmp, err := RenderImage(img, ImageOptions{Width: 32, Dither: DitherThreshold})
+
+	require.NoError(t, err)
+	assert.Zero(t, blackRatio(bmp))
+}
+
+func TestRenderImage_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		img  image.Image
+		opts ImageOptions
+	}{
+		{name: "empty image", img: image.NewGray(image.Rect(0, 0, 0, 0))},
+		{name: "unknown dither", img: uniformGray(4, 4, 0), opts: ImageOptions{Dither: "halftone"}},
+		{name: "too tall", img: uniformGray(1, 1000, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := RenderImage(tt.img, tt.opts)
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDecodeImage(t *testing.T) {
+	// Arrange
+	data := encodePNG(t, uniformGray(3, 2, 0))
+
+	// Act
+	img, err := DecodeImage(data)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 3, 2), img.Bounds())
+
+	_, err = DecodeImage([]byte("not an image"))
+	assert.Error(t, err)
+	_, err = DecodeImage(nil)
+	assert.Error(t, err)
+}
+
+func TestCheckImage_RejectsHugeImages(t *testing.T) {
+	// A PNG header claiming 6000x5000 pixels is refused before decoding.
+	data := encodePNG(t, image.NewGray(image.Rect(0, 0, 6000, 5000)))
+
+	err := CheckImage(data)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "too large")
+}
+
+func TestCheckImageFits(t *testing.T) {
+	tests := []struct {
+		name          string
+		img           image.Image
+		width         int
+		errorContains string
+	}{
+		{name: "fits the head width", img: uniformGray(384, 2000, 0)},
+		{name: "narrow and tall", img: uniformGray(10, 1000, 0), errorContains: "too tall: 38400 dots"},
+		{name: "narrow and tall at a small width", img: uniformGray(10, 1000, 0), width: 100},
+		{name: "just too tall at a small width", img: uniformGray(10, 1000, 0), width: 201, errorContains: "too tall"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckImageFits(encodePNG(t, tt.img), tt.width)
+
+			if tt.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
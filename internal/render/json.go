@@ -0,0 +1,288 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/font"
+)
+
+// JSONOptions controls how JSON data is laid out.
+// Zero values are replaced by the defaults from DefaultJSONOptions.
+type JSONOptions struct {
+	// Width is the bitmap width in dots.
+	Width int
+	// Size is the font size in pixels.
+	Size float64
+	// Margin is blank space in dots kept on the left and right edges.
+	Margin int
+	// MaxDepth is the number of nesting levels printed. Objects and arrays
+	// deeper than that are summarized as "{3 fields}" or "[12 items]".
+	MaxDepth int
+	// MaxItems is the number of array elements printed before the rest are
+	// summarized as "... N more".
+	MaxItems int
+}
+
+// DefaultJSONOptions returns the layout used for JSON print jobs: 20px Go
+// fonts across the full head width, four levels deep and ten array
+// elements long.
+func DefaultJSONOptions() JSONOptions {
+	return JSONOptions{
+		Width:    HeadWidth,
+		Size:     20,
+		MaxDepth: 4,
+		MaxItems: 10,
+	}
+}
+
+func (o JSONOptions) withDefaults() JSONOptions {
+	d := DefaultJSONOptions()
+	if o.Width <= 0 {
+		o.Width = d.Width
+	}
+	if o.Size <= 0 {
+		o.Size = d.Size
+	}
+	if o.MaxDepth <= 0 {
+		o.MaxDepth = d.MaxDepth
+	}
+	if o.MaxItems <= 0 {
+		o.MaxItems = d.MaxItems
+	}
+	return o
+}
+
+// jsonGuideWidth is the width in dots of the lines marking nesting levels.
+const jsonGuideWidth = 2
+
+// RenderJSON lays out JSON data as a receipt and rasterizes it into a
+// bitmap. Object keys are printed in bold with their value after them,
+// wrapping under the key. Nested objects and arrays are indented one level
+// with a vertical guide line, array elements are numbered, arrays of plain
+// values are printed on one line, and object keys are sorted. data may be any value encoding/json can marshal.
+func RenderJSON(data interface{}, opts JSONOptions) (*Bitmap, error) {
+	opts = opts.withDefaults()
+
+	avail := opts.Width - 2*opts.Margin
+	if avail <= 0 {
+		return nil, fmt.Errorf("margin %d leaves no room for text in width %d", opts.Margin, opts.Width)
+	}
+
+	value, err := normalizeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	faces := newFaceCache()
+	defer faces.close()
+	faceFor := func(style inlineStyle) (font.Face, error) {
+		return faces.get(styleFamily(style), opts.Size)
+	}
+	base, err := faceFor(0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deep nesting stops indenting once half the line is used up.
+	step := int(opts.Size)
+	maxIndent := avail / 2
+
+	rows := jsonRows(value, opts)
+	parts := make([]*Bitmap, 0, len(rows))
+	for _, row := range rows {
+		indent := min(row.depth*step, maxIndent)
+		x := opts.Margin + indent
+		lines, err := layoutRuns(row.runs, faceFor, avail-indent)
+		if err != nil {
+			return nil, err
+		}
+		bmp := drawLines(lines, base, opts.Width, x)
+		for level := 0; level < row.depth; level++ {
+			guide := opts.Margin + level*step + step/4
+			if guide+jsonGuideWidth > x {
+				break
+			}
+			for y := 0; y < bmp.Height; y++ {
+				for gx := guide; gx < guide+jsonGuideWidth; gx++ {
+					bmp.Set(gx, y, true)
+				}
+			}
+		}
+		parts = append(parts, bmp)
+	}
+	return Stack(parts...), nil
+}
+
+// normalizeJSON converts data to the values encoding/json decodes into,
+// keeping numbers as written.
+func normalizeJSON(data interface{}) (interface{}, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON: %w", err)
+	}
+	return value, nil
+}
+
+// jsonRow is one entry of the layout: a key or array index with its value,
+// at a nesting depth. Rows wrap to as many lines as they need.
+type jsonRow struct {
+	depth int
+	runs  []run
+}
+
+// jsonRows flattens a decoded JSON value into rows. The entries of a
+// top-level object or array are at depth 0.
+func jsonRows(value interface{}, opts JSONOptions) []jsonRow {
+	var rows []jsonRow
+	if isContainer(value) && jsonLen(value) > 0 {
+		appendEntries(&rows, value, 0, opts)
+		return rows
+	}
+	return appendValue(rows, 0, nil, value)
+}
+
+// appendEntries adds a row for each field of an object or element of an
+// array at depth, followed by the rows of their nested values.
+func appendEntries(rows *[]jsonRow, value interface{}, depth int, opts JSONOptions) {
+	entry := func(label string, v interface{}) {
+		labelRun := run{text: label, style: styleBold}
+		if list, ok := scalarList(v, opts.MaxItems); ok {
+			*rows = append(*rows, jsonRow{depth: depth, runs: []run{labelRun, {text: " " + list}}})
+			return
+		}
+		if !isContainer(v) || jsonLen(v) == 0 || depth+1 >= opts.MaxDepth {
+			*rows = appendValue(*rows, depth, &labelRun, v)
+			return
+		}
+		*rows = append(*rows, jsonRow{depth: depth, runs: []run{labelRun}})
+		appendEntries(rows, v, depth+1, opts)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			entry(key+":", v[key])
+		}
+
+	case []interface{}:
+		for i, item := range v {
+			if i == opts.MaxItems {
+				*rows = append(*rows, jsonRow{depth: depth, runs: []run{
+					{text: fmt.Sprintf("... %d more", len(v)-i), style: styleItalic},
+				}})
+				return
+			}
+			entry(strconv.Itoa(i+1)+".", item)
+		}
+	}
+}
+
+// appendValue adds the rows printing a scalar, or a summary of an object or
+// array, after an optional label. Each line of a multi-line string gets its
+// own row so the text keeps its line breaks.
+func appendValue(rows []jsonRow, depth int, label *run, value interface{}) []jsonRow {
+	text, style := jsonScalar(value)
+	for i, line := range strings.Split(text, "\n") {
+		var runs []run
+		if i == 0 && label != nil {
+			runs = append(runs, *label, run{text: " "})
+		}
+		if line != "" {
+			runs = append(runs, run{text: line, style: style})
+		}
+		rows = append(rows, jsonRow{depth: depth, runs: runs})
+	}
+	return rows
+}
+
+// jsonScalar returns the text printed for a value on one row. Strings print
+// without quotes; null and summaries of objects and arrays are in italics.
+func jsonScalar(value interface{}) (string, inlineStyle) {
+	switch v := value.(type) {
+	case nil:
+		return "null", styleItalic
+	case string:
+		return strings.ReplaceAll(strings.ReplaceAll(v, "\r\n", "\n"), "\r", "\n"), 0
+	case json.Number:
+		return v.String(), 0
+	case bool:
+		return strconv.FormatBool(v), 0
+	case map[string]interface{}:
+		switch len(v) {
+		case 0:
+			return "{}", styleItalic
+		case 1:
+			return "{1 field}", styleItalic
+		}
+		return fmt.Sprintf("{%d fields}", len(v)), styleItalic
+	case []interface{}:
+		switch len(v) {
+		case 0:
+			return "[]", styleItalic
+		case 1:
+			return "[1 item]", styleItalic
+		}
+		return fmt.Sprintf("[%d items]", len(v)), styleItalic
+	}
+	return fmt.Sprint(value), 0
+}
+
+// scalarList joins the elements of an array of scalars into one line, as in
+// "1, 2, 3 ... 9 more". It reports false for anything else.
+func scalarList(value interface{}, maxItems int) (string, bool) {
+	items, ok := value.([]interface{})
+	if !ok || len(items) == 0 {
+		return "", false
+	}
+	texts := make([]string, 0, min(len(items), maxItems))
+	for i, item := range items {
+		if isContainer(item) {
+			return "", false
+		}
+		if i < maxItems {
+			text, _ := jsonScalar(item)
+			texts = append(texts, strings.ReplaceAll(text, "\n", " "))
+		}
+	}
+	list := strings.Join(texts, ", ")
+	if len(items) > maxItems {
+		list += fmt.Sprintf(" ... %d more", len(items)-maxItems)
+	}
+	return list, true
+}
+
+// isContainer reports whether value is a decoded JSON object or array.
+func isContainer(value interface{}) bool {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return true
+	}
+	return false
+}
+
+// jsonLen returns the number of fields or elements of an object or array.
+func jsonLen(value interface{}) int {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return len(v)
+	case []interface{}:
+		return len(v)
+	}
+	return 0
+}
@@ -0,0 +1,159 @@
+package render
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONRows(t *testing.T) {
+	bold := func(text string) run { return run{text: text, style: styleBold} }
+	italic := func(text string) run { return run{text: text, style: styleItalic} }
+	space := run{text: " "}
+
+	tests := []struct {
+		name     string
+		data     string
+		opts     JSONOptions
+		expected []jsonRow
+	}{
+		{
+			name:     "scalar",
+			data:     `"hello"`,
+			expected: []jsonRow{{runs: []run{{text: "hello"}}}},
+		},
+		{
+			name: "keys are sorted and bold",
+			data: `{"total": 12.50, "paid": true, "note": null}`,
+			expected: []jsonRow{
+				{runs: []run{bold("note:"), space, italic("null")}},
+				{runs: []run{bold("paid:"), space, {text: "true"}}},
+				{runs: []run{bold("total:"), space, {text: "12.50"}}},
+			},
+		},
+		{
+			name: "nested objects are indented",
+			data: `{"customer": {"name": "Jane", "address": {"city": "Springfield"}}}`,
+			expected: []jsonRow{
+				{runs: []run{bold("customer:")}},
+				{depth: 1, runs: []run{bold("address:")}},
+				{depth: 2, runs: []run{bold("city:"), space, {text: "Springfield"}}},
+				{depth: 1, runs: []run{bold("name:"), space, {text: "Jane"}}},
+			},
+		},
+		{
+			name: "array elements are numbered",
+			data: `{"items": [{"name": "Coffee"}, {"name": "Milk"}]}`,
+			expected: []jsonRow{
+				{runs: []run{bold("items:")}},
+				{depth: 1, runs: []run{bold("1.")}},
+				{depth: 2, runs: []run{bold("name:"), space, {text: "Coffee"}}},
+				{depth: 1, runs: []run{bold("2.")}},
+				{depth: 2, runs: []run{bold("name:"), space, {text: "Milk"}}},
+			},
+		},
+		{
+			name: "long arrays are truncated",
+			data: `[{"a": 1}, {"a": 2}, {"a": 3}, {"a": 4}]`,
+			opts: JSONOptions{MaxItems: 2},
+			expected: []jsonRow{
+				{runs: []run{bold("1.")}},
+				{depth: 1, runs: []run{bold("a:"), space, {text: "1"}}},
+				{runs: []run{bold("2.")}},
+				{depth: 1, runs: []run{bold("a:"), space, {text: "2"}}},
+				{runs: []run{italic("... 2 more")}},
+			},
+		},
+		{
+			name: "arrays of plain values share a line",
+			data: `{"tags": ["a", 2, true, null, "e"], "empty": []}`,
+			opts: JSONOptions{MaxItems: 3},
+			expected: []jsonRow{
+				{runs: []run{bold("empty:"), space, italic("[]")}},
+				{runs: []run{bold("tags:"), {text: " a, 2, true ... 2 more"}}},
+			},
+		},
+		{
+			name: "nesting below the maximum depth is summarized",
+			data: `{"a": {"b": {"c": 1, "d": 2}, "e": [{"f": 1}]}}`,
+			opts: JSONOptions{MaxDepth: 2},
+			expected: []jsonRow{
+				{runs: []run{bold("a:")}},
+				{depth: 1, runs: []run{bold("b:"), space, italic("{2 fields}")}},
+				{depth: 1, runs: []run{bold("e:"), space, italic("[1 item]")}},
+			},
+		},
+		{
+			name: "multi-line strings keep their line breaks",
+			data: `{"address": "1 Main St\r\nSpringfield\n"}`,
+			expected: []jsonRow{
+				{runs: []run{bold("address:"), space, {text: "1 Main St"}}},
+				{runs: []run{{text: "Springfield"}}},
+				{runs: nil},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			value, err := normalizeJSON(json.RawMessage(tt.data))
+			require.NoError(t, err)
+
+			// Act
+			rows := jsonRows(value, tt.opts.withDefaults())
+
+			// Assert
+			assert.Equal(t, tt.expected, rows)
+		})
+	}
+}
+
+func TestRenderJSON(t *testing.T) {
+	render := func(t *testing.T, data interface{}, opts JSONOptions) *Bitmap {
+		t.Helper()
+		bmp, err := RenderJSON(data, opts)
+		require.NoError(t, err)
+		assert.Equal(t, HeadWidth, bmp.Width)
+		return bmp
+	}
+	lineHeight := render(t, "x", DefaultJSONOptions()).Height
+
+	t.Run("one line per field", func(t *testing.T) {
+		bmp := render(t, map[string]interface{}{"a": 1, "b": "two", "c": false}, DefaultJSONOptions())
+		assert.Equal(t, 3*lineHeight, bmp.Height)
+	})
+
+	t.Run("long values wrap", func(t *testing.T) {
+		bmp := render(t, map[string]string{"note": "a long note that cannot fit on a single line of the receipt"}, DefaultJSONOptions())
+		assert.Greater(t, bmp.Height, lineHeight)
+	})
+
+	t.Run("nested values have a guide line", func(t *testing.T) {
+		opts := DefaultJSONOptions()
+		bmp := render(t, map[string]interface{}{"a": map[string]interface{}{"b": 1}}, opts)
+		require.Equal(t, 2*lineHeight, bmp.Height)
+
+		guide := int(opts.Size) / 4
+		for y := lineHeight; y < bmp.Height; y++ {
+			assert.True(t, bmp.Black(guide, y), "guide at row %d", y)
+		}
+		assert.False(t, bmp.Black(guide, 0), "no guide next to the top level")
+	})
+
+	t.Run("deep nesting stays on the paper", func(t *testing.T) {
+		var data interface{} = "bottom"
+		for i := 0; i < 30; i++ {
+			data = map[string]interface{}{"level": data}
+		}
+		bmp := render(t, data, JSONOptions{MaxDepth: 40})
+		assert.Equal(t, 30*lineHeight, bmp.Height, "one row per level")
+	})
+
+	t.Run("unsupported data", func(t *testing.T) {
+		_, err := RenderJSON(make(chan int), DefaultJSONOptions())
+		assert.Error(t, err)
+	})
+}
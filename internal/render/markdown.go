@@ -0,0 +1,805 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/princem/peripage-printer/internal/barcode"
+	"github.com/princem/peripage-printer/internal/qr"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// MarkdownOptions controls how Markdown is laid out.
+// Zero values are replaced by the defaults from DefaultMarkdownOptions.
+type MarkdownOptions struct {
+	// Width is the bitmap width in dots.
+	Width int
+	// Size is the font size of body text in pixels. Headings scale from it.
+	Size float64
+	// Margin is blank space in dots kept on the left and right edges.
+	Margin int
+}
+
+// DefaultMarkdownOptions returns the layout used for Markdown print jobs:
+// 20px Go Regular across the full head width.
+func DefaultMarkdownOptions() MarkdownOptions {
+	return MarkdownOptions{
+		Width: HeadWidth,
+		Size:  20,
+	}
+}
+
+func (o MarkdownOptions) withDefaults() MarkdownOptions {
+	d := DefaultMarkdownOptions()
+	if o.Width <= 0 {
+		o.Width = d.Width
+	}
+	if o.Size <= 0 {
+		o.Size = d.Size
+	}
+	return o
+}
+
+// headingScale is the font size of each heading level relative to body text.
+var headingScale = [...]float64{1: 1.6, 2: 1.4, 3: 1.2, 4: 1, 5: 1, 6: 1}
+
+// Rule and checkbox geometry in dots.
+const (
+	ruleThickness     = 2
+	checkboxThickness = 2
+	checkboxInset     = 4
+	codeBarWidth      = 3
+)
+
+// RenderMarkdown lays out a Markdown document and rasterizes it into a bitmap.
+// It supports ATX headings, paragraphs, bullet and numbered lists with
+// nesting, "- [ ]" and "- [x]" checkboxes, horizontal rules and fenced code
+// blocks, and inline bold, italic and code. Other syntax prints as written.
+// Body text uses the proportional Go fonts and code uses Go Mono. A fenced
+// block whose info string is "qr" prints its contents as a QR code, with
+// the rest of the info string as its caption, and one whose info string is
+// "barcode" prints them as a barcode of the type that follows, such as
+// "barcode ean13". A fenced block whose info string is "table" prints its
+// lines as the rows of a table, with cells separated by "|"; see
+// parseTableFence for the options that may follow.
+func RenderMarkdown(source string, opts MarkdownOptions) (*Bitmap, error) {
+	opts = opts.withDefaults()
+
+	avail := opts.Width - 2*opts.Margin
+	if avail <= 0 {
+		return nil, fmt.Errorf("margin %d leaves no room for text in width %d", opts.Margin, opts.Width)
+	}
+
+	r := &markdownRenderer{opts: opts, avail: avail, faces: newFaceCache()}
+	defer r.faces.close()
+
+	blocks := parseMarkdown(source)
+	parts := make([]*Bitmap, 0, 2*len(blocks))
+	for i, b := range blocks {
+		if i > 0 {
+			parts = append(parts, NewBitmap(opts.Width, r.gap(blocks[i-1], b)))
+		}
+		bmp, err := r.block(b)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, bmp)
+	}
+	if len(parts) == 0 {
+		return NewBitmap(opts.Width, 0), nil
+	}
+	return Stack(parts...), nil
+}
+
+// CheckMarkdown reports the first QR code, barcode or table fence in source
+// that RenderMarkdown could not draw with opts, such as a barcode of
+// invalid data or table columns that do not fit. Other Markdown always
+// renders.
+func CheckMarkdown(source string, opts MarkdownOptions) error {
+	opts = opts.withDefaults()
+
+	avail := opts.Width - 2*opts.Margin
+	if avail <= 0 {
+		return fmt.Errorf("margin %d leaves no room for text in width %d", opts.Margin, opts.Width)
+	}
+
+	r := &markdownRenderer{opts: opts, avail: avail, faces: newFaceCache()}
+	defer r.faces.close()
+
+	for _, b := range parseMarkdown(source) {
+		switch b.kind {
+		case mdQR, mdBarcode, mdTable:
+			if _, err := r.block(b); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// mdBlockKind identifies a block-level Markdown element.
+type mdBlockKind int
+
+const (
+	mdParagraph mdBlockKind = iota
+	mdHeading
+	mdBullet
+	mdNumbered
+	mdCheckbox
+	mdRule
+	mdCode
+	mdQR
+	mdBarcode
+	mdTable
+)
+
+// mdBlock is one block-level Markdown element.
+type mdBlock struct {
+	kind mdBlockKind
+	// level is the heading level, or the nesting depth of a list item.
+	level int
+	// marker is the number of a numbered list item, e.g. "3.".
+	marker  string
+	checked bool
+	// text is the inline Markdown of the block, the code of a code block or
+	// the data of a QR code or barcode.
+	text string
+	// caption is printed under a QR code.
+	caption string
+	// symbology is the barcode type of a barcode.
+	symbology string
+	// table holds the column specs and options of a table.
+	table string
+}
+
+// isListItem reports whether the block is a list item of any kind.
+func (b mdBlock) isListItem() bool {
+	return b.kind == mdBullet || b.kind == mdNumbered || b.kind == mdCheckbox
+}
+
+var (
+	headingPattern  = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	rulePattern     = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	checkboxPattern = regexp.MustCompile(`^([ \t]*)[-*+][ \t]+\[([ xX])\](?:[ \t]+(.*))?$`)
+	bulletPattern   = regexp.MustCompile(`^([ \t]*)[-*+][ \t]+(.*)$`)
+	numberedPattern = regexp.MustCompile(`^([ \t]*)(\d{1,9})([.)])[ \t]+(.*)$`)
+	fencePattern    = regexp.MustCompile("^ {0,3}(```+|~~~+)")
+)
+
+// maxListDepth limits how far nested list items are indented.
+const maxListDepth = 4
+
+// parseMarkdown splits source into blocks. Lines that do not start a block
+// continue the paragraph or list item above them, joined with a space.
+func parseMarkdown(source string) []mdBlock {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\r", "\n")
+	lines := strings.Split(source, "\n")
+
+	var blocks []mdBlock
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if fence := fencePattern.FindStringSubmatch(line); fence != nil {
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence[1]); i++ {
+				code = append(code, lines[i])
+			}
+			block := mdBlock{kind: mdCode, text: strings.Join(code, "\n")}
+			if info := strings.Fields(line[len(fence[0]):]); len(info) > 0 {
+				switch info[0] {
+				case "qr":
+					block.kind, block.caption = mdQR, strings.Join(info[1:], " ")
+				case "barcode":
+					block.kind, block.symbology = mdBarcode, strings.Join(info[1:], " ")
+				case "table":
+					block.kind, block.table = mdTable, strings.Join(info[1:], " ")
+				}
+			}
+			blocks = append(blocks, block)
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if m := headingPattern.FindStringSubmatch(line); m != nil {
+			blocks = append(blocks, mdBlock{kind: mdHeading, level: len(m[1]), text: m[2]})
+			continue
+		}
+		if rulePattern.MatchString(line) {
+			blocks = append(blocks, mdBlock{kind: mdRule})
+			continue
+		}
+
+		if m := checkboxPattern.FindStringSubmatch(line); m != nil {
+			blocks = append(blocks, mdBlock{kind: mdCheckbox, level: listDepth(m[1]), checked: m[2] != " ", text: m[3]})
+			continue
+		}
+		if m := bulletPattern.FindStringSubmatch(line); m != nil {
+			blocks = append(blocks, mdBlock{kind: mdBullet, level: listDepth(m[1]), text: m[2]})
+			continue
+		}
+		if m := numberedPattern.FindStringSubmatch(line); m != nil {
+			blocks = append(blocks, mdBlock{kind: mdNumbered, level: listDepth(m[1]), marker: m[2] + m[3], text: m[4]})
+			continue
+		}
+
+		text := strings.TrimSpace(line)
+		if last := len(blocks) - 1; last >= 0 && isContinued(blocks[last], i, lines) {
+			blocks[last].text = strings.TrimSpace(blocks[last].text + " " + text)
+			continue
+		}
+		blocks = append(blocks, mdBlock{kind: mdParagraph, text: text})
+	}
+	return blocks
+}
+
+// isContinued reports whether line i continues block, which is the block
+// the previous line belongs to.
+func isContinued(block mdBlock, i int, lines []string) bool {
+	if i == 0 || strings.TrimSpace(lines[i-1]) == "" {
+		return false
+	}
+	return block.kind == mdParagraph || block.isListItem()
+}
+
+// listDepth converts the indentation of a list item to its nesting depth,
+// two columns per level with tabs counting as four.
+func listDepth(indent string) int {
+	columns := 0
+	for _, r := range indent {
+		if r == '\t' {
+			columns += 4
+		} else {
+			columns++
+		}
+	}
+	return min(columns/2, maxListDepth)
+}
+
+// inlineStyle is a combination of inline Markdown styles.
+type inlineStyle uint8
+
+const (
+	styleBold inlineStyle = 1 << iota
+	styleItalic
+	styleCode
+)
+
+// run is a piece of text in one style.
+type run struct {
+	text  string
+	style inlineStyle
+}
+
+// parseInline splits inline Markdown into styled runs. Code spans are taken
+// literally, and emphasis markers without a partner print as written.
+func parseInline(s string, style inlineStyle) []run {
+	var runs []run
+	var plain strings.Builder
+	flush := func() {
+		if plain.Len() > 0 {
+			runs = append(runs, run{text: plain.String(), style: style})
+			plain.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			plain.WriteByte(s[i+1])
+			i += 2
+			continue
+
+		case c == '`':
+			if end := strings.IndexByte(s[i+1:], '`'); end >= 0 {
+				flush()
+				runs = append(runs, run{text: s[i+1 : i+1+end], style: style | styleCode})
+				i += end + 2
+				continue
+			}
+
+		case c == '*' || c == '_':
+			delim := s[i : i+1]
+			if i+1 < len(s) && s[i+1] == c {
+				delim = s[i : i+2]
+			}
+			if inner, n, ok := emphasis(s, i, delim); ok {
+				flush()
+				add := styleItalic
+				if len(delim) == 2 {
+					add = styleBold
+				}
+				runs = append(runs, parseInline(inner, style|add)...)
+				i += n
+				continue
+			}
+		}
+		plain.WriteByte(c)
+		i++
+	}
+	flush()
+	return runs
+}
+
+// emphasis finds the text emphasized by the delimiter at s[i:]. It returns
+// the text between the delimiters and the length of the whole span.
+// Underscores inside words, as in snake_case, are not emphasis.
+func emphasis(s string, i int, delim string) (string, int, bool) {
+	start := i + len(delim)
+	if start >= len(s) || isSpace(s[start]) {
+		return "", 0, false
+	}
+	if delim[0] == '_' && i > 0 && isWordByte(s[i-1]) {
+		return "", 0, false
+	}
+
+	double := strings.Repeat(delim[:1], 2)
+	for j := start + 1; j+len(delim) <= len(s); j++ {
+		// Skip nested strong emphasis when looking for a single delimiter.
+		if len(delim) == 1 && strings.HasPrefix(s[j:], double) && j+2 < len(s) && !isSpace(s[j+2]) {
+			if _, n, ok := emphasis(s, j, double); ok {
+				j += n - 1
+				continue
+			}
+		}
+		if s[j:j+len(delim)] != delim || isSpace(s[j-1]) || s[j-1] == '\\' {
+			continue
+		}
+		// A longer run of markers closes at its end, as in "***both***".
+		for j+len(delim) < len(s) && s[j+len(delim)] == delim[0] {
+			j++
+		}
+		if delim[0] == '_' && j+len(delim) < len(s) && isWordByte(s[j+len(delim)]) {
+			continue
+		}
+		return s[start:j], j + len(delim) - i, true
+	}
+	return "", 0, false
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+// styleFamily returns the font drawing a style.
+func styleFamily(style inlineStyle) Family {
+	bold, italic := style&styleBold != 0, style&styleItalic != 0
+	switch {
+	case style&styleCode != 0 && bold:
+		return MonoBold
+	case style&styleCode != 0:
+		return Mono
+	case bold && italic:
+		return BoldItalic
+	case bold:
+		return Bold
+	case italic:
+		return Italic
+	}
+	return Regular
+}
+
+// faceKey identifies a cached face.
+type faceKey struct {
+	family Family
+	size   float64
+}
+
+// faceCache creates each face of a render once. Faces are not safe for
+// concurrent use, so a cache belongs to one render call.
+type faceCache struct {
+	faces map[faceKey]font.Face
+}
+
+func newFaceCache() *faceCache {
+	return &faceCache{faces: make(map[faceKey]font.Face)}
+}
+
+// get returns the face of family at size pixels.
+func (c *faceCache) get(family Family, size float64) (font.Face, error) {
+	key := faceKey{family: family, size: size}
+	if face, ok := c.faces[key]; ok {
+		return face, nil
+	}
+	face, err := newFace(family, size)
+	if err != nil {
+		return nil, err
+	}
+	c.faces[key] = face
+	return face, nil
+}
+
+// close closes every cached face.
+func (c *faceCache) close() {
+	for _, face := range c.faces {
+		face.Close()
+	}
+}
+
+// piece is text drawn with one face at a horizontal offset.
+type piece struct {
+	x    int
+	text string
+	face font.Face
+}
+
+// richLine is one laid out line of styled text.
+type richLine []piece
+
+// wordPart is the part of a word in one style.
+type wordPart struct {
+	text  string
+	face  font.Face
+	width int
+}
+
+// word is a run of text without spaces, possibly in several styles.
+type word struct {
+	parts []wordPart
+	// space is the advance of the spaces before the word.
+	space int
+	width int
+}
+
+// markdownRenderer renders the blocks of one Markdown document.
+type markdownRenderer struct {
+	opts  MarkdownOptions
+	avail int
+	faces *faceCache
+}
+
+// gap returns the blank space between two blocks. List items follow each
+// other without a gap.
+func (r *markdownRenderer) gap(prev, next mdBlock) int {
+	if prev.isListItem() && next.isListItem() {
+		return 0
+	}
+	return int(r.opts.Size / 2)
+}
+
+// block renders one block across the full width.
+func (r *markdownRenderer) block(b mdBlock) (*Bitmap, error) {
+	switch b.kind {
+	case mdHeading:
+		size := math.Round(r.opts.Size * headingScale[b.level])
+		return r.text(parseInline(b.text, styleBold), size, r.opts.Margin, nil)
+
+	case mdBullet, mdNumbered, mdCheckbox:
+		return r.listItem(b)
+
+	case mdRule:
+		height := int(r.opts.Size)
+		bmp := NewBitmap(r.opts.Width, height)
+		top := (height - ruleThickness) / 2
+		for y := top; y < top+ruleThickness; y++ {
+			for x := r.opts.Margin; x < r.opts.Width-r.opts.Margin; x++ {
+				bmp.Set(x, y, true)
+			}
+		}
+		return bmp, nil
+
+	case mdCode:
+		indent := int(r.opts.Size / 2)
+		bmp, err := RenderText(b.text, TextOptions{
+			Width:  r.opts.Width - r.opts.Margin - indent,
+			Family: Mono,
+			Size:   r.opts.Size,
+		})
+		if err != nil {
+			return nil, err
+		}
+		out := NewBitmap(r.opts.Width, bmp.Height)
+		out.Draw(bmp, r.opts.Margin+indent, 0)
+		for y := 0; y < out.Height; y++ {
+			for x := r.opts.Margin; x < r.opts.Margin+codeBarWidth; x++ {
+				out.Set(x, y, true)
+			}
+		}
+		return out, nil
+
+	case mdQR:
+		code, err := qr.Encode(b.text, qr.Medium)
+		if err != nil {
+			return nil, err
+		}
+		return RenderQR(code, QROptions{
+			Width:   r.opts.Width,
+			Margin:  r.opts.Margin,
+			Caption: b.caption,
+			Size:    r.opts.Size,
+		})
+
+	case mdBarcode:
+		sym, err := barcode.ParseSymbology(b.symbology)
+		if err != nil {
+			return nil, err
+		}
+		code, err := barcode.Encode(sym, b.text)
+		if err != nil {
+			return nil, err
+		}
+		return RenderBarcode(code, BarcodeOptions{
+			Width:  r.opts.Width,
+			Margin: r.opts.Margin,
+			Size:   r.opts.Size,
+		})
+
+	case mdTable:
+		header, rows, opts, err := parseTableFence(b.table, b.text)
+		if err != nil {
+			return nil, err
+		}
+		opts.Width, opts.Margin, opts.Size = r.opts.Width, r.opts.Margin, r.opts.Size
+		return RenderTable(header, rows, opts)
+
+	default:
+		return r.text(parseInline(b.text, 0), r.opts.Size, r.opts.Margin, nil)
+	}
+}
+
+// tableDelimiterPattern matches the line under the header of a table, such
+// as "---" or "|---|---|".
+var tableDelimiterPattern = regexp.MustCompile(`^\|?(?:[ \t]*:?-{3,}:?[ \t]*\|?)+$`)
+
+// parseTableFence reads a fenced table. The info string after "table" lists
+// a column spec per column, as read by ParseColumnSpec, and may add
+// "borders" and "padding=N" for N dots of cell padding. Each line of the
+// body is a row; cells are separated by "|", which may also start and end
+// the line, and "\|" is a literal bar. When the second line is a delimiter
+// such as "---", the first row is a bold header.
+func parseTableFence(info, body string) ([]string, [][]string, TableOptions, error) {
+	var opts TableOptions
+	for _, word := range strings.Fields(info) {
+		switch {
+		case word == "borders":
+			opts.Borders = true
+		case strings.HasPrefix(word, "padding="):
+			n, err := strconv.Atoi(strings.TrimPrefix(word, "padding="))
+			if err != nil || n < 0 {
+				return nil, nil, opts, fmt.Errorf("invalid table padding %q", word)
+			}
+			opts.Padding = n
+		default:
+			spec, err := ParseColumnSpec(word)
+			if err != nil {
+				return nil, nil, opts, err
+			}
+			opts.Columns = append(opts.Columns, spec)
+		}
+	}
+
+	var header []string
+	var rows [][]string
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if tableDelimiterPattern.MatchString(line) {
+			if header == nil && len(rows) == 1 {
+				header, rows = rows[0], nil
+			}
+			continue
+		}
+		rows = append(rows, splitTableRow(line))
+	}
+	return header, rows, opts, nil
+}
+
+// splitTableRow splits a table line into trimmed cells at bars that are not
+// escaped with a backslash.
+func splitTableRow(line string) []string {
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, "\\|") {
+		line = strings.TrimSuffix(line, "|")
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// listItem renders a list item with its marker hanging left of the text.
+func (r *markdownRenderer) listItem(b mdBlock) (*Bitmap, error) {
+	body, err := r.faces.get(Regular, r.opts.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	step := int(r.opts.Size)
+	markerX := r.opts.Margin + b.level*step
+	var marker *piece
+	textX := markerX + step
+	switch b.kind {
+	case mdBullet:
+		marker = &piece{text: "•", face: body}
+	case mdNumbered:
+		marker = &piece{text: b.marker, face: body}
+		textX = max(textX, markerX+measure(body, b.marker+" "))
+	}
+	if textX >= r.opts.Width-r.opts.Margin {
+		textX = r.opts.Margin
+	}
+	if marker != nil {
+		marker.x = markerX - textX
+	}
+
+	bmp, err := r.text(parseInline(b.text, 0), r.opts.Size, textX, marker)
+	if err != nil {
+		return nil, err
+	}
+	if b.kind == mdCheckbox {
+		ascent := body.Metrics().Ascent.Ceil()
+		drawCheckbox(bmp, markerX, ascent, ascent*3/4, b.checked)
+	}
+	return bmp, nil
+}
+
+// drawCheckbox draws a square box of size dots whose bottom edge sits on
+// the baseline. A checked box is filled in.
+func drawCheckbox(bmp *Bitmap, left, baseline, size int, checked bool) {
+	top := baseline - size
+	for y := top; y < baseline; y++ {
+		for x := left; x < left+size; x++ {
+			edge := x < left+checkboxThickness || x >= left+size-checkboxThickness ||
+				y < top+checkboxThickness || y >= baseline-checkboxThickness
+			inside := x >= left+checkboxInset && x < left+size-checkboxInset &&
+				y >= top+checkboxInset && y < baseline-checkboxInset
+			if edge || checked && inside {
+				bmp.Set(x, y, true)
+			}
+		}
+	}
+}
+
+// text lays out runs at size pixels between x and the right margin and
+// rasterizes them. A marker is added to the first line, placed relative to x.
+func (r *markdownRenderer) text(runs []run, size float64, x int, marker *piece) (*Bitmap, error) {
+	faceFor := func(style inlineStyle) (font.Face, error) {
+		return r.faces.get(styleFamily(style), size)
+	}
+	base, err := faceFor(0)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := layoutRuns(runs, faceFor, r.opts.Width-r.opts.Margin-x)
+	if err != nil {
+		return nil, err
+	}
+	if marker != nil {
+		lines[0] = append(richLine{*marker}, lines[0]...)
+	}
+	return drawLines(lines, base, r.opts.Width, x), nil
+}
+
+// layoutRuns wraps styled runs into lines of at most width dots. Lines break
+// at spaces only, so a style change inside a word does not split it; words
+// longer than a line are broken between characters. There is always at
+// least one line.
+func layoutRuns(runs []run, faceFor func(inlineStyle) (font.Face, error), width int) ([]richLine, error) {
+	var words []word
+	space, inWord := 0, false
+	for _, rn := range runs {
+		face, err := faceFor(rn.style)
+		if err != nil {
+			return nil, err
+		}
+		for _, tok := range tokenize(rn.text) {
+			if tok == " " || tok == "\t" {
+				space += measure(face, " ")
+				inWord = false
+				continue
+			}
+			if !inWord {
+				words = append(words, word{space: space})
+				space, inWord = 0, true
+			}
+			w := &words[len(words)-1]
+			part := wordPart{text: tok, face: face, width: measure(face, tok)}
+			w.parts = append(w.parts, part)
+			w.width += part.width
+		}
+	}
+
+	var lines []richLine
+	var cur richLine
+	x := 0
+	newline := func() {
+		lines = append(lines, cur)
+		cur = nil
+		x = 0
+	}
+
+	for _, w := range words {
+		if x > 0 && x+w.space+w.width > width {
+			newline()
+		}
+		if x > 0 {
+			x += w.space
+		}
+		if w.width <= width-x {
+			for _, p := range w.parts {
+				cur = append(cur, piece{x: x, text: p.text, face: p.face})
+				x += p.width
+			}
+			continue
+		}
+		for _, p := range w.parts {
+			for text := p.text; text != ""; {
+				head, rest := splitToFit(p.face, text, width-x)
+				if x > 0 && measure(p.face, head) > width-x {
+					newline()
+					continue
+				}
+				cur = append(cur, piece{x: x, text: head, face: p.face})
+				x += measure(p.face, head)
+				if text = rest; text != "" {
+					newline()
+				}
+			}
+		}
+	}
+	return append(lines, cur), nil
+}
+
+// drawLines rasterizes lines with their left edge at x. Each line is as tall
+// as its tallest face; empty lines take the height of base.
+func drawLines(lines []richLine, base font.Face, width, x int) *Bitmap {
+	heights := make([]int, len(lines))
+	ascents := make([]int, len(lines))
+	total := 0
+	for i, l := range lines {
+		metrics := base.Metrics()
+		heights[i], ascents[i] = metrics.Height.Ceil(), metrics.Ascent.Ceil()
+		for _, p := range l {
+			metrics := p.face.Metrics()
+			heights[i] = max(heights[i], metrics.Height.Ceil())
+			ascents[i] = max(ascents[i], metrics.Ascent.Ceil())
+		}
+		total += heights[i]
+	}
+
+	canvas := image.NewAlpha(image.Rect(0, 0, width, total))
+	y := 0
+	for i, l := range lines {
+		for _, p := range l {
+			drawer := font.Drawer{Dst: canvas, Src: image.Opaque, Face: p.face}
+			drawer.Dot = fixed.P(x+p.x, y+ascents[i])
+			drawer.DrawString(p.text)
+		}
+		y += heights[i]
+	}
+
+	bmp := NewBitmap(width, total)
+	bmp.thresholdAlpha(canvas)
+	return bmp
+}
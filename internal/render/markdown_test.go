@@ -0,0 +1,342 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/princem/peripage-printer/internal/barcode"
+	"github.com/princem/peripage-printer/internal/qr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected []mdBlock
+	}{
+		{
+			name:   "headings",
+			source: "# Title\n### Section ###\n#hashtag",
+			expected: []mdBlock{
+				{kind: mdHeading, level: 1, text: "Title"},
+				{kind: mdHeading, level: 3, text: "Section"},
+				{kind: mdParagraph, text: "#hashtag"},
+			},
+		},
+		{
+			name:   "paragraph lines are joined",
+			source: "one\ntwo\n\nthree",
+			expected: []mdBlock{
+				{kind: mdParagraph, text: "one two"},
+				{kind: mdParagraph, text: "three"},
+			},
+		},
+		{
+			name:   "lists and checkboxes",
+			source: "- milk\n* [ ] eggs\n  + [x] bread\n    continued\n1. first\n12) twelfth",
+			expected: []mdBlock{
+				{kind: mdBullet, text: "milk"},
+				{kind: mdCheckbox, text: "eggs"},
+				{kind: mdCheckbox, level: 1, checked: true, text: "bread continued"},
+				{kind: mdNumbered, marker: "1.", text: "first"},
+				{kind: mdNumbered, marker: "12)", text: "twelfth"},
+			},
+		},
+		{
+			name:   "rules are not list items",
+			source: "---\n* * *\n- - -\n___",
+			expected: []mdBlock{
+				{kind: mdRule}, {kind: mdRule}, {kind: mdRule}, {kind: mdRule},
+			},
+		},
+		{
+			name:   "fenced code is kept verbatim",
+			source: "```go\n# not a heading\n\n\tx := 1\n```\nafter",
+			expected: []mdBlock{
+				{kind: mdCode, text: "# not a heading\n\n\tx := 1"},
+				{kind: mdParagraph, text: "after"},
+			},
+		},
+		{
+			name:   "qr fence takes a caption",
+			source: "```qr Scan to join\nWIFI:S:Office;;\n```\n```qr\nhttps://example.com\n```",
+			expected: []mdBlock{
+				{kind: mdQR, text: "WIFI:S:Office;;", caption: "Scan to join"},
+				{kind: mdQR, text: "https://example.com"},
+			},
+		},
+		{
+			name:   "barcode fence takes a type",
+			source: "```barcode ean13\n400638133393\n```\n```barcode\nBIN-A-17\n```",
+			expected: []mdBlock{
+				{kind: mdBarcode, text: "400638133393", symbology: "ean13"},
+				{kind: mdBarcode, text: "BIN-A-17"},
+			},
+		},
+		{
+			name:   "table fence takes column specs",
+			source: "```table 3 80px:right borders\nItem | Price\n---\nTea | 5.00\n```",
+			expected: []mdBlock{
+				{kind: mdTable, text: "Item | Price\n---\nTea | 5.00", table: "3 80px:right borders"},
+			},
+		},
+		{
+			name:     "unclosed fence runs to the end",
+			source:   "~~~\ncode",
+			expected: []mdBlock{{kind: mdCode, text: "code"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseMarkdown(tt.source))
+		})
+	}
+}
+
+func TestParseTableFence(t *testing.T) {
+	tests := []struct {
+		name           string
+		info           string
+		body           string
+		expectedHeader []string
+		expectedRows   [][]string
+		expectedOpts   TableOptions
+		wantErr        bool
+	}{
+		{
+			name:         "rows without a header",
+			body:         "Tea | 5.00\n\nCake|3.50",
+			expectedRows: [][]string{{"Tea", "5.00"}, {"Cake", "3.50"}},
+		},
+		{
+			name:           "header and outer bars",
+			body:           "| Item | Price |\n|------|------:|\n| Tea | 5.00 |",
+			expectedHeader: []string{"Item", "Price"},
+			expectedRows:   [][]string{{"Tea", "5.00"}},
+		},
+		{
+			name:         "escaped bars and empty cells",
+			body:         `a \| b || c \|`,
+			expectedRows: [][]string{{"a | b", "", "c |"}},
+		},
+		{
+			name:         "options",
+			info:         "2 80px:right borders padding=4",
+			body:         "Tea | 5.00",
+			expectedRows: [][]string{{"Tea", "5.00"}},
+			expectedOpts: TableOptions{
+				Columns: []ColumnSpec{{Ratio: 2, Align: AlignLeft}, {Width: 80, Align: AlignRight}},
+				Padding: 4,
+				Borders: true,
+			},
+		},
+		{name: "invalid padding", info: "padding=x", wantErr: true},
+		{name: "invalid column", info: "2:middle", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			header, rows, opts, err := parseTableFence(tt.info, tt.body)
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedHeader, header)
+			assert.Equal(t, tt.expectedRows, rows)
+			assert.Equal(t, tt.expectedOpts, opts)
+		})
+	}
+}
+
+func TestParseInline(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []run
+	}{
+		{
+			name:     "plain",
+			text:     "just text",
+			expected: []run{{text: "just text"}},
+		},
+		{
+			name: "bold, italic and code",
+			text: "a **b** _c_ `d*e`",
+			expected: []run{
+				{text: "a "}, {text: "b", style: styleBold}, {text: " "},
+				{text: "c", style: styleItalic}, {text: " "}, {text: "d*e", style: styleCode},
+			},
+		},
+		{
+			name:     "bold italic",
+			text:     "***both***",
+			expected: []run{{text: "both", style: styleBold | styleItalic}},
+		},
+		{
+			name: "nested",
+			text: "*it **bold** it*",
+			expected: []run{
+				{text: "it ", style: styleItalic},
+				{text: "bold", style: styleItalic | styleBold},
+				{text: " it", style: styleItalic},
+			},
+		},
+		{
+			name:     "unmatched markers and snake_case stay",
+			text:     "2 * 3 and snake_case_name and *open",
+			expected: []run{{text: "2 * 3 and snake_case_name and *open"}},
+		},
+		{
+			name:     "escapes",
+			text:     `\*not italic\*`,
+			expected: []run{{text: "*not italic*"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseInline(tt.text, 0))
+		})
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	render := func(t *testing.T, source string) *Bitmap {
+		t.Helper()
+		bmp, err := RenderMarkdown(source, DefaultMarkdownOptions())
+		require.NoError(t, err)
+		assert.Equal(t, HeadWidth, bmp.Width)
+		return bmp
+	}
+
+	t.Run("empty document", func(t *testing.T) {
+		assert.Equal(t, 0, render(t, "").Height)
+	})
+
+	t.Run("headings are larger than body text", func(t *testing.T) {
+		body := render(t, "Notes").Height
+		assert.Greater(t, render(t, "# Notes").Height, render(t, "### Notes").Height)
+		assert.Greater(t, render(t, "### Notes").Height, body)
+	})
+
+	t.Run("long paragraphs wrap", func(t *testing.T) {
+		line := render(t, "word").Height
+		wrapped := render(t, strings.Repeat("word ", 30)).Height
+		assert.Greater(t, wrapped, line)
+		assert.Zero(t, wrapped%line, "wrapped lines keep the body line height")
+	})
+
+	t.Run("checked and unchecked boxes differ", func(t *testing.T) {
+		open := render(t, "- [ ] eggs")
+		done := render(t, "- [x] eggs")
+		assert.Equal(t, open.Height, done.Height)
+		assert.NotEqual(t, open.Pix, done.Pix)
+	})
+
+	t.Run("nested list items are indented", func(t *testing.T) {
+		top := render(t, "- item")
+		nested := render(t, "  - item")
+		assert.Greater(t, leftmostInk(nested), leftmostInk(top))
+	})
+
+	t.Run("code blocks use the monospace layout", func(t *testing.T) {
+		code := render(t, "```\nx := 1\ny := 2\n```")
+		assert.Equal(t, 2*lineHeightFor(t, DefaultTextOptions()), code.Height)
+	})
+
+	t.Run("qr blocks draw the code", func(t *testing.T) {
+		code, err := qr.Encode("https://example.com", qr.Medium)
+		require.NoError(t, err)
+		expected, err := RenderQR(code, QROptions{Caption: "Menu"})
+		require.NoError(t, err)
+
+		assert.Equal(t, expected, render(t, "```qr Menu\nhttps://example.com\n```"))
+	})
+
+	t.Run("barcode blocks draw the code", func(t *testing.T) {
+		code, err := barcode.Encode(barcode.EAN13, "400638133393")
+		require.NoError(t, err)
+		expected, err := RenderBarcode(code, BarcodeOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, expected, render(t, "```barcode ean13\n400638133393\n```"))
+	})
+
+	t.Run("table blocks draw the table", func(t *testing.T) {
+		expected, err := RenderTable([]string{"Item", "Price"}, [][]string{{"Tea", "5.00"}}, TableOptions{
+			Columns: []ColumnSpec{{Align: AlignLeft}, {Width: 80, Align: AlignRight}},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, expected, render(t, "```table left 80px:right\nItem | Price\n---\nTea | 5.00\n```"))
+	})
+
+	t.Run("invalid table specs fail", func(t *testing.T) {
+		_, err := RenderMarkdown("```table wide\nTea | 5.00\n```", MarkdownOptions{})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid barcodes fail", func(t *testing.T) {
+		_, err := RenderMarkdown("```barcode ean13\n4006381333932\n```", MarkdownOptions{})
+		assert.ErrorIs(t, err, barcode.ErrCheckDigit)
+	})
+
+	t.Run("rules span the width", func(t *testing.T) {
+		rule := render(t, "---")
+		y := rule.Height / 2
+		assert.True(t, rule.Black(0, y))
+		assert.True(t, rule.Black(HeadWidth-1, y))
+	})
+}
+
+func TestCheckMarkdown(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		wantErr bool
+	}{
+		{name: "plain markdown", source: "# Title\n\n- item\n\n```go\nx := 1\n```"},
+		{name: "valid fences", source: "```qr Menu\nhttps://example.com\n```\n```barcode ean13\n400638133393\n```\n```table 3 96px:right\nTea | 5.00\n```"},
+		{name: "invalid barcode", source: "```barcode ean13\nabc\n```", wantErr: true},
+		{name: "qr too large", source: "```qr\n" + strings.Repeat("A", 5000) + "\n```", wantErr: true},
+		{name: "table columns too narrow", source: "```table 10px 10px\na | b\n```", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := CheckMarkdown(tt.source, MarkdownOptions{})
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRenderMarkdown_NeverDrawsOutsideWidth(t *testing.T) {
+	// Arrange: a narrow bitmap with a word much longer than a line
+	opts := MarkdownOptions{Width: 100, Margin: 4}
+
+	// Act
+	bmp, err := RenderMarkdown("- **"+strings.Repeat("m", 40)+"**", opts)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 100, bmp.Width)
+	for y := 0; y < bmp.Height; y++ {
+		for x := 96; x < 100; x++ {
+			assert.False(t, bmp.Black(x, y), "ink in the right margin at (%d, %d)", x, y)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/image/font"
+)
+
+// Align is the horizontal alignment of content within its width.
+type Align string
+
+const (
+	// AlignLeft puts content against the left edge. It is the default.
+	AlignLeft Align = "left"
+	// AlignCenter centres content.
+	AlignCenter Align = "center"
+	// AlignRight puts content against the right edge.
+	AlignRight Align = "right"
+)
+
+// ParseAlign validates an alignment name. An empty name selects AlignLeft.
+func ParseAlign(name string) (Align, error) {
+	switch Align(name) {
+	case "", AlignLeft:
+		return AlignLeft, nil
+	case AlignCenter, AlignRight:
+		return Align(name), nil
+	}
+	return "", fmt.Errorf("unknown alignment %q: use left, center or right", name)
+}
+
+// Offset returns how far to move content used dots wide to align it in
+// avail dots.
+func (a Align) Offset(used, avail int) int {
+	switch a {
+	case AlignCenter:
+		return (avail - used) / 2
+	case AlignRight:
+		return avail - used
+	}
+	return 0
+}
+
+// ParagraphOptions controls how a paragraph of styled text is laid out.
+// Zero values are replaced by the defaults from DefaultParagraphOptions.
+type ParagraphOptions struct {
+	// Width is the bitmap width in dots.
+	Width int
+	// Margin is blank space in dots kept on the left and right edges.
+	Margin int
+	// Size is the font size in pixels.
+	Size float64
+	// Bold, Italic and Mono select the font. Go Mono has no italic, so
+	// Mono ignores Italic.
+	Bold   bool
+	Italic bool
+	Mono   bool
+	// Align places each line between the margins.
+	Align Align
+}
+
+// DefaultParagraphOptions returns 20px Go Regular across the full head,
+// aligned left.
+func DefaultParagraphOptions() ParagraphOptions {
+	return ParagraphOptions{
+		Width: HeadWidth,
+		Size:  20,
+		Align: AlignLeft,
+	}
+}
+
+func (o ParagraphOptions) withDefaults() ParagraphOptions {
+	d := DefaultParagraphOptions()
+	if o.Width <= 0 {
+		o.Width = d.Width
+	}
+	if o.Size <= 0 {
+		o.Size = d.Size
+	}
+	if o.Align == "" {
+		o.Align = d.Align
+	}
+	return o
+}
+
+// family returns the font of the options.
+func (o ParagraphOptions) family() Family {
+	var style inlineStyle
+	if o.Bold {
+		style |= styleBold
+	}
+	if o.Italic {
+		style |= styleItalic
+	}
+	if o.Mono {
+		style |= styleCode
+	}
+	return styleFamily(style)
+}
+
+// RenderParagraph lays out text in one font, wrapping it at spaces to fit
+// between the margins and aligning every line. "\n" starts a new line.
+func RenderParagraph(text string, opts ParagraphOptions) (*Bitmap, error) {
+	opts = opts.withDefaults()
+	if _, err := ParseAlign(string(opts.Align)); err != nil {
+		return nil, err
+	}
+	if opts.Width-2*opts.Margin <= 0 {
+		return nil, fmt.Errorf("margin %d leaves no room for text in width %d", opts.Margin, opts.Width)
+	}
+
+	faces := newFaceCache()
+	defer faces.close()
+	return layoutParagraph(text, opts.family(), opts.Size, opts.Width, opts.Margin, opts.Align, faces)
+}
+
+// layoutParagraph wraps and aligns text in one font between the margins.
+func layoutParagraph(text string, family Family, size float64, width, margin int, align Align, faces *faceCache) (*Bitmap, error) {
+	faceFor := func(inlineStyle) (font.Face, error) {
+		return faces.get(family, size)
+	}
+	base, err := faceFor(0)
+	if err != nil {
+		return nil, err
+	}
+
+	avail := width - 2*margin
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	var lines []richLine
+	for _, para := range strings.Split(text, "\n") {
+		wrapped, err := layoutRuns([]run{{text: para}}, faceFor, avail)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, wrapped...)
+	}
+	for _, l := range lines {
+		if len(l) == 0 {
+			continue
+		}
+		last := l[len(l)-1]
+		shift := align.Offset(last.x+measure(last.face, last.text), avail)
+		for i := range l {
+			l[i].x += shift
+		}
+	}
+	return drawLines(lines, base, width, margin), nil
+}
@@ -0,0 +1,146 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rightmostInk returns the x of the last black column, or -1 if the bitmap is blank.
+func rightmostInk(bmp *Bitmap) int {
+	for x := bmp.Width - 1; x >= 0; x-- {
+		for y := 0; y < bmp.Height; y++ {
+			if bmp.Black(x, y) {
+				return x
+			}
+		}
+	}
+	return -1
+}
+
+func TestParseAlign(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Align
+		wantErr  bool
+	}{
+		{input: "", expected: AlignLeft},
+		{input: "left", expected: AlignLeft},
+		{input: "center", expected: AlignCenter},
+		{input: "right", expected: AlignRight},
+		{input: "centre", wantErr: true},
+		{input: "Right", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		align, err := ParseAlign(tt.input)
+		if tt.wantErr {
+			assert.Error(t, err, "input %q", tt.input)
+			continue
+		}
+		require.NoError(t, err, "input %q", tt.input)
+		assert.Equal(t, tt.expected, align)
+	}
+}
+
+func TestRenderParagraph_Align(t *testing.T) {
+	tests := []struct {
+		align Align
+		check func(t *testing.T, left, right int)
+	}{
+		{align: AlignLeft, check: func(t *testing.T, left, right int) {
+			assert.Less(t, left, 4)
+		}},
+		{align: AlignCenter, check: func(t *testing.T, left, right int) {
+			assert.InDelta(t, HeadWidth-1-right, left, 4)
+		}},
+		{align: AlignRight, check: func(t *testing.T, left, right int) {
+			assert.Greater(t, right, HeadWidth-5)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.align), func(t *testing.T) {
+			// Act
+			bmp, err := RenderParagraph("Total 12.50", ParagraphOptions{Align: tt.align})
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, HeadWidth, bmp.Width)
+			tt.check(t, leftmostInk(bmp), rightmostInk(bmp))
+		})
+	}
+}
+
+func TestRenderParagraph_Lines(t *testing.T) {
+	// Arrange
+	one, err := RenderParagraph("Hello", ParagraphOptions{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		text          string
+		opts          ParagraphOptions
+		expectedLines int
+	}{
+		{name: "newlines start lines", text: "a\nb\n\nc", expectedLines: 4},
+		{name: "long text wraps", text: "the quick brown fox jumps over the lazy dog again and again", opts: ParagraphOptions{Width: 200}, expectedLines: 3},
+		{name: "margins narrow the lines", text: "the quick brown fox jumps", opts: ParagraphOptions{Margin: 120}, expectedLines: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			bmp, err := RenderParagraph(tt.text, tt.opts)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedLines*one.Height, bmp.Height)
+		})
+	}
+}
+
+func TestRenderParagraph_Style(t *testing.T) {
+	// Arrange
+	regular, err := RenderParagraph("Receipt", ParagraphOptions{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		opts ParagraphOptions
+	}{
+		{name: "bold", opts: ParagraphOptions{Bold: true}},
+		{name: "italic", opts: ParagraphOptions{Italic: true}},
+		{name: "mono", opts: ParagraphOptions{Mono: true}},
+		{name: "larger", opts: ParagraphOptions{Size: 32}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			bmp, err := RenderParagraph("Receipt", tt.opts)
+
+			// Assert
+			require.NoError(t, err)
+			assert.NotEqual(t, regular.Pix, bmp.Pix)
+		})
+	}
+}
+
+func TestRenderParagraph_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ParagraphOptions
+	}{
+		{name: "unknown alignment", opts: ParagraphOptions{Align: "justify"}},
+		{name: "margins too wide", opts: ParagraphOptions{Margin: HeadWidth / 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := RenderParagraph("x", tt.opts)
+			assert.Error(t, err)
+		})
+	}
+}
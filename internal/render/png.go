@@ -0,0 +1,33 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// bitmapPalette maps bit values to colours: index 0 is paper, 1 is a burned dot.
+var bitmapPalette = color.Palette{color.White, color.Black}
+
+// Paletted converts the bitmap to a two-colour paletted image.
+func (b *Bitmap) Paletted() *image.Paletted {
+	img := image.NewPaletted(image.Rect(0, 0, b.Width, b.Height), bitmapPalette)
+	for y := 0; y < b.Height; y++ {
+		for x := 0; x < b.Width; x++ {
+			if b.Black(x, y) {
+				img.Pix[y*img.Stride+x] = 1
+			}
+		}
+	}
+	return img
+}
+
+// EncodePNG writes the bitmap as a 1-bit PNG.
+func EncodePNG(w io.Writer, b *Bitmap) error {
+	if b.Width == 0 || b.Height == 0 {
+		return fmt.Errorf("cannot encode an empty %dx%d bitmap", b.Width, b.Height)
+	}
+	return png.Encode(w, b.Paletted())
+}
@@ -0,0 +1,46 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodePNG_RoundTrip(t *testing.T) {
+	// Arrange
+	bmp := NewBitmap(HeadWidth, 3)
+	bmp.Set(0, 0, true)
+	bmp.Set(383, 2, true)
+
+	// Act
+	var buf bytes.Buffer
+	err := EncodePNG(&buf, bmp)
+
+	// Assert
+	require.NoError(t, err)
+	decoded, err := png.Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, HeadWidth, 3), decoded.Bounds())
+
+	for y := 0; y < bmp.Height; y++ {
+		for x := 0; x < bmp.Width; x++ {
+			r, _, _, _ := decoded.At(x, y).RGBA()
+			assert.Equal(t, bmp.Black(x, y), r == 0, "pixel %d,%d", x, y)
+		}
+	}
+}
+
+func TestEncodePNG_EmptyBitmap(t *testing.T) {
+	err := EncodePNG(&bytes.Buffer{}, NewBitmap(HeadWidth, 0))
+
+	assert.Error(t, err)
+}
+
+func TestLengthMM(t *testing.T) {
+	assert.Equal(t, 0.0, LengthMM(0))
+	assert.Equal(t, 12.5, LengthMM(100))
+}
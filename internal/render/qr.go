@@ -0,0 +1,109 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/princem/peripage-printer/internal/qr"
+)
+
+// QRQuietZone is the light border around a QR code, in modules, that the
+// QR standard asks scanners to be given.
+const QRQuietZone = 4
+
+// QROptions controls how a QR code is drawn.
+// Zero values are replaced by the defaults from DefaultQROptions.
+type QROptions struct {
+	// Width is the bitmap width in dots. The code is centred in it.
+	Width int
+	// Margin is blank space in dots kept on the left and right edges.
+	Margin int
+	// Scale is the size of one module in dots. Zero picks the largest scale
+	// at which the code and its quiet zone fit between the margins.
+	Scale int
+	// Caption is printed centred under the code.
+	Caption string
+	// Size is the caption font size in pixels.
+	Size float64
+}
+
+// DefaultQROptions returns the layout used for QR code print jobs: as large
+// as fits the head, with a 20px Go Regular caption.
+func DefaultQROptions() QROptions {
+	return QROptions{
+		Width: HeadWidth,
+		Size:  20,
+	}
+}
+
+func (o QROptions) withDefaults() QROptions {
+	d := DefaultQROptions()
+	if o.Width <= 0 {
+		o.Width = d.Width
+	}
+	if o.Size <= 0 {
+		o.Size = d.Size
+	}
+	return o
+}
+
+// MaxQRScale returns the largest module size in dots at which a code of
+// size modules fits width dots together with its quiet zone.
+func MaxQRScale(size, width int) int {
+	return width / (size + 2*QRQuietZone)
+}
+
+// RenderQR draws a QR code at a whole number of dots per module, so every
+// module is the same size, surrounded by its quiet zone and followed by an
+// optional caption.
+func RenderQR(code *qr.Code, opts QROptions) (*Bitmap, error) {
+	opts = opts.withDefaults()
+
+	avail := opts.Width - 2*opts.Margin
+	fit := MaxQRScale(code.Size, avail)
+	if fit < 1 {
+		return nil, fmt.Errorf("a version %d QR code does not fit in %d dots", code.Version, avail)
+	}
+	scale := opts.Scale
+	if scale <= 0 {
+		scale = fit
+	}
+	if scale > fit {
+		return nil, fmt.Errorf("scale %d is too large for a version %d QR code: at most %d fits", scale, code.Version, fit)
+	}
+
+	side := (code.Size + 2*QRQuietZone) * scale
+	bmp := NewBitmap(opts.Width, side)
+	left := opts.Margin + (avail-side)/2 + QRQuietZone*scale
+	top := QRQuietZone * scale
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if !code.Black(x, y) {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					bmp.Set(left+x*scale+dx, top+y*scale+dy, true)
+				}
+			}
+		}
+	}
+
+	if strings.TrimSpace(opts.Caption) == "" {
+		return bmp, nil
+	}
+	caption, err := renderCaption(opts.Caption, Regular, opts.Size, opts.Width, opts.Margin)
+	if err != nil {
+		return nil, err
+	}
+	return Stack(bmp, caption), nil
+}
+
+// renderCaption lays out a short text centred between the margins,
+// wrapping it at spaces when it is too long for one line.
+func renderCaption(text string, family Family, size float64, width, margin int) (*Bitmap, error) {
+	faces := newFaceCache()
+	defer faces.close()
+	text = strings.Join(strings.Fields(text), " ")
+	return layoutParagraph(text, family, size, width, margin, AlignCenter, faces)
+}
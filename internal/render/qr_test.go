@@ -0,0 +1,114 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/princem/peripage-printer/internal/qr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderQR_Scale(t *testing.T) {
+	// Version 1 is 21 modules, 29 with the quiet zone; version 40 is 177 and 185.
+	small, err := qr.Encode("hi", qr.Low)
+	require.NoError(t, err)
+	large, err := qr.Encode(strings.Repeat("a", 2900), qr.Low)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		code          *qr.Code
+		opts          QROptions
+		expectedScale int
+	}{
+		{name: "largest that fits", code: small, opts: QROptions{}, expectedScale: 13},
+		{name: "explicit scale", code: small, opts: QROptions{Scale: 4}, expectedScale: 4},
+		{name: "margins shrink the fit", code: small, opts: QROptions{Margin: 48}, expectedScale: 9},
+		{name: "version 40 fits the head", code: large, opts: QROptions{}, expectedScale: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			bmp, err := RenderQR(tt.code, tt.opts)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, HeadWidth, bmp.Width)
+			assert.Equal(t, (tt.code.Size+2*QRQuietZone)*tt.expectedScale, bmp.Height)
+		})
+	}
+}
+
+func TestRenderQR_DrawsEveryModule(t *testing.T) {
+	// Arrange
+	code, err := qr.Encode("https://example.com", qr.Medium)
+	require.NoError(t, err)
+	scale := 5
+
+	// Act
+	bmp, err := RenderQR(code, QROptions{Scale: scale})
+
+	// Assert: the code is centred and every dot of a module matches it.
+	require.NoError(t, err)
+	side := (code.Size + 2*QRQuietZone) * scale
+	left := (HeadWidth-side)/2 + QRQuietZone*scale
+	top := QRQuietZone * scale
+	for y := -QRQuietZone; y < code.Size+QRQuietZone; y++ {
+		for x := -QRQuietZone; x < code.Size+QRQuietZone; x++ {
+			for d := 0; d < scale; d++ {
+				require.Equal(t, code.Black(x, y), bmp.Black(left+x*scale+d, top+y*scale+d), "module %d,%d", x, y)
+			}
+		}
+	}
+}
+
+func TestRenderQR_Caption(t *testing.T) {
+	// Arrange
+	code, err := qr.Encode("https://example.com", qr.Medium)
+	require.NoError(t, err)
+	plain, err := RenderQR(code, QROptions{Scale: 4})
+	require.NoError(t, err)
+
+	// Act
+	captioned, err := RenderQR(code, QROptions{Scale: 4, Caption: "Scan for the menu"})
+
+	// Assert: the caption is added below and centred.
+	require.NoError(t, err)
+	require.Greater(t, captioned.Height, plain.Height)
+	assert.Equal(t, plain.Pix, captioned.Top(plain.Height).Pix)
+
+	caption := NewBitmap(HeadWidth, captioned.Height-plain.Height)
+	caption.Draw(captioned, 0, -plain.Height)
+	left := leftmostInk(caption)
+	right := -1
+	for y := 0; y < caption.Height; y++ {
+		for x := HeadWidth - 1; x > right; x-- {
+			if caption.Black(x, y) {
+				right = x
+			}
+		}
+	}
+	assert.InDelta(t, HeadWidth-1-right, left, 4)
+}
+
+func TestRenderQR_Errors(t *testing.T) {
+	code, err := qr.Encode("hi", qr.Low)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		opts QROptions
+	}{
+		{name: "scale too large", opts: QROptions{Scale: 14}},
+		{name: "width too narrow", opts: QROptions{Width: 20}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := RenderQR(code, tt.opts)
+			assert.Error(t, err)
+		})
+	}
+}
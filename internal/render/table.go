@@ -0,0 +1,340 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Table geometry in dots.
+const (
+	// minColumnWidth is the narrowest column a table or row of columns
+	// may have.
+	minColumnWidth     = 2 * DotsPerMM
+	tableRuleGap       = 4
+	tableRuleThickness = 2
+)
+
+// ColumnSpec sets the width and alignment of one column. The zero spec
+// shares the width equally with the other columns and aligns left.
+type ColumnSpec struct {
+	// Width is a fixed width in dots.
+	Width int
+	// Ratio is the column's share of the width left over by fixed columns,
+	// relative to the other shared columns. Zero means 1. A column cannot
+	// set both Width and Ratio.
+	Ratio int
+	// Align places the text of the column's cells.
+	Align Align
+}
+
+// ParseColumnSpec parses a column written as a width and an alignment
+// separated by a colon, either of which may be left out: "3" takes three
+// shares of the width, "80px" is 80 dots wide, and "2:right", "80px:center"
+// or "right" also set the alignment.
+func ParseColumnSpec(s string) (ColumnSpec, error) {
+	var spec ColumnSpec
+	width, align, hasAlign := strings.Cut(s, ":")
+	if !hasAlign {
+		if _, err := ParseAlign(width); err == nil {
+			width, align = "", width
+		}
+	}
+	if width != "" {
+		n, err := strconv.Atoi(strings.TrimSuffix(width, "px"))
+		if err != nil || n <= 0 {
+			return ColumnSpec{}, fmt.Errorf("invalid column width %q: use a ratio such as 2 or dots such as 80px", width)
+		}
+		if strings.HasSuffix(width, "px") {
+			spec.Width = n
+		} else {
+			spec.Ratio = n
+		}
+	}
+	a, err := ParseAlign(align)
+	if err != nil {
+		return ColumnSpec{}, err
+	}
+	spec.Align = a
+	return spec, nil
+}
+
+// TableOptions controls how a table is laid out.
+// Zero values are replaced by the defaults from DefaultTableOptions.
+type TableOptions struct {
+	// Width is the bitmap width in dots.
+	Width int
+	// Margin is blank space in dots kept on the left and right edges.
+	Margin int
+	// Size is the font size in pixels.
+	Size float64
+	// Gap is the space in dots between columns. Borders replace it with
+	// a line.
+	Gap int
+	// Columns sets the width and alignment of each column. Columns past
+	// the end share the width equally and align left.
+	Columns []ColumnSpec
+	// Padding is blank space in dots around the text of every cell.
+	Padding int
+	// Borders draws lines around every cell. Without borders, only a rule
+	// under the header separates rows.
+	Borders bool
+}
+
+// DefaultTableOptions returns 20px Go Regular across the full head with
+// 1mm between columns.
+func DefaultTableOptions() TableOptions {
+	return TableOptions{
+		Width: HeadWidth,
+		Size:  20,
+		Gap:   DotsPerMM,
+	}
+}
+
+func (o TableOptions) withDefaults() TableOptions {
+	d := DefaultTableOptions()
+	if o.Width <= 0 {
+		o.Width = d.Width
+	}
+	if o.Size <= 0 {
+		o.Size = d.Size
+	}
+	if o.Gap <= 0 {
+		o.Gap = d.Gap
+	}
+	return o
+}
+
+// gaps returns the space between columns and the space kept outside the
+// first and last column, which are the border lines when there are
+// borders.
+func (o TableOptions) gaps() (gap, edge int) {
+	if o.Borders {
+		return tableRuleThickness, tableRuleThickness
+	}
+	return o.Gap, 0
+}
+
+// ColumnWidths returns the widths in dots of the n columns of a table laid
+// out with the options, including their padding. It fails when the columns
+// or their padding do not fit.
+func (o TableOptions) ColumnWidths(n int) ([]int, error) {
+	o = o.withDefaults()
+	if o.Padding < 0 {
+		return nil, fmt.Errorf("cell padding must not be negative, got %d", o.Padding)
+	}
+	specs := make([]ColumnSpec, n)
+	copy(specs, o.Columns)
+	gap, edge := o.gaps()
+	widths, err := ResolveColumns(o.Width-2*o.Margin-2*edge, gap, specs)
+	if err != nil {
+		return nil, err
+	}
+	for i, w := range widths {
+		if w-2*o.Padding <= 0 {
+			return nil, fmt.Errorf("padding %d leaves no room for text in column %d", o.Padding, i+1)
+		}
+	}
+	return widths, nil
+}
+
+// RenderTable lays out rows of cells in columns. Cells wrap within their
+// column and a row is as tall as its tallest cell. An optional header row
+// is printed in bold above a rule. The table has as many columns as its
+// longest row or its column specs; shorter rows leave the last cells empty.
+func RenderTable(header []string, rows [][]string, opts TableOptions) (*Bitmap, error) {
+	opts = opts.withDefaults()
+
+	columns := max(len(header), len(opts.Columns))
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	if columns == 0 || len(header) == 0 && len(rows) == 0 {
+		return NewBitmap(opts.Width, 0), nil
+	}
+	widths, err := opts.ColumnWidths(columns)
+	if err != nil {
+		return nil, err
+	}
+	aligns := make([]Align, columns)
+	for i := range aligns {
+		if i < len(opts.Columns) {
+			if aligns[i], err = ParseAlign(string(opts.Columns[i].Align)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	faces := newFaceCache()
+	defer faces.close()
+	t := &tableLayout{opts: opts, widths: widths, aligns: aligns, faces: faces}
+
+	var parts []*Bitmap
+	if opts.Borders {
+		parts = append(parts, t.rule(0))
+	}
+	if len(header) > 0 {
+		bmp, err := t.row(header, Bold)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Borders {
+			parts = append(parts, bmp, t.rule(0))
+		} else {
+			parts = append(parts, bmp, t.rule(tableRuleGap))
+		}
+	}
+	for _, row := range rows {
+		bmp, err := t.row(row, Regular)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, bmp)
+		if opts.Borders {
+			parts = append(parts, t.rule(0))
+		}
+	}
+	return Stack(parts...), nil
+}
+
+// tableLayout holds the column geometry of a table being rendered.
+type tableLayout struct {
+	opts   TableOptions
+	widths []int
+	aligns []Align
+	faces  *faceCache
+}
+
+// right returns the x just past the right edge of the table, which may be
+// narrower than the margins when all columns have a fixed width.
+func (t *tableLayout) right() int {
+	gap, edge := t.opts.gaps()
+	x := t.opts.Margin + 2*edge + (len(t.widths)-1)*gap
+	for _, w := range t.widths {
+		x += w
+	}
+	return x
+}
+
+// rule draws a line across the table with space dots above and below it.
+func (t *tableLayout) rule(space int) *Bitmap {
+	bmp := NewBitmap(t.opts.Width, 2*space+tableRuleThickness)
+	fillRect(bmp, t.opts.Margin, space, t.right(), space+tableRuleThickness)
+	return bmp
+}
+
+// row lays out the cells of one row side by side, with the border lines
+// between them when the table has borders.
+func (t *tableLayout) row(cells []string, family Family) (*Bitmap, error) {
+	pad := t.opts.Padding
+	bitmaps := make([]*Bitmap, len(cells))
+	for i, cell := range cells {
+		text, err := layoutParagraph(cell, family, t.opts.Size, t.widths[i]-2*pad, 0, t.aligns[i], t.faces)
+		if err != nil {
+			return nil, err
+		}
+		bitmaps[i] = NewBitmap(t.widths[i], text.Height+2*pad)
+		bitmaps[i].Draw(text, pad, pad)
+	}
+	if len(cells) == 0 {
+		bitmaps = []*Bitmap{NewBitmap(t.widths[0], 2*pad)}
+	}
+
+	gap, edge := t.opts.gaps()
+	out := Beside(t.opts.Width, t.opts.Margin+edge, gap, t.widths, bitmaps)
+	if t.opts.Borders {
+		x := t.opts.Margin
+		fillRect(out, x, 0, x+edge, out.Height)
+		x += edge
+		for _, w := range t.widths {
+			x += w
+			fillRect(out, x, 0, x+gap, out.Height)
+			x += gap
+		}
+	}
+	return out, nil
+}
+
+// fillRect blackens the dots from (x0, y0) up to but not including
+// (x1, y1).
+func fillRect(b *Bitmap, x0, y0, x1, y1 int) {
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			b.Set(x, y, true)
+		}
+	}
+}
+
+// ResolveColumns splits width dots into columns with gap dots between
+// them. Columns with a fixed width get it and the others share what is
+// left by their ratios; dots left over from the division go to the first
+// shared columns. Every column must be at least 2mm wide.
+func ResolveColumns(width, gap int, specs []ColumnSpec) ([]int, error) {
+	n := len(specs)
+	if n == 0 {
+		return nil, fmt.Errorf("at least one column is needed")
+	}
+
+	rest, shares := width-(n-1)*gap, 0
+	for i, s := range specs {
+		switch {
+		case s.Width < 0 || s.Ratio < 0:
+			return nil, fmt.Errorf("column %d: width and ratio must not be negative", i+1)
+		case s.Width > 0 && s.Ratio > 0:
+			return nil, fmt.Errorf("column %d: set either a width or a ratio, not both", i+1)
+		case s.Width > 0 && s.Width < minColumnWidth:
+			return nil, fmt.Errorf("column %d: width must be at least %d dots", i+1, minColumnWidth)
+		case s.Width > 0:
+			rest -= s.Width
+		default:
+			shares += max(s.Ratio, 1)
+		}
+	}
+	if rest < 0 {
+		return nil, fmt.Errorf("%d columns do not fit in %d dots", n, width)
+	}
+
+	widths := make([]int, n)
+	left := rest
+	for i, s := range specs {
+		if s.Width > 0 {
+			widths[i] = s.Width
+			continue
+		}
+		widths[i] = rest * max(s.Ratio, 1) / shares
+		left -= widths[i]
+	}
+	for i, s := range specs {
+		if left > 0 && s.Width == 0 {
+			widths[i]++
+			left--
+		}
+		if widths[i] < minColumnWidth {
+			return nil, fmt.Errorf("%d columns do not fit in %d dots", n, width)
+		}
+	}
+	return widths, nil
+}
+
+// Beside places parts side by side, left to right, in columns of the given
+// widths with gap dots between them, starting margin dots from the left of
+// a bitmap width dots wide. It is as tall as the tallest part. Nil parts
+// leave their column empty.
+func Beside(width, margin, gap int, widths []int, parts []*Bitmap) *Bitmap {
+	height := 0
+	for _, p := range parts {
+		if p != nil {
+			height = max(height, p.Height)
+		}
+	}
+
+	out := NewBitmap(width, height)
+	x := margin
+	for i, w := range widths {
+		if i < len(parts) && parts[i] != nil {
+			out.Draw(parts[i], x, 0)
+		}
+		x += w + gap
+	}
+	return out
+}
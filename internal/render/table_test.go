@@ -0,0 +1,236 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseColumnSpec(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ColumnSpec
+		wantErr  bool
+	}{
+		{input: "", expected: ColumnSpec{Align: AlignLeft}},
+		{input: "3", expected: ColumnSpec{Ratio: 3, Align: AlignLeft}},
+		{input: "80px", expected: ColumnSpec{Width: 80, Align: AlignLeft}},
+		{input: "2:right", expected: ColumnSpec{Ratio: 2, Align: AlignRight}},
+		{input: "80px:center", expected: ColumnSpec{Width: 80, Align: AlignCenter}},
+		{input: "right", expected: ColumnSpec{Align: AlignRight}},
+		{input: ":center", expected: ColumnSpec{Align: AlignCenter}},
+		{input: "0", wantErr: true},
+		{input: "-2", wantErr: true},
+		{input: "wide", wantErr: true},
+		{input: "2:middle", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			// Act
+			spec, err := ParseColumnSpec(tt.input)
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, spec)
+		})
+	}
+}
+
+func TestResolveColumns(t *testing.T) {
+	tests := []struct {
+		name     string
+		width    int
+		gap      int
+		specs    []ColumnSpec
+		expected []int
+		wantErr  bool
+	}{
+		{name: "ratios", width: 408, gap: 8, specs: []ColumnSpec{{Ratio: 3}, {}}, expected: []int{300, 100}},
+		{name: "fixed and shared", width: 384, gap: 8, specs: []ColumnSpec{{}, {Width: 80}}, expected: []int{296, 80}},
+		{name: "leftover dots go to shared columns", width: 384, gap: 0, specs: []ColumnSpec{{Width: 100}, {Ratio: 2}, {Ratio: 1}}, expected: []int{100, 190, 94}},
+		{name: "all fixed leaves the rest empty", width: 384, gap: 8, specs: []ColumnSpec{{Width: 100}, {Width: 100}}, expected: []int{100, 100}},
+		{name: "fixed columns too wide", width: 384, gap: 8, specs: []ColumnSpec{{Width: 300}, {Width: 100}}, wantErr: true},
+		{name: "no room left to share", width: 384, gap: 8, specs: []ColumnSpec{{Width: 370}, {}}, wantErr: true},
+		{name: "ratio too small", width: 384, gap: 0, specs: []ColumnSpec{{Ratio: 100}, {}}, wantErr: true},
+		{name: "fixed column too narrow", width: 384, specs: []ColumnSpec{{Width: 4}}, wantErr: true},
+		{name: "width and ratio", width: 384, specs: []ColumnSpec{{Width: 80, Ratio: 2}}, wantErr: true},
+		{name: "negative", width: 384, specs: []ColumnSpec{{Ratio: -1}}, wantErr: true},
+		{name: "no columns", width: 384, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			widths, err := ResolveColumns(tt.width, tt.gap, tt.specs)
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, widths)
+		})
+	}
+}
+
+func TestResolveColumns_Even(t *testing.T) {
+	tests := []struct {
+		name     string
+		width    int
+		n        int
+		gap      int
+		expected []int
+		wantErr  bool
+	}{
+		{name: "one column", width: 384, n: 1, gap: 8, expected: []int{384}},
+		{name: "even split", width: 384, n: 2, gap: 8, expected: []int{188, 188}},
+		{name: "leftover dots go first", width: 384, n: 3, gap: 8, expected: []int{123, 123, 122}},
+		{name: "no columns", width: 384, n: 0, wantErr: true},
+		{name: "too many columns", width: 40, n: 5, gap: 8, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			widths, err := ResolveColumns(tt.width, tt.gap, make([]ColumnSpec, tt.n))
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, widths)
+		})
+	}
+}
+
+func TestRenderTable(t *testing.T) {
+	// Arrange
+	line, err := RenderParagraph("x", ParagraphOptions{})
+	require.NoError(t, err)
+	ruleHeight := 2*tableRuleGap + tableRuleThickness
+	tall, err := RenderParagraph("Earl Grey tea with lemon and honey", ParagraphOptions{Width: (HeadWidth - 3*DotsPerMM) / 4})
+	require.NoError(t, err)
+	require.Greater(t, tall.Height, line.Height)
+
+	tests := []struct {
+		name           string
+		header         []string
+		rows           [][]string
+		expectedHeight int
+	}{
+		{name: "rows", rows: [][]string{{"Tea", "2", "5.00"}, {"Cake", "1", "3.50"}}, expectedHeight: 2 * line.Height},
+		{name: "header and rule", header: []string{"Item", "Qty"}, rows: [][]string{{"Tea", "2"}}, expectedHeight: 2*line.Height + ruleHeight},
+		{name: "a row is as tall as its tallest cell", rows: [][]string{{"Earl Grey tea with lemon and honey", "2", "5.00", "x"}}, expectedHeight: tall.Height},
+		{name: "short rows", rows: [][]string{{"Subtotal", "", "8.50"}, {"Thank you"}}, expectedHeight: 2 * line.Height},
+		{name: "empty", expectedHeight: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			bmp, err := RenderTable(tt.header, tt.rows, TableOptions{})
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, HeadWidth, bmp.Width)
+			assert.Equal(t, tt.expectedHeight, bmp.Height)
+		})
+	}
+}
+
+func TestRenderTable_Columns(t *testing.T) {
+	// Act
+	bmp, err := RenderTable(nil, [][]string{{"", "x"}}, TableOptions{Gap: 8})
+
+	// Assert: the second cell starts at the second of two equal columns.
+	require.NoError(t, err)
+	assert.InDelta(t, 188+8, leftmostInk(bmp), 3)
+}
+
+func TestRenderTable_ColumnSpecs(t *testing.T) {
+	// Act
+	bmp, err := RenderTable(nil, [][]string{{"Tea", "5.00"}}, TableOptions{
+		Gap:     8,
+		Columns: []ColumnSpec{{}, {Width: 80, Align: AlignRight}},
+	})
+
+	// Assert: the price is pushed against the right edge of its fixed column.
+	require.NoError(t, err)
+	assert.InDelta(t, HeadWidth-1, rightmostInk(bmp), 3)
+	assert.Less(t, leftmostInk(bmp), 4)
+}
+
+func TestRenderTable_Padding(t *testing.T) {
+	// Arrange
+	plain, err := RenderTable(nil, [][]string{{"Tea", "5.00"}}, TableOptions{})
+	require.NoError(t, err)
+
+	// Act
+	bmp, err := RenderTable(nil, [][]string{{"Tea", "5.00"}}, TableOptions{Padding: 6})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, plain.Height+12, bmp.Height)
+	assert.Equal(t, leftmostInk(plain)+6, leftmostInk(bmp))
+}
+
+func TestRenderTable_Borders(t *testing.T) {
+	// Arrange
+	plain, err := RenderTable([]string{"Item", "Price"}, [][]string{{"Tea", "5.00"}}, TableOptions{})
+	require.NoError(t, err)
+	ruleHeight := 2*tableRuleGap + tableRuleThickness
+
+	// Act
+	bmp, err := RenderTable([]string{"Item", "Price"}, [][]string{{"Tea", "5.00"}}, TableOptions{Borders: true, Margin: 8})
+
+	// Assert: three rules frame the header and the row.
+	require.NoError(t, err)
+	assert.Equal(t, plain.Height-ruleHeight+3*tableRuleThickness, bmp.Height)
+	for y := 0; y < bmp.Height; y++ {
+		assert.True(t, bmp.Black(8, y), "left border at line %d", y)
+		assert.True(t, bmp.Black(HeadWidth-9, y), "right border at line %d", y)
+		assert.False(t, bmp.Black(7, y), "margin at line %d", y)
+	}
+	for x := 8; x < HeadWidth-8; x++ {
+		assert.True(t, bmp.Black(x, 0), "top border at dot %d", x)
+		assert.True(t, bmp.Black(x, bmp.Height-1), "bottom border at dot %d", x)
+	}
+	middle := 8 + tableRuleThickness + (HeadWidth-16-3*tableRuleThickness+1)/2
+	assert.True(t, bmp.Black(middle, bmp.Height/2))
+}
+
+func TestRenderTable_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts TableOptions
+	}{
+		{name: "padding fills the column", opts: TableOptions{Padding: 100, Columns: []ColumnSpec{{}, {Width: 100}}}},
+		{name: "negative padding", opts: TableOptions{Padding: -1}},
+		{name: "unknown alignment", opts: TableOptions{Columns: []ColumnSpec{{Align: "middle"}}}},
+		{name: "columns too wide", opts: TableOptions{Columns: []ColumnSpec{{Width: 300}, {Width: 300}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := RenderTable(nil, [][]string{{"Tea", "5.00"}}, tt.opts)
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRenderTable_TooManyColumns(t *testing.T) {
+	_, err := RenderTable(nil, [][]string{make([]string, 60)}, TableOptions{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,220 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// TextOptions controls how plain text is laid out.
+// Zero values are replaced by the defaults from DefaultTextOptions.
+type TextOptions struct {
+	// Width is the bitmap width in dots.
+	Width int
+	// Family is the font used for the whole text.
+	Family Family
+	// Size is the font size in pixels.
+	Size float64
+	// LineSpacing is extra space in dots added below every line.
+	LineSpacing int
+	// TabWidth is the distance between tab stops, measured in spaces.
+	TabWidth int
+	// Margin is blank space in dots kept on the left and right edges.
+	Margin int
+}
+
+// DefaultTextOptions returns the layout used for plain text print jobs:
+// 20px Go Mono across the full head width, which fits 32 characters per line.
+func DefaultTextOptions() TextOptions {
+	return TextOptions{
+		Width:    HeadWidth,
+		Family:   Mono,
+		Size:     20,
+		TabWidth: 4,
+	}
+}
+
+func (o TextOptions) withDefaults() TextOptions {
+	d := DefaultTextOptions()
+	if o.Width <= 0 {
+		o.Width = d.Width
+	}
+	if o.Size <= 0 {
+		o.Size = d.Size
+	}
+	if o.TabWidth <= 0 {
+		o.TabWidth = d.TabWidth
+	}
+	return o
+}
+
+// segment is a run of text drawn at a fixed horizontal offset within a line.
+type segment struct {
+	x    int
+	text string
+}
+
+// line is one row of laid-out text.
+type line []segment
+
+// RenderText lays out text and rasterizes it into a bitmap.
+// Lines are wrapped at word boundaries to fit the width, words longer than a
+// full line are broken, "\n" starts a new line and "\t" advances to the next
+// tab stop. The bitmap height is derived from the number of lines.
+func RenderText(text string, opts TextOptions) (*Bitmap, error) {
+	opts = opts.withDefaults()
+
+	avail := opts.Width - 2*opts.Margin
+	if avail <= 0 {
+		return nil, fmt.Errorf("margin %d leaves no room for text in width %d", opts.Margin, opts.Width)
+	}
+
+	face, err := newFace(opts.Family, opts.Size)
+	if err != nil {
+		return nil, err
+	}
+	defer face.Close()
+
+	lines := wrapText(face, text, avail, opts.TabWidth)
+	if len(lines) == 0 {
+		return NewBitmap(opts.Width, 0), nil
+	}
+
+	metrics := face.Metrics()
+	lineHeight := metrics.Height.Ceil() + opts.LineSpacing
+	ascent := metrics.Ascent.Ceil()
+
+	canvas := image.NewAlpha(image.Rect(0, 0, opts.Width, len(lines)*lineHeight))
+	drawer := font.Drawer{Dst: canvas, Src: image.Opaque, Face: face}
+	for i, l := range lines {
+		for _, seg := range l {
+			drawer.Dot = fixed.P(opts.Margin+seg.x, i*lineHeight+ascent)
+			drawer.DrawString(seg.text)
+		}
+	}
+
+	bmp := NewBitmap(opts.Width, canvas.Bounds().Dy())
+	bmp.thresholdAlpha(canvas)
+	return bmp, nil
+}
+
+// wrapText splits text into paragraphs on newlines and wraps each one.
+// Empty text produces no lines at all.
+func wrapText(face font.Face, text string, width, tabWidth int) []line {
+	if text == "" {
+		return nil
+	}
+
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	var lines []line
+	for _, para := range strings.Split(text, "\n") {
+		lines = append(lines, wrapParagraph(face, para, width, tabWidth)...)
+	}
+	return lines
+}
+
+// wrapParagraph greedily fills lines of at most width dots.
+// Whitespace at the start of a soft-wrapped line is dropped so wrapped text
+// stays flush left, while indentation at the start of a paragraph is kept.
+func wrapParagraph(face font.Face, para string, width, tabWidth int) []line {
+	spaceAdv := measure(face, " ")
+	tabStop := spaceAdv * tabWidth
+	if tabStop <= 0 {
+		tabStop = 1
+	}
+
+	var (
+		lines   []line
+		cur     line
+		x       int
+		wrapped bool
+	)
+	newline := func() {
+		lines = append(lines, cur)
+		cur = nil
+		x = 0
+		wrapped = true
+	}
+
+	for _, tok := range tokenize(para) {
+		switch tok {
+		case " ", "\t":
+			if x == 0 && wrapped {
+				continue
+			}
+			next := x + spaceAdv
+			if tok == "\t" {
+				next = (x/tabStop + 1) * tabStop
+			}
+			if next > width {
+				newline()
+				continue
+			}
+			x = next
+
+		default:
+			w := measure(face, tok)
+			if x > 0 && x+w > width {
+				newline()
+			}
+			for w > width {
+				head, rest := splitToFit(face, tok, width)
+				cur = append(cur, segment{x: x, text: head})
+				newline()
+				tok = rest
+				w = measure(face, tok)
+			}
+			cur = append(cur, segment{x: x, text: tok})
+			x += w
+		}
+	}
+
+	return append(lines, cur)
+}
+
+// tokenize splits s into words, single spaces and single tabs.
+func tokenize(s string) []string {
+	var tokens []string
+	start := -1
+	for i, r := range s {
+		if r == ' ' || r == '\t' {
+			if start >= 0 {
+				tokens = append(tokens, s[start:i])
+				start = -1
+			}
+			tokens = append(tokens, string(r))
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, s[start:])
+	}
+	return tokens
+}
+
+// splitToFit returns the longest prefix of word that fits in width dots and
+// the remainder. At least one rune is always taken so the caller makes progress.
+func splitToFit(face font.Face, word string, width int) (string, string) {
+	end := 0
+	for i, r := range word {
+		next := i + len(string(r))
+		if end > 0 && measure(face, word[:next]) > width {
+			break
+		}
+		end = next
+	}
+	return word[:end], word[end:]
+}
+
+// measure returns the advance width of s in whole dots.
+func measure(face font.Face, s string) int {
+	return font.MeasureString(face, s).Ceil()
+}
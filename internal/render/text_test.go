@@ -0,0 +1,115 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lineHeightFor(t *testing.T, opts TextOptions) int {
+	t.Helper()
+	bmp, err := RenderText("X", opts)
+	require.NoError(t, err)
+	return bmp.Height
+}
+
+// leftmostInk returns the x of the first black column, or -1 if the bitmap is blank.
+func leftmostInk(bmp *Bitmap) int {
+	for x := 0; x < bmp.Width; x++ {
+		for y := 0; y < bmp.Height; y++ {
+			if bmp.Black(x, y) {
+				return x
+			}
+		}
+	}
+	return -1
+}
+
+func TestRenderText_Dimensions(t *testing.T) {
+	opts := DefaultTextOptions()
+	lh := lineHeightFor(t, opts)
+	require.Greater(t, lh, 0)
+
+	tests := []struct {
+		name          string
+		text          string
+		expectedLines int
+	}{
+		{name: "empty text has no lines", text: "", expectedLines: 0},
+		{name: "single line", text: "Hello, World!", expectedLines: 1},
+		{name: "explicit newlines", text: "Line 1\nLine 2\nLine 3", expectedLines: 3},
+		{name: "windows newlines", text: "Line 1\r\nLine 2", expectedLines: 2},
+		{name: "blank line is kept", text: "a\n\nb", expectedLines: 3},
+		{name: "long text wraps at word boundaries", text: strings.Repeat("word ", 20), expectedLines: 4},
+		{name: "overlong word is broken", text: strings.Repeat("x", 70), expectedLines: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			bmp, err := RenderText(tt.text, opts)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, HeadWidth, bmp.Width)
+			assert.Equal(t, 48, bmp.Stride)
+			assert.Equal(t, tt.expectedLines*lh, bmp.Height)
+			assert.Len(t, bmp.Pix, bmp.Stride*bmp.Height)
+		})
+	}
+}
+
+func TestRenderText_ProducesInk(t *testing.T) {
+	bmp, err := RenderText("Hello", TextOptions{})
+	require.NoError(t, err)
+
+	assert.GreaterOrEqual(t, leftmostInk(bmp), 0, "rendered text should contain black dots")
+}
+
+func TestRenderText_Tabs(t *testing.T) {
+	plain, err := RenderText("X", TextOptions{})
+	require.NoError(t, err)
+	tabbed, err := RenderText("\tX", TextOptions{})
+	require.NoError(t, err)
+
+	spaceAdv := 12 // Go Mono advance at 20px
+	assert.Equal(t, leftmostInk(plain)+4*spaceAdv, leftmostInk(tabbed))
+}
+
+func TestRenderText_WrappedLinesStayFlushLeft(t *testing.T) {
+	// 32 characters fill a line exactly, so the space after them wraps.
+	text := strings.Repeat("a", 32) + " b"
+	bmp, err := RenderText(text, TextOptions{})
+	require.NoError(t, err)
+
+	single, err := RenderText("b", TextOptions{})
+	require.NoError(t, err)
+
+	lh := single.Height
+	require.Equal(t, 2*lh, bmp.Height)
+
+	second := &Bitmap{Width: bmp.Width, Height: lh, Stride: bmp.Stride, Pix: bmp.Pix[lh*bmp.Stride:]}
+	assert.Equal(t, leftmostInk(single), leftmostInk(second))
+}
+
+func TestRenderText_Margin(t *testing.T) {
+	bmp, err := RenderText("X", TextOptions{Margin: 50})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, leftmostInk(bmp), 50)
+
+	_, err = RenderText("X", TextOptions{Margin: HeadWidth / 2})
+	assert.Error(t, err)
+}
+
+func TestRenderText_NeverDrawsOutsideWidth(t *testing.T) {
+	bmp, err := RenderText(strings.Repeat("W", 200), TextOptions{Width: 100})
+	require.NoError(t, err)
+
+	// Padding bits past the width in the last byte of every row must stay clear.
+	for y := 0; y < bmp.Height; y++ {
+		row := bmp.Row(y)
+		assert.Zero(t, row[len(row)-1]&0x0f, "row %d has ink past the width", y)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/princem/peripage-printer/internal/adapters/printer"
+	"github.com/princem/peripage-printer/internal/adapters/printer/tinygoble"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -28,9 +29,13 @@ func TestBLEPrinter_RealDevice_Discovery(t *testing.T) {
 	}
 
 	// Arrange
+	transport, err := tinygoble.NewTransport()
+	require.NoError(t, err, "Failed to enable Bluetooth adapter")
+
 	config := printer.BLEPrinterConfig{
 		DeviceName:  deviceName,
 		ScanTimeout: 30 * time.Second,
+		Transport:   transport,
 		Logger:      log.New(os.Stdout, "[BLE TEST] ", log.LstdFlags),
 	}
 
@@ -192,9 +197,15 @@ func setupRealBLEPrinter(t *testing.T) (*printer.BLEPrinter, error) {
 		deviceName = "Peripage"
 	}
 
+	transport, err := tinygoble.NewTransport()
+	if err != nil {
+		return nil, err
+	}
+
 	config := printer.BLEPrinterConfig{
 		DeviceName:  deviceName,
 		ScanTimeout: 30 * time.Second,
+		Transport:   transport,
 		Logger:      log.New(os.Stdout, "[BLE TEST] ", log.LstdFlags),
 	}
 